@@ -1,22 +1,26 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "recon-engine/docs"
+	"recon-engine/internal/bootstrap"
 	"recon-engine/internal/config"
+	"recon-engine/internal/export"
 	"recon-engine/internal/handler"
 	"recon-engine/internal/middleware"
 	"recon-engine/internal/repository"
 	"recon-engine/internal/service"
+	"recon-engine/internal/storage"
 	"recon-engine/pkg/logger"
+	"recon-engine/pkg/metrics"
+	"recon-engine/pkg/tracing"
 )
 
 // @title Transaction Reconciliation API
@@ -42,11 +46,20 @@ func main() {
 	}
 
 	// Initialize logger
-	logger.Init(cfg.App.LogLevel)
+	logger.Init(cfg.App.LogLevel, bootstrap.LoggerOptions(cfg.App)...)
 	logger.GetLogger().Info("Starting Transaction Reconciliation Service")
 
+	shutdownTracing, err := tracing.Init(context.Background(), bootstrap.TracingOptions(cfg.Tracing)...)
+	if err != nil {
+		logger.GetLogger().WithError(err).Fatal("Invalid tracing configuration")
+	}
+	defer shutdownTracing(context.Background())
+	if cfg.Tracing.Endpoint != "" {
+		logger.GetLogger().WithField("endpoint", cfg.Tracing.Endpoint).Info("Exporting traces over OTLP")
+	}
+
 	// Connect to database
-	db, err := connectDB(cfg.Database)
+	db, err := bootstrap.ConnectDB(cfg.Database)
 	if err != nil {
 		logger.GetLogger().WithError(err).Fatal("Failed to connect to database")
 	}
@@ -54,20 +67,75 @@ func main() {
 
 	logger.GetLogger().Info("Database connection established")
 
+	if cfg.Database.AutoMigrate {
+		applied, err := bootstrap.Migrate(db)
+		if err != nil {
+			logger.GetLogger().WithError(err).Fatal("Failed to apply migrations")
+		}
+		logger.GetLogger().WithField("applied", len(applied)).Info("Database schema is up to date")
+	}
+
 	// Initialize repositories
-	txRepo := repository.NewTransactionRepository(db)
-	reconRepo := repository.NewReconciliationRepository(db)
+	repoOpts := bootstrap.RepositoryOptions(cfg)
+	txRepo := repository.NewTransactionRepository(db, repoOpts...)
+	reconRepo := repository.NewReconciliationRepository(db, repoOpts...)
+	bankRepo := repository.NewBankStatementRepository(db, repoOpts...)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+
+	reconOpts, err := bootstrap.ReconciliationOptions(cfg)
+	if err != nil {
+		logger.GetLogger().WithError(err).Fatal("Invalid configuration")
+	}
+
+	jobMetrics := metrics.Prometheus
+	if cfg.Metrics.StatsDAddr != "" {
+		statsd, err := metrics.DialStatsD(cfg.Metrics.StatsDAddr,
+			metrics.WithStatsDPrefix(cfg.Metrics.StatsDPrefix),
+			metrics.WithStatsDTags(cfg.Metrics.StatsDTags),
+		)
+		if err != nil {
+			logger.GetLogger().WithError(err).Fatal("Invalid metrics configuration")
+		}
+		defer statsd.Close()
+		jobMetrics = metrics.Recorders(metrics.Prometheus, statsd)
+		logger.GetLogger().WithField("addr", cfg.Metrics.StatsDAddr).Info("Sending job metrics to StatsD")
+	}
 
 	// Initialize services
 	txService := service.NewTransactionService(txRepo)
-	reconService := service.NewReconciliationService(txRepo, reconRepo, cfg.App.BatchSize)
+	bankStatementService := service.NewBankStatementService(bankRepo)
+	attachmentService := service.NewAttachmentService(attachmentRepo, storage.NewLocalFileStore(cfg.App.AttachmentDir))
+	reconService := service.NewReconciliationService(
+		txRepo,
+		reconRepo,
+		cfg.App.BatchSize,
+		append(reconOpts,
+			service.WithBankStatementRepository(bankRepo),
+			service.WithBankStatementPersistence(cfg.App.PersistBankStatements),
+			service.WithMetricsRecorder(jobMetrics),
+			service.WithIdempotencyStore(idempotencyRepo, cfg.App.IdempotencyKeyTTL),
+		)...,
+	)
 
 	// Initialize handlers
+	healthHandler := handler.NewHealthHandler(db, cfg.Server.HealthCheckTimeout)
 	txHandler := handler.NewTransactionHandler(txService)
-	reconHandler := handler.NewReconciliationHandler(reconService)
+	bankStatementHandler := handler.NewBankStatementHandler(bankStatementService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, cfg.Server.MaxUploadSize)
+	reconHandler := handler.NewReconciliationHandler(
+		reconService,
+		handler.WithMaxUploadSize(cfg.Server.MaxUploadSize),
+		handler.WithJournalAccounts(export.JournalAccounts{
+			Bank:     cfg.App.JournalBankAccount,
+			Clearing: cfg.App.JournalClearingAccount,
+			Suspense: cfg.App.JournalSuspenseAccount,
+		}),
+		handler.WithAmountScales(bootstrap.ExportAmountScales(cfg.Matcher)),
+	)
 
 	// Setup router
-	router := setupRouter(txHandler, reconHandler)
+	router := setupRouter(healthHandler, txHandler, bankStatementHandler, reconHandler, attachmentHandler, reconcileRateLimit(cfg.App), middleware.Timeout(cfg.Server.RequestTimeout))
 
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
@@ -78,42 +146,46 @@ func main() {
 	}
 }
 
-func connectDB(cfg config.DatabaseConfig) (*sql.DB, error) {
-	db, err := sql.Open("postgres", cfg.ConnectionString())
-	if err != nil {
-		return nil, err
+// reconcileRateLimit limits the requests that start reconciliation jobs;
+// it lets everything through when RECONCILE_RATE_LIMIT is 0
+func reconcileRateLimit(cfg config.AppConfig) gin.HandlerFunc {
+	var limiter *middleware.RateLimiter
+	if cfg.ReconcileRateLimit > 0 {
+		limiter = middleware.NewRateLimiter(cfg.ReconcileRateLimit, cfg.ReconcileRateBurst)
 	}
-
-	if err := db.Ping(); err != nil {
-		return nil, err
+	key := middleware.ClientIPKey
+	if cfg.ReconcileRateLimitBy == "api_key" {
+		key = middleware.APIKeyKey
 	}
-
-	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-
-	return db, nil
+	return middleware.RateLimit(limiter, key)
 }
 
-func setupRouter(txHandler *handler.TransactionHandler, reconHandler *handler.ReconciliationHandler) *gin.Engine {
+func setupRouter(healthHandler *handler.HealthHandler, txHandler *handler.TransactionHandler, bankStatementHandler *handler.BankStatementHandler, reconHandler *handler.ReconciliationHandler, attachmentHandler *handler.AttachmentHandler, reconcileLimit, apiTimeout gin.HandlerFunc) *gin.Engine {
 	router := gin.New()
 
 	// Global middleware
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Tracing())
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Logger())
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Metrics())
 	router.Use(gin.Recovery())
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
+	// Health checks: /livez never touches the database; /health and
+	// /readyz fail with 503 while it is unreachable
+	router.GET("/livez", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/health", healthHandler.Ready)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", apiTimeout)
 	{
 		// Transaction routes
 		transactions := v1.Group("/transactions")
@@ -121,15 +193,40 @@ func setupRouter(txHandler *handler.TransactionHandler, reconHandler *handler.Re
 			transactions.POST("", txHandler.CreateTransaction)
 			transactions.POST("/bulk", txHandler.BulkCreateTransactions)
 			transactions.GET("/:trx_id", txHandler.GetTransaction)
+			transactions.PUT("/:trx_id", txHandler.UpdateTransaction)
+			transactions.DELETE("/:trx_id", txHandler.DeleteTransaction)
 			transactions.GET("", txHandler.GetTransactionsByDateRange)
 		}
 
+		// Bank statement routes
+		bankStatements := v1.Group("/bank-statements")
+		{
+			bankStatements.GET("", bankStatementHandler.GetBankStatements)
+		}
+
 		// Reconciliation routes
 		reconciliation := v1.Group("/reconcile")
 		{
-			reconciliation.POST("", reconHandler.Reconcile)
+			reconciliation.POST("", reconcileLimit, reconHandler.Reconcile)
+			reconciliation.POST("/upload", reconcileLimit, reconHandler.ReconcileUpload)
+			reconciliation.POST("/validate", reconcileLimit, reconHandler.ValidateFiles)
+			reconciliation.POST("/rollup", reconHandler.RollupJobs)
+			reconciliation.GET("/jobs", reconHandler.ListJobs)
 			reconciliation.GET("/jobs/:job_id", reconHandler.GetJobStatus)
+			reconciliation.DELETE("/jobs/:job_id", reconHandler.DeleteJob)
+			reconciliation.POST("/jobs/:job_id/rerun", reconcileLimit, reconHandler.RerunJob)
+			reconciliation.POST("/jobs/:job_id/reprocess", reconcileLimit, reconHandler.ReprocessUnmatched)
+			reconciliation.POST("/jobs/:job_id/recompute", reconHandler.RecomputeJobTotals)
+			reconciliation.POST("/jobs/:job_id/manual-match", reconHandler.ManualMatch)
 			reconciliation.GET("/jobs/:job_id/summary", reconHandler.GetJobSummary)
+			reconciliation.GET("/jobs/:job_id/stats", reconHandler.GetJobStats)
+			reconciliation.GET("/jobs/:job_id/verify", reconHandler.VerifyJobResults)
+			reconciliation.GET("/jobs/:job_id/results", reconHandler.GetJobResults)
+			reconciliation.GET("/jobs/:job_id/unmatched-bank", reconHandler.GetUnmatchedBank)
+			reconciliation.GET("/jobs/:job_id/export", reconHandler.ExportJobResults)
+			reconciliation.GET("/jobs/:job_id/diff/:other_id", reconHandler.DiffJobs)
+			reconciliation.POST("/results/:id/attachments", attachmentHandler.UploadAttachment)
+			reconciliation.GET("/results/:id/attachments", attachmentHandler.ListAttachments)
 		}
 	}
 
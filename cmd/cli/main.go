@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"recon-engine/internal/bootstrap"
+	"recon-engine/internal/config"
+	"recon-engine/internal/domain"
+	"recon-engine/internal/export"
+	"recon-engine/internal/repository"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/tracing"
+)
+
+const usage = `Usage: recon-cli --bank FILE [--bank FILE ...] --start YYYY-MM-DD --end YYYY-MM-DD [flags]
+       recon-cli migrate
+
+Reconciles system transactions, from --system files or the database, against
+bank statement files and prints the summary as JSON to stdout. Configuration
+is read from the same environment as the API server; logs go to stderr.
+
+The migrate command applies pending schema migrations and exits.
+
+Flags:
+`
+
+// fileList collects a repeatable file flag
+type fileList []string
+
+func (f *fileList) String() string { return strings.Join(*f, ",") }
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+type options struct {
+	systemFiles fileList
+	bankFiles   fileList
+	startDate   time.Time
+	endDate     time.Time
+	format      string
+	offline     bool
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrations(); err != nil {
+			fmt.Fprintln(os.Stderr, "migration failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(2)
+	}
+
+	// Ctrl-C cancels the queries in flight and marks the job failed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, opts, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "reconciliation failed:", err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string) (*options, error) {
+	opts := &options{}
+	var start, end string
+	flags := flag.NewFlagSet("recon-cli", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	flags.Var(&opts.systemFiles, "system", "system transactions CSV; repeat to merge files (default: the database)")
+	flags.Var(&opts.bankFiles, "bank", "bank statement file or zip archive; repeat for each bank")
+	flags.StringVar(&start, "start", "", "first day to reconcile (YYYY-MM-DD)")
+	flags.StringVar(&end, "end", "", "last day to reconcile, inclusive (YYYY-MM-DD)")
+	flags.StringVar(&opts.format, "format", "json", "output format: json (summary) or csv (results)")
+	flags.BoolVar(&opts.offline, "offline", false, "match without a database connection; nothing is persisted")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if len(opts.bankFiles) == 0 {
+		return nil, fmt.Errorf("at least one --bank file is required")
+	}
+	if opts.offline && len(opts.systemFiles) == 0 {
+		return nil, fmt.Errorf("--offline requires --system files: there is no database to read system transactions from")
+	}
+	if opts.format != "json" && opts.format != "csv" {
+		return nil, fmt.Errorf("invalid --format %q: must be json or csv", opts.format)
+	}
+
+	var err error
+	if opts.startDate, err = time.Parse("2006-01-02", start); err != nil {
+		return nil, fmt.Errorf("invalid --start: use YYYY-MM-DD")
+	}
+	if opts.endDate, err = time.Parse("2006-01-02", end); err != nil {
+		return nil, fmt.Errorf("invalid --end: use YYYY-MM-DD")
+	}
+	if opts.startDate.After(opts.endDate) {
+		return nil, fmt.Errorf("--start must be on or before --end")
+	}
+	return opts, nil
+}
+
+// loadConfig reads the configuration and sends logs to stderr, keeping
+// stdout for the summary or CSV
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.Init(cfg.App.LogLevel, bootstrap.LoggerOptions(cfg.App)...)
+	logger.GetLogger().SetOutput(os.Stderr)
+	return cfg, nil
+}
+
+func runMigrations() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := bootstrap.ConnectDB(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	applied, err := bootstrap.Migrate(db)
+	if err != nil {
+		return err
+	}
+	logger.GetLogger().WithField("applied", len(applied)).Info("Database schema is up to date")
+	return nil
+}
+
+func run(ctx context.Context, opts *options, out io.Writer) (err error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	shutdownTracing, err := tracing.Init(ctx, bootstrap.TracingOptions(cfg.Tracing)...)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.WithoutCancel(ctx))
+	ctx, span := tracing.Start(ctx, "recon-cli.reconcile")
+	defer func() { tracing.End(span, err) }()
+
+	reconOpts, err := bootstrap.ReconciliationOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Offline runs are dry runs with no repositories behind them
+	var txRepo repository.TransactionRepository
+	var reconRepo repository.ReconciliationRepository
+	if !opts.offline {
+		db, err := bootstrap.ConnectDB(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+
+		if cfg.Database.AutoMigrate {
+			if _, err := bootstrap.Migrate(db); err != nil {
+				return fmt.Errorf("failed to apply migrations: %w", err)
+			}
+		}
+
+		repoOpts := bootstrap.RepositoryOptions(cfg)
+		txRepo = repository.NewTransactionRepository(db, repoOpts...)
+		reconRepo = repository.NewReconciliationRepository(db, repoOpts...)
+		bankRepo := repository.NewBankStatementRepository(db, repoOpts...)
+		reconOpts = append(reconOpts,
+			service.WithBankStatementRepository(bankRepo),
+			service.WithBankStatementPersistence(cfg.App.PersistBankStatements),
+		)
+	}
+	reconService := service.NewReconciliationService(txRepo, reconRepo, cfg.App.BatchSize, reconOpts...)
+
+	summary, err := reconService.Reconcile(ctx, opts.systemFiles, opts.bankFiles, opts.startDate, opts.endDate, opts.offline)
+	if err != nil {
+		return err
+	}
+	// Sinks are written in the background; let them finish before exiting
+	if err := reconService.FlushSinks(ctx); err != nil {
+		logger.GetLogger().WithError(err).Warn("Result sinks did not finish")
+	}
+
+	if opts.format == "csv" {
+		return writeResultsCSV(ctx, reconService, summary, out, bootstrap.ExportAmountScales(cfg.Matcher))
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
+
+// writeResultsCSV writes every stored result of the job. An offline run
+// stores none, so it writes the exceptions listed in the summary instead.
+func writeResultsCSV(ctx context.Context, reconService service.ReconciliationService, summary *domain.ReconciliationSummary, out io.Writer, scales export.AmountScales) error {
+	writer := export.NewCSVResultWriter(out, export.WithAmountScales(scales))
+	if err := writer.WriteHeader(); err != nil {
+		return err
+	}
+
+	if summary.DryRun {
+		if summary.Truncated {
+			logger.GetLogger().Warn("Summary lists were capped; the CSV holds only the listed exceptions")
+		}
+		if err := writer.Write(summaryResults(summary)); err != nil {
+			return err
+		}
+		return writer.Flush()
+	}
+
+	if err := reconService.StreamJobResults(ctx, summary.JobID, writer.Write); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// summaryResults flattens the exception lists of a summary
+func summaryResults(summary *domain.ReconciliationSummary) []domain.ReconciliationResult {
+	var results []domain.ReconciliationResult
+	results = append(results, summary.Discrepancies...)
+	results = append(results, summary.UnmatchedSystem...)
+	sources := make([]string, 0, len(summary.UnmatchedBank))
+	for source := range summary.UnmatchedBank {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		results = append(results, summary.UnmatchedBank[source]...)
+	}
+	results = append(results, summary.DateMismatches...)
+	results = append(results, summary.CurrencyMismatches...)
+	results = append(results, summary.DirectionMismatches...)
+	results = append(results, summary.DuplicateSystem...)
+	results = append(results, summary.DuplicateBank...)
+	results = append(results, summary.MalformedReferences...)
+	results = append(results, summary.AmbiguousMatches...)
+	return results
+}
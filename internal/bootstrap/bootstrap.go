@@ -0,0 +1,345 @@
+// Package bootstrap wires configuration into the database connection and
+// reconciliation service shared by the API server and the CLI
+package bootstrap
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"recon-engine/internal/config"
+	"recon-engine/internal/export"
+	"recon-engine/internal/matcher"
+	"recon-engine/internal/migrate"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/repository"
+	"recon-engine/internal/service"
+	"recon-engine/internal/storage"
+	"recon-engine/migrations"
+	"recon-engine/pkg/kafka"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/tracing"
+	"recon-engine/pkg/webhook"
+)
+
+// LoggerOptions maps the logging settings to logger.Init options
+func LoggerOptions(cfg config.AppConfig) []logger.Option {
+	return []logger.Option{
+		logger.WithFormat(cfg.LogFormat),
+		logger.WithTimestamps(cfg.LogTimestamps),
+		logger.WithTimestampFormat(cfg.LogTimestampFormat),
+	}
+}
+
+// TracingOptions maps the trace export settings to tracing.Init options
+func TracingOptions(cfg config.TracingConfig) []tracing.Option {
+	return []tracing.Option{
+		tracing.WithEndpoint(cfg.Endpoint),
+		tracing.WithInsecure(cfg.Insecure),
+		tracing.WithServiceName(cfg.ServiceName),
+		tracing.WithSampleRatio(cfg.SampleRatio),
+	}
+}
+
+// ExportAmountScales writes exported amounts with the decimal places they
+// are matched at
+func ExportAmountScales(cfg config.MatcherConfig) export.AmountScales {
+	return export.AmountScales{Default: cfg.AmountScale, ByCurrency: cfg.CurrencyScales}
+}
+
+// ConnectDB opens and pings the configured database and applies the pool settings
+func ConnectDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.ConnectionString())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	// Set connection pool settings
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// Migrate applies the embedded schema migrations not yet recorded in the
+// database and returns the names of those applied
+func Migrate(db *sql.DB) ([]string, error) {
+	all, err := migrate.Load(migrations.Files)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Up(db, all)
+}
+
+// RepositoryOptions maps the progress logging and write retry settings to
+// repository options
+func RepositoryOptions(cfg *config.Config) []repository.RepositoryOption {
+	return []repository.RepositoryOption{
+		repository.WithProgressInterval(cfg.App.ProgressLogInterval),
+		repository.WithRetry(cfg.Database.RetryAttempts, cfg.Database.RetryBackoff, cfg.Database.RetryMaxBackoff),
+	}
+}
+
+// ReconciliationOptions builds the service options for the configured
+// matching, parsing, notification and hash chain settings. Options that need a
+// repository or metrics sink are left to the caller.
+func ReconciliationOptions(cfg *config.Config) ([]service.ServiceOption, error) {
+	engineOpts, err := engineOptions(cfg.Matcher)
+	if err != nil {
+		return nil, fmt.Errorf("invalid matcher configuration: %w", err)
+	}
+	strategyConfig, err := matchingStrategyConfig(cfg.Matcher)
+	if err != nil {
+		return nil, fmt.Errorf("invalid matcher configuration: %w", err)
+	}
+	strategy, err := matcher.NewStrategy(cfg.Matcher.Strategy, strategyConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid matcher configuration: %w", err)
+	}
+
+	balance, err := balanceRows(cfg.App)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parser configuration: %w", err)
+	}
+
+	sinkOpts, err := resultSinkOptions(cfg.Sinks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result sink configuration: %w", err)
+	}
+
+	return append([]service.ServiceOption{
+		service.WithStrategy(strategy),
+		service.WithStrategyConfig(strategyConfig),
+		service.WithEngineOptions(engineOpts...),
+		service.WithDateWindow(time.Duration(cfg.Matcher.DateWindowDays) * 24 * time.Hour),
+		service.WithSplitByDirection(cfg.Matcher.SplitByDirection),
+		service.WithPerSource(cfg.Matcher.PerSource),
+		service.WithStreaming(cfg.App.StreamSystemTransactions),
+		service.WithColumnMappings(columnMappings(cfg.App.BankColumnAliases)),
+		service.WithDateLayouts(cfg.App.BankDateLayouts),
+		service.WithSourceFingerprints(sourceFingerprints(cfg.App.BankSourceFingerprints)),
+		service.WithMaxArchiveSize(cfg.App.MaxArchiveSize),
+		service.WithBankFileWorkers(cfg.App.BankFileWorkers),
+		service.WithSummaryCache(cfg.App.SummaryCacheSize, cfg.App.SummaryCacheTTL),
+		service.WithNotifier(webhook.NewClient(
+			webhook.WithTimeout(cfg.App.WebhookTimeout),
+			webhook.WithRetries(cfg.App.WebhookRetries, cfg.App.WebhookBackoff),
+			webhook.WithSecret([]byte(cfg.App.WebhookSecret)),
+		)),
+		service.WithResultHashChain(cfg.App.ResultHashChain, []byte(cfg.App.ResultChainKey)),
+		service.WithParserOptions(
+			parser.WithCallbackRetry(cfg.App.CallbackRetries, cfg.App.CallbackBackoff, nil),
+			parser.WithDefaultCurrency(cfg.App.DefaultCurrency),
+			parser.WithSkipRows(cfg.App.SkipRows),
+			parser.WithHeaderDetection(cfg.App.DetectHeader),
+			parser.WithMaxRowErrors(cfg.App.MaxRowErrors),
+			parser.WithBalanceRows(balance),
+			parser.WithDelimiter(cfg.App.CSVDelimiter),
+			parser.WithComment(cfg.App.CSVComment),
+			parser.WithDecimalComma(cfg.App.AmountDecimalComma),
+			parser.WithDateFormats(cfg.App.DateFormats...),
+			parser.WithDayFirst(cfg.App.DateDayFirst),
+			parser.WithFileOpener(fileOpener(cfg.Storage)),
+		),
+	}, sinkOpts...), nil
+}
+
+// resultSinkOptions stores results in the results table and publishes
+// exceptions to Kafka as RESULT_SINKS lists
+func resultSinkOptions(cfg config.ResultSinkConfig) ([]service.ServiceOption, error) {
+	if len(cfg.Sinks) == 0 {
+		return nil, fmt.Errorf("RESULT_SINKS lists no sink")
+	}
+	postgres := false
+	var sinks []service.ResultSink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "postgres":
+			postgres = true
+		case "kafka":
+			if cfg.KafkaRESTURL == "" || cfg.KafkaTopic == "" {
+				return nil, fmt.Errorf("the kafka sink needs KAFKA_REST_URL and KAFKA_TOPIC")
+			}
+			producer := kafka.NewProducer(cfg.KafkaRESTURL,
+				kafka.WithTimeout(cfg.KafkaTimeout),
+				kafka.WithRetries(cfg.KafkaRetries, cfg.KafkaBackoff),
+				kafka.WithBasicAuth(cfg.KafkaUsername, cfg.KafkaPassword),
+			)
+			sinks = append(sinks, service.NewKafkaSink(producer, cfg.KafkaTopic))
+		default:
+			return nil, fmt.Errorf("unknown result sink: %s (use postgres or kafka)", name)
+		}
+	}
+	return []service.ServiceOption{
+		service.WithRepositoryResults(postgres),
+		service.WithResultSinks(sinks...),
+		service.WithSinkTimeout(cfg.Timeout),
+	}, nil
+}
+
+// fileOpener reads s3:// and gs:// URLs from their object stores and other
+// paths from the local filesystem
+func fileOpener(cfg config.StorageConfig) parser.FileOpener {
+	return parser.SchemeOpener{Schemes: map[string]parser.FileOpener{
+		"s3": storage.NewS3Opener(cfg.S3Region,
+			storage.WithS3Endpoint(cfg.S3Endpoint),
+			storage.WithS3PathStyle(cfg.S3PathStyle),
+			storage.WithS3Credentials(storage.S3Credentials{
+				AccessKeyID:     cfg.AWSAccessKeyID,
+				SecretAccessKey: cfg.AWSSecretAccessKey,
+				SessionToken:    cfg.AWSSessionToken,
+			}),
+		),
+		"gs": storage.NewGCSOpener(storage.S3Credentials{
+			AccessKeyID:     cfg.GCSHMACAccessID,
+			SecretAccessKey: cfg.GCSHMACSecret,
+		}),
+	}}
+}
+
+func engineOptions(cfg config.MatcherConfig) ([]matcher.EngineOption, error) {
+	duplicatePolicy, err := matcher.ParseDuplicatePolicy(cfg.DuplicatePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	bankConventions, err := bankAmountConventions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []matcher.EngineOption{
+		matcher.WithResultEnrichment(cfg.EnrichResults),
+		matcher.WithDuplicatePolicy(duplicatePolicy),
+		matcher.WithUnsignedAmounts(cfg.UnsignedAmounts),
+		matcher.WithUniqueClaims(cfg.UniqueClaims),
+		matcher.WithWorkers(cfg.Workers),
+		matcher.WithCurrencyScales(matcher.CurrencyScales{
+			Default:    cfg.AmountScale,
+			ByCurrency: cfg.CurrencyScales,
+		}),
+		matcher.WithBasisPointTolerance(matcher.BasisPointTolerance{
+			Default:  cfg.ToleranceBps,
+			BySource: cfg.SourceToleranceBps,
+		}),
+		matcher.WithBankAmountConventions(bankConventions),
+	}
+	if cfg.MinAmount != nil || cfg.MaxAmount != nil {
+		opts = append(opts, matcher.WithAmountBounds(cfg.MinAmount, cfg.MaxAmount))
+	}
+
+	formats, err := referenceFormats(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, matcher.WithReferenceFormats(formats))
+	return opts, nil
+}
+
+// referenceFormats compiles the configured reference patterns
+func referenceFormats(cfg config.MatcherConfig) (matcher.ReferenceFormats, error) {
+	var formats matcher.ReferenceFormats
+	if cfg.SystemReferencePattern != "" {
+		pattern, err := regexp.Compile(cfg.SystemReferencePattern)
+		if err != nil {
+			return formats, fmt.Errorf("invalid SYSTEM_REFERENCE_PATTERN: %w", err)
+		}
+		formats.System = pattern
+	}
+	for source, expr := range cfg.BankReferencePatterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return formats, fmt.Errorf("invalid BANK_REFERENCE_PATTERNS entry %q: %w", source, err)
+		}
+		if formats.Bank == nil {
+			formats.Bank = make(map[string]*regexp.Regexp)
+		}
+		formats.Bank[source] = pattern
+	}
+	return formats, nil
+}
+
+// matchingStrategyConfig collects the settings strategies are built from
+func matchingStrategyConfig(cfg config.MatcherConfig) (matcher.StrategyConfig, error) {
+	transforms, err := matcher.StripPatterns(cfg.NormalizeStripPatterns...)
+	if err != nil {
+		return matcher.StrategyConfig{}, fmt.Errorf("invalid MATCH_NORMALIZE_STRIP_PATTERNS: %w", err)
+	}
+	referenceField, err := matcher.ParseReferenceField(cfg.ReferenceField)
+	if err != nil {
+		return matcher.StrategyConfig{}, fmt.Errorf("invalid MATCH_REFERENCE_FIELD: %w", err)
+	}
+	strategyConfig := matcher.StrategyConfig{
+		AmountTolerance: cfg.AmountTolerance,
+		DateWindow:      time.Duration(cfg.DateWindowDays) * 24 * time.Hour,
+		Transforms:      transforms,
+		ReferenceField:  referenceField,
+	}
+	if cfg.InstallmentPattern != "" {
+		if strategyConfig.InstallmentPattern, err = matcher.CompileInstallmentPattern(cfg.InstallmentPattern); err != nil {
+			return matcher.StrategyConfig{}, fmt.Errorf("invalid MATCH_INSTALLMENT_PATTERN: %w", err)
+		}
+	}
+	return strategyConfig, nil
+}
+
+// balanceRows compiles the configured balance line patterns
+func balanceRows(cfg config.AppConfig) (parser.BalanceRows, error) {
+	rows := parser.BalanceRows{Column: cfg.BalanceColumn, Validate: cfg.ValidateBalance}
+	var err error
+	if cfg.BalanceOpeningPattern != "" {
+		if rows.Opening, err = regexp.Compile(cfg.BalanceOpeningPattern); err != nil {
+			return rows, fmt.Errorf("invalid BALANCE_OPENING_PATTERN: %w", err)
+		}
+	}
+	if cfg.BalanceClosingPattern != "" {
+		if rows.Closing, err = regexp.Compile(cfg.BalanceClosingPattern); err != nil {
+			return rows, fmt.Errorf("invalid BALANCE_CLOSING_PATTERN: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+// bankAmountConventions validates the configured amount sign conventions
+func bankAmountConventions(cfg config.MatcherConfig) (matcher.BankAmountConventions, error) {
+	conventions := matcher.BankAmountConventions{BySource: make(map[string]matcher.BankAmountConvention, len(cfg.SourceBankAmountConventions))}
+	var err error
+	if conventions.Default, err = matcher.ParseBankAmountConvention(cfg.BankAmountConvention); err != nil {
+		return conventions, err
+	}
+	for source, name := range cfg.SourceBankAmountConventions {
+		convention, err := matcher.ParseBankAmountConvention(name)
+		if err != nil {
+			return conventions, fmt.Errorf("bank %s: %w", source, err)
+		}
+		conventions.BySource[source] = convention
+	}
+	return conventions, nil
+}
+
+// columnMappings converts the configured header aliases into parser mappings
+func columnMappings(aliases map[string]map[string]string) map[string]parser.ColumnMapping {
+	mappings := make(map[string]parser.ColumnMapping, len(aliases))
+	for source, mapping := range aliases {
+		mappings[source] = parser.ColumnMapping(mapping)
+	}
+	return mappings
+}
+
+// sourceFingerprints converts the configured content fingerprints for the parser
+func sourceFingerprints(configured []config.SourceFingerprint) []parser.SourceFingerprint {
+	fingerprints := make([]parser.SourceFingerprint, 0, len(configured))
+	for _, f := range configured {
+		fingerprints = append(fingerprints, parser.SourceFingerprint(f))
+	}
+	return fingerprints
+}
@@ -1,15 +1,27 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
 )
 
 type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	App      AppConfig
+	Matcher  MatcherConfig
+	Metrics  MetricsConfig
+	Tracing  TracingConfig
+	Storage  StorageConfig
+	Sinks    ResultSinkConfig
 }
 
 type DatabaseConfig struct {
@@ -19,38 +31,662 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// Connection pool limits; zero lifetimes and MaxOpenConns mean no limit
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// AutoMigrate applies pending schema migrations on startup
+	AutoMigrate bool
+	// Retry* repeat job and result writes failing with transient errors
+	RetryAttempts   int
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
 }
 
 type ServerConfig struct {
 	Port string
+	// MaxUploadSize caps a multipart reconcile upload, in bytes
+	MaxUploadSize int64
+	// HealthCheckTimeout bounds the database ping of the readiness probe
+	HealthCheckTimeout time.Duration
+	// RequestTimeout cancels an API request's context after this long; 0 disables it
+	RequestTimeout time.Duration
 }
 
 type AppConfig struct {
-	LogLevel  string
-	BatchSize int
+	LogLevel string
+	// LogFormat is "json" or "text"; LogTimestamps adds a timestamp to each
+	// entry, in the Go time layout LogTimestampFormat (RFC3339 when empty)
+	LogFormat          string
+	LogTimestamps      bool
+	LogTimestampFormat string
+	BatchSize          int
+	// CallbackRetries is how many times a failed parser batch callback is retried
+	CallbackRetries int
+	CallbackBackoff time.Duration
+	// ProgressLogInterval logs bulk insert progress every N rows; 0 disables it
+	ProgressLogInterval int
+	// DefaultCurrency is assigned to parsed rows without a currency
+	DefaultCurrency string
+	// Journal*Account name the ledger accounts of the journal export
+	JournalBankAccount     string
+	JournalClearingAccount string
+	JournalSuspenseAccount string
+	// BankColumnAliases maps a bank file name to its header aliases
+	// (alias -> canonical column name)
+	BankColumnAliases map[string]map[string]string
+	// BankSourceFingerprints identify bank files by content, checked in order
+	BankSourceFingerprints []SourceFingerprint
+	// SkipRows is the number of preamble rows before a file's header
+	SkipRows int
+	// DetectHeader finds the header row by its required column names
+	DetectHeader bool
+	// MaxRowErrors caps the skipped rows reported for each parsed file
+	MaxRowErrors int
+	// CSVDelimiter separates bank CSV fields; AmountDecimalComma reads bank
+	// CSV amounts as 1.234,56
+	CSVDelimiter       rune
+	AmountDecimalComma bool
+	// CSVComment starts CSV lines to skip, e.g. '#'; zero keeps every line
+	CSVComment rune
+	// DateFormats are Go time layouts accepted after the built-in ones;
+	// DateDayFirst reads 03/04/2024 as 3 April rather than March 4
+	DateFormats  []string
+	DateDayFirst bool
+	// BankDateLayouts maps a bank source to the only Go time layout its
+	// dates are read with; other sources use the formats above
+	BankDateLayouts map[string]string
+	// BalanceColumn holds the marker of opening/closing balance lines, which
+	// are recognised by the Balance*Pattern regexes and excluded from matching
+	BalanceColumn         string
+	BalanceOpeningPattern string
+	BalanceClosingPattern string
+	// ValidateBalance checks opening + transactions = closing per bank file
+	ValidateBalance bool
+	// AttachmentDir is where files attached to results are stored
+	AttachmentDir string
+	// MaxArchiveSize caps the uncompressed size of one bank .zip, in bytes
+	MaxArchiveSize int64
+	// BankFileWorkers is how many bank files are parsed at once; 0 uses
+	// every CPU
+	BankFileWorkers int
+	// SummaryCacheSize completed-job summaries are kept in memory for
+	// SummaryCacheTTL; zero disables the cache
+	SummaryCacheSize int
+	SummaryCacheTTL  time.Duration
+	// Webhook* configure job callbacks: each attempt's timeout, the retries
+	// after a failed delivery and the first wait between them, and the HMAC
+	// secret signing payloads (unsigned when empty)
+	WebhookTimeout time.Duration
+	WebhookRetries int
+	WebhookBackoff time.Duration
+	WebhookSecret  string
+	// PersistBankStatements stores bank statements loaded from files
+	PersistBankStatements bool
+	// StreamSystemTransactions matches database system transactions batch
+	// by batch instead of loading them all first
+	StreamSystemTransactions bool
+	// ResultHashChain links each job's stored results by hash so tampering
+	// can be detected; ResultChainKey, when set, signs the chain with HMAC
+	ResultHashChain bool
+	ResultChainKey  string
+	// IdempotencyKeyTTL is how long an Idempotency-Key maps to its job
+	IdempotencyKeyTTL time.Duration
+	// ReconcileRateLimit caps the reconcile requests each client may start
+	// per minute (0 disables), with bursts of up to ReconcileRateBurst.
+	// ReconcileRateLimitBy tells clients apart: "ip" or "api_key".
+	ReconcileRateLimit   int
+	ReconcileRateBurst   int
+	ReconcileRateLimitBy string
+}
+
+// MatcherConfig holds optional reconciliation engine settings
+type MatcherConfig struct {
+	MinAmount *decimal.Decimal // Nil when no lower bound is configured
+	MaxAmount *decimal.Decimal // Nil when no upper bound is configured
+	// DateWindowDays is the allowed posting delay for an ID match; 0 disables the check
+	DateWindowDays int
+	// SplitByDirection reconciles debits and credits in separate passes
+	SplitByDirection bool
+	// PerSource reconciles each bank source separately against the system set
+	PerSource bool
+	// EnrichResults stores the transaction type and created_at on each result
+	EnrichResults bool
+	// DuplicatePolicy resolves bank statements sharing a reference ID
+	DuplicatePolicy string
+	// Strategy is the default pairing: "exact" (reference ID), "tolerance"
+	// (amount within AmountTolerance and date within DateWindowDays, ignoring
+	// IDs), "normalized" (IDs compared after NormalizeStripPatterns,
+	// uppercasing and dropping punctuation), "amount_date" (statements
+	// without a reference paired on amount and date) or "installment"
+	// (installments such as TX001-1 summed and matched to their parent, read
+	// with InstallmentPattern). A comma-separated list such as
+	// "exact,normalized" tries each in turn. Requests may pick another.
+	Strategy        string
+	AmountTolerance decimal.Decimal
+	// NormalizeStripPatterns are regexes the normalized strategy removes from IDs
+	NormalizeStripPatterns []string
+	// InstallmentPattern is the regex whose first group, or "parent" group,
+	// captures the parent reference of an installment
+	InstallmentPattern string
+	// ReferenceField is the transaction field bank references are matched
+	// against: "trx_id", or "order_id" falling back to trx_id when empty
+	ReferenceField string
+	// AmountScale is the decimal places amounts are rounded to before a pair
+	// is compared; CurrencyScales overrides it by ISO 4217 code
+	AmountScale    int32
+	CurrencyScales map[string]int32
+	// ToleranceBps matches ID pairs whose amounts differ by at most this many
+	// basis points of the system amount; SourceToleranceBps overrides it per
+	// bank file name
+	ToleranceBps       decimal.Decimal
+	SourceToleranceBps map[string]decimal.Decimal
+	// BankAmountConvention is how banks sign their amounts: debits_negative,
+	// debits_positive, always_positive, separate_columns or auto;
+	// SourceBankAmountConventions overrides it per bank file name
+	BankAmountConvention        string
+	SourceBankAmountConventions map[string]string
+	// UnsignedAmounts compares amount magnitudes and directions separately
+	UnsignedAmounts bool
+	// UniqueClaims reports system transactions sharing a bank reference as
+	// AMBIGUOUS_MATCH instead of matching them all to one statement
+	UniqueClaims bool
+	// Workers is the number of matching goroutines; 0 uses every CPU
+	Workers int
+	// SystemReferencePattern is the regex system TrxIDs must match; empty skips the check
+	SystemReferencePattern string
+	// BankReferencePatterns maps a bank file name, or "*" for any other, to
+	// the regex its TrxRefIDs must match
+	BankReferencePatterns map[string]string
+}
+
+// SourceFingerprint recognises a bank file by its header row or by the value
+// of a marker column in its first data row
+type SourceFingerprint struct {
+	Source       string   `json:"source"`
+	Headers      []string `json:"headers"`
+	MarkerColumn string   `json:"marker_column"`
+	MarkerValue  string   `json:"marker_value"`
+}
+
+// MetricsConfig holds optional metrics outputs besides /metrics
+type MetricsConfig struct {
+	// StatsDAddr is the host:port of a StatsD agent; empty disables StatsD
+	StatsDAddr   string
+	StatsDPrefix string
+	// StatsDTags sends labels as DogStatsD tags instead of name segments
+	StatsDTags bool
+}
+
+// TracingConfig holds the OpenTelemetry trace export settings
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP collector, host:port or a base URL; empty
+	// disables export
+	Endpoint string
+	// Insecure exports to a host:port endpoint over plain HTTP
+	Insecure    bool
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1
+	SampleRatio float64
+}
+
+// ResultSinkConfig chooses where the results of completed jobs go
+type ResultSinkConfig struct {
+	// Sinks lists "postgres", the results table, and "kafka"
+	Sinks []string
+	// Timeout bounds each background delivery to the sinks other than postgres
+	Timeout time.Duration
+	// Kafka* configure the REST Proxy exceptions are published through:
+	// its base URL, topic, basic auth credentials, each request's timeout
+	// and the retries after a failed request
+	KafkaRESTURL  string
+	KafkaTopic    string
+	KafkaUsername string
+	KafkaPassword string
+	KafkaTimeout  time.Duration
+	KafkaRetries  int
+	KafkaBackoff  time.Duration
+}
+
+// StorageConfig holds the object store settings for s3:// and gs:// input
+// file URLs
+type StorageConfig struct {
+	S3Region string
+	// S3Endpoint replaces AWS with an S3-compatible store such as MinIO
+	S3Endpoint  string
+	S3PathStyle bool
+	// AWS* are the S3 credentials; without an access key requests are unsigned
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	// GCSHMAC* are a Cloud Storage HMAC key, used through its XML API
+	GCSHMACAccessID string
+	GCSHMACSecret   string
 }
 
 func Load() (*Config, error) {
+	maxOpenConns, err := strconv.Atoi(getEnv("DB_MAX_OPEN_CONNS", "25"))
+	if err != nil || maxOpenConns < 0 {
+		return nil, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: must be a non-negative integer")
+	}
+	maxIdleConns, err := strconv.Atoi(getEnv("DB_MAX_IDLE_CONNS", "5"))
+	if err != nil || maxIdleConns < 0 {
+		return nil, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: must be a non-negative integer")
+	}
+	connMaxLifetime, err := time.ParseDuration(getEnv("DB_CONN_MAX_LIFETIME", "0s"))
+	if err != nil || connMaxLifetime < 0 {
+		return nil, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: must be a non-negative duration")
+	}
+	connMaxIdleTime, err := time.ParseDuration(getEnv("DB_CONN_MAX_IDLE_TIME", "0s"))
+	if err != nil || connMaxIdleTime < 0 {
+		return nil, fmt.Errorf("invalid DB_CONN_MAX_IDLE_TIME: must be a non-negative duration")
+	}
+	retryAttempts, err := strconv.Atoi(getEnv("DB_RETRY_ATTEMPTS", "3"))
+	if err != nil || retryAttempts < 1 {
+		return nil, fmt.Errorf("invalid DB_RETRY_ATTEMPTS: must be a positive integer")
+	}
+	retryBackoff, err := time.ParseDuration(getEnv("DB_RETRY_BACKOFF", "100ms"))
+	if err != nil || retryBackoff < 0 {
+		return nil, fmt.Errorf("invalid DB_RETRY_BACKOFF: must be a non-negative duration")
+	}
+	retryMaxBackoff, err := time.ParseDuration(getEnv("DB_RETRY_MAX_BACKOFF", "2s"))
+	if err != nil || retryMaxBackoff < 0 {
+		return nil, fmt.Errorf("invalid DB_RETRY_MAX_BACKOFF: must be a non-negative duration")
+	}
+
 	batchSize, err := strconv.Atoi(getEnv("BATCH_SIZE", "10000"))
 	if err != nil {
 		batchSize = 10000
 	}
 
+	callbackRetries, err := strconv.Atoi(getEnv("PARSER_CALLBACK_RETRIES", "0"))
+	if err != nil || callbackRetries < 0 {
+		return nil, fmt.Errorf("invalid PARSER_CALLBACK_RETRIES: must be a non-negative integer")
+	}
+	callbackBackoff, err := time.ParseDuration(getEnv("PARSER_CALLBACK_BACKOFF", "100ms"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PARSER_CALLBACK_BACKOFF: %w", err)
+	}
+
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_TIMEOUT: %w", err)
+	}
+	webhookRetries, err := strconv.Atoi(getEnv("WEBHOOK_RETRIES", "3"))
+	if err != nil || webhookRetries < 0 {
+		return nil, fmt.Errorf("invalid WEBHOOK_RETRIES: must be a non-negative integer")
+	}
+	webhookBackoff, err := time.ParseDuration(getEnv("WEBHOOK_BACKOFF", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_BACKOFF: %w", err)
+	}
+
+	var resultSinks []string
+	for _, name := range strings.Split(getEnv("RESULT_SINKS", "postgres"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			resultSinks = append(resultSinks, name)
+		}
+	}
+	sinkTimeout, err := time.ParseDuration(getEnv("RESULT_SINK_TIMEOUT", "1m"))
+	if err != nil || sinkTimeout <= 0 {
+		return nil, fmt.Errorf("invalid RESULT_SINK_TIMEOUT: must be a positive duration")
+	}
+	kafkaTimeout, err := time.ParseDuration(getEnv("KAFKA_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_TIMEOUT: %w", err)
+	}
+	kafkaRetries, err := strconv.Atoi(getEnv("KAFKA_RETRIES", "3"))
+	if err != nil || kafkaRetries < 0 {
+		return nil, fmt.Errorf("invalid KAFKA_RETRIES: must be a non-negative integer")
+	}
+	kafkaBackoff, err := time.ParseDuration(getEnv("KAFKA_BACKOFF", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_BACKOFF: %w", err)
+	}
+
+	idempotencyKeyTTL, err := time.ParseDuration(getEnv("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil || idempotencyKeyTTL <= 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL: must be a positive duration")
+	}
+
+	reconcileRateLimit, err := strconv.Atoi(getEnv("RECONCILE_RATE_LIMIT", "0"))
+	if err != nil || reconcileRateLimit < 0 {
+		return nil, fmt.Errorf("invalid RECONCILE_RATE_LIMIT: must be a non-negative integer")
+	}
+	reconcileRateBurst, err := strconv.Atoi(getEnv("RECONCILE_RATE_BURST", "5"))
+	if err != nil || reconcileRateBurst < 1 {
+		return nil, fmt.Errorf("invalid RECONCILE_RATE_BURST: must be a positive integer")
+	}
+	reconcileRateLimitBy := getEnv("RECONCILE_RATE_LIMIT_BY", "ip")
+	if reconcileRateLimitBy != "ip" && reconcileRateLimitBy != "api_key" {
+		return nil, fmt.Errorf("invalid RECONCILE_RATE_LIMIT_BY: must be ip or api_key")
+	}
+
+	skipRows, err := strconv.Atoi(getEnv("PARSER_SKIP_ROWS", "0"))
+	if err != nil || skipRows < 0 {
+		return nil, fmt.Errorf("invalid PARSER_SKIP_ROWS: must be a non-negative integer")
+	}
+
+	maxRowErrors, err := strconv.Atoi(getEnv("PARSER_MAX_ROW_ERRORS", "20"))
+	if err != nil || maxRowErrors <= 0 {
+		return nil, fmt.Errorf("invalid PARSER_MAX_ROW_ERRORS: must be a positive integer")
+	}
+
+	csvDelimiter, err := parseDelimiter(getEnv("CSV_DELIMITER", ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV_DELIMITER: %w", err)
+	}
+	var csvComment rune
+	if raw := os.Getenv("CSV_COMMENT_CHAR"); raw != "" {
+		if csvComment, err = parseDelimiter(raw); err != nil {
+			return nil, fmt.Errorf("invalid CSV_COMMENT_CHAR: %w", err)
+		}
+		if csvComment == csvDelimiter || csvComment == ',' {
+			return nil, fmt.Errorf("invalid CSV_COMMENT_CHAR: must differ from the delimiters")
+		}
+	}
+
+	var dateFormats []string
+	if raw := os.Getenv("DATE_FORMATS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &dateFormats); err != nil {
+			return nil, fmt.Errorf("invalid DATE_FORMATS: %w", err)
+		}
+		for _, format := range dateFormats {
+			if !validDateFormat(format) {
+				return nil, fmt.Errorf("invalid DATE_FORMATS: %q is not a Go time layout", format)
+			}
+		}
+	}
+
+	var bankDateLayouts map[string]string
+	if raw := os.Getenv("BANK_DATE_LAYOUTS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &bankDateLayouts); err != nil {
+			return nil, fmt.Errorf("invalid BANK_DATE_LAYOUTS: %w", err)
+		}
+		for source, layout := range bankDateLayouts {
+			if !validDateFormat(layout) {
+				return nil, fmt.Errorf("invalid BANK_DATE_LAYOUTS: %q for %s is not a Go time layout", layout, source)
+			}
+		}
+	}
+
+	logFormat := getEnv("LOG_FORMAT", "json")
+	if logFormat != "json" && logFormat != "text" {
+		return nil, fmt.Errorf("invalid LOG_FORMAT: must be json or text")
+	}
+	logTimestampFormat := getEnv("LOG_TIMESTAMP_FORMAT", "")
+	if logTimestampFormat != "" && !validDateFormat(logTimestampFormat) {
+		return nil, fmt.Errorf("invalid LOG_TIMESTAMP_FORMAT: %q is not a Go time layout", logTimestampFormat)
+	}
+
+	traceSampleRatio, err := strconv.ParseFloat(getEnv("OTEL_TRACES_SAMPLE_RATIO", "1"), 64)
+	if err != nil || traceSampleRatio < 0 || traceSampleRatio > 1 {
+		return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLE_RATIO: must be between 0 and 1")
+	}
+
+	progressLogInterval, err := strconv.Atoi(getEnv("PROGRESS_LOG_INTERVAL", "0"))
+	if err != nil || progressLogInterval < 0 {
+		return nil, fmt.Errorf("invalid PROGRESS_LOG_INTERVAL: must be a non-negative integer")
+	}
+
+	healthCheckTimeout, err := time.ParseDuration(getEnv("HEALTH_CHECK_TIMEOUT", "2s"))
+	if err != nil || healthCheckTimeout <= 0 {
+		return nil, fmt.Errorf("invalid HEALTH_CHECK_TIMEOUT: must be a positive duration")
+	}
+
+	requestTimeout, err := time.ParseDuration(getEnv("REQUEST_TIMEOUT", "0s"))
+	if err != nil || requestTimeout < 0 {
+		return nil, fmt.Errorf("invalid REQUEST_TIMEOUT: must be a non-negative duration")
+	}
+
+	maxUploadMB, err := strconv.ParseInt(getEnv("MAX_UPLOAD_SIZE_MB", "100"), 10, 64)
+	if err != nil || maxUploadMB <= 0 {
+		return nil, fmt.Errorf("invalid MAX_UPLOAD_SIZE_MB: must be a positive integer")
+	}
+
+	maxArchiveMB, err := strconv.ParseInt(getEnv("MAX_ARCHIVE_SIZE_MB", "1024"), 10, 64)
+	if err != nil || maxArchiveMB <= 0 {
+		return nil, fmt.Errorf("invalid MAX_ARCHIVE_SIZE_MB: must be a positive integer")
+	}
+
+	bankFileWorkers, err := strconv.Atoi(getEnv("BANK_FILE_WORKERS", "4"))
+	if err != nil || bankFileWorkers < 0 {
+		return nil, fmt.Errorf("invalid BANK_FILE_WORKERS: must be a non-negative integer")
+	}
+
+	summaryCacheSize, err := strconv.Atoi(getEnv("SUMMARY_CACHE_SIZE", "0"))
+	if err != nil || summaryCacheSize < 0 {
+		return nil, fmt.Errorf("invalid SUMMARY_CACHE_SIZE: must be a non-negative integer")
+	}
+	summaryCacheTTL, err := time.ParseDuration(getEnv("SUMMARY_CACHE_TTL", "5m"))
+	if err != nil || summaryCacheTTL <= 0 {
+		return nil, fmt.Errorf("invalid SUMMARY_CACHE_TTL: must be a positive duration")
+	}
+
+	var bankColumnAliases map[string]map[string]string
+	if raw := os.Getenv("BANK_COLUMN_ALIASES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &bankColumnAliases); err != nil {
+			return nil, fmt.Errorf("invalid BANK_COLUMN_ALIASES: %w", err)
+		}
+	}
+
+	var bankSourceFingerprints []SourceFingerprint
+	if raw := os.Getenv("BANK_SOURCE_FINGERPRINTS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &bankSourceFingerprints); err != nil {
+			return nil, fmt.Errorf("invalid BANK_SOURCE_FINGERPRINTS: %w", err)
+		}
+		for _, fingerprint := range bankSourceFingerprints {
+			if fingerprint.Source == "" || (len(fingerprint.Headers) == 0 && fingerprint.MarkerColumn == "") {
+				return nil, fmt.Errorf("invalid BANK_SOURCE_FINGERPRINTS: each entry needs a source and headers or a marker_column")
+			}
+		}
+	}
+
+	var bankReferencePatterns map[string]string
+	if raw := os.Getenv("BANK_REFERENCE_PATTERNS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &bankReferencePatterns); err != nil {
+			return nil, fmt.Errorf("invalid BANK_REFERENCE_PATTERNS: %w", err)
+		}
+	}
+
+	amountScale, err := strconv.Atoi(getEnv("MATCH_AMOUNT_SCALE", "2"))
+	if err != nil || amountScale < 0 || amountScale > domain.MaxAmountScale {
+		return nil, fmt.Errorf("invalid MATCH_AMOUNT_SCALE: must be an integer from 0 to %d", domain.MaxAmountScale)
+	}
+
+	var currencyScales map[string]int32
+	if raw := os.Getenv("MATCH_CURRENCY_SCALES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &currencyScales); err != nil {
+			return nil, fmt.Errorf("invalid MATCH_CURRENCY_SCALES: %w", err)
+		}
+		for currency, scale := range currencyScales {
+			if scale < 0 || scale > domain.MaxAmountScale {
+				return nil, fmt.Errorf("invalid MATCH_CURRENCY_SCALES entry %q: must be from 0 to %d", currency, domain.MaxAmountScale)
+			}
+		}
+	}
+
+	toleranceBps, err := decimal.NewFromString(getEnv("MATCH_TOLERANCE_BPS", "0"))
+	if err != nil || toleranceBps.IsNegative() {
+		return nil, fmt.Errorf("invalid MATCH_TOLERANCE_BPS: must be a non-negative decimal")
+	}
+
+	var sourceToleranceBps map[string]decimal.Decimal
+	if raw := os.Getenv("MATCH_TOLERANCE_BPS_BY_SOURCE"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &sourceToleranceBps); err != nil {
+			return nil, fmt.Errorf("invalid MATCH_TOLERANCE_BPS_BY_SOURCE: %w", err)
+		}
+		for source, bps := range sourceToleranceBps {
+			if bps.IsNegative() {
+				return nil, fmt.Errorf("invalid MATCH_TOLERANCE_BPS_BY_SOURCE entry %q: must be non-negative", source)
+			}
+		}
+	}
+
+	var sourceBankAmountConventions map[string]string
+	if raw := os.Getenv("BANK_AMOUNT_CONVENTION_BY_SOURCE"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &sourceBankAmountConventions); err != nil {
+			return nil, fmt.Errorf("invalid BANK_AMOUNT_CONVENTION_BY_SOURCE: %w", err)
+		}
+	}
+
+	var normalizeStripPatterns []string
+	if raw := os.Getenv("MATCH_NORMALIZE_STRIP_PATTERNS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &normalizeStripPatterns); err != nil {
+			return nil, fmt.Errorf("invalid MATCH_NORMALIZE_STRIP_PATTERNS: %w", err)
+		}
+	}
+
+	minAmount, err := getEnvDecimal("MATCH_MIN_AMOUNT")
+	if err != nil {
+		return nil, err
+	}
+	maxAmount, err := getEnvDecimal("MATCH_MAX_AMOUNT")
+	if err != nil {
+		return nil, err
+	}
+
+	dateWindowDays, err := strconv.Atoi(getEnv("MATCH_DATE_WINDOW_DAYS", "0"))
+	if err != nil || dateWindowDays < 0 {
+		return nil, fmt.Errorf("invalid MATCH_DATE_WINDOW_DAYS: must be a non-negative integer")
+	}
+
+	workers, err := strconv.Atoi(getEnv("MATCH_WORKERS", "0"))
+	if err != nil || workers < 0 {
+		return nil, fmt.Errorf("invalid MATCH_WORKERS: must be a non-negative integer")
+	}
+
+	amountTolerance, err := decimal.NewFromString(getEnv("MATCH_AMOUNT_TOLERANCE", "0"))
+	if err != nil || amountTolerance.IsNegative() {
+		return nil, fmt.Errorf("invalid MATCH_AMOUNT_TOLERANCE: must be a non-negative decimal")
+	}
+
 	return &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "recon_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:            getEnv("DB_HOST", "localhost"),
+			Port:            getEnv("DB_PORT", "5432"),
+			User:            getEnv("DB_USER", "postgres"),
+			Password:        getEnv("DB_PASSWORD", "postgres"),
+			DBName:          getEnv("DB_NAME", "recon_db"),
+			SSLMode:         getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:    maxOpenConns,
+			MaxIdleConns:    maxIdleConns,
+			ConnMaxLifetime: connMaxLifetime,
+			ConnMaxIdleTime: connMaxIdleTime,
+			AutoMigrate:     getEnv("DB_AUTO_MIGRATE", "false") == "true",
+			RetryAttempts:   retryAttempts,
+			RetryBackoff:    retryBackoff,
+			RetryMaxBackoff: retryMaxBackoff,
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:               getEnv("SERVER_PORT", "8080"),
+			MaxUploadSize:      maxUploadMB << 20,
+			HealthCheckTimeout: healthCheckTimeout,
+			RequestTimeout:     requestTimeout,
 		},
 		App: AppConfig{
-			LogLevel:  getEnv("LOG_LEVEL", "info"),
-			BatchSize: batchSize,
+			LogLevel:                 getEnv("LOG_LEVEL", "info"),
+			LogFormat:                logFormat,
+			LogTimestamps:            getEnv("LOG_TIMESTAMPS", "true") == "true",
+			LogTimestampFormat:       logTimestampFormat,
+			BatchSize:                batchSize,
+			CallbackRetries:          callbackRetries,
+			CallbackBackoff:          callbackBackoff,
+			ProgressLogInterval:      progressLogInterval,
+			DefaultCurrency:          getEnv("DEFAULT_CURRENCY", ""),
+			BankColumnAliases:        bankColumnAliases,
+			BankSourceFingerprints:   bankSourceFingerprints,
+			JournalBankAccount:       getEnv("JOURNAL_BANK_ACCOUNT", "Bank"),
+			JournalClearingAccount:   getEnv("JOURNAL_CLEARING_ACCOUNT", "Clearing"),
+			JournalSuspenseAccount:   getEnv("JOURNAL_SUSPENSE_ACCOUNT", "Suspense"),
+			SkipRows:                 skipRows,
+			DetectHeader:             getEnv("PARSER_DETECT_HEADER", "false") == "true",
+			MaxRowErrors:             maxRowErrors,
+			CSVDelimiter:             csvDelimiter,
+			CSVComment:               csvComment,
+			AmountDecimalComma:       getEnv("AMOUNT_DECIMAL_COMMA", "false") == "true",
+			DateFormats:              dateFormats,
+			DateDayFirst:             getEnv("DATE_DAY_FIRST", "true") == "true",
+			BankDateLayouts:          bankDateLayouts,
+			BalanceColumn:            getEnv("BALANCE_ROW_COLUMN", ""),
+			BalanceOpeningPattern:    getEnv("BALANCE_OPENING_PATTERN", ""),
+			BalanceClosingPattern:    getEnv("BALANCE_CLOSING_PATTERN", ""),
+			ValidateBalance:          getEnv("BALANCE_VALIDATE", "false") == "true",
+			AttachmentDir:            getEnv("ATTACHMENT_DIR", "./data/attachments"),
+			MaxArchiveSize:           maxArchiveMB << 20,
+			BankFileWorkers:          bankFileWorkers,
+			SummaryCacheSize:         summaryCacheSize,
+			SummaryCacheTTL:          summaryCacheTTL,
+			WebhookTimeout:           webhookTimeout,
+			WebhookRetries:           webhookRetries,
+			WebhookBackoff:           webhookBackoff,
+			WebhookSecret:            os.Getenv("WEBHOOK_SECRET"),
+			PersistBankStatements:    getEnv("PERSIST_BANK_STATEMENTS", "true") == "true",
+			StreamSystemTransactions: getEnv("RECONCILE_STREAMING", "false") == "true",
+			ResultHashChain:          getEnv("RESULT_HASH_CHAIN", "false") == "true",
+			ResultChainKey:           os.Getenv("RESULT_CHAIN_KEY"),
+			IdempotencyKeyTTL:        idempotencyKeyTTL,
+			ReconcileRateLimit:       reconcileRateLimit,
+			ReconcileRateBurst:       reconcileRateBurst,
+			ReconcileRateLimitBy:     reconcileRateLimitBy,
+		},
+		Matcher: MatcherConfig{
+			MinAmount:                   minAmount,
+			MaxAmount:                   maxAmount,
+			DateWindowDays:              dateWindowDays,
+			SplitByDirection:            getEnv("MATCH_SPLIT_BY_DIRECTION", "false") == "true",
+			PerSource:                   getEnv("MATCH_PER_SOURCE", "false") == "true",
+			EnrichResults:               getEnv("RESULT_ENRICHMENT", "false") == "true",
+			DuplicatePolicy:             getEnv("MATCH_DUPLICATE_POLICY", "first"),
+			Strategy:                    getEnv("MATCH_STRATEGY", "exact"),
+			AmountTolerance:             amountTolerance,
+			NormalizeStripPatterns:      normalizeStripPatterns,
+			InstallmentPattern:          getEnv("MATCH_INSTALLMENT_PATTERN", `^(.+)-\d+$`),
+			ReferenceField:              getEnv("MATCH_REFERENCE_FIELD", "trx_id"),
+			AmountScale:                 int32(amountScale),
+			CurrencyScales:              currencyScales,
+			ToleranceBps:                toleranceBps,
+			SourceToleranceBps:          sourceToleranceBps,
+			BankAmountConvention:        getEnv("BANK_AMOUNT_CONVENTION", "debits_negative"),
+			SourceBankAmountConventions: sourceBankAmountConventions,
+			UnsignedAmounts:             getEnv("MATCH_UNSIGNED_AMOUNTS", "false") == "true",
+			UniqueClaims:                getEnv("MATCH_UNIQUE_CLAIMS", "false") == "true",
+			Workers:                     workers,
+			SystemReferencePattern:      getEnv("SYSTEM_REFERENCE_PATTERN", ""),
+			BankReferencePatterns:       bankReferencePatterns,
+		},
+		Metrics: MetricsConfig{
+			StatsDAddr:   getEnv("STATSD_ADDR", ""),
+			StatsDPrefix: getEnv("STATSD_PREFIX", "recon"),
+			StatsDTags:   getEnv("STATSD_TAGS", "false") == "true",
+		},
+		Storage: StorageConfig{
+			S3Region:           getEnv("AWS_REGION", getEnv("AWS_DEFAULT_REGION", "us-east-1")),
+			S3Endpoint:         getEnv("S3_ENDPOINT", ""),
+			S3PathStyle:        getEnv("S3_FORCE_PATH_STYLE", "false") == "true",
+			AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+			AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+			AWSSessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
+			GCSHMACAccessID:    getEnv("GCS_HMAC_ACCESS_ID", ""),
+			GCSHMACSecret:      getEnv("GCS_HMAC_SECRET", ""),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			Insecure:    getEnv("OTEL_EXPORTER_OTLP_INSECURE", "false") == "true",
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "recon-engine"),
+			SampleRatio: traceSampleRatio,
+		},
+		Sinks: ResultSinkConfig{
+			Sinks:         resultSinks,
+			Timeout:       sinkTimeout,
+			KafkaRESTURL:  getEnv("KAFKA_REST_URL", ""),
+			KafkaTopic:    getEnv("KAFKA_TOPIC", "recon.exceptions"),
+			KafkaUsername: getEnv("KAFKA_USERNAME", ""),
+			KafkaPassword: os.Getenv("KAFKA_PASSWORD"),
+			KafkaTimeout:  kafkaTimeout,
+			KafkaRetries:  kafkaRetries,
+			KafkaBackoff:  kafkaBackoff,
 		},
 	}, nil
 }
@@ -62,9 +698,41 @@ func (c *DatabaseConfig) ConnectionString() string {
 	)
 }
 
+// validDateFormat reports whether layout holds at least one element of the
+// Go reference time: formatting any other time then changes it
+func validDateFormat(layout string) bool {
+	other := time.Date(2011, 11, 23, 21, 37, 48, 0, time.UTC)
+	return strings.TrimSpace(layout) != "" && other.Format(layout) != layout
+}
+
+// parseDelimiter reads a single character separator; "tab" or "\t" is a tab
+func parseDelimiter(value string) (rune, error) {
+	if value == "tab" || value == `\t` {
+		return '\t', nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("must be a single character other than a quote or line break")
+	}
+	return runes[0], nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// getEnvDecimal returns nil when the variable is unset
+func getEnvDecimal(key string) (*decimal.Decimal, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return &d, nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// AgingBuckets counts unmatched results by their age in days on the day the
+// job ran
+type AgingBuckets struct {
+	Days0To1  int `json:"0-1"`
+	Days2To7  int `json:"2-7"`
+	Days8To30 int `json:"8-30"`
+	Over30    int `json:"30+"`
+}
+
+// Add counts one result of the given age
+func (b *AgingBuckets) Add(days int) {
+	switch {
+	case days <= 1:
+		b.Days0To1++
+	case days <= 7:
+		b.Days2To7++
+	case days <= 30:
+		b.Days8To30++
+	default:
+		b.Over30++
+	}
+}
+
+// AgingBoundaries are the smallest ages of the buckets after 0-1
+var AgingBoundaries = []int{2, 8, 31}
+
+// IsUnmatched reports whether status is one that ages
+func IsUnmatched(status MatchStatus) bool {
+	return status == UnmatchedSystem || status == UnmatchedBank
+}
+
+// AgeDays is the number of calendar days (UTC) from transactionDate to
+// runDate, never negative
+func AgeDays(transactionDate, runDate time.Time) int {
+	days := int(utcDay(runDate).Sub(utcDay(transactionDate)).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// AgedBefore returns the instant before which a transaction is at least
+// minAgeDays old on runDate
+func AgedBefore(runDate time.Time, minAgeDays int) time.Time {
+	return utcDay(runDate).AddDate(0, 0, 1-minAgeDays)
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,4 @@
+package domain
+
+// MaxAmountScale is the decimal places the amount columns store
+const MaxAmountScale = 4
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Attachment is a supporting document attached to a reconciliation result
+type Attachment struct {
+	ID          int       `json:"id" db:"id"`
+	ResultID    int       `json:"result_id" db:"result_id"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size_bytes"`
+	Description string    `json:"description,omitempty" db:"description"`
+	StorageKey  string    `json:"-" db:"storage_key"` // Location in the file store
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
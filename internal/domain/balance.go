@@ -0,0 +1,45 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// BalanceCheck compares the net totals of both sides, credits positive and
+// debits negative, regardless of how individual lines matched
+type BalanceCheck struct {
+	SystemNet decimal.Decimal `json:"system_net"`
+	BankNet   decimal.Decimal `json:"bank_net"`
+	// Difference is system minus bank: positive when the bank shows less
+	Difference decimal.Decimal `json:"difference"`
+	Balanced   bool            `json:"balanced"`
+	// UnsignedBank counts bank amounts with no direction, which are left out
+	// of BankNet
+	UnsignedBank int `json:"unsigned_bank,omitempty"`
+}
+
+// NewBalanceCheck compares the two net totals
+func NewBalanceCheck(systemNet, bankNet decimal.Decimal, unsignedBank int) *BalanceCheck {
+	difference := systemNet.Sub(bankNet)
+	return &BalanceCheck{
+		SystemNet:    systemNet,
+		BankNet:      bankNet,
+		Difference:   difference,
+		Balanced:     difference.IsZero(),
+		UnsignedBank: unsignedBank,
+	}
+}
+
+// LedgerBalance checks the opening and closing balances of a bank account
+// against the reconciled lines. Expected is the opening balance carried
+// forward by the matched system transactions; Actual is the reported closing
+// balance less what the per-line exceptions (unmatched bank lines and the
+// amount gaps of pairs) already explain. Difference is what remains.
+type LedgerBalance struct {
+	Opening    decimal.Decimal `json:"opening"`
+	Closing    decimal.Decimal `json:"closing"`
+	Expected   decimal.Decimal `json:"expected"`
+	Actual     decimal.Decimal `json:"actual"`
+	Difference decimal.Decimal `json:"difference"`
+	Balanced   bool            `json:"balanced"`
+	// UnsignedBank counts unpaired bank amounts with no direction, which
+	// are left out of Actual
+	UnsignedBank int `json:"unsigned_bank,omitempty"`
+}
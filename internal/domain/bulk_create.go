@@ -0,0 +1,53 @@
+package domain
+
+import "sort"
+
+// BulkRowStatus is the outcome of one row of a bulk create
+type BulkRowStatus string
+
+const (
+	BulkRowInserted BulkRowStatus = "INSERTED"
+	// BulkRowDuplicate rows carry a trx_id that is already stored
+	BulkRowDuplicate BulkRowStatus = "SKIPPED_DUPLICATE"
+	BulkRowFailed    BulkRowStatus = "FAILED"
+)
+
+// BulkRowOutcome reports one row; Index is its position in the request
+type BulkRowOutcome struct {
+	Index  int           `json:"index"`
+	TrxID  string        `json:"trx_id"`
+	Status BulkRowStatus `json:"status"`
+	Reason string        `json:"reason,omitempty"`
+}
+
+// BulkCreateReport lists the outcome of every row of a bulk create, in
+// request order, with a count per status
+type BulkCreateReport struct {
+	Inserted int              `json:"inserted"`
+	Skipped  int              `json:"skipped"`
+	Failed   int              `json:"failed"`
+	Rows     []BulkRowOutcome `json:"rows"`
+}
+
+// Add records the outcome of one row
+func (r *BulkCreateReport) Add(outcome BulkRowOutcome) {
+	switch outcome.Status {
+	case BulkRowInserted:
+		r.Inserted++
+	case BulkRowDuplicate:
+		r.Skipped++
+	case BulkRowFailed:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, outcome)
+}
+
+// Merge adds the rows of a report built from part of the input, whose row i
+// was row positions[i] of the whole input, keeping the rows in input order
+func (r *BulkCreateReport) Merge(part *BulkCreateReport, positions []int) {
+	for _, outcome := range part.Rows {
+		outcome.Index = positions[outcome.Index]
+		r.Add(outcome)
+	}
+	sort.SliceStable(r.Rows, func(i, j int) bool { return r.Rows[i].Index < r.Rows[j].Index })
+}
@@ -0,0 +1,13 @@
+package domain
+
+// ChainVerification reports whether a job's stored results still follow the
+// hash chain recorded when they were saved
+type ChainVerification struct {
+	JobID          string `json:"job_id"`
+	Valid          bool   `json:"valid"`
+	ResultsChecked int    `json:"results_checked"`
+	// BrokenResultID is the first result whose hash does not follow from the
+	// results before it; nil when the chain is intact or breaks at its end
+	BrokenResultID *int   `json:"broken_result_id,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
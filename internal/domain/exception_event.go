@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExceptionEventVersion is raised only when an ExceptionEvent field is
+// removed or changes meaning; new optional fields keep the version
+const ExceptionEventVersion = 1
+
+// ExceptionEventType tells whether an exception appeared or was resolved
+type ExceptionEventType string
+
+const (
+	// ExceptionRaised is sent for each exception of a completed job, and for
+	// an exception that replaced another one
+	ExceptionRaised ExceptionEventType = "raised"
+	// ExceptionResolved is sent when a reprocess or manual match replaces an
+	// exception; ResolvedAs is the status of its replacement
+	ExceptionResolved ExceptionEventType = "resolved"
+)
+
+// ExceptionEvent is the message sent for each result of a job other than
+// MATCHED, and for each such result later replaced. EventID is the same for
+// every delivery of the event, so consumers can drop repeats: the job ID
+// and the exception's position in the job for a completed job's exceptions,
+// or the job ID and the replaced result's ID for changes.
+type ExceptionEvent struct {
+	SchemaVersion     int                `json:"schema_version"`
+	EventID           string             `json:"event_id"`
+	EventType         ExceptionEventType `json:"event_type"`
+	JobID             string             `json:"job_id"`
+	JobStartDate      time.Time          `json:"job_start_date"`
+	JobEndDate        time.Time          `json:"job_end_date"`
+	MatchStatus       MatchStatus        `json:"match_status"`
+	TrxID             *string            `json:"trx_id,omitempty"`
+	TrxRefID          *string            `json:"trx_ref_id,omitempty"`
+	SystemAmount      *decimal.Decimal   `json:"system_amount,omitempty"`
+	BankAmount        *decimal.Decimal   `json:"bank_amount,omitempty"`
+	Discrepancy       *decimal.Decimal   `json:"discrepancy,omitempty"`
+	SignedDiscrepancy *decimal.Decimal   `json:"signed_discrepancy,omitempty"`
+	BankSource        *string            `json:"bank_source,omitempty"`
+	TransactionDate   *time.Time         `json:"transaction_date,omitempty"`
+	Currency          *string            `json:"currency,omitempty"`
+	BankCurrency      *string            `json:"bank_currency,omitempty"`
+	Description       *string            `json:"description,omitempty"`
+	BankDescription   *string            `json:"bank_description,omitempty"`
+	ResolvedAs        *MatchStatus       `json:"resolved_as,omitempty"`
+	ReviewedBy        *string            `json:"reviewed_by,omitempty"`
+	EmittedAt         time.Time          `json:"emitted_at"`
+}
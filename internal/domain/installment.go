@@ -0,0 +1,19 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// InstallmentMatch is a system transaction paired with the bank installments
+// paying it
+type InstallmentMatch struct {
+	TrxID       string      `json:"trx_id"`
+	BankSource  string      `json:"bank_source"`
+	MatchStatus MatchStatus `json:"match_status"`
+	// SystemAmount is the parent amount and PaidAmount the installments' total
+	SystemAmount decimal.Decimal `json:"system_amount"`
+	PaidAmount   decimal.Decimal `json:"paid_amount"`
+	// Outstanding is what the installments fall short of the parent amount;
+	// Partial is set when it is above zero
+	Outstanding  decimal.Decimal `json:"outstanding"`
+	Partial      bool            `json:"partial"`
+	Installments []BankStatement `json:"installments"`
+}
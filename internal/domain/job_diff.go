@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// JobDiff compares the results of a job with those of a later one. Items
+// are matched across the jobs by trx_id, or by bank source and trx_ref_id
+// for bank statements without a system transaction.
+type JobDiff struct {
+	JobID      string `json:"job_id"`
+	OtherJobID string `json:"other_job_id"`
+	// OverlapStart and OverlapEnd bound the days both jobs cover; they are
+	// nil when the date ranges do not overlap
+	OverlapStart *time.Time `json:"overlap_start,omitempty"`
+	OverlapEnd   *time.Time `json:"overlap_end,omitempty"`
+	// NewlyMatched items are matched in the other job after an exception
+	NewlyMatched []JobDiffItem `json:"newly_matched"`
+	// NewlyUnmatched items are unmatched in the other job but were matched,
+	// another exception or absent before
+	NewlyUnmatched []JobDiffItem `json:"newly_unmatched"`
+	StillUnmatched []JobDiffItem `json:"still_unmatched"`
+	// ResolvedDiscrepancies were amount discrepancies and are now matched
+	ResolvedDiscrepancies []JobDiffItem `json:"resolved_discrepancies"`
+	// OutOfRange counts the results left out because they fall outside the
+	// overlap of the two date ranges
+	OutOfRange int `json:"out_of_range"`
+}
+
+// JobDiffItem is one item of a JobDiff with its status in each job
+type JobDiffItem struct {
+	TrxID      *string `json:"trx_id,omitempty"`
+	TrxRefID   *string `json:"trx_ref_id,omitempty"`
+	BankSource *string `json:"bank_source,omitempty"`
+	// PreviousStatus is empty when the first job has no result for the item
+	PreviousStatus MatchStatus `json:"previous_status,omitempty"`
+	Status         MatchStatus `json:"status"`
+}
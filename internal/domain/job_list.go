@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// JobFilter selects jobs to list. Empty fields match every job; the creation
+// time range is half-open: CreatedFrom <= created_at < CreatedBefore.
+type JobFilter struct {
+	Status        JobStatus
+	CreatedFrom   *time.Time
+	CreatedBefore *time.Time
+}
+
+// JobPage is one page of a job listing
+type JobPage struct {
+	Jobs       []ReconciliationJob `json:"jobs"`
+	Page       int                 `json:"page"`
+	Size       int                 `json:"size"`
+	Total      int                 `json:"total"`
+	TotalPages int                 `json:"total_pages"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ResultCount is the number of a job's results with one status from one
+// bank source. Results without a bank source have an empty BankSource.
+type ResultCount struct {
+	BankSource  string      `json:"bank_source"`
+	MatchStatus MatchStatus `json:"match_status"`
+	Count       int         `json:"count"`
+}
+
+// JobStats holds the headline numbers of a job without its result rows
+type JobStats struct {
+	JobID              string              `json:"job_id"`
+	Status             JobStatus           `json:"status"`
+	StartDate          time.Time           `json:"start_date"`
+	EndDate            time.Time           `json:"end_date"`
+	TotalProcessed     int                 `json:"total_processed"`
+	TotalMatched       int                 `json:"total_matched"`
+	TotalUnmatched     int                 `json:"total_unmatched"`
+	TotalDiscrepancies decimal.Decimal     `json:"total_discrepancies"`
+	NetDiscrepancy     decimal.Decimal     `json:"net_discrepancy"`
+	TotalResults       int                 `json:"total_results"`
+	StatusCounts       map[MatchStatus]int `json:"status_counts"`
+	// BySource counts results per bank source and status; results with no
+	// bank statement, such as UNMATCHED_SYSTEM, are left out
+	BySource map[string]map[MatchStatus]int `json:"by_source"`
+}
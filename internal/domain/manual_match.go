@@ -0,0 +1,20 @@
+package domain
+
+// ManualMatch names an unmatched system result and an unmatched bank result
+// a reviewer has found to be the same payment
+type ManualMatch struct {
+	TrxID    string
+	TrxRefID string
+	// BankSource picks the bank result when several sources report TrxRefID
+	BankSource string
+	ReviewedBy string
+}
+
+// ManualMatchResult reports a manual match stored in place of the two
+// unmatched results
+type ManualMatchResult struct {
+	JobID  string               `json:"job_id"`
+	Result ReconciliationResult `json:"result"`
+	// Job carries the updated totals
+	Job *ReconciliationJob `json:"job"`
+}
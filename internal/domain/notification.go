@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// JobNotification is posted to a job's callback URL when it completes or fails
+type JobNotification struct {
+	JobID              string          `json:"job_id"`
+	Status             JobStatus       `json:"status"`
+	StartDate          time.Time       `json:"start_date"`
+	EndDate            time.Time       `json:"end_date"`
+	TotalProcessed     int             `json:"total_processed"`
+	TotalMatched       int             `json:"total_matched"`
+	TotalUnmatched     int             `json:"total_unmatched"`
+	TotalDiscrepancies decimal.Decimal `json:"total_discrepancies"`
+	NetDiscrepancy     decimal.Decimal `json:"net_discrepancy"`
+	ErrorMessage       string          `json:"error_message,omitempty"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// JobTotals are the totals of a job that follow from its stored results
+type JobTotals struct {
+	TotalMatched       int             `json:"total_matched"`
+	TotalUnmatched     int             `json:"total_unmatched"`
+	TotalDiscrepancies decimal.Decimal `json:"total_discrepancies"`
+	NetDiscrepancy     decimal.Decimal `json:"net_discrepancy"`
+}
+
+// Equal reports whether both totals agree
+func (t JobTotals) Equal(other JobTotals) bool {
+	return t.TotalMatched == other.TotalMatched &&
+		t.TotalUnmatched == other.TotalUnmatched &&
+		t.TotalDiscrepancies.Equal(other.TotalDiscrepancies) &&
+		t.NetDiscrepancy.Equal(other.NetDiscrepancy)
+}
+
+// JobRecompute reports a job's totals recounted from its stored results
+type JobRecompute struct {
+	JobID        string `json:"job_id"`
+	TotalResults int    `json:"total_results"`
+	// Previous holds the totals stored before the recount; Changed is set
+	// when they differed from the recounted ones and were rewritten
+	Previous JobTotals `json:"previous"`
+	Totals   JobTotals `json:"totals"`
+	Changed  bool      `json:"changed"`
+	// Job carries the corrected totals
+	Job *ReconciliationJob `json:"job"`
+}
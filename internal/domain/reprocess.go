@@ -0,0 +1,35 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// JobTotalsDelta is added to a job's stored totals when some of its results
+// are replaced
+type JobTotalsDelta struct {
+	Processed          int
+	Matched            int
+	Unmatched          int
+	TotalDiscrepancies decimal.Decimal
+	NetDiscrepancy     decimal.Decimal
+}
+
+// ReprocessSummary reports a retry of a job's unmatched system results
+// against late bank files
+type ReprocessSummary struct {
+	JobID string `json:"job_id"`
+	// Reprocessed counts the unmatched system results retried
+	Reprocessed int `json:"reprocessed"`
+	// Resolved counts those now paired with a bank statement; their results
+	// were replaced by the new ones
+	Resolved       int `json:"resolved"`
+	StillUnmatched int `json:"still_unmatched"`
+	// Skipped counts unmatched results left as they were because the
+	// transaction type could not be recovered
+	Skipped int `json:"skipped,omitempty"`
+	// UnmatchedBank counts rows of the new files no retried transaction
+	// matched; they are not stored with the job
+	UnmatchedBank  int                    `json:"unmatched_bank"`
+	Results        []ReconciliationResult `json:"results"`
+	FileLoadReport []FileLoadReport       `json:"file_load_report,omitempty"`
+	// Job carries the updated totals
+	Job *ReconciliationJob `json:"job"`
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ResultFilter selects a job's results. Empty fields match every result. The
+// amount bounds are inclusive and compare the magnitude of the system amount,
+// or of the bank amount for results without one.
+type ResultFilter struct {
+	Status     MatchStatus
+	BankSource string
+	MinAmount  *decimal.Decimal
+	MaxAmount  *decimal.Decimal
+	// TrxID and TrxRefID match the references exactly
+	TrxID    string
+	TrxRefID string
+	// MinDiscrepancy keeps results whose absolute discrepancy is at least
+	// this; results without a discrepancy never match it
+	MinDiscrepancy *decimal.Decimal
+	// MinConfidence keeps paired results scored at least this; unpaired
+	// results have no score and never match it
+	MinConfidence *float64
+	// MinAgeDays keeps unmatched results at least this old on the job's run
+	// date; the service turns it into UnmatchedBefore
+	MinAgeDays int
+	// UnmatchedBefore keeps unmatched results dated before it
+	UnmatchedBefore *time.Time
+}
+
+// ResultCursor is the position after which a keyset page of results starts:
+// the created_at and id of the last result already seen
+type ResultCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// CursorAfter returns the cursor positioned after result
+func CursorAfter(result ReconciliationResult) ResultCursor {
+	return ResultCursor{CreatedAt: result.CreatedAt, ID: result.ID}
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c ResultCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseResultCursor decodes a token made by Encode
+func ParseResultCursor(token string) (ResultCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ResultCursor{}, fmt.Errorf("malformed cursor")
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return ResultCursor{}, fmt.Errorf("malformed cursor")
+	}
+	var cursor ResultCursor
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return ResultCursor{}, fmt.Errorf("malformed cursor")
+	}
+	if cursor.ID, err = strconv.Atoi(id); err != nil {
+		return ResultCursor{}, fmt.Errorf("malformed cursor")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RollupSummary consolidates the persisted results of several completed
+// jobs, e.g. the daily jobs of a month-end close
+type RollupSummary struct {
+	JobIDs             []string                 `json:"job_ids"`
+	StartDate          time.Time                `json:"start_date"`
+	EndDate            time.Time                `json:"end_date"`
+	TotalResults       int                      `json:"total_results"`
+	TotalMatched       int                      `json:"total_matched"`
+	TotalUnmatched     int                      `json:"total_unmatched"`
+	TotalDiscrepancies decimal.Decimal          `json:"total_discrepancies"`
+	StatusCounts       map[MatchStatus]int      `json:"status_counts"`
+	BySource           map[string]*SourceRollup `json:"by_source"`
+	// SupersededResults counts results dropped because a later job covered
+	// the same transaction or bank statement
+	SupersededResults int `json:"superseded_results"`
+}
+
+// SourceRollup holds the consolidated totals of one bank source
+type SourceRollup struct {
+	TotalResults       int             `json:"total_results"`
+	TotalMatched       int             `json:"total_matched"`
+	TotalUnmatched     int             `json:"total_unmatched"`
+	TotalDiscrepancies decimal.Decimal `json:"total_discrepancies"`
+}
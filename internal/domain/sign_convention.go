@@ -0,0 +1,14 @@
+package domain
+
+// SignConvention is the amount sign convention detected for a bank source
+// configured as auto
+type SignConvention struct {
+	Convention string `json:"convention"`
+	// Detected is false when the ID pairs were too few or too mixed to tell;
+	// the source's amounts were then left as they are
+	Detected bool `json:"detected"`
+	// Pairs counts the ID pairs whose signs were compared; Agreement is the
+	// share of them the convention explains
+	Pairs     int     `json:"pairs"`
+	Agreement float64 `json:"agreement"`
+}
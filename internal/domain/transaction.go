@@ -12,14 +12,43 @@ type TransactionType string
 const (
 	Debit  TransactionType = "DEBIT"
 	Credit TransactionType = "CREDIT"
+	// Refund and Chargeback reverse an earlier credit and move money out like
+	// a debit. Their amounts may be recorded negative or as magnitudes.
+	Refund     TransactionType = "REFUND"
+	Chargeback TransactionType = "CHARGEBACK"
 )
 
+// Valid reports whether t is one of the known transaction types
+func (t TransactionType) Valid() bool {
+	return t == Debit || t == Credit || t.IsReversal()
+}
+
+// IsReversal reports whether t reverses an earlier credit; reversals are the
+// only transactions whose amounts may be negative
+func (t TransactionType) IsReversal() bool {
+	return t == Refund || t == Chargeback
+}
+
+// Direction is the way money moves: Debit for debits and reversals, Credit
+// otherwise
+func (t TransactionType) Direction() TransactionType {
+	if t == Debit || t.IsReversal() {
+		return Debit
+	}
+	return Credit
+}
+
 // Transaction represents a system transaction
 type Transaction struct {
 	ID              int             `json:"id" db:"id"`
 	TrxID           string          `json:"trx_id" db:"trx_id"`
+	// OrderID is an optional external reference some banks report instead of trx_id
+	OrderID         string          `json:"order_id,omitempty" db:"order_id"`
+	// Description is optional free text, such as a merchant name or memo
+	Description     string          `json:"description,omitempty" db:"description"`
 	Amount          decimal.Decimal `json:"amount" db:"amount"`
 	Type            TransactionType `json:"type" db:"type"`
+	Currency        string          `json:"currency,omitempty"` // ISO 4217 code, empty when unknown
 	TransactionTime time.Time       `json:"transaction_time" db:"transaction_time"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
@@ -31,16 +60,39 @@ type BankStatement struct {
 	Amount   decimal.Decimal `json:"amount"`
 	Date     time.Time       `json:"date"`
 	Source   string          `json:"source"` // Bank identifier
+	Currency string          `json:"currency,omitempty"` // ISO 4217 code, empty when unknown
+	Type     TransactionType `json:"type,omitempty"`     // Explicit direction, empty when implied by the amount sign
+	JobID    string          `json:"job_id,omitempty"`   // Job that imported the statement from a file
+	// Description is the bank's free text for the line, such as a memo
+	Description string       `json:"description,omitempty"`
+	// Installments are the statements a group merged by the installment
+	// strategy was built from; never stored
+	Installments []BankStatement `json:"installments,omitempty"`
+}
+
+// DayAfter returns midnight following the calendar day of t. Reconciliation
+// ranges are half-open, so this is the exclusive upper bound that keeps every
+// instant of t's day, including sub-second times just before midnight.
+func DayAfter(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
 }
 
 // MatchStatus represents the reconciliation match status
 type MatchStatus string
 
 const (
-	Matched          MatchStatus = "MATCHED"
-	UnmatchedSystem  MatchStatus = "UNMATCHED_SYSTEM"
-	UnmatchedBank    MatchStatus = "UNMATCHED_BANK"
-	Discrepancy      MatchStatus = "DISCREPANCY"
+	Matched            MatchStatus = "MATCHED"
+	UnmatchedSystem    MatchStatus = "UNMATCHED_SYSTEM"
+	UnmatchedBank      MatchStatus = "UNMATCHED_BANK"
+	Discrepancy        MatchStatus = "DISCREPANCY"
+	DateMismatch       MatchStatus = "DATE_MISMATCH"
+	CurrencyMismatch   MatchStatus = "CURRENCY_MISMATCH"
+	DirectionMismatch  MatchStatus = "DIRECTION_MISMATCH"
+	DuplicateSystem    MatchStatus = "DUPLICATE_SYSTEM"
+	DuplicateBank      MatchStatus = "DUPLICATE_BANK"
+	MalformedReference MatchStatus = "MALFORMED_REFERENCE"
+	// AmbiguousMatch flags items tied with others on amount and date
+	AmbiguousMatch     MatchStatus = "AMBIGUOUS_MATCH"
 )
 
 // ReconciliationResult represents the result of matching
@@ -52,9 +104,31 @@ type ReconciliationResult struct {
 	SystemAmount    *decimal.Decimal `json:"system_amount,omitempty" db:"system_amount"`
 	BankAmount      *decimal.Decimal `json:"bank_amount,omitempty" db:"bank_amount"`
 	Discrepancy     *decimal.Decimal `json:"discrepancy,omitempty" db:"discrepancy"`
+	// SignedDiscrepancy is system minus bank; negative when the bank reported more
+	SignedDiscrepancy *decimal.Decimal `json:"signed_discrepancy,omitempty" db:"signed_discrepancy"`
 	MatchStatus     MatchStatus     `json:"match_status" db:"match_status"`
 	BankSource      *string         `json:"bank_source,omitempty" db:"bank_source"`
 	TransactionDate *time.Time      `json:"transaction_date,omitempty" db:"transaction_date"`
+	// Optional enrichment copied from the system transaction
+	TransactionType      *TransactionType `json:"transaction_type,omitempty" db:"transaction_type"`
+	TransactionCreatedAt *time.Time       `json:"transaction_created_at,omitempty" db:"transaction_created_at"`
+	Currency             *string          `json:"currency,omitempty" db:"currency"`
+	BankCurrency         *string          `json:"bank_currency,omitempty" db:"bank_currency"`
+	// Descriptions of the system transaction and bank statement, for review
+	Description          *string          `json:"description,omitempty" db:"description"`
+	BankDescription      *string          `json:"bank_description,omitempty" db:"bank_description"`
+	// MatchedVia names the matching strategy that paired the records
+	MatchedVia           *string          `json:"matched_via,omitempty" db:"matched_via"`
+	// Confidence rates a paired result's match from 0 to 1; 1 for exact matches
+	Confidence           *float64         `json:"confidence,omitempty" db:"confidence"`
+	// ManualMatch marks a pair made by a reviewer, named in ReviewedBy
+	ManualMatch          bool             `json:"manual_match,omitempty" db:"manual_match"`
+	ReviewedBy           *string          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	// ChainHash links the result to the one saved before it, when enabled
+	ChainHash            *string          `json:"chain_hash,omitempty" db:"chain_hash"`
+	// AgeDays is how old an unmatched result was on the day its job ran;
+	// it is computed when results are read, not stored
+	AgeDays              *int             `json:"age_days,omitempty" db:"-"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 }
 
@@ -79,7 +153,13 @@ type ReconciliationJob struct {
 	TotalMatched        int             `json:"total_matched" db:"total_matched"`
 	TotalUnmatched      int             `json:"total_unmatched" db:"total_unmatched"`
 	TotalDiscrepancies  decimal.Decimal `json:"total_discrepancies" db:"total_discrepancies"`
+	NetDiscrepancy      decimal.Decimal `json:"net_discrepancy" db:"net_discrepancy"`
 	ErrorMessage        *string         `json:"error_message,omitempty" db:"error_message"`
+	ResultChainHead     *string         `json:"result_chain_head,omitempty" db:"result_chain_head"`
+	// ResultChainVersion is the encoding the chain was hashed with
+	ResultChainVersion  int             `json:"result_chain_version,omitempty" db:"result_chain_version"`
+	// ExceptionsOnly jobs stored no MATCHED results; their totals still count them
+	ExceptionsOnly      bool            `json:"exceptions_only,omitempty" db:"exceptions_only"`
 	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -91,7 +171,111 @@ type ReconciliationSummary struct {
 	TotalMatched       int                        `json:"total_matched"`
 	TotalUnmatched     int                        `json:"total_unmatched"`
 	TotalDiscrepancies decimal.Decimal            `json:"total_discrepancies"`
+	// NetDiscrepancy sums the signed discrepancies, so opposing ones cancel
+	NetDiscrepancy     decimal.Decimal            `json:"net_discrepancy"`
+	ExcludedSystem     int                        `json:"excluded_system,omitempty"`
+	ExcludedBank       int                        `json:"excluded_bank,omitempty"`
 	UnmatchedSystem    []ReconciliationResult     `json:"unmatched_system,omitempty"`
 	UnmatchedBank      map[string][]ReconciliationResult `json:"unmatched_bank,omitempty"`
 	Discrepancies      []ReconciliationResult     `json:"discrepancies,omitempty"`
+	DateMismatches     []ReconciliationResult     `json:"date_mismatches,omitempty"`
+	CurrencyMismatches []ReconciliationResult     `json:"currency_mismatches,omitempty"`
+	DirectionMismatches []ReconciliationResult    `json:"direction_mismatches,omitempty"`
+	DuplicateSystem    []ReconciliationResult     `json:"duplicate_system,omitempty"`
+	DuplicateBank      []ReconciliationResult     `json:"duplicate_bank,omitempty"`
+	MalformedReferences []ReconciliationResult    `json:"malformed_references,omitempty"`
+	AmbiguousMatches   []ReconciliationResult     `json:"ambiguous_matches,omitempty"`
+	// Installments lists the installment rows of each system transaction
+	// the installment strategy paired, flagging those not paid in full
+	Installments       []InstallmentMatch         `json:"installments,omitempty"`
+	// UnmatchedAging buckets the unmatched results by age in days
+	UnmatchedAging      *AgingBuckets              `json:"unmatched_aging,omitempty"`
+	// BalanceDiscrepancy compares the system and bank net totals, whether or
+	// not the lines matched
+	BalanceDiscrepancy *BalanceCheck              `json:"balance_discrepancy,omitempty"`
+	// LedgerBalance is set when the request gave opening and closing balances
+	LedgerBalance      *LedgerBalance             `json:"balance_check,omitempty"`
+	Debits             *DirectionSummary          `json:"debits,omitempty"`
+	Credits            *DirectionSummary          `json:"credits,omitempty"`
+	// BySource holds each bank source's own summary when sources are
+	// reconciled separately
+	BySource           map[string]*ReconciliationSummary `json:"by_source,omitempty"`
+	// SignConventions reports the amount convention detected for each bank
+	// source configured as auto
+	SignConventions    map[string]SignConvention  `json:"sign_conventions,omitempty"`
+	// FailureReason is set when the job failed a discrepancy or unmatched
+	// threshold; its results are saved all the same
+	FailureReason      string                     `json:"failure_reason,omitempty"`
+	// Truncated is set when a result list above was capped; page through
+	// the results endpoint for the full set
+	Truncated          bool                       `json:"truncated,omitempty"`
+	// DryRun is set when nothing was persisted; the summary has no job ID
+	DryRun             bool                       `json:"dry_run,omitempty"`
+	// FileLoadReport lists each bank file with its row count or load error
+	FileLoadReport     []FileLoadReport           `json:"file_load_report,omitempty"`
+	// SystemFileReport lists each system file with its row count; files
+	// with none are flagged empty
+	SystemFileReport   []FileLoadReport           `json:"system_file_report,omitempty"`
+	// Incomplete is set when some bank files failed to load; the totals
+	// cover only the files that loaded
+	Incomplete         bool                       `json:"incomplete,omitempty"`
+	// CrossFileDuplicates lists transaction IDs found in more than one
+	// system file; repeats are also reported as duplicate_system
+	CrossFileDuplicates []DuplicateTransaction    `json:"cross_file_duplicates,omitempty"`
+	// Replayed is set when the summary is of the job an earlier request with
+	// the same idempotency key started
+	Replayed           bool                       `json:"replayed,omitempty"`
+}
+
+// FileLoadReport describes how one bank file loaded
+type FileLoadReport struct {
+	File   string `json:"file"`
+	Source string `json:"source,omitempty"`
+	Rows   int    `json:"rows"`
+	// Empty is set when the file parsed but had no data rows
+	Empty bool   `json:"empty,omitempty"`
+	Error string `json:"error,omitempty"`
+	// SkippedRows counts malformed rows the parser dropped; RowErrors lists
+	// the first of them
+	SkippedRows int        `json:"skipped_rows,omitempty"`
+	RowErrors   []RowError `json:"row_errors,omitempty"`
+}
+
+// DuplicateTransaction is a transaction ID and the system files it appears in
+type DuplicateTransaction struct {
+	TrxID string   `json:"trx_id"`
+	Files []string `json:"files"`
+}
+
+// ResultPage is one page of a job's reconciliation results
+type ResultPage struct {
+	Results    []ReconciliationResult `json:"results"`
+	Page       int                    `json:"page"`
+	Size       int                    `json:"size"`
+	Total      int                    `json:"total"`
+	TotalPages int                    `json:"total_pages"`
+}
+
+// UnmatchedBankPages holds one page of a job's UNMATCHED_BANK results for
+// each bank source
+type UnmatchedBankPages struct {
+	JobID   string                 `json:"job_id"`
+	Sources map[string]*ResultPage `json:"unmatched_bank"`
+}
+
+// ResultCursorPage is one keyset page of results. NextCursor continues after
+// the last result and is empty on the final page.
+type ResultCursorPage struct {
+	Results    []ReconciliationResult `json:"results"`
+	Size       int                    `json:"size"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// DirectionSummary holds the totals of a single-direction reconciliation pass
+type DirectionSummary struct {
+	TotalProcessed     int             `json:"total_processed"`
+	TotalMatched       int             `json:"total_matched"`
+	TotalUnmatched     int             `json:"total_unmatched"`
+	TotalDiscrepancies decimal.Decimal `json:"total_discrepancies"`
+	NetDiscrepancy     decimal.Decimal `json:"net_discrepancy"`
 }
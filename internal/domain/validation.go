@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Validated file kinds
+const (
+	SystemFile = "system"
+	BankFile   = "bank"
+)
+
+// RowError is a row a parser skipped and why. Raw is the row's text as read,
+// truncated to 512 bytes.
+type RowError struct {
+	Line  int    `json:"line"`
+	Raw   string `json:"raw,omitempty"`
+	Error string `json:"error"`
+}
+
+// FileValidation reports a parse of one input file made without matching
+type FileValidation struct {
+	File   string `json:"file"`
+	Kind   string `json:"kind"`
+	Source string `json:"source,omitempty"`
+	Rows   int    `json:"rows"`
+	// SkippedRows counts malformed rows; RowErrors lists the first of them
+	SkippedRows int        `json:"skipped_rows"`
+	RowErrors   []RowError `json:"row_errors,omitempty"`
+	// Columns maps each canonical column read to the file's header name
+	Columns map[string]string `json:"columns,omitempty"`
+	// FirstDate and LastDate span the dates of the rows read
+	FirstDate *time.Time `json:"first_date,omitempty"`
+	LastDate  *time.Time `json:"last_date,omitempty"`
+	// Error is set when the file could not be parsed at all
+	Error string `json:"error,omitempty"`
+}
+
+// AddDate widens the file's date range to include date
+func (f *FileValidation) AddDate(date time.Time) {
+	if f.FirstDate == nil || date.Before(*f.FirstDate) {
+		f.FirstDate = &date
+	}
+	if f.LastDate == nil || date.After(*f.LastDate) {
+		f.LastDate = &date
+	}
+}
+
+// ValidationReport covers every file of a validation, system files first.
+// Valid means every file parsed, with at least one row and none skipped.
+type ValidationReport struct {
+	Valid bool             `json:"valid"`
+	Files []FileValidation `json:"files"`
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// ResultColumns is the column order of the CSV result export
+var ResultColumns = []string{
+	"match_status",
+	"trx_id",
+	"trx_ref_id",
+	"system_amount",
+	"bank_amount",
+	"discrepancy",
+	"bank_source",
+	"transaction_date",
+	"transaction_type",
+	"transaction_created_at",
+	"currency",
+	"bank_currency",
+}
+
+// ResultWriter streams reconciliation results into an export format
+type ResultWriter interface {
+	WriteHeader() error
+	Write(results []domain.ReconciliationResult) error
+}
+
+// CSVResultWriter writes reconciliation results as CSV rows
+type CSVResultWriter struct {
+	writer    *csv.Writer
+	formatter Formatter
+	scales    AmountScales
+}
+
+// WriterOption configures a CSVResultWriter
+type WriterOption func(*CSVResultWriter)
+
+// WithFormatter replaces the default RawFormatter
+func WithFormatter(f Formatter) WriterOption {
+	return func(w *CSVResultWriter) {
+		w.formatter = f
+	}
+}
+
+// WithAmountScales writes amounts with the decimal places of their currency
+// instead of DefaultAmountScales
+func WithAmountScales(scales AmountScales) WriterOption {
+	return func(w *CSVResultWriter) {
+		w.scales = scales
+	}
+}
+
+func NewCSVResultWriter(w io.Writer, opts ...WriterOption) *CSVResultWriter {
+	writer := &CSVResultWriter{writer: csv.NewWriter(w), formatter: RawFormatter{}, scales: DefaultAmountScales}
+	for _, opt := range opts {
+		opt(writer)
+	}
+	return writer
+}
+
+// WriteHeader writes the column header row
+func (w *CSVResultWriter) WriteHeader() error {
+	return w.writer.Write(w.formatter.Columns())
+}
+
+// Write appends a batch of results and flushes them to the underlying writer
+func (w *CSVResultWriter) Write(results []domain.ReconciliationResult) error {
+	f := w.formatter
+	for _, result := range results {
+		places := w.scales.Places(result)
+		record := []string{
+			f.Status(result.MatchStatus),
+			formatString(result.TrxID),
+			formatString(result.TrxRefID),
+			f.Decimal(result.SystemAmount, places),
+			f.Decimal(result.BankAmount, places),
+			f.Decimal(result.Discrepancy, places),
+			formatString(result.BankSource),
+			f.Time(result.TransactionDate),
+			f.Type(result.TransactionType),
+			f.Time(result.TransactionCreatedAt),
+			formatString(result.Currency),
+			formatString(result.BankCurrency),
+		}
+		if err := w.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *CSVResultWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func formatString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatType(t *domain.TransactionType) string {
+	if t == nil {
+		return ""
+	}
+	return string(*t)
+}
+
+func formatDecimal(d *decimal.Decimal, places int32) string {
+	if d == nil {
+		return ""
+	}
+	return d.StringFixed(places)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// Formatter renders result fields as export cells
+type Formatter interface {
+	Columns() []string
+	Status(status domain.MatchStatus) string
+	Type(t *domain.TransactionType) string
+	Decimal(d *decimal.Decimal, places int32) string
+	Time(t *time.Time) string
+}
+
+// AmountScales are the decimal places amounts are written with. ByCurrency
+// is keyed by ISO 4217 code; other currencies, and results without one, use
+// Default.
+type AmountScales struct {
+	Default    int32
+	ByCurrency map[string]int32
+}
+
+// DefaultAmountScales writes every amount with two decimal places
+var DefaultAmountScales = AmountScales{Default: 2}
+
+// Places returns the decimal places of a result's amounts, taken from its
+// system currency or, when it has none, its bank currency
+func (s AmountScales) Places(result domain.ReconciliationResult) int32 {
+	currency := formatString(result.Currency)
+	if currency == "" {
+		currency = formatString(result.BankCurrency)
+	}
+	for code, places := range s.ByCurrency {
+		if strings.EqualFold(code, currency) {
+			return places
+		}
+	}
+	return s.Default
+}
+
+// RawFormatter keeps machine-readable values: status codes, plain decimals
+// and RFC3339 timestamps
+type RawFormatter struct{}
+
+func (RawFormatter) Columns() []string                       { return ResultColumns }
+func (RawFormatter) Status(status domain.MatchStatus) string { return string(status) }
+func (RawFormatter) Type(t *domain.TransactionType) string   { return formatType(t) }
+func (RawFormatter) Time(t *time.Time) string                { return formatTime(t) }
+
+func (RawFormatter) Decimal(d *decimal.Decimal, places int32) string {
+	return formatDecimal(d, places)
+}
+
+// Locale describes how numbers and dates are written for a region
+type Locale struct {
+	ThousandsSeparator string
+	DecimalSeparator   string
+	DateLayout         string // Go time layout
+}
+
+var locales = map[string]Locale{
+	"en-US": {ThousandsSeparator: ",", DecimalSeparator: ".", DateLayout: "01/02/2006 15:04"},
+	"en-GB": {ThousandsSeparator: ",", DecimalSeparator: ".", DateLayout: "02/01/2006 15:04"},
+	"id-ID": {ThousandsSeparator: ".", DecimalSeparator: ",", DateLayout: "02/01/2006 15:04"},
+	"de-DE": {ThousandsSeparator: ".", DecimalSeparator: ",", DateLayout: "02.01.2006 15:04"},
+}
+
+// ParseLocale resolves a locale tag such as "en-US"; an empty tag means en-US
+func ParseLocale(tag string) (Locale, error) {
+	if tag == "" {
+		return locales["en-US"], nil
+	}
+	for name, locale := range locales {
+		if strings.EqualFold(name, tag) {
+			return locale, nil
+		}
+	}
+	return Locale{}, fmt.Errorf("unsupported locale %q", tag)
+}
+
+// friendlyColumns are the header labels of the analyst export, in ResultColumns order
+var friendlyColumns = []string{
+	"Status",
+	"Transaction ID",
+	"Bank Reference",
+	"System Amount",
+	"Bank Amount",
+	"Difference",
+	"Bank",
+	"Transaction Date",
+	"Type",
+	"Recorded At",
+	"Currency",
+	"Bank Currency",
+}
+
+var statusLabels = map[domain.MatchStatus]string{
+	domain.Matched:            "Matched",
+	domain.Discrepancy:        "Amount Mismatch",
+	domain.UnmatchedSystem:    "Missing from Bank",
+	domain.UnmatchedBank:      "Missing from System",
+	domain.DateMismatch:       "Date Mismatch",
+	domain.CurrencyMismatch:   "Currency Mismatch",
+	domain.DirectionMismatch:  "Direction Mismatch",
+	domain.DuplicateSystem:    "Duplicate in System",
+	domain.DuplicateBank:      "Duplicate in Bank",
+	domain.MalformedReference: "Malformed Reference",
+	domain.AmbiguousMatch:     "Ambiguous Match",
+}
+
+// FriendlyFormatter produces an export for non-technical readers: readable
+// headers and status labels, grouped amounts and localized dates
+type FriendlyFormatter struct {
+	Locale Locale
+}
+
+func NewFriendlyFormatter(locale Locale) *FriendlyFormatter {
+	return &FriendlyFormatter{Locale: locale}
+}
+
+func (f *FriendlyFormatter) Columns() []string {
+	return friendlyColumns
+}
+
+func (f *FriendlyFormatter) Status(status domain.MatchStatus) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return string(status)
+}
+
+func (f *FriendlyFormatter) Type(t *domain.TransactionType) string {
+	switch {
+	case t == nil:
+		return ""
+	case *t == domain.Debit:
+		return "Debit"
+	case *t == domain.Credit:
+		return "Credit"
+	case *t == domain.Refund:
+		return "Refund"
+	case *t == domain.Chargeback:
+		return "Chargeback"
+	default:
+		return string(*t)
+	}
+}
+
+func (f *FriendlyFormatter) Decimal(d *decimal.Decimal, places int32) string {
+	if d == nil {
+		return ""
+	}
+
+	fixed := d.Abs().StringFixed(places)
+	intPart, fracPart := fixed, ""
+	if idx := strings.IndexByte(fixed, '.'); idx >= 0 {
+		intPart, fracPart = fixed[:idx], fixed[idx+1:]
+	}
+
+	var sb strings.Builder
+	if d.IsNegative() {
+		sb.WriteByte('-')
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteString(f.Locale.ThousandsSeparator)
+		}
+		sb.WriteRune(digit)
+	}
+	if fracPart != "" {
+		sb.WriteString(f.Locale.DecimalSeparator)
+		sb.WriteString(fracPart)
+	}
+	return sb.String()
+}
+
+func (f *FriendlyFormatter) Time(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(f.Locale.DateLayout)
+}
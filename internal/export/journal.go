@@ -0,0 +1,191 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// JournalColumns is the column order of the CSV journal export
+var JournalColumns = []string{
+	"entry_id",
+	"date",
+	"account",
+	"debit",
+	"credit",
+	"reference",
+	"description",
+	"currency",
+}
+
+// JournalAccounts names the ledger accounts journal lines are posted to
+type JournalAccounts struct {
+	Bank     string // Cash at bank, moved by the bank statement amount
+	Clearing string // Settles the system transaction amount
+	Suspense string // Holds amount differences until they are investigated
+}
+
+// DefaultJournalAccounts are used when no account names are configured
+var DefaultJournalAccounts = JournalAccounts{
+	Bank:     "Bank",
+	Clearing: "Clearing",
+	Suspense: "Suspense",
+}
+
+// JournalLine is a single debit or credit posting
+type JournalLine struct {
+	EntryID     string
+	Date        string
+	Account     string
+	Debit       decimal.Decimal
+	Credit      decimal.Decimal
+	Reference   string
+	Description string
+	Currency    string
+}
+
+// JournalLines translates a matched or discrepancy result into balanced
+// double-entry lines. Money in (a positive bank amount) debits the bank and
+// credits clearing; money out is the reverse. For a discrepancy, clearing
+// moves by the system amount, the bank by the bank amount, and the difference
+// is posted to suspense. Both amounts are rounded to places first, so the
+// entry balances as written. Other statuses produce no lines.
+func JournalLines(result domain.ReconciliationResult, accounts JournalAccounts, places int32) []JournalLine {
+	if result.MatchStatus != domain.Matched && result.MatchStatus != domain.Discrepancy {
+		return nil
+	}
+	if result.SystemAmount == nil || result.BankAmount == nil {
+		return nil
+	}
+
+	bankAmount := result.BankAmount.Abs().Round(places)
+	systemAmount := result.SystemAmount.Abs().Round(places)
+	if bankAmount.IsZero() && systemAmount.IsZero() {
+		return nil
+	}
+	moneyIn := result.BankAmount.IsPositive() ||
+		(result.BankAmount.IsZero() && (result.TransactionType == nil || result.TransactionType.Direction() == domain.Credit))
+
+	description := "Reconciled"
+	if result.MatchStatus == domain.Discrepancy {
+		description = "Amount mismatch"
+	}
+
+	base := JournalLine{
+		EntryID:     formatString(result.TrxID),
+		Date:        formatDate(result),
+		Reference:   formatString(result.TrxRefID),
+		Description: description,
+		Currency:    formatString(result.Currency),
+	}
+	line := func(account string, debit, credit decimal.Decimal) JournalLine {
+		l := base
+		l.Account, l.Debit, l.Credit = account, debit, credit
+		return l
+	}
+
+	var lines []JournalLine
+	if moneyIn {
+		lines = append(lines,
+			line(accounts.Bank, bankAmount, decimal.Zero),
+			line(accounts.Clearing, decimal.Zero, systemAmount),
+		)
+	} else {
+		lines = append(lines,
+			line(accounts.Clearing, systemAmount, decimal.Zero),
+			line(accounts.Bank, decimal.Zero, bankAmount),
+		)
+	}
+
+	// The bank moved more or less than the system expected
+	diff := bankAmount.Sub(systemAmount)
+	if !diff.IsZero() {
+		excess := diff.IsPositive() == moneyIn
+		if excess {
+			lines = append(lines, line(accounts.Suspense, decimal.Zero, diff.Abs()))
+		} else {
+			lines = append(lines, line(accounts.Suspense, diff.Abs(), decimal.Zero))
+		}
+	}
+
+	return lines
+}
+
+func formatDate(result domain.ReconciliationResult) string {
+	if result.TransactionDate == nil {
+		return ""
+	}
+	return result.TransactionDate.Format("2006-01-02")
+}
+
+// CSVJournalWriter writes reconciliation results as double-entry journal rows
+type CSVJournalWriter struct {
+	writer   *csv.Writer
+	accounts JournalAccounts
+	scales   AmountScales
+}
+
+// JournalWriterOption configures a CSVJournalWriter
+type JournalWriterOption func(*CSVJournalWriter)
+
+// WithJournalAmountScales posts amounts with the decimal places of their
+// currency instead of DefaultAmountScales
+func WithJournalAmountScales(scales AmountScales) JournalWriterOption {
+	return func(w *CSVJournalWriter) {
+		w.scales = scales
+	}
+}
+
+func NewCSVJournalWriter(w io.Writer, accounts JournalAccounts, opts ...JournalWriterOption) *CSVJournalWriter {
+	writer := &CSVJournalWriter{writer: csv.NewWriter(w), accounts: accounts, scales: DefaultAmountScales}
+	for _, opt := range opts {
+		opt(writer)
+	}
+	return writer
+}
+
+// WriteHeader writes the column header row
+func (w *CSVJournalWriter) WriteHeader() error {
+	return w.writer.Write(JournalColumns)
+}
+
+// Write appends the journal lines for a batch of results and flushes them
+func (w *CSVJournalWriter) Write(results []domain.ReconciliationResult) error {
+	for _, result := range results {
+		places := w.scales.Places(result)
+		for _, line := range JournalLines(result, w.accounts, places) {
+			record := []string{
+				line.EntryID,
+				line.Date,
+				line.Account,
+				formatAmount(line.Debit, places),
+				formatAmount(line.Credit, places),
+				line.Reference,
+				line.Description,
+				line.Currency,
+			}
+			if err := w.writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *CSVJournalWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// formatAmount leaves the unused side of a posting blank
+func formatAmount(d decimal.Decimal, places int32) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.StringFixed(places)
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"recon-engine/internal/domain"
+)
+
+// ResultCloser is implemented by writers that must close their output after
+// the last batch, such as the JSON array writer's closing bracket
+type ResultCloser interface {
+	Close() error
+}
+
+// JSONResultWriter writes reconciliation results as one JSON array
+type JSONResultWriter struct {
+	writer  io.Writer
+	written bool
+}
+
+func NewJSONResultWriter(w io.Writer) *JSONResultWriter {
+	return &JSONResultWriter{writer: w}
+}
+
+// WriteHeader opens the array
+func (w *JSONResultWriter) WriteHeader() error {
+	_, err := io.WriteString(w.writer, "[")
+	return err
+}
+
+// Write appends a batch of results as array elements
+func (w *JSONResultWriter) Write(results []domain.ReconciliationResult) error {
+	for _, result := range results {
+		if w.written {
+			if _, err := io.WriteString(w.writer, ","); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		if _, err := w.writer.Write(data); err != nil {
+			return err
+		}
+		w.written = true
+	}
+	return nil
+}
+
+// Close ends the array
+func (w *JSONResultWriter) Close() error {
+	_, err := io.WriteString(w.writer, "]\n")
+	return err
+}
+
+// NDJSONResultWriter writes a line holding the job, then one line per result
+type NDJSONResultWriter struct {
+	encoder *json.Encoder
+	job     *domain.ReconciliationJob
+}
+
+// NDJSONHeader is the first line of an NDJSON export
+type NDJSONHeader struct {
+	Job *domain.ReconciliationJob `json:"job"`
+}
+
+func NewNDJSONResultWriter(w io.Writer, job *domain.ReconciliationJob) *NDJSONResultWriter {
+	return &NDJSONResultWriter{encoder: json.NewEncoder(w), job: job}
+}
+
+// WriteHeader writes the job metadata line
+func (w *NDJSONResultWriter) WriteHeader() error {
+	return w.encoder.Encode(NDJSONHeader{Job: w.job})
+}
+
+// Write appends a line per result
+func (w *NDJSONResultWriter) Write(results []domain.ReconciliationResult) error {
+	for _, result := range results {
+		if err := w.encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+type AttachmentHandler struct {
+	service       service.AttachmentService
+	maxUploadSize int64
+}
+
+// NewAttachmentHandler creates the handler; maxUploadSize <= 0 uses the
+// reconcile upload default
+func NewAttachmentHandler(service service.AttachmentService, maxUploadSize int64) *AttachmentHandler {
+	if maxUploadSize <= 0 {
+		maxUploadSize = defaultMaxUploadSize
+	}
+	return &AttachmentHandler{service: service, maxUploadSize: maxUploadSize}
+}
+
+// UploadAttachment godoc
+// @Summary Attach a document to a reconciliation result
+// @Description Upload a supporting document, such as a bank advice or email, for a reconciliation result
+// @Tags reconciliation
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Result ID"
+// @Param file formData file true "Document to attach"
+// @Param description formData string false "Short note about the document"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/results/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	resultID, ok := parseResultID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+	if err := c.Request.ParseMultipartForm(uploadMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.PayloadTooLarge(c, fmt.Sprintf("Uploads are limited to %d bytes", h.maxUploadSize))
+			return
+		}
+		response.BadRequest(c, "Invalid multipart form", err.Error())
+		return
+	}
+	defer c.Request.MultipartForm.RemoveAll()
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.ValidationError(c, "file is required")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		response.BadRequest(c, "Invalid file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.Upload(c.Request.Context(), resultID, header.Filename, header.Header.Get("Content-Type"), c.PostForm("description"), file)
+	if err != nil {
+		if errors.Is(err, service.ErrResultNotFound) {
+			response.NotFound(c, "Result not found")
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("result_id", resultID).Error("Failed to upload attachment")
+		response.InternalError(c, "Failed to upload attachment", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Attachment uploaded successfully", attachment)
+}
+
+// ListAttachments godoc
+// @Summary List attachments of a reconciliation result
+// @Description List the documents attached to a reconciliation result, oldest first
+// @Tags reconciliation
+// @Produce json
+// @Param id path int true "Result ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/results/{id}/attachments [get]
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	resultID, ok := parseResultID(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.List(c.Request.Context(), resultID)
+	if err != nil {
+		if errors.Is(err, service.ErrResultNotFound) {
+			response.NotFound(c, "Result not found")
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("result_id", resultID).Error("Failed to list attachments")
+		response.InternalError(c, "Failed to list attachments", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Attachments retrieved successfully", attachments)
+}
+
+// parseResultID reads the :id path parameter, writing a 400 response and
+// returning false when it is not a positive integer
+func parseResultID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		response.BadRequest(c, "Invalid result id", "id must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
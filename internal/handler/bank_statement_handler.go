@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+type BankStatementHandler struct {
+	service service.BankStatementService
+}
+
+func NewBankStatementHandler(service service.BankStatementService) *BankStatementHandler {
+	return &BankStatementHandler{service: service}
+}
+
+type GetBankStatementsRequest struct {
+	StartDate string `form:"start_date" binding:"required"`
+	EndDate   string `form:"end_date" binding:"required"`
+	Source    string `form:"source"`
+}
+
+// GetBankStatements godoc
+// @Summary Get stored bank statements
+// @Description Get the bank statements stored with start_date <= date < end_date, as imported from bank files, optionally for one source
+// @Tags bank-statements
+// @Produce json
+// @Param start_date query string true "Start date, inclusive (RFC3339 format)"
+// @Param end_date query string true "End date, exclusive (RFC3339 format)"
+// @Param source query string false "Bank source"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/bank-statements [get]
+func (h *BankStatementHandler) GetBankStatements(c *gin.Context) {
+	var req GetBankStatementsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	startDate, err := time.Parse(time.RFC3339, req.StartDate)
+	if err != nil {
+		response.BadRequest(c, "Invalid start_date format", "Use RFC3339 format")
+		return
+	}
+
+	endDate, err := time.Parse(time.RFC3339, req.EndDate)
+	if err != nil {
+		response.BadRequest(c, "Invalid end_date format", "Use RFC3339 format")
+		return
+	}
+
+	statements, err := h.service.GetByDateRangeAndSource(c.Request.Context(), startDate, endDate, req.Source)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("Failed to get bank statements")
+		response.InternalError(c, "Failed to get bank statements", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Bank statements retrieved successfully", statements)
+}
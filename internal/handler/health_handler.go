@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/pkg/logger"
+)
+
+// Pinger checks a dependency is reachable; *sql.DB satisfies it
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthHandler serves liveness and readiness probes
+type HealthHandler struct {
+	db      Pinger
+	timeout time.Duration
+}
+
+// NewHealthHandler checks db within timeout on each readiness probe
+func NewHealthHandler(db Pinger, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, timeout: timeout}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Report that the process is up; dependencies are not checked
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /livez [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Ping the database and report 503 when it is unreachable, so load balancers stop routing to this instance
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		logger.FromContext(c).WithError(err).Warn("Database health check failed")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":   "unhealthy",
+			"database": "down",
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "healthy", "database": "up"})
+}
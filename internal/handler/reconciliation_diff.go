@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// DiffJobs godoc
+// @Summary Compare two reconciliation jobs
+// @Description Compare the results of a completed job with those of another, usually a later run, listing the items newly matched, newly unmatched, still unmatched and the discrepancies now matched. Items are followed by trx_id, or by bank source and trx_ref_id. Only the days both jobs cover are compared; results outside them are counted in out_of_range.
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Param other_id path string true "Job ID to compare with"
+// @Success 200 {object} response.Response{data=domain.JobDiff}
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/diff/{other_id} [get]
+func (h *ReconciliationHandler) DiffJobs(c *gin.Context) {
+	jobID, otherID := c.Param("job_id"), c.Param("other_id")
+
+	for _, id := range []string{jobID, otherID} {
+		if _, err := h.service.GetJobStatus(c.Request.Context(), id); err != nil {
+			if requestAborted(c) {
+				return
+			}
+			logger.FromContext(c).WithError(err).WithField("job_id", id).Error("Job not found")
+			response.NotFound(c, "Job not found: "+id)
+			return
+		}
+	}
+
+	diff, err := h.service.DiffJobs(c.Request.Context(), jobID, otherID)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		if errors.Is(err, service.ErrJobNotCompleted) {
+			response.Conflict(c, "Job is not completed", err.Error())
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to compare jobs")
+		response.InternalError(c, "Failed to compare jobs", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Jobs compared successfully", diff)
+}
@@ -1,31 +1,146 @@
 package handler
 
 import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 
+	"recon-engine/internal/domain"
+	"recon-engine/internal/export"
 	"recon-engine/internal/service"
 	"recon-engine/pkg/logger"
 	"recon-engine/pkg/response"
 )
 
+// defaultMaxUploadSize caps a multipart reconcile request at 100 MB
+const defaultMaxUploadSize int64 = 100 << 20
+
 type ReconciliationHandler struct {
-	service service.ReconciliationService
+	service         service.ReconciliationService
+	maxUploadSize   int64
+	journalAccounts export.JournalAccounts
+	amountScales    export.AmountScales
+}
+
+// HandlerOption configures optional behaviour of the reconciliation handler
+type HandlerOption func(*ReconciliationHandler)
+
+// WithMaxUploadSize limits the total size in bytes of a multipart upload
+func WithMaxUploadSize(bytes int64) HandlerOption {
+	return func(h *ReconciliationHandler) {
+		if bytes > 0 {
+			h.maxUploadSize = bytes
+		}
+	}
+}
+
+// WithJournalAccounts sets the ledger accounts used by the journal export
+func WithJournalAccounts(accounts export.JournalAccounts) HandlerOption {
+	return func(h *ReconciliationHandler) {
+		h.journalAccounts = accounts
+	}
+}
+
+// WithAmountScales sets the decimal places amounts are exported with
+func WithAmountScales(scales export.AmountScales) HandlerOption {
+	return func(h *ReconciliationHandler) {
+		h.amountScales = scales
+	}
 }
 
-func NewReconciliationHandler(service service.ReconciliationService) *ReconciliationHandler {
-	return &ReconciliationHandler{service: service}
+func NewReconciliationHandler(service service.ReconciliationService, opts ...HandlerOption) *ReconciliationHandler {
+	h := &ReconciliationHandler{
+		service:         service,
+		maxUploadSize:   defaultMaxUploadSize,
+		journalAccounts: export.DefaultJournalAccounts,
+		amountScales:    export.DefaultAmountScales,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 type ReconcileRequest struct {
-	SystemFilePath string   `json:"system_file_path"`
-	BankFilePaths  []string `json:"bank_file_paths" binding:"required,min=1"`
-	StartDate      string   `json:"start_date" binding:"required"`
-	EndDate        string   `json:"end_date" binding:"required"`
+	// SystemFilePath and SystemFilePaths are merged, e.g. for system exports
+	// split by region; with neither, system transactions come from the database.
+	// Every file path may also be an s3://bucket/key or gs://bucket/key URL.
+	SystemFilePath  string   `json:"system_file_path"`
+	SystemFilePaths []string `json:"system_file_paths"`
+	BankFilePaths   []string `json:"bank_file_paths"`
+	BankSource      string   `json:"bank_source"` // "file" (default) or "database"
+	StartDate       string   `json:"start_date" binding:"required"`
+	EndDate         string   `json:"end_date" binding:"required"`
+	// DryRun matches and returns the summary without saving a job or results
+	DryRun bool `json:"dry_run"`
+	// Strategy is a strategy name, such as "exact" or "installment", or a
+	// comma-separated chain; empty uses the server default
+	Strategy string `json:"strategy"`
+	// CallbackURL receives a POST with the job's status and totals when it
+	// completes or fails
+	CallbackURL string `json:"callback_url"`
+	// BatchSize overrides BATCH_SIZE for this job's reads, parsing and
+	// saves, up to service.MaxBatchSize; zero keeps the default
+	BatchSize int `json:"batch_size"`
+	// OpeningBalance and ClosingBalance are the bank account's balances
+	// around the period; given together, the summary's balance_check
+	// reports any difference the reconciled lines do not explain
+	OpeningBalance *decimal.Decimal `json:"opening_balance"`
+	ClosingBalance *decimal.Decimal `json:"closing_balance"`
+	// PersistMatched set to false stores only the job's exceptions, not its
+	// MATCHED results; the totals still count them
+	PersistMatched *bool `json:"persist_matched"`
+	// MaxDiscrepancyRatio and MaxUnmatchedRatio, from 0 to 1, fail the job
+	// when more of its ID pairs have discrepancies, or more of its rows are
+	// unmatched; the results are still saved
+	MaxDiscrepancyRatio *float64 `json:"max_discrepancy_ratio"`
+	MaxUnmatchedRatio   *float64 `json:"max_unmatched_ratio"`
+}
+
+// systemFiles returns system_file_path followed by system_file_paths,
+// rejecting empty and repeated paths
+func (r ReconcileRequest) systemFiles() ([]string, error) {
+	return mergeSystemFiles(r.SystemFilePath, r.SystemFilePaths)
+}
+
+func mergeSystemFiles(single string, many []string) ([]string, error) {
+	var paths []string
+	if single != "" {
+		paths = append(paths, single)
+	}
+	paths = append(paths, many...)
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			return nil, fmt.Errorf("system_file_paths must not contain empty paths")
+		}
+		if seen[path] {
+			return nil, fmt.Errorf("duplicate system file path %q", path)
+		}
+		seen[path] = true
+	}
+	return paths, nil
 }
 
+const (
+	bankSourceFile     = "file"
+	bankSourceDatabase = "database"
+)
+
+// IdempotencyKeyHeader names the header that makes a reconcile request safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLength = 255
+
 // Reconcile godoc
 // @Summary Perform reconciliation
 // @Description Reconcile system transactions with bank statements
@@ -33,49 +148,288 @@ type ReconcileRequest struct {
 // @Accept json
 // @Produce json
 // @Param request body ReconcileRequest true "Reconciliation request"
+// @Param Idempotency-Key header string false "Repeats with the same key return the original job instead of starting another"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/reconcile [post]
 func (h *ReconciliationHandler) Reconcile(c *gin.Context) {
 	var req ReconcileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.GetLogger().WithError(err).Error("Invalid request")
+		logger.FromContext(c).WithError(err).Error("Invalid request")
 		response.ValidationError(c, err.Error())
 		return
 	}
 
-	// Parse dates
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	systemFiles, err := req.systemFiles()
 	if err != nil {
-		response.BadRequest(c, "Invalid start_date format", "Use YYYY-MM-DD format")
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	switch req.BankSource {
+	case "", bankSourceFile:
+		if len(req.BankFilePaths) == 0 {
+			response.ValidationError(c, "bank_file_paths is required when bank_source is file")
+			return
+		}
+	case bankSourceDatabase:
+	default:
+		response.BadRequest(c, "Invalid bank_source", "Use file or database")
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	svc, ok := h.serviceForStrategy(c, req.Strategy)
+	if !ok {
+		return
+	}
+	if svc, ok = serviceWithBatchSize(c, svc, req.BatchSize); !ok {
+		return
+	}
+	if svc, ok = serviceWithLedgerBalance(c, svc, req.OpeningBalance, req.ClosingBalance); !ok {
+		return
+	}
+	if svc, ok = serviceWithCallback(c, svc, req.CallbackURL); !ok {
+		return
+	}
+	if req.PersistMatched != nil {
+		svc = svc.ForPersistMatched(*req.PersistMatched)
+	}
+	if svc, ok = serviceWithFailureThresholds(c, svc, req.MaxDiscrepancyRatio, req.MaxUnmatchedRatio); !ok {
+		return
+	}
+	if svc, ok = serviceWithIdempotencyKey(c, svc); !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+
+	// end_date is inclusive; the service covers its whole day
+	logger.FromContext(c).WithFields(map[string]interface{}{
+		"system_files": systemFiles,
+		"bank_files":   req.BankFilePaths,
+		"bank_source":  req.BankSource,
+		"start_date":   startDate,
+		"end_date":     endDate,
+		"dry_run":      req.DryRun,
+		"strategy":     req.Strategy,
+		"batch_size":   req.BatchSize,
+	}).Info("Starting reconciliation")
+
+	var summary *domain.ReconciliationSummary
+	if req.BankSource == bankSourceDatabase {
+		summary, err = svc.ReconcileFromDatabase(c.Request.Context(), startDate, endDate, req.DryRun)
+	} else {
+		summary, err = svc.Reconcile(c.Request.Context(), systemFiles, req.BankFilePaths, startDate, endDate, req.DryRun)
+	}
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		reconcileFailed(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, completionMessage(summary), summary)
+}
+
+// completionMessage warns when the summary leaves out bank files that failed
+// to load or includes files without data rows; file_load_report and
+// system_file_report have the details
+func completionMessage(summary *domain.ReconciliationSummary) string {
+	if summary.Replayed {
+		return "Reconciliation already submitted with this Idempotency-Key; returning the original job"
+	}
+	if summary.FailureReason != "" {
+		return "Reconciliation failed its thresholds; results were saved for investigation: " + summary.FailureReason
+	}
+	if summary.Incomplete {
+		return "Reconciliation completed with incomplete data: some bank files failed to load"
+	}
+	for _, reports := range [][]domain.FileLoadReport{summary.SystemFileReport, summary.FileLoadReport} {
+		for _, report := range reports {
+			if report.Empty {
+				return "Reconciliation completed, but some input files have no data rows"
+			}
+		}
+	}
+	return "Reconciliation completed successfully"
+}
+
+// serviceForStrategy returns the service for the requested matching
+// strategy, writing a 400 response and returning false for an unknown name
+func (h *ReconciliationHandler) serviceForStrategy(c *gin.Context, name string) (service.ReconciliationService, bool) {
+	svc, err := h.service.ForStrategy(name)
+	if err != nil {
+		response.BadRequest(c, "Invalid strategy", err.Error())
+		return nil, false
+	}
+	return svc, true
+}
+
+// serviceWithBatchSize returns svc working in batches of size, writing a 400
+// response and returning false when size is out of range
+func serviceWithBatchSize(c *gin.Context, svc service.ReconciliationService, size int) (service.ReconciliationService, bool) {
+	scoped, err := svc.ForBatchSize(size)
+	if err != nil {
+		response.BadRequest(c, "Invalid batch_size", err.Error())
+		return nil, false
+	}
+	return scoped, true
+}
+
+// serviceWithLedgerBalance returns svc checking the opening and closing
+// balances, writing a 400 response and returning false when only one is given
+func serviceWithLedgerBalance(c *gin.Context, svc service.ReconciliationService, opening, closing *decimal.Decimal) (service.ReconciliationService, bool) {
+	scoped, err := svc.ForLedgerBalance(opening, closing)
+	if err != nil {
+		response.BadRequest(c, "Invalid balances", "Give both opening_balance and closing_balance")
+		return nil, false
+	}
+	return scoped, true
+}
+
+// serviceWithFailureThresholds returns svc failing jobs above the given
+// ratios, writing a 400 response and returning false for a ratio outside 0-1
+func serviceWithFailureThresholds(c *gin.Context, svc service.ReconciliationService, maxDiscrepancyRatio, maxUnmatchedRatio *float64) (service.ReconciliationService, bool) {
+	scoped, err := svc.ForFailureThresholds(maxDiscrepancyRatio, maxUnmatchedRatio)
+	if err != nil {
+		response.BadRequest(c, "Invalid thresholds", "Give max_discrepancy_ratio and max_unmatched_ratio between 0 and 1")
+		return nil, false
+	}
+	return scoped, true
+}
+
+// serviceWithCallback returns svc notifying callbackURL, writing a 400
+// response and returning false unless the URL is absolute http(s)
+func serviceWithCallback(c *gin.Context, svc service.ReconciliationService, callbackURL string) (service.ReconciliationService, bool) {
+	if callbackURL == "" {
+		return svc, true
+	}
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		response.BadRequest(c, "Invalid callback_url", "Use an absolute http or https URL")
+		return nil, false
+	}
+	return svc.ForCallback(callbackURL), true
+}
+
+// serviceWithIdempotencyKey returns svc deduplicating by the request's
+// Idempotency-Key header, writing a 400 response and returning false when
+// the key is too long
+func serviceWithIdempotencyKey(c *gin.Context, svc service.ReconciliationService) (service.ReconciliationService, bool) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		response.BadRequest(c, "Invalid Idempotency-Key", fmt.Sprintf("Use at most %d characters", maxIdempotencyKeyLength))
+		return nil, false
+	}
+	return svc.ForIdempotencyKey(key), true
+}
+
+// reconcileFailed writes the error response for a failed reconcile request
+func reconcileFailed(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrIdempotencyKeyInUse) {
+		response.Conflict(c, "Reconciliation already in progress", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrEmptyInput) {
+		response.ValidationError(c, err.Error())
+		return
+	}
+	logger.FromContext(c).WithError(err).Error("Reconciliation failed")
+	response.InternalError(c, "Reconciliation failed", err.Error())
+}
+
+// parseDateRange parses YYYY-MM-DD start and end dates, writing a 400
+// response and returning false when either is malformed
+func parseDateRange(c *gin.Context, start, end string) (time.Time, time.Time, bool) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		response.BadRequest(c, "Invalid start_date format", "Use YYYY-MM-DD format")
+		return time.Time{}, time.Time{}, false
+	}
+
+	endDate, err := time.Parse("2006-01-02", end)
 	if err != nil {
 		response.BadRequest(c, "Invalid end_date format", "Use YYYY-MM-DD format")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return startDate, endDate, true
+}
+
+// ListJobs godoc
+// @Summary List reconciliation jobs
+// @Description Page through past reconciliation jobs, newest first, optionally filtered by status and by the day they were created
+// @Tags reconciliation
+// @Produce json
+// @Param status query string false "Job status (PENDING, PROCESSING, COMPLETED, FAILED)"
+// @Param from query string false "Created on or after this day (YYYY-MM-DD)"
+// @Param to query string false "Created on or before this day (YYYY-MM-DD)"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size (max 1000)" default(100)
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs [get]
+func (h *ReconciliationHandler) ListJobs(c *gin.Context) {
+	var filter domain.JobFilter
+
+	filter.Status = domain.JobStatus(c.Query("status"))
+	switch filter.Status {
+	case "", domain.Pending, domain.Processing, domain.Completed, domain.Failed:
+	default:
+		response.BadRequest(c, "Invalid status", fmt.Sprintf("Unknown job status %q", filter.Status))
 		return
 	}
 
-	// Set end date to end of day
-	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+	if from := c.Query("from"); from != "" {
+		day, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			response.BadRequest(c, "Invalid from format", "Use YYYY-MM-DD format")
+			return
+		}
+		filter.CreatedFrom = &day
+	}
+	if to := c.Query("to"); to != "" {
+		day, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			response.BadRequest(c, "Invalid to format", "Use YYYY-MM-DD format")
+			return
+		}
+		before := domain.DayAfter(day)
+		filter.CreatedBefore = &before
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		response.BadRequest(c, "Invalid page", "page must be a positive integer")
+		return
+	}
 
-	logger.GetLogger().WithFields(map[string]interface{}{
-		"system_file":     req.SystemFilePath,
-		"bank_files":      req.BankFilePaths,
-		"start_date":      startDate,
-		"end_date":        endDate,
-	}).Info("Starting reconciliation")
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultResultPageSize)))
+	if err != nil || size < 1 || size > maxResultPageSize {
+		response.BadRequest(c, "Invalid size", fmt.Sprintf("size must be between 1 and %d", maxResultPageSize))
+		return
+	}
 
-	summary, err := h.service.Reconcile(req.SystemFilePath, req.BankFilePaths, startDate, endDate)
+	jobs, err := h.service.ListJobs(c.Request.Context(), filter, page, size)
 	if err != nil {
-		logger.GetLogger().WithError(err).Error("Reconciliation failed")
-		response.InternalError(c, "Reconciliation failed", err.Error())
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("Failed to list jobs")
+		response.InternalError(c, "Failed to list jobs", err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Reconciliation completed successfully", summary)
+	response.Success(c, http.StatusOK, "Jobs retrieved successfully", jobs)
 }
 
 // GetJobStatus godoc
@@ -91,9 +445,12 @@ func (h *ReconciliationHandler) Reconcile(c *gin.Context) {
 func (h *ReconciliationHandler) GetJobStatus(c *gin.Context) {
 	jobID := c.Param("job_id")
 
-	job, err := h.service.GetJobStatus(jobID)
+	job, err := h.service.GetJobStatus(c.Request.Context(), jobID)
 	if err != nil {
-		logger.GetLogger().WithError(err).WithField("job_id", jobID).Error("Job not found")
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
 		response.NotFound(c, "Job not found")
 		return
 	}
@@ -101,25 +458,475 @@ func (h *ReconciliationHandler) GetJobStatus(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Job status retrieved successfully", job)
 }
 
+// DeleteJob godoc
+// @Summary Delete a reconciliation job
+// @Description Soft-delete a reconciliation job and remove its results. Jobs that are still processing cannot be deleted.
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id} [delete]
+func (h *ReconciliationHandler) DeleteJob(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	if err := h.service.DeleteJob(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		if errors.Is(err, service.ErrJobProcessing) {
+			response.Conflict(c, "Job is still processing", "Wait for the job to complete or fail before deleting it")
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to delete job")
+		response.InternalError(c, "Failed to delete job", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Job deleted successfully", nil)
+}
+
+// RerunJob godoc
+// @Summary Re-run a reconciliation job
+// @Description Reconcile the job's date range again as a new job, reading transactions and bank statements from the database
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/rerun [post]
+func (h *ReconciliationHandler) RerunJob(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	summary, err := h.service.RerunJob(c.Request.Context(), jobID)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Re-run failed")
+		response.InternalError(c, "Reconciliation failed", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Reconciliation completed successfully", summary)
+}
+
 // GetJobSummary godoc
 // @Summary Get reconciliation job summary
-// @Description Get the detailed summary of a reconciliation job by ID
+// @Description Get the detailed summary of a reconciliation job by ID. With min_discrepancy only discrepancies at least that large are listed, and with min_confidence only paired results scored at least that; the totals still count every result.
 // @Tags reconciliation
 // @Produce json
 // @Param job_id path string true "Job ID"
+// @Param min_discrepancy query number false "Smallest absolute discrepancy listed, inclusive"
+// @Param min_confidence query number false "Lowest confidence of paired results listed, from 0 to 1, inclusive"
 // @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/reconcile/jobs/{job_id}/summary [get]
 func (h *ReconciliationHandler) GetJobSummary(c *gin.Context) {
 	jobID := c.Param("job_id")
+	minDiscrepancy, ok := amountQuery(c, "min_discrepancy")
+	if !ok {
+		return
+	}
+	minConfidence, ok := confidenceQuery(c, "min_confidence")
+	if !ok {
+		return
+	}
 
-	summary, err := h.service.GetJobSummary(jobID)
+	summary, err := h.service.GetJobSummary(c.Request.Context(), jobID, minDiscrepancy, minConfidence)
 	if err != nil {
-		logger.GetLogger().WithError(err).WithField("job_id", jobID).Error("Failed to get job summary")
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to get job summary")
 		response.NotFound(c, "Job not found")
 		return
 	}
 
 	response.Success(c, http.StatusOK, "Job summary retrieved successfully", summary)
 }
+
+// GetJobStats godoc
+// @Summary Get reconciliation job stats
+// @Description Get a job's totals and its result counts by status and bank source, without any result rows
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/stats [get]
+func (h *ReconciliationHandler) GetJobStats(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	stats, err := h.service.GetJobStats(c.Request.Context(), jobID)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to get job stats")
+		response.InternalError(c, "Failed to get job stats", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Job stats retrieved successfully", stats)
+}
+
+// VerifyJobResults godoc
+// @Summary Verify reconciliation job results
+// @Description Recompute the hash chain over a job's stored results and report whether any result was altered, inserted or deleted since the job completed. Only jobs saved with the result hash chain enabled can be verified.
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/verify [get]
+func (h *ReconciliationHandler) VerifyJobResults(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	verification, err := h.service.VerifyJobResults(c.Request.Context(), jobID)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		if errors.Is(err, service.ErrJobNotChained) {
+			response.Conflict(c, "Job has no result hash chain", err.Error())
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to verify job results")
+		response.InternalError(c, "Failed to verify job results", err.Error())
+		return
+	}
+
+	if !verification.Valid {
+		logger.FromContext(c).WithField("job_id", jobID).Warn("Job results failed hash chain verification")
+		response.Success(c, http.StatusOK, "Job results failed verification", verification)
+		return
+	}
+	response.Success(c, http.StatusOK, "Job results verified", verification)
+}
+
+const (
+	defaultResultPageSize = 100
+	maxResultPageSize     = 1000
+)
+
+// GetJobResults godoc
+// @Summary List reconciliation job results
+// @Description Page through the results of a reconciliation job, optionally filtered by status, bank source, amount, confidence and age
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Param status query string false "Match status (MATCHED, DISCREPANCY, UNMATCHED_SYSTEM, UNMATCHED_BANK, DATE_MISMATCH, CURRENCY_MISMATCH, DIRECTION_MISMATCH, DUPLICATE_SYSTEM, DUPLICATE_BANK, MALFORMED_REFERENCE, AMBIGUOUS_MATCH)"
+// @Param bank_source query string false "Bank source (file name), e.g. bank_bca.csv"
+// @Param min_amount query number false "Smallest amount magnitude, inclusive (system amount, else bank amount)"
+// @Param max_amount query number false "Largest amount magnitude, inclusive (system amount, else bank amount)"
+// @Param min_confidence query number false "Lowest confidence, from 0 to 1, inclusive; unpaired results have none and are left out"
+// @Param min_age_days query int false "Only unmatched results at least this many days old on the job's run date"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param size query int false "Page size (max 1000)" default(100)
+// @Param cursor query string false "Keyset paging: empty for the first page, then the previous page's next_cursor; cannot be combined with page"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/results [get]
+func (h *ReconciliationHandler) GetJobResults(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	filter := domain.ResultFilter{
+		Status:     domain.MatchStatus(c.Query("status")),
+		BankSource: c.Query("bank_source"),
+	}
+	if filter.Status != "" && !validMatchStatus(filter.Status) {
+		response.BadRequest(c, "Invalid status", fmt.Sprintf("Unknown match status %q", filter.Status))
+		return
+	}
+
+	var ok bool
+	if filter.MinAmount, ok = amountQuery(c, "min_amount"); !ok {
+		return
+	}
+	if filter.MaxAmount, ok = amountQuery(c, "max_amount"); !ok {
+		return
+	}
+	if filter.MinConfidence, ok = confidenceQuery(c, "min_confidence"); !ok {
+		return
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.GreaterThan(*filter.MaxAmount) {
+		response.BadRequest(c, "Invalid amount range", "min_amount must not exceed max_amount")
+		return
+	}
+
+	if value := c.Query("min_age_days"); value != "" {
+		minAge, err := strconv.Atoi(value)
+		if err != nil || minAge < 0 {
+			response.BadRequest(c, "Invalid min_age_days", "min_age_days must be a non-negative integer")
+			return
+		}
+		filter.MinAgeDays = minAge
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		response.BadRequest(c, "Invalid page", "page must be a positive integer")
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultResultPageSize)))
+	if err != nil || size < 1 || size > maxResultPageSize {
+		response.BadRequest(c, "Invalid size", fmt.Sprintf("size must be between 1 and %d", maxResultPageSize))
+		return
+	}
+
+	// A cursor, empty for the first page, switches to keyset paging
+	if token, keyset := c.GetQuery("cursor"); keyset {
+		if _, paged := c.GetQuery("page"); paged {
+			response.BadRequest(c, "Invalid paging", "page and cursor cannot be combined")
+			return
+		}
+		var cursor *domain.ResultCursor
+		if token != "" {
+			parsed, err := domain.ParseResultCursor(token)
+			if err != nil {
+				response.BadRequest(c, "Invalid cursor", err.Error())
+				return
+			}
+			cursor = &parsed
+		}
+
+		results, err := h.service.GetJobResultsAfter(c.Request.Context(), jobID, filter, cursor, size)
+		if err != nil {
+			if requestAborted(c) {
+				return
+			}
+			logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+			response.NotFound(c, "Job not found")
+			return
+		}
+		response.Success(c, http.StatusOK, "Job results retrieved successfully", results)
+		return
+	}
+
+	results, err := h.service.GetJobResults(c.Request.Context(), jobID, filter, page, size)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Job results retrieved successfully", results)
+}
+
+// amountQuery parses an optional non-negative amount query parameter,
+// writing a 400 response and returning false when it is malformed
+func amountQuery(c *gin.Context, name string) (*decimal.Decimal, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	amount, err := decimal.NewFromString(value)
+	if err != nil || amount.IsNegative() {
+		response.BadRequest(c, "Invalid "+name, name+" must be a non-negative number")
+		return nil, false
+	}
+	return &amount, true
+}
+
+// confidenceQuery parses an optional confidence query parameter between 0
+// and 1, writing a 400 response and returning false when it is malformed
+func confidenceQuery(c *gin.Context, name string) (*float64, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	confidence, err := strconv.ParseFloat(value, 64)
+	if err != nil || confidence < 0 || confidence > 1 {
+		response.BadRequest(c, "Invalid "+name, name+" must be a number from 0 to 1")
+		return nil, false
+	}
+	return &confidence, true
+}
+
+func validMatchStatus(status domain.MatchStatus) bool {
+	switch status {
+	case domain.Matched, domain.Discrepancy, domain.UnmatchedSystem, domain.UnmatchedBank,
+		domain.DateMismatch, domain.CurrencyMismatch, domain.DirectionMismatch, domain.DuplicateSystem, domain.DuplicateBank,
+		domain.MalformedReference, domain.AmbiguousMatch:
+		return true
+	}
+	return false
+}
+
+// ExportJobResults godoc
+// @Summary Export reconciliation job results
+// @Description Download all results of a reconciliation job as CSV, a JSON array or NDJSON, optionally gzip-compressed
+// @Tags reconciliation
+// @Produce text/csv
+// @Produce application/json
+// @Produce application/x-ndjson
+// @Param job_id path string true "Job ID"
+// @Param format query string false "Export format: csv (one row per result), journal (double-entry journal lines as CSV), json (one array of results) or ndjson (a job line, then a line per result)" default(csv)
+// @Param style query string false "raw (status codes, plain numbers) or friendly (labels, localized numbers and dates); friendly is rejected with 400 unless format is csv" default(raw)
+// @Param locale query string false "Locale for the friendly style (en-US, en-GB, id-ID, de-DE)" default(en-US)
+// @Param gzip query bool false "Compress the body and set Content-Encoding: gzip" default(false)
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/export [get]
+func (h *ReconciliationHandler) ExportJobResults(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	format := c.DefaultQuery("format", "csv")
+	switch format {
+	case "csv", "journal", "json", "ndjson":
+	default:
+		response.BadRequest(c, "Unsupported export format", "Supported formats: csv, journal, json, ndjson")
+		return
+	}
+
+	opts := []export.WriterOption{export.WithAmountScales(h.amountScales)}
+	switch c.DefaultQuery("style", "raw") {
+	case "raw":
+	case "friendly":
+		if format != "csv" {
+			response.BadRequest(c, "Unsupported export style", "The friendly style applies only to format=csv")
+			return
+		}
+		locale, err := export.ParseLocale(c.Query("locale"))
+		if err != nil {
+			response.BadRequest(c, "Unsupported locale", err.Error())
+			return
+		}
+		opts = append(opts, export.WithFormatter(export.NewFriendlyFormatter(locale)))
+	default:
+		response.BadRequest(c, "Unsupported export style", "Supported styles: raw, friendly")
+		return
+	}
+
+	compress := false
+	if value := c.Query("gzip"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid gzip", "Use true or false")
+			return
+		}
+		compress = parsed
+	}
+
+	job, err := h.service.GetJobStatus(c.Request.Context(), jobID)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	var out io.Writer = c.Writer
+	flush := c.Writer.Flush
+	if compress {
+		gz := gzip.NewWriter(c.Writer)
+		defer gz.Close()
+		out = gz
+		flush = func() {
+			gz.Flush()
+			c.Writer.Flush()
+		}
+		c.Header("Content-Encoding", "gzip")
+	}
+
+	// The JSON formats page through the results by keyset instead of holding
+	// one cursor open for the whole download
+	stream := h.service.StreamJobResults
+	var writer export.ResultWriter
+	var contentType, filename string
+	switch format {
+	case "journal":
+		writer = export.NewCSVJournalWriter(out, h.journalAccounts, export.WithJournalAmountScales(h.amountScales))
+		contentType, filename = "text/csv", fmt.Sprintf("journal_%s.csv", jobID)
+	case "json":
+		writer, stream = export.NewJSONResultWriter(out), h.service.PageJobResults
+		contentType, filename = "application/json", fmt.Sprintf("reconciliation_%s.json", jobID)
+	case "ndjson":
+		writer, stream = export.NewNDJSONResultWriter(out, job), h.service.PageJobResults
+		contentType, filename = "application/x-ndjson", fmt.Sprintf("reconciliation_%s.ndjson", jobID)
+	default:
+		writer = export.NewCSVResultWriter(out, opts...)
+		contentType, filename = "text/csv", fmt.Sprintf("reconciliation_%s.csv", jobID)
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := writer.WriteHeader(); err != nil {
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to write export header")
+		return
+	}
+
+	err = stream(c.Request.Context(), jobID, func(batch []domain.ReconciliationResult) error {
+		if err := writer.Write(batch); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the best we can do is log and truncate
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to export job results")
+		return
+	}
+	if closer, ok := writer.(export.ResultCloser); ok {
+		if err := closer.Close(); err != nil {
+			logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to finish export")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// ManualMatchRequest names the unmatched system and bank results a reviewer
+// pairs, and the reviewer
+type ManualMatchRequest struct {
+	TrxID    string `json:"trx_id"`
+	TrxRefID string `json:"trx_ref_id"`
+	// BankSource is needed only when several bank files report trx_ref_id
+	BankSource string `json:"bank_source,omitempty"`
+	ReviewedBy string `json:"reviewed_by"`
+}
+
+// ManualMatch godoc
+// @Summary Manually match unmatched results
+// @Description Pair a completed job's UNMATCHED_SYSTEM result for trx_id with its UNMATCHED_BANK result for trx_ref_id. Both are replaced by one MATCHED result flagged manual_match with the reviewer, and the job's totals are updated. A reference that is not unmatched, e.g. already matched, is refused. Jobs saved with a result hash chain cannot be changed.
+// @Tags reconciliation
+// @Accept json
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Param request body ManualMatchRequest true "Results to pair"
+// @Success 201 {object} response.Response{data=domain.ManualMatchResult}
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/manual-match [post]
+func (h *ReconciliationHandler) ManualMatch(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	var req ManualMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+	match := domain.ManualMatch{
+		TrxID:      strings.TrimSpace(req.TrxID),
+		TrxRefID:   strings.TrimSpace(req.TrxRefID),
+		BankSource: strings.TrimSpace(req.BankSource),
+		ReviewedBy: strings.TrimSpace(req.ReviewedBy),
+	}
+	switch {
+	case match.TrxID == "":
+		response.ValidationError(c, "trx_id is required")
+		return
+	case match.TrxRefID == "":
+		response.ValidationError(c, "trx_ref_id is required")
+		return
+	case match.ReviewedBy == "":
+		response.ValidationError(c, "reviewed_by is required")
+		return
+	}
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	result, err := h.service.ManualMatch(c.Request.Context(), jobID, match)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrJobNotCompleted):
+			response.Conflict(c, "Job is not completed", err.Error())
+		case errors.Is(err, service.ErrJobChained):
+			response.Conflict(c, "Job results cannot be changed", err.Error())
+		case errors.Is(err, service.ErrNotUnmatched):
+			response.Conflict(c, "Result is not unmatched", err.Error())
+		case errors.Is(err, service.ErrResultsChanged):
+			response.Conflict(c, "Job results changed during the match; retry", err.Error())
+		case errors.Is(err, service.ErrAmbiguousManualMatch):
+			response.ValidationError(c, err.Error()+"; give bank_source")
+		default:
+			logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Manual match failed")
+			response.InternalError(c, "Manual match failed", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Results matched manually", result)
+}
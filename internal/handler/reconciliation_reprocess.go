@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// ReprocessRequest names the late bank files to retry a job's unmatched
+// system results against
+type ReprocessRequest struct {
+	BankFilePaths []string `json:"bank_file_paths"`
+}
+
+// ReprocessUnmatched godoc
+// @Summary Reprocess unmatched system results
+// @Description Match a completed job's UNMATCHED_SYSTEM results against late bank files. Results that now pair with a bank statement are replaced by the new outcome and the job's totals are updated; bank rows that pair with none are counted but not stored. Jobs saved with a result hash chain cannot be reprocessed.
+// @Tags reconciliation
+// @Accept json
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Param request body ReprocessRequest true "Late bank files"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/reprocess [post]
+func (h *ReconciliationHandler) ReprocessUnmatched(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	var req ReprocessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+	if len(req.BankFilePaths) == 0 {
+		response.ValidationError(c, "bank_file_paths is required")
+		return
+	}
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	summary, err := h.service.ReprocessUnmatched(c.Request.Context(), jobID, req.BankFilePaths)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrJobNotCompleted):
+			response.Conflict(c, "Job is not completed", err.Error())
+		case errors.Is(err, service.ErrJobChained):
+			response.Conflict(c, "Job results cannot be changed", err.Error())
+		case errors.Is(err, service.ErrResultsChanged):
+			response.Conflict(c, "Job results changed during reprocessing; retry", err.Error())
+		case errors.Is(err, service.ErrEmptyInput):
+			response.ValidationError(c, err.Error())
+		default:
+			logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Reprocess failed")
+			response.InternalError(c, "Reprocess failed", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Unmatched results reprocessed successfully", summary)
+}
+
+// RecomputeJobTotals godoc
+// @Summary Recompute job totals from stored results
+// @Description Recount a completed job's stored results by status and rewrite its matched, unmatched and discrepancy totals when they have drifted from them, e.g. after a partially failed result write. total_processed counts input rows and is left as stored.
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/recompute [post]
+func (h *ReconciliationHandler) RecomputeJobTotals(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	recompute, err := h.service.RecomputeJobTotals(c.Request.Context(), jobID)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		if errors.Is(err, service.ErrJobNotCompleted) {
+			response.Conflict(c, "Job is not completed", err.Error())
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to recompute job totals")
+		response.InternalError(c, "Failed to recompute job totals", err.Error())
+		return
+	}
+
+	if !recompute.Changed {
+		response.Success(c, http.StatusOK, "Job totals already match its results", recompute)
+		return
+	}
+	response.Success(c, http.StatusOK, "Job totals recomputed successfully", recompute)
+}
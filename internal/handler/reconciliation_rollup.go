@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// RollupRequest names the jobs to consolidate, either by ID or as every
+// completed job within a date range
+type RollupRequest struct {
+	JobIDs    []string `json:"job_ids"`
+	StartDate string   `json:"start_date"`
+	EndDate   string   `json:"end_date"`
+}
+
+// RollupJobs godoc
+// @Summary Consolidate reconciliation jobs
+// @Description Combine the persisted results of several completed jobs into one summary with totals, per-source totals and the discrepancy sum. Give job_ids, or start_date and end_date to include every completed job within the range. When jobs overlap, the newest job's result for a transaction or bank statement is kept.
+// @Tags reconciliation
+// @Accept json
+// @Produce json
+// @Param request body RollupRequest true "Jobs to consolidate"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/rollup [post]
+func (h *ReconciliationHandler) RollupJobs(c *gin.Context) {
+	var req RollupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	byRange := req.StartDate != "" || req.EndDate != ""
+	switch {
+	case len(req.JobIDs) > 0 && byRange:
+		response.ValidationError(c, "use either job_ids or start_date and end_date, not both")
+		return
+	case len(req.JobIDs) == 0 && !byRange:
+		response.ValidationError(c, "job_ids or start_date and end_date are required")
+		return
+	}
+
+	var summary *domain.RollupSummary
+	var err error
+	if byRange {
+		startDate, endDate, ok := parseDateRange(c, req.StartDate, req.EndDate)
+		if !ok {
+			return
+		}
+		summary, err = h.service.RollupDateRange(c.Request.Context(), startDate, endDate)
+	} else {
+		for _, jobID := range req.JobIDs {
+			if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+				if requestAborted(c) {
+					return
+				}
+				logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+				response.NotFound(c, "Job not found: "+jobID)
+				return
+			}
+		}
+		summary, err = h.service.RollupJobs(c.Request.Context(), req.JobIDs)
+	}
+
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrJobNotCompleted):
+			response.Conflict(c, "Job is not completed", err.Error())
+		case errors.Is(err, service.ErrNoJobs):
+			response.NotFound(c, "No completed jobs in the date range")
+		default:
+			logger.FromContext(c).WithError(err).Error("Failed to roll up jobs")
+			response.InternalError(c, "Failed to roll up jobs", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Jobs consolidated successfully", summary)
+}
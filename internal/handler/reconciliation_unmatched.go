@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// GetUnmatchedBank godoc
+// @Summary List a job's unmatched bank items by source
+// @Description Page through the UNMATCHED_BANK results of one bank source, or of every source with unmatched items when source is omitted, each source paged on its own
+// @Tags reconciliation
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Param source query string false "Bank source (file name), e.g. bank_bca.csv"
+// @Param page query int false "Page number of each source, starting at 1" default(1)
+// @Param size query int false "Page size of each source (max 1000)" default(100)
+// @Success 200 {object} response.Response{data=domain.UnmatchedBankPages}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/jobs/{job_id}/unmatched-bank [get]
+func (h *ReconciliationHandler) GetUnmatchedBank(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		response.BadRequest(c, "Invalid page", "page must be a positive integer")
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultResultPageSize)))
+	if err != nil || size < 1 || size > maxResultPageSize {
+		response.BadRequest(c, "Invalid size", fmt.Sprintf("size must be between 1 and %d", maxResultPageSize))
+		return
+	}
+
+	if _, err := h.service.GetJobStatus(c.Request.Context(), jobID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Job not found")
+		response.NotFound(c, "Job not found")
+		return
+	}
+
+	pages, err := h.service.GetUnmatchedBank(c.Request.Context(), jobID, c.Query("source"), page, size)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("job_id", jobID).Error("Failed to list unmatched bank items")
+		response.InternalError(c, "Failed to list unmatched bank items", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Unmatched bank items retrieved successfully", pages)
+}
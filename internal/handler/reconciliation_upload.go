@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// uploadMemory is how much of a multipart form is held in memory before
+// file parts spill to disk
+const uploadMemory = 8 << 20
+
+// uploadTypes lists the accepted file extensions and their content types.
+// Browsers and CLI tools disagree on CSV types, so several are allowed.
+var uploadTypes = map[string][]string{
+	".csv": {
+		"text/csv",
+		"application/csv",
+		"text/plain",
+		"application/vnd.ms-excel",
+		"application/octet-stream",
+	},
+	".xlsx": {
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/octet-stream",
+	},
+	".jsonl": {
+		"application/x-ndjson",
+		"application/jsonl",
+		"application/json",
+		"text/plain",
+		"application/octet-stream",
+	},
+	".ndjson": {
+		"application/x-ndjson",
+		"application/json",
+		"text/plain",
+		"application/octet-stream",
+	},
+	".zip": {
+		"application/zip",
+		"application/x-zip-compressed",
+		"application/octet-stream",
+	},
+	".csv.gz": {
+		"application/gzip",
+		"application/x-gzip",
+		"application/octet-stream",
+	},
+}
+
+// uploadExt returns the lower-cased extension of name, keeping .csv.gz whole
+func uploadExt(name string) string {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".csv.gz") {
+		return ".csv.gz"
+	}
+	return filepath.Ext(lower)
+}
+
+// ReconcileUpload godoc
+// @Summary Perform reconciliation on uploaded files
+// @Description Reconcile uploaded bank statement files against system transactions from an uploaded CSV or the database
+// @Tags reconciliation
+// @Accept multipart/form-data
+// @Produce json
+// @Param system_file formData file false "System transactions CSV (optionally .csv.gz); repeat to merge several, omit to use the database"
+// @Param bank_files formData file true "Bank statement files (.csv, .csv.gz, .xlsx, .jsonl, or .zip of them); repeat for several banks"
+// @Param start_date formData string true "Start date (YYYY-MM-DD)"
+// @Param end_date formData string true "End date (YYYY-MM-DD, inclusive)"
+// @Param dry_run formData bool false "Match and summarize without saving a job or results"
+// @Param strategy formData string false "Matching strategy: exact, tolerance or normalized; defaults to the server setting"
+// @Param batch_size formData int false "Rows per read, parse and save batch for this job, up to 100000; defaults to BATCH_SIZE"
+// @Param opening_balance formData string false "Opening balance of the bank account; give with closing_balance to check the closing balance"
+// @Param closing_balance formData string false "Closing balance reported by the bank"
+// @Param callback_url formData string false "URL notified with the job's status and totals when it completes or fails"
+// @Param persist_matched formData bool false "Set to false to store only the exceptions, not the MATCHED results; totals still count them" default(true)
+// @Param max_discrepancy_ratio formData number false "Fail the job when more than this share (0-1) of its ID pairs have discrepancies"
+// @Param max_unmatched_ratio formData number false "Fail the job when more than this share (0-1) of its rows are unmatched"
+// @Param Idempotency-Key header string false "Repeats with the same key return the original job instead of starting another"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/upload [post]
+func (h *ReconciliationHandler) ReconcileUpload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+
+	if err := c.Request.ParseMultipartForm(uploadMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.PayloadTooLarge(c, fmt.Sprintf("Uploads are limited to %d bytes", h.maxUploadSize))
+			return
+		}
+		response.BadRequest(c, "Invalid multipart form", err.Error())
+		return
+	}
+	defer c.Request.MultipartForm.RemoveAll()
+
+	form := c.Request.MultipartForm
+
+	startDate, endDate, ok := parseDateRange(c, c.PostForm("start_date"), c.PostForm("end_date"))
+	if !ok {
+		return
+	}
+
+	dryRun := false
+	if value := c.PostForm("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid dry_run", "Use true or false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	persistMatched := true
+	if value := c.PostForm("persist_matched"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid persist_matched", "Use true or false")
+			return
+		}
+		persistMatched = parsed
+	}
+
+	batchSize := 0
+	if value := c.PostForm("batch_size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid batch_size", "Use a positive integer")
+			return
+		}
+		batchSize = parsed
+	}
+
+	var balances [2]*decimal.Decimal
+	for i, field := range []string{"opening_balance", "closing_balance"} {
+		value := c.PostForm(field)
+		if value == "" {
+			continue
+		}
+		parsed, err := decimal.NewFromString(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid "+field, "Use a decimal amount such as 1250.00")
+			return
+		}
+		balances[i] = &parsed
+	}
+
+	var ratios [2]*float64
+	for i, field := range []string{"max_discrepancy_ratio", "max_unmatched_ratio"} {
+		value := c.PostForm(field)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid "+field, "Use a number between 0 and 1")
+			return
+		}
+		ratios[i] = &parsed
+	}
+
+	svc, ok := h.serviceForStrategy(c, c.PostForm("strategy"))
+	if !ok {
+		return
+	}
+	if svc, ok = serviceWithBatchSize(c, svc, batchSize); !ok {
+		return
+	}
+	if svc, ok = serviceWithLedgerBalance(c, svc, balances[0], balances[1]); !ok {
+		return
+	}
+	if svc, ok = serviceWithCallback(c, svc, c.PostForm("callback_url")); !ok {
+		return
+	}
+	svc = svc.ForPersistMatched(persistMatched)
+	if svc, ok = serviceWithFailureThresholds(c, svc, ratios[0], ratios[1]); !ok {
+		return
+	}
+	if svc, ok = serviceWithIdempotencyKey(c, svc); !ok {
+		return
+	}
+
+	bankFiles := form.File["bank_files"]
+	if len(bankFiles) == 0 {
+		response.ValidationError(c, "at least one bank_files part is required")
+		return
+	}
+
+	tempDir, err := os.MkdirTemp("", "recon-upload-")
+	if err != nil {
+		response.InternalError(c, "Failed to store uploads", err.Error())
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Each system file gets its own directory so equal names do not collide
+	systemFiles := form.File["system_file"]
+	systemFilePaths := make([]string, 0, len(systemFiles))
+	for i, fh := range systemFiles {
+		if ext := uploadExt(fh.Filename); ext != ".csv" && ext != ".csv.gz" {
+			response.BadRequest(c, "Unsupported system_file type", "System transactions must be a .csv or .csv.gz file")
+			return
+		}
+		path, err := saveUpload(fh, filepath.Join(tempDir, "system", strconv.Itoa(i)))
+		if err != nil {
+			response.BadRequest(c, "Invalid system_file", err.Error())
+			return
+		}
+		systemFilePaths = append(systemFilePaths, path)
+	}
+
+	// Bank files keep their names because the file name identifies the bank source
+	bankDir := filepath.Join(tempDir, "banks")
+	bankFilePaths := make([]string, 0, len(bankFiles))
+	seen := make(map[string]bool, len(bankFiles))
+	for _, fh := range bankFiles {
+		name := filepath.Base(fh.Filename)
+		if seen[name] {
+			response.ValidationError(c, fmt.Sprintf("duplicate bank file name %q", name))
+			return
+		}
+		seen[name] = true
+
+		path, err := saveUpload(fh, bankDir)
+		if err != nil {
+			response.BadRequest(c, "Invalid bank file", err.Error())
+			return
+		}
+		bankFilePaths = append(bankFilePaths, path)
+	}
+
+	logger.FromContext(c).WithFields(map[string]interface{}{
+		"system_files": len(systemFilePaths),
+		"bank_files":   len(bankFilePaths),
+		"start_date":   startDate,
+		"end_date":     endDate,
+		"dry_run":      dryRun,
+		"strategy":     c.PostForm("strategy"),
+	}).Info("Starting reconciliation from upload")
+
+	summary, err := svc.Reconcile(c.Request.Context(), systemFilePaths, bankFilePaths, startDate, endDate, dryRun)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		reconcileFailed(c, err)
+		return
+	}
+
+	// Report bank files by their uploaded names rather than temp paths
+	for i := range summary.FileLoadReport {
+		report := &summary.FileLoadReport[i]
+		report.File = strings.TrimPrefix(report.File, bankDir+string(filepath.Separator))
+	}
+
+	response.Success(c, http.StatusOK, completionMessage(summary), summary)
+}
+
+// saveUpload validates an uploaded file's extension and content type and
+// copies it into dir under its base name
+func saveUpload(fh *multipart.FileHeader, dir string) (string, error) {
+	name := filepath.Base(fh.Filename)
+	if name == "." || name == string(filepath.Separator) {
+		return "", fmt.Errorf("missing file name")
+	}
+
+	ext := uploadExt(name)
+	allowed, ok := uploadTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("%s: unsupported file extension %q", name, ext)
+	}
+	if contentType := fh.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !containsString(allowed, mediaType) {
+			return "", fmt.Errorf("%s: unsupported content type %q", name, contentType)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	path := filepath.Join(dir, name)
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
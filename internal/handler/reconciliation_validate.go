@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// ValidateRequest names the files to check before a reconcile; the fields
+// mean the same as in ReconcileRequest
+type ValidateRequest struct {
+	SystemFilePath  string   `json:"system_file_path"`
+	SystemFilePaths []string `json:"system_file_paths"`
+	BankFilePaths   []string `json:"bank_file_paths"`
+}
+
+// ValidateFiles godoc
+// @Summary Validate reconciliation input files
+// @Description Parse system and bank files as a reconcile would and report, per file, the rows read, the malformed rows skipped with the first reasons, the header column each field was read from and the range of dates. Nothing is matched or stored, and the date range is not applied. valid is true when every file parsed with at least one row and none skipped.
+// @Tags reconciliation
+// @Accept json
+// @Produce json
+// @Param request body ValidateRequest true "Files to validate"
+// @Success 200 {object} response.Response{data=domain.ValidationReport}
+// @Failure 422 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/reconcile/validate [post]
+func (h *ReconciliationHandler) ValidateFiles(c *gin.Context) {
+	var req ValidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	systemFiles, err := mergeSystemFiles(req.SystemFilePath, req.SystemFilePaths)
+	if err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+	if len(systemFiles) == 0 && len(req.BankFilePaths) == 0 {
+		response.ValidationError(c, "system_file_paths or bank_file_paths is required")
+		return
+	}
+
+	report, err := h.service.ValidateFiles(c.Request.Context(), systemFiles, req.BankFilePaths)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("Failed to validate files")
+		response.InternalError(c, "Failed to validate files", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Files validated successfully", report)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// requestAborted reports whether the request's context ended before the
+// handler finished, answering 504 when its timeout passed. A client that
+// disconnected gets no response.
+func requestAborted(c *gin.Context) bool {
+	err := c.Request.Context().Err()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.FromContext(c).Warn("Request timed out")
+		response.GatewayTimeout(c, "The request did not finish within the configured timeout")
+		return true
+	case errors.Is(err, context.Canceled):
+		logger.FromContext(c).Info("Request cancelled by the client")
+		c.Abort()
+		return true
+	}
+	return false
+}
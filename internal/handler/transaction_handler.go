@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -21,11 +24,74 @@ func NewTransactionHandler(service service.TransactionService) *TransactionHandl
 	return &TransactionHandler{service: service}
 }
 
+// TransactionAmount is a transaction's amount, negative only for REFUND and
+// CHARGEBACK: either amount, preferably a decimal string such as "19.99", or
+// amount_minor in minor units with amount_scale decimal places (default 2).
+// Amounts are stored with at most domain.MaxAmountScale decimal places.
+// A JSON number amount is deprecated; it is read from its text rather than
+// as a float, but clients may already have rounded it while encoding.
+type TransactionAmount struct {
+	Amount      json.Number `json:"amount,omitempty" swaggertype:"string" example:"19.99"`
+	AmountMinor *int64      `json:"amount_minor,omitempty" example:"1999"`
+	AmountScale *int32      `json:"amount_scale,omitempty" example:"2"`
+}
+
+// defaultAmountScale is how many decimal places amount_minor has by default
+const defaultAmountScale = 2
+
+// decimal returns the amount exactly as given
+func (a TransactionAmount) decimal() (decimal.Decimal, error) {
+	if a.AmountMinor != nil {
+		if a.Amount != "" {
+			return decimal.Decimal{}, fmt.Errorf("give amount or amount_minor, not both")
+		}
+		scale := int32(defaultAmountScale)
+		if a.AmountScale != nil {
+			scale = *a.AmountScale
+		}
+		if scale < 0 || scale > domain.MaxAmountScale {
+			return decimal.Decimal{}, fmt.Errorf("amount_scale must be between 0 and %d", domain.MaxAmountScale)
+		}
+		if *a.AmountMinor == 0 {
+			return decimal.Decimal{}, fmt.Errorf("amount must not be zero")
+		}
+		return decimal.New(*a.AmountMinor, -scale), nil
+	}
+	if a.AmountScale != nil {
+		return decimal.Decimal{}, fmt.Errorf("amount_scale is only used with amount_minor")
+	}
+	if a.Amount == "" {
+		return decimal.Decimal{}, fmt.Errorf("amount or amount_minor is required")
+	}
+	amount, err := decimal.NewFromString(string(a.Amount))
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("invalid amount %q: use a decimal such as \"19.99\"", a.Amount)
+	}
+	if amount.IsZero() {
+		return decimal.Decimal{}, fmt.Errorf("amount must not be zero")
+	}
+	if !amount.Equal(amount.Truncate(domain.MaxAmountScale)) {
+		return decimal.Decimal{}, fmt.Errorf("amount must have at most %d decimal places", domain.MaxAmountScale)
+	}
+	return amount, nil
+}
+
 type CreateTransactionRequest struct {
-	TrxID           string  `json:"trx_id" binding:"required"`
-	Amount          float64 `json:"amount" binding:"required,gt=0"`
-	Type            string  `json:"type" binding:"required,oneof=DEBIT CREDIT"`
-	TransactionTime string  `json:"transaction_time" binding:"required"`
+	TrxID string `json:"trx_id" binding:"required"`
+	TransactionAmount
+	Type            string `json:"type" binding:"required,oneof=DEBIT CREDIT REFUND CHARGEBACK"`
+	TransactionTime string `json:"transaction_time" binding:"required"`
+	OrderID         string `json:"order_id,omitempty"`    // external reference matched when MATCH_REFERENCE_FIELD is order_id
+	Description     string `json:"description,omitempty"` // free text carried to results, e.g. a merchant name
+}
+
+// UpdateTransactionRequest replaces a transaction's fields; the trx_id comes from the path
+type UpdateTransactionRequest struct {
+	TransactionAmount
+	Type            string `json:"type" binding:"required,oneof=DEBIT CREDIT REFUND CHARGEBACK"`
+	TransactionTime string `json:"transaction_time" binding:"required"`
+	OrderID         string `json:"order_id,omitempty"`
+	Description     string `json:"description,omitempty"`
 }
 
 type BulkCreateTransactionRequest struct {
@@ -51,7 +117,13 @@ type GetTransactionsByDateRangeRequest struct {
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 	var req CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.GetLogger().WithError(err).Error("Invalid request")
+		logger.FromContext(c).WithError(err).Error("Invalid request")
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	amount, err := req.decimal()
+	if err != nil {
 		response.ValidationError(c, err.Error())
 		return
 	}
@@ -64,13 +136,18 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 
 	tx := &domain.Transaction{
 		TrxID:           req.TrxID,
-		Amount:          decimal.NewFromFloat(req.Amount),
+		OrderID:         req.OrderID,
+		Description:     req.Description,
+		Amount:          amount,
 		Type:            domain.TransactionType(req.Type),
 		TransactionTime: transactionTime,
 	}
 
-	if err := h.service.Create(tx); err != nil {
-		logger.GetLogger().WithError(err).Error("Failed to create transaction")
+	if err := h.service.Create(c.Request.Context(), tx); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("Failed to create transaction")
 		response.InternalError(c, "Failed to create transaction", err.Error())
 		return
 	}
@@ -80,12 +157,13 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 
 // BulkCreateTransactions godoc
 // @Summary Bulk create transactions
-// @Description Create multiple transactions at once
+// @Description Create multiple transactions at once. Rows whose trx_id is already stored are skipped and invalid rows are rejected; the report lists the outcome of every row. Answers 201 when no row failed and 207 otherwise.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param transactions body BulkCreateTransactionRequest true "Transactions data"
-// @Success 201 {object} response.Response
+// @Success 201 {object} response.Response{data=domain.BulkCreateReport}
+// @Success 207 {object} response.Response{data=domain.BulkCreateReport}
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/transactions/bulk [post]
@@ -96,29 +174,50 @@ func (h *TransactionHandler) BulkCreateTransactions(c *gin.Context) {
 		return
 	}
 
+	report := &domain.BulkCreateReport{Rows: make([]domain.BulkRowOutcome, 0, len(req.Transactions))}
 	transactions := make([]domain.Transaction, 0, len(req.Transactions))
-	for _, txReq := range req.Transactions {
+	positions := make([]int, 0, len(req.Transactions))
+	for i, txReq := range req.Transactions {
+		amount, err := txReq.decimal()
+		if err != nil {
+			report.Add(domain.BulkRowOutcome{Index: i, TrxID: txReq.TrxID, Status: domain.BulkRowFailed, Reason: err.Error()})
+			continue
+		}
+
 		transactionTime, err := time.Parse(time.RFC3339, txReq.TransactionTime)
 		if err != nil {
-			logger.GetLogger().WithError(err).WithField("trx_id", txReq.TrxID).Warn("Invalid transaction time")
+			logger.FromContext(c).WithError(err).WithField("trx_id", txReq.TrxID).Warn("Invalid transaction time")
+			report.Add(domain.BulkRowOutcome{Index: i, TrxID: txReq.TrxID, Status: domain.BulkRowFailed, Reason: "invalid transaction time format: use RFC3339"})
 			continue
 		}
 
 		transactions = append(transactions, domain.Transaction{
 			TrxID:           txReq.TrxID,
-			Amount:          decimal.NewFromFloat(txReq.Amount),
+			OrderID:         txReq.OrderID,
+			Description:     txReq.Description,
+			Amount:          amount,
 			Type:            domain.TransactionType(txReq.Type),
 			TransactionTime: transactionTime,
 		})
+		positions = append(positions, i)
 	}
 
-	if err := h.service.BulkCreate(transactions); err != nil {
-		logger.GetLogger().WithError(err).Error("Failed to bulk create transactions")
+	created, err := h.service.BulkCreate(c.Request.Context(), transactions)
+	if err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("Failed to bulk create transactions")
 		response.InternalError(c, "Failed to bulk create transactions", err.Error())
 		return
 	}
+	report.Merge(created, positions)
 
-	response.Success(c, http.StatusCreated, "Transactions created successfully", map[string]int{"count": len(transactions)})
+	if report.Failed > 0 {
+		response.Success(c, http.StatusMultiStatus, "Some transactions were rejected", report)
+		return
+	}
+	response.Success(c, http.StatusCreated, "Transactions created successfully", report)
 }
 
 // GetTransaction godoc
@@ -134,9 +233,12 @@ func (h *TransactionHandler) BulkCreateTransactions(c *gin.Context) {
 func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 	trxID := c.Param("trx_id")
 
-	tx, err := h.service.GetByTrxID(trxID)
+	tx, err := h.service.GetByTrxID(c.Request.Context(), trxID)
 	if err != nil {
-		logger.GetLogger().WithError(err).WithField("trx_id", trxID).Error("Transaction not found")
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("trx_id", trxID).Error("Transaction not found")
 		response.NotFound(c, "Transaction not found")
 		return
 	}
@@ -144,13 +246,117 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Transaction retrieved successfully", tx)
 }
 
+// UpdateTransaction godoc
+// @Summary Update a transaction
+// @Description Replace the amount, type and transaction time of a transaction, e.g. to correct a bad row before reconciling
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param trx_id path string true "Transaction ID"
+// @Param transaction body UpdateTransactionRequest true "Transaction data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/transactions/{trx_id} [put]
+func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
+	trxID := c.Param("trx_id")
+
+	var req UpdateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c).WithError(err).Error("Invalid request")
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	amount, err := req.decimal()
+	if err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	transactionTime, err := time.Parse(time.RFC3339, req.TransactionTime)
+	if err != nil {
+		response.BadRequest(c, "Invalid transaction time format", "Use RFC3339 format")
+		return
+	}
+
+	if _, err := h.service.GetByTrxID(c.Request.Context(), trxID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("trx_id", trxID).Error("Transaction not found")
+		response.NotFound(c, "Transaction not found")
+		return
+	}
+
+	tx := &domain.Transaction{
+		TrxID:           trxID,
+		OrderID:         req.OrderID,
+		Description:     req.Description,
+		Amount:          amount,
+		Type:            domain.TransactionType(req.Type),
+		TransactionTime: transactionTime,
+	}
+
+	if err := h.service.Update(c.Request.Context(), tx); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTransaction) {
+			response.ValidationError(c, err.Error())
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("trx_id", trxID).Error("Failed to update transaction")
+		response.InternalError(c, "Failed to update transaction", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Transaction updated successfully", tx)
+}
+
+// DeleteTransaction godoc
+// @Summary Delete a transaction
+// @Description Remove a transaction, e.g. a test row, so later reconciliations no longer see it
+// @Tags transactions
+// @Produce json
+// @Param trx_id path string true "Transaction ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/transactions/{trx_id} [delete]
+func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
+	trxID := c.Param("trx_id")
+
+	if _, err := h.service.GetByTrxID(c.Request.Context(), trxID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("trx_id", trxID).Error("Transaction not found")
+		response.NotFound(c, "Transaction not found")
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), trxID); err != nil {
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).WithField("trx_id", trxID).Error("Failed to delete transaction")
+		response.InternalError(c, "Failed to delete transaction", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Transaction deleted successfully", nil)
+}
+
 // GetTransactionsByDateRange godoc
 // @Summary Get transactions by date range
-// @Description Get all transactions within a specified date range
+// @Description Get all transactions with start_date <= transaction_time < end_date
 // @Tags transactions
 // @Produce json
-// @Param start_date query string true "Start date (RFC3339 format)"
-// @Param end_date query string true "End date (RFC3339 format)"
+// @Param start_date query string true "Start date, inclusive (RFC3339 format)"
+// @Param end_date query string true "End date, exclusive (RFC3339 format)"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
@@ -174,9 +380,12 @@ func (h *TransactionHandler) GetTransactionsByDateRange(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.service.GetByDateRange(startDate, endDate)
+	transactions, err := h.service.GetByDateRange(c.Request.Context(), startDate, endDate)
 	if err != nil {
-		logger.GetLogger().WithError(err).Error("Failed to get transactions")
+		if requestAborted(c) {
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("Failed to get transactions")
 		response.InternalError(c, "Failed to get transactions", err.Error())
 		return
 	}
@@ -0,0 +1,139 @@
+package matcher
+
+import (
+	"time"
+
+	"recon-engine/internal/domain"
+)
+
+// UniqueCandidateIndexer is a CandidateIndexer whose keys say nothing about
+// which record is which. The engine pairs a system transaction only when it
+// has a single candidate and no other transaction competes for it; every
+// record involved in a tie is reported as ambiguous rather than guessed.
+type UniqueCandidateIndexer interface {
+	CandidateIndexer
+	UniqueCandidates()
+}
+
+// AmountDateMatchStrategy matches bank statements without a reference ID to
+// the system transaction with the same signed amount posted at most
+// DateWindow apart. Statements carrying a reference are left to the other
+// strategies of a chain, so it is meant to run last, as in "exact,amount_date".
+type AmountDateMatchStrategy struct {
+	DateWindow time.Duration
+}
+
+func NewAmountDateMatchStrategy(dateWindow time.Duration) *AmountDateMatchStrategy {
+	return &AmountDateMatchStrategy{DateWindow: dateWindow}
+}
+
+func (s *AmountDateMatchStrategy) Match(systemTx domain.Transaction, bankStmt domain.BankStatement) bool {
+	if bankStmt.TrxRefID != "" || !signedAmount(systemTx).Equal(bankStmt.Amount) {
+		return false
+	}
+	return dateGap(systemTx.TransactionTime, bankStmt.Date) <= s.DateWindow
+}
+
+// BankKey buckets statements by amount and calendar day
+func (s *AmountDateMatchStrategy) BankKey(stmt domain.BankStatement) string {
+	return amountDateKey(stmt.Amount.String(), dayKey(stmt.Date))
+}
+
+// SystemKeys returns the amount bucket of tx for every day within the date window
+func (s *AmountDateMatchStrategy) SystemKeys(tx domain.Transaction) []string {
+	window := ToleranceWindowStrategy{DateWindow: s.DateWindow}
+	amount := signedAmount(tx).String()
+	keys := window.SystemKeys(tx)
+	for i, day := range keys {
+		keys[i] = amountDateKey(amount, day)
+	}
+	return keys
+}
+
+func (s *AmountDateMatchStrategy) UniqueCandidates() {}
+
+// amountDateKey joins a canonical amount, which decimal prints without
+// trailing zeros, and a day key
+func amountDateKey(amount, day string) string {
+	return amount + "|" + day
+}
+
+// candidateSlot locates a statement in a bankMap
+type candidateSlot struct {
+	key string
+	idx int
+}
+
+// matchUnique pairs transactions under a UniqueCandidateIndexer. All
+// candidates are collected before anything is claimed, so the outcome does
+// not depend on the order of the transactions.
+func (e *ReconciliationEngine) matchUnique(m *bankMap, indexer UniqueCandidateIndexer, transactions []domain.Transaction, output *ReconciliationOutput) {
+	candidates := make([][]candidateSlot, len(transactions))
+	contenders := make(map[candidateSlot]int)
+	for i, sysTx := range transactions {
+		for _, key := range indexer.SystemKeys(sysTx) {
+			for idx, stmt := range m.candidates[key] {
+				if m.claimed[key][idx] || !e.strategy.Match(sysTx, stmt) {
+					continue
+				}
+				slot := candidateSlot{key: key, idx: idx}
+				candidates[i] = append(candidates[i], slot)
+				contenders[slot]++
+			}
+		}
+	}
+
+	for i, sysTx := range transactions {
+		slots := candidates[i]
+		switch {
+		case len(slots) == 0:
+			output.UnmatchedSystem = append(output.UnmatchedSystem, sysTx)
+		case len(slots) == 1 && contenders[slots[0]] == 1:
+			m.claimed[slots[0].key][slots[0].idx] = true
+			e.classifyPair(sysTx, m.candidates[slots[0].key][slots[0].idx], output)
+		default:
+			output.AmbiguousSystem = append(output.AmbiguousSystem, sysTx)
+			for _, slot := range slots {
+				m.flagAmbiguous(slot)
+			}
+		}
+	}
+}
+
+// flagAmbiguous takes a statement out of matching and marks it as ambiguous
+func (m *bankMap) flagAmbiguous(slot candidateSlot) {
+	if m.ambiguous == nil {
+		m.ambiguous = make(map[string][]bool)
+	}
+	if m.ambiguous[slot.key] == nil {
+		m.ambiguous[slot.key] = make([]bool, len(m.candidates[slot.key]))
+	}
+	m.claimed[slot.key][slot.idx] = true
+	m.ambiguous[slot.key][slot.idx] = true
+}
+
+// ambiguousStatements returns, in input order, the statements flagged as ambiguous
+func (e *ReconciliationEngine) ambiguousStatements(m *bankMap, statements []domain.BankStatement) []domain.BankStatement {
+	flagged := make([]domain.BankStatement, 0)
+	if len(m.ambiguous) == 0 {
+		return flagged
+	}
+	seen := make(map[string]int, len(m.ambiguous))
+	for _, stmt := range statements {
+		key := e.bankKey(stmt)
+		idx := seen[key]
+		seen[key]++
+		if m.ambiguous[key] != nil && m.ambiguous[key][idx] {
+			flagged = append(flagged, stmt)
+		}
+	}
+	return flagged
+}
+
+// defersMatching reports whether the engine must see every system
+// transaction before pairing any, as UniqueCandidateIndexer and the
+// WithUniqueClaims check do
+func (e *ReconciliationEngine) defersMatching() bool {
+	_, unique := e.strategy.(UniqueCandidateIndexer)
+	return unique || e.checksClaims()
+}
@@ -0,0 +1,91 @@
+package matcher
+
+import (
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// ReconcileBalances compares the net total of the system transactions with
+// that of the bank statements, independent of line matching. Items outside
+// the amount bounds are left out and bank amounts are normalized by their
+// source's convention, as for matching.
+func (e *ReconciliationEngine) ReconcileBalances(input ReconciliationInput) *domain.BalanceCheck {
+	bankNet, unsigned := e.BankNet(input.BankStatements)
+	return domain.NewBalanceCheck(e.SystemNet(input.SystemTransactions), bankNet, unsigned)
+}
+
+// SystemNet sums the signed amounts of the transactions within the amount
+// bounds: credits minus debits
+func (e *ReconciliationEngine) SystemNet(transactions []domain.Transaction) decimal.Decimal {
+	transactions, _ = e.filterTransactionsByAmount(transactions)
+	net := decimal.Zero
+	for _, tx := range transactions {
+		net = net.Add(e.normalizeAmount(tx))
+	}
+	return net
+}
+
+// BankNet sums the normalized amounts of the statements within the amount
+// bounds. Amounts whose direction only pairing with a system transaction
+// could tell are counted and left out.
+func (e *ReconciliationEngine) BankNet(statements []domain.BankStatement) (decimal.Decimal, int) {
+	statements, _ = e.filterStatementsByAmount(statements)
+	net := decimal.Zero
+	unsigned := 0
+	for _, stmt := range e.NormalizeBankAmounts(statements) {
+		if e.unsignedStatement(stmt) {
+			unsigned++
+			continue
+		}
+		net = net.Add(stmt.Amount)
+	}
+	return net, unsigned
+}
+
+// CheckLedgerBalance verifies that opening plus the matched transactions
+// gives the closing balance the bank reported, once the per-line exceptions
+// of output are accounted for. Any difference left is unexplained by the
+// lines: a missing or extra bank line, or a wrong balance. Items outside the
+// amount bounds are not in output, so they count towards the difference.
+func (e *ReconciliationEngine) CheckLedgerBalance(opening, closing decimal.Decimal, output *ReconciliationOutput) *domain.LedgerBalance {
+	matchedNet := decimal.Zero
+	explained := decimal.Zero
+	addPair := func(sysTx domain.Transaction, stmt domain.BankStatement) {
+		systemAmount := e.normalizeAmount(sysTx)
+		matchedNet = matchedNet.Add(systemAmount)
+		explained = explained.Add(e.bankAmount(sysTx, stmt).Sub(systemAmount))
+	}
+	for _, pairs := range [][]MatchedPair{output.Matched, output.DateMismatches, output.CurrencyMismatches, output.DirectionMismatches} {
+		for _, pair := range pairs {
+			addPair(pair.SystemTx, pair.BankStmt)
+		}
+	}
+	for _, pair := range output.Discrepancies {
+		addPair(pair.SystemTx, pair.BankStmt)
+	}
+
+	unsigned := 0
+	for _, statements := range [][]domain.BankStatement{output.UnmatchedBank, output.DuplicateBank, output.MalformedBank, output.AmbiguousBank} {
+		for _, stmt := range statements {
+			if e.unsignedStatement(stmt) {
+				unsigned++
+				continue
+			}
+			explained = explained.Add(stmt.Amount)
+		}
+	}
+
+	expected := opening.Add(matchedNet)
+	actual := closing.Sub(explained)
+	difference := actual.Sub(expected)
+	return &domain.LedgerBalance{
+		Opening:      opening,
+		Closing:      closing,
+		Expected:     expected,
+		Actual:       actual,
+		Difference:   difference,
+		Balanced:     difference.IsZero(),
+		UnsignedBank: unsigned,
+	}
+}
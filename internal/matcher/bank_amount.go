@@ -0,0 +1,116 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// BankAmountConvention describes how a bank writes the sign of its amounts.
+// Statements are normalized to credits positive, debits negative before
+// matching. A statement with an explicit type is already signed by the
+// parser and is left as it is.
+type BankAmountConvention string
+
+const (
+	// BankAmountDebitsNegative is the default: credits positive, debits negative
+	BankAmountDebitsNegative BankAmountConvention = "debits_negative"
+	// BankAmountDebitsPositive flips the sign, for banks writing debits
+	// positive and credits negative
+	BankAmountDebitsPositive BankAmountConvention = "debits_positive"
+	// BankAmountAlwaysPositive treats amounts as magnitudes. Without a type
+	// column the direction is taken from the system transaction the
+	// statement pairs with.
+	BankAmountAlwaysPositive BankAmountConvention = "always_positive"
+	// BankAmountSeparateColumns is for files with debit and credit columns,
+	// which the parser combines into a signed, typed amount
+	BankAmountSeparateColumns BankAmountConvention = "separate_columns"
+	// BankAmountAuto picks one of the first three from the signs of the
+	// source's ID pairs; see DetectBankAmountConventions
+	BankAmountAuto BankAmountConvention = "auto"
+)
+
+// ParseBankAmountConvention validates a convention name, defaulting to
+// BankAmountDebitsNegative
+func ParseBankAmountConvention(name string) (BankAmountConvention, error) {
+	switch convention := BankAmountConvention(strings.ToLower(strings.TrimSpace(name))); convention {
+	case "":
+		return BankAmountDebitsNegative, nil
+	case BankAmountDebitsNegative, BankAmountDebitsPositive, BankAmountAlwaysPositive, BankAmountSeparateColumns, BankAmountAuto:
+		return convention, nil
+	default:
+		return "", fmt.Errorf("unknown bank amount convention: %s", name)
+	}
+}
+
+// BankAmountConventions holds the amount convention of each bank source
+// (file name); other sources use Default
+type BankAmountConventions struct {
+	Default  BankAmountConvention
+	BySource map[string]BankAmountConvention
+}
+
+// WithBankAmountConventions normalizes bank statement amounts by the
+// convention of their source before matching
+func WithBankAmountConventions(conventions BankAmountConventions) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.bankConventions = conventions
+	}
+}
+
+func (c BankAmountConventions) source(source string) BankAmountConvention {
+	if convention, ok := c.BySource[source]; ok {
+		return convention
+	}
+	return c.Default
+}
+
+// NormalizeBankAmount returns stmt with its amount signed credits positive,
+// debits negative under convention. Flipped amounts get the type their new
+// sign implies, so normalizing twice changes nothing.
+func NormalizeBankAmount(stmt domain.BankStatement, convention BankAmountConvention) domain.BankStatement {
+	if stmt.Type != "" {
+		return stmt
+	}
+	switch convention {
+	case BankAmountDebitsPositive:
+		if !stmt.Amount.IsZero() {
+			stmt.Amount = stmt.Amount.Neg()
+			stmt.Type = bankDirection(stmt)
+		}
+	case BankAmountAlwaysPositive:
+		stmt.Amount = stmt.Amount.Abs()
+	}
+	return stmt
+}
+
+// NormalizeBankAmounts applies NormalizeBankAmount to each statement by the
+// convention of its source
+func (e *ReconciliationEngine) NormalizeBankAmounts(statements []domain.BankStatement) []domain.BankStatement {
+	if e.bankConventions.Default == "" && len(e.bankConventions.BySource) == 0 {
+		return statements
+	}
+	normalized := make([]domain.BankStatement, len(statements))
+	for i, stmt := range statements {
+		normalized[i] = NormalizeBankAmount(stmt, e.bankConventions.source(stmt.Source))
+	}
+	return normalized
+}
+
+// unsignedStatement reports whether the statement's amount is a magnitude
+// with no direction of its own
+func (e *ReconciliationEngine) unsignedStatement(stmt domain.BankStatement) bool {
+	return stmt.Type == "" && e.bankConventions.source(stmt.Source) == BankAmountAlwaysPositive
+}
+
+// bankAmount is the statement amount compared with sysTx. A magnitude takes
+// the direction of the system transaction.
+func (e *ReconciliationEngine) bankAmount(sysTx domain.Transaction, stmt domain.BankStatement) decimal.Decimal {
+	if e.unsignedStatement(stmt) && sysTx.Type.Direction() == domain.Debit {
+		return stmt.Amount.Abs().Neg()
+	}
+	return stmt.Amount
+}
@@ -0,0 +1,301 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// DuplicatePolicy decides which bank statement a system transaction is
+// matched against when several statements share the same reference ID
+type DuplicatePolicy string
+
+const (
+	// DuplicateFirst matches the first statement seen and reports the
+	// remaining duplicates
+	DuplicateFirst DuplicatePolicy = "first"
+	// DuplicateClosestAmount matches the unclaimed statement with the
+	// smallest discrepancy and reports the others as duplicates
+	DuplicateClosestAmount DuplicatePolicy = "closest_amount"
+	// DuplicateLast matches the last statement seen, for banks that append
+	// corrections after the original row
+	DuplicateLast DuplicatePolicy = "last"
+	// DuplicateLargestAmount matches the statement with the largest absolute
+	// amount
+	DuplicateLargestAmount DuplicatePolicy = "largest_amount"
+	// DuplicateSum merges statements sharing a reference ID into one whose
+	// amount is their total, for payments the bank settles in parts
+	DuplicateSum DuplicatePolicy = "sum"
+	// DuplicateError fails the reconciliation, listing the repeated IDs
+	DuplicateError DuplicatePolicy = "error"
+)
+
+// maxListedDuplicates caps the IDs named in a DuplicateReferenceError message
+const maxListedDuplicates = 20
+
+// DuplicateReferenceError is returned under DuplicateError when bank
+// statements share a reference ID
+type DuplicateReferenceError struct {
+	References []string
+}
+
+func (e *DuplicateReferenceError) Error() string {
+	listed := e.References
+	more := ""
+	if len(listed) > maxListedDuplicates {
+		listed = listed[:maxListedDuplicates]
+		more = fmt.Sprintf(" and %d more", len(e.References)-maxListedDuplicates)
+	}
+	return fmt.Sprintf("duplicate bank reference IDs: %s%s", strings.Join(listed, ", "), more)
+}
+
+// ParseDuplicatePolicy validates a policy name, defaulting to DuplicateFirst
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return DuplicateFirst, nil
+	case DuplicateFirst, DuplicateClosestAmount, DuplicateLast, DuplicateLargestAmount, DuplicateSum, DuplicateError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate policy: %s", name)
+	}
+}
+
+// resolveDuplicates applies the policies that act on the statements
+// themselves: DuplicateSum merges statements sharing a reference ID, in place
+// of the first, and DuplicateError rejects them. Statements without a
+// reference ID are never duplicates.
+func (e *ReconciliationEngine) resolveDuplicates(statements []domain.BankStatement) ([]domain.BankStatement, error) {
+	if e.duplicatePolicy != DuplicateSum && e.duplicatePolicy != DuplicateError {
+		return statements, nil
+	}
+
+	counts := make(map[string]int, len(statements))
+	var repeated []string
+	for _, stmt := range statements {
+		if stmt.TrxRefID == "" {
+			continue
+		}
+		key := e.key(stmt.TrxRefID)
+		if counts[key]++; counts[key] == 2 {
+			repeated = append(repeated, stmt.TrxRefID)
+		}
+	}
+	if len(repeated) == 0 {
+		return statements, nil
+	}
+	if e.duplicatePolicy == DuplicateError {
+		return nil, &DuplicateReferenceError{References: repeated}
+	}
+
+	merged := make([]domain.BankStatement, 0, len(statements)-len(repeated))
+	first := make(map[string]int, len(repeated))
+	for _, stmt := range statements {
+		key := e.key(stmt.TrxRefID)
+		if stmt.TrxRefID == "" || counts[key] == 1 {
+			merged = append(merged, stmt)
+			continue
+		}
+		idx, seen := first[key]
+		if !seen {
+			first[key] = len(merged)
+			merged = append(merged, stmt)
+			continue
+		}
+		addStatement(&merged[idx], stmt)
+	}
+	return merged, nil
+}
+
+// addStatement adds the amount of stmt to total
+func addStatement(total *domain.BankStatement, stmt domain.BankStatement) {
+	total.Amount = total.Amount.Add(stmt.Amount)
+	if total.Type != stmt.Type {
+		// Mixed directions: the sign of the total decides
+		total.Type = domain.Credit
+		if total.Amount.IsNegative() {
+			total.Type = domain.Debit
+		}
+	}
+}
+
+// bankMap indexes bank statements by lookup key and tracks which have
+// been claimed by a system transaction
+type bankMap struct {
+	candidates map[string][]domain.BankStatement
+	claimed    map[string][]bool
+	// ambiguous marks the claimed statements that were tied between candidates
+	ambiguous map[string][]bool
+}
+
+// buildBankMap creates a hash map indexed by (normalized) reference ID, or
+// by the strategy's own key when it is a CandidateIndexer
+func (e *ReconciliationEngine) buildBankMap(statements []domain.BankStatement) *bankMap {
+	m := &bankMap{
+		candidates: make(map[string][]domain.BankStatement, len(statements)),
+		claimed:    make(map[string][]bool, len(statements)),
+	}
+	for _, stmt := range statements {
+		key := e.bankKey(stmt)
+		m.candidates[key] = append(m.candidates[key], stmt)
+		m.claimed[key] = append(m.claimed[key], false)
+	}
+	return m
+}
+
+// claim finds a bank statement not yet claimed for sysTx according to the
+// duplicate policy and marks it as matched
+func (e *ReconciliationEngine) claim(m *bankMap, sysTx domain.Transaction) (domain.BankStatement, bool) {
+	if indexer, ok := e.strategy.(CandidateIndexer); ok {
+		return e.claimIndexed(m, indexer.SystemKeys(sysTx), sysTx)
+	}
+
+	key := e.key(e.reference(sysTx))
+	candidates, found := m.candidates[key]
+	if !found {
+		return domain.BankStatement{}, false
+	}
+
+	// Transactions sharing an order_id look up the same bucket; each
+	// statement still pairs with one of them
+	var idx int
+	switch e.duplicatePolicy {
+	case DuplicateClosestAmount:
+		idx = e.closestCandidate(sysTx, candidates, m.claimed[key])
+	case DuplicateLast:
+		idx = lastCandidate(m.claimed[key])
+	case DuplicateLargestAmount:
+		idx = largestCandidate(candidates, m.claimed[key])
+	default:
+		idx = firstCandidate(m.claimed[key])
+	}
+
+	if idx < 0 || !e.strategy.Match(sysTx, candidates[idx]) {
+		return domain.BankStatement{}, false
+	}
+
+	m.claimed[key][idx] = true
+	return candidates[idx], true
+}
+
+// claimIndexed searches every probed bucket for the unclaimed statement the
+// strategy accepts with the smallest discrepancy
+func (e *ReconciliationEngine) claimIndexed(m *bankMap, keys []string, sysTx domain.Transaction) (domain.BankStatement, bool) {
+	bestKey, bestIdx := "", -1
+	var bestGap decimal.Decimal
+
+	for _, key := range keys {
+		for i, candidate := range m.candidates[key] {
+			if m.claimed[key][i] || !e.strategy.Match(sysTx, candidate) {
+				continue
+			}
+			gap := e.amountGap(sysTx, candidate)
+			if bestIdx < 0 || gap.LessThan(bestGap) {
+				bestKey, bestIdx, bestGap = key, i, gap
+			}
+		}
+	}
+
+	if bestIdx < 0 {
+		return domain.BankStatement{}, false
+	}
+	m.claimed[bestKey][bestIdx] = true
+	return m.candidates[bestKey][bestIdx], true
+}
+
+// closestCandidate returns the index of the unclaimed candidate with the
+// smallest discrepancy, or -1 when all are claimed
+func (e *ReconciliationEngine) closestCandidate(sysTx domain.Transaction, candidates []domain.BankStatement, claimed []bool) int {
+	best := -1
+	var bestGap decimal.Decimal
+	for i, candidate := range candidates {
+		if claimed[i] {
+			continue
+		}
+		if gap := e.amountGap(sysTx, candidate); best < 0 || gap.LessThan(bestGap) {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+// firstCandidate returns the index of the first candidate not yet claimed,
+// or -1 when all are
+func firstCandidate(claimed []bool) int {
+	for i, c := range claimed {
+		if !c {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastCandidate returns the index of the last candidate not yet claimed,
+// or -1 when all are
+func lastCandidate(claimed []bool) int {
+	for i := len(claimed) - 1; i >= 0; i-- {
+		if !claimed[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// largestCandidate returns the index of the unclaimed candidate with the
+// largest absolute amount, the first among equals, or -1 when all are
+// claimed
+func largestCandidate(candidates []domain.BankStatement, claimed []bool) int {
+	best := -1
+	for i, candidate := range candidates {
+		if claimed[i] {
+			continue
+		}
+		if best < 0 || candidate.Amount.Abs().GreaterThan(candidates[best].Amount.Abs()) {
+			best = i
+		}
+	}
+	return best
+}
+
+// unclaimed returns, in input order, the statements no system transaction
+// matched and the duplicates among them. A statement is a duplicate when its
+// reference ID was matched to another statement or an earlier unmatched
+// statement already carries it. Buckets of a CandidateIndexer group unrelated
+// statements, so they never hold duplicates. Unclaimed installment groups are
+// reported as their installments.
+func (e *ReconciliationEngine) unclaimed(m *bankMap, statements []domain.BankStatement) ([]domain.BankStatement, []domain.BankStatement) {
+	unmatched := make([]domain.BankStatement, 0)
+	duplicates := make([]domain.BankStatement, 0)
+	_, indexed := e.strategy.(CandidateIndexer)
+
+	seen := make(map[string]int, len(m.candidates))
+	reported := make(map[string]bool)
+	for _, stmt := range statements {
+		key := e.bankKey(stmt)
+		idx := seen[key]
+		seen[key]++
+		if m.claimed[key][idx] {
+			continue
+		}
+
+		if !indexed && stmt.TrxRefID != "" && (reported[key] || anyClaimed(m.claimed[key])) {
+			duplicates = append(duplicates, ungrouped(stmt)...)
+			continue
+		}
+		reported[key] = true
+		unmatched = append(unmatched, ungrouped(stmt)...)
+	}
+	return unmatched, duplicates
+}
+
+func anyClaimed(claimed []bool) bool {
+	for _, c := range claimed {
+		if c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package matcher
+
+import (
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+var basisPointsPerUnit = decimal.NewFromInt(10000)
+
+// BasisPointTolerance lets an ID-matched pair count as MATCHED when its
+// amounts differ by at most a share of the system amount, in basis points
+// (1 bps = 0.01%), e.g. to absorb bank fees. A source's entry in BySource,
+// including zero, replaces Default for its statements.
+type BasisPointTolerance struct {
+	Default decimal.Decimal
+	// BySource holds tolerances by bank source (file name)
+	BySource map[string]decimal.Decimal
+}
+
+// WithBasisPointTolerance matches pairs whose amount gap is within the
+// statement source's basis point tolerance. Pairs beyond it are still
+// reported as discrepancies.
+func WithBasisPointTolerance(tolerance BasisPointTolerance) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.bpsTolerance = tolerance
+	}
+}
+
+// sourceBps returns the tolerance that applies to a bank source
+func (t BasisPointTolerance) sourceBps(source string) decimal.Decimal {
+	if bps, ok := t.BySource[source]; ok {
+		return bps
+	}
+	return t.Default
+}
+
+// withinBpsTolerance reports whether gap is covered by the tolerance of the
+// bank statement's source, relative to the system amount
+func (e *ReconciliationEngine) withinBpsTolerance(gap decimal.Decimal, sysTx domain.Transaction, bankStmt domain.BankStatement) bool {
+	bps := e.bpsTolerance.sourceBps(bankStmt.Source)
+	if !bps.IsPositive() {
+		return false
+	}
+	allowed := sysTx.Amount.Abs().Mul(bps).Div(basisPointsPerUnit)
+	return gap.LessThanOrEqual(allowed)
+}
@@ -0,0 +1,106 @@
+package matcher
+
+import (
+	"strings"
+
+	"recon-engine/internal/domain"
+)
+
+// NamedStrategy is implemented by strategies that report their name on the
+// pairs they match
+type NamedStrategy interface {
+	Name() string
+}
+
+func (s *ExactMatchStrategy) Name() string      { return StrategyExact }
+func (s *ToleranceWindowStrategy) Name() string { return StrategyTolerance }
+func (s *NormalizedMatchStrategy) Name() string { return StrategyNormalized }
+func (s *AmountDateMatchStrategy) Name() string { return StrategyAmountDate }
+
+// ChainedMatchStrategy tries its strategies in order. The engine runs one
+// matching pass per strategy, feeding the system transactions and bank
+// statements left unmatched by a pass into the next, so a stricter strategy
+// listed first always wins over a looser one. Each pair records the strategy
+// that matched it.
+type ChainedMatchStrategy struct {
+	Strategies []MatchingStrategy
+}
+
+// NewChainedMatchStrategy chains strategies in the order given. Nested chains
+// are flattened into their strategies.
+func NewChainedMatchStrategy(strategies ...MatchingStrategy) *ChainedMatchStrategy {
+	chain := &ChainedMatchStrategy{Strategies: make([]MatchingStrategy, 0, len(strategies))}
+	for _, strategy := range strategies {
+		if nested, ok := strategy.(*ChainedMatchStrategy); ok {
+			chain.Strategies = append(chain.Strategies, nested.Strategies...)
+		} else if strategy != nil {
+			chain.Strategies = append(chain.Strategies, strategy)
+		}
+	}
+	return chain
+}
+
+// Match reports whether any strategy in the chain matches the pair
+func (s *ChainedMatchStrategy) Match(systemTx domain.Transaction, bankStmt domain.BankStatement) bool {
+	for _, strategy := range s.Strategies {
+		if strategy.Match(systemTx, bankStmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name lists the chained strategy names, e.g. "exact,normalized"
+func (s *ChainedMatchStrategy) Name() string {
+	names := make([]string, len(s.Strategies))
+	for i, strategy := range s.Strategies {
+		names[i] = strategyName(strategy)
+	}
+	return strings.Join(names, ",")
+}
+
+// strategyName returns the name of a NamedStrategy, or "" for other strategies
+func strategyName(strategy MatchingStrategy) string {
+	if named, ok := strategy.(NamedStrategy); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// stages returns one engine per matching pass: a copy of e for each strategy
+// of a chain, or e itself
+func (e *ReconciliationEngine) stages() []*ReconciliationEngine {
+	chain, ok := e.strategy.(*ChainedMatchStrategy)
+	if !ok || len(chain.Strategies) == 0 {
+		return []*ReconciliationEngine{e}
+	}
+	stages := make([]*ReconciliationEngine, len(chain.Strategies))
+	for i, strategy := range chain.Strategies {
+		stage := *e
+		stage.strategy = strategy
+		stage.strategyName = strategyName(strategy)
+		stages[i] = &stage
+	}
+	return stages
+}
+
+// matchLeftovers runs each stage over the system transactions and bank
+// statements the previous passes left unmatched
+func matchLeftovers(stages []*ReconciliationEngine, output *ReconciliationOutput) {
+	for _, stage := range stages {
+		systemTransactions, bankStatements := output.UnmatchedSystem, output.UnmatchedBank
+		if len(systemTransactions) == 0 || len(bankStatements) == 0 {
+			return
+		}
+		output.UnmatchedSystem = make([]domain.Transaction, 0)
+
+		bankStatements = stage.groupStatements(bankStatements)
+		bankMap := stage.buildBankMap(bankStatements)
+		stage.matchBatch(bankMap, systemTransactions, make(map[string]bool, len(systemTransactions)), output)
+
+		var duplicates []domain.BankStatement
+		output.UnmatchedBank, duplicates = stage.unclaimed(bankMap, bankStatements)
+		output.DuplicateBank = append(output.DuplicateBank, duplicates...)
+		output.AmbiguousBank = append(output.AmbiguousBank, stage.ambiguousStatements(bankMap, bankStatements)...)
+	}
+}
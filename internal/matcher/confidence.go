@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// ScoringStrategy is implemented by strategies that rate their own pairs.
+// Score returns how confident the strategy is, from 0 to 1, that a pair it
+// matched is the same payment.
+type ScoringStrategy interface {
+	Score(systemTx domain.Transaction, bankStmt domain.BankStatement) float64
+}
+
+// Weights of the parts of the default confidence score
+const (
+	idWeight     = 0.5
+	amountWeight = 0.3
+	dateWeight   = 0.2
+)
+
+// Score rates every exact ID match 1.0
+func (s *ExactMatchStrategy) Score(systemTx domain.Transaction, bankStmt domain.BankStatement) float64 {
+	return 1
+}
+
+// confidence scores a pair this engine matched, rounded to 3 decimals: the
+// ScoringStrategy's own score, or a weighted blend of ID similarity, amount
+// closeness and date proximity. IDs are compared as given, so a pair that
+// only matched once normalized scores below one matched exactly. Identical
+// IDs, amounts and days score 1.0.
+func (e *ReconciliationEngine) confidence(sysTx domain.Transaction, bankStmt domain.BankStatement) float64 {
+	var score float64
+	if scorer, ok := e.strategy.(ScoringStrategy); ok {
+		score = scorer.Score(sysTx, bankStmt)
+	} else {
+		score = idWeight*IDSimilarity(e.reference(sysTx), bankStmt.TrxRefID) +
+			amountWeight*AmountCloseness(e.signedGap(sysTx, bankStmt), sysTx.Amount, bankStmt.Amount) +
+			dateWeight*DateProximity(dateGap(sysTx.TransactionTime, bankStmt.Date).Hours()/24)
+	}
+	return math.Round(math.Min(math.Max(score, 0), 1)*1000) / 1000
+}
+
+// IDSimilarity is 1 minus the edit distance between a and b over the length
+// of the longer: 1 for equal IDs, 0 when either is empty
+func IDSimilarity(a, b string) float64 {
+	if a == b && a != "" {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	longest := max(len(ra), len(rb))
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance counts the insertions, deletions and substitutions turning a
+// into b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// AmountCloseness is 1 minus the gap between two amounts over the larger
+// magnitude: 1 for equal amounts, 0 when the gap is as large as either
+func AmountCloseness(gap, a, b decimal.Decimal) float64 {
+	if gap.IsZero() {
+		return 1
+	}
+	larger := decimal.Max(a.Abs(), b.Abs())
+	if larger.IsZero() {
+		return 0
+	}
+	closeness, _ := decimal.NewFromInt(1).Sub(gap.Abs().Div(larger)).Float64()
+	return math.Max(closeness, 0)
+}
+
+// DateProximity halves for the first day apart and keeps falling: 1 on the
+// same day, 0.5 a day apart, 0.25 three days apart
+func DateProximity(days float64) float64 {
+	return 1 / (1 + math.Abs(days))
+}
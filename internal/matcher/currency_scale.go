@@ -0,0 +1,52 @@
+package matcher
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// CurrencyScales are the decimal places amounts are rounded to before a pair
+// is compared, so precision below a currency's minor unit (e.g. 0.004 JPY)
+// is not reported as a discrepancy. ByCurrency is keyed by ISO 4217 code;
+// other currencies, and pairs without one, use Default.
+type CurrencyScales struct {
+	Default    int32
+	ByCurrency map[string]int32
+}
+
+// WithCurrencyScales rounds both amounts of a pair to the scale of its
+// currency before computing the discrepancy
+func WithCurrencyScales(scales CurrencyScales) EngineOption {
+	return func(e *ReconciliationEngine) {
+		byCurrency := make(map[string]int32, len(scales.ByCurrency))
+		for currency, scale := range scales.ByCurrency {
+			byCurrency[strings.ToUpper(currency)] = scale
+		}
+		scales.ByCurrency = byCurrency
+		e.currencyScales = &scales
+	}
+}
+
+func (s CurrencyScales) scale(currency string) int32 {
+	if scale, ok := s.ByCurrency[strings.ToUpper(currency)]; ok {
+		return scale
+	}
+	return s.Default
+}
+
+// roundPair rounds a pair's amounts to the scale of its currency, taken from
+// the system transaction or, when it has none, the bank statement
+func (e *ReconciliationEngine) roundPair(sysTx domain.Transaction, bankStmt domain.BankStatement, system, bank decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	if e.currencyScales == nil {
+		return system, bank
+	}
+	currency := sysTx.Currency
+	if currency == "" {
+		currency = bankStmt.Currency
+	}
+	scale := e.currencyScales.scale(currency)
+	return system.Round(scale), bank.Round(scale)
+}
@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"recon-engine/internal/domain"
+)
+
+// SplitByDirection partitions the input into debit and credit passes. System
+// transactions split on the direction of their Type, with refunds and
+// chargebacks among the debits; bank statements on their Type when set,
+// otherwise on the sign of their amount, with negative amounts treated as
+// debits. An untyped zero amount (including a parsed "-0.00") has no sign, so
+// it follows the system transaction with the same ID or order ID and defaults
+// to credits.
+func SplitByDirection(input ReconciliationInput) (debits, credits ReconciliationInput) {
+	debits = ReconciliationInput{StartDate: input.StartDate, EndDate: input.EndDate}
+	credits = ReconciliationInput{StartDate: input.StartDate, EndDate: input.EndDate}
+
+	debitIDs := make(map[string]bool)
+	for _, tx := range input.SystemTransactions {
+		if tx.Type.Direction() == domain.Debit {
+			debits.SystemTransactions = append(debits.SystemTransactions, tx)
+			debitIDs[tx.TrxID] = true
+			if tx.OrderID != "" {
+				debitIDs[tx.OrderID] = true
+			}
+		} else {
+			credits.SystemTransactions = append(credits.SystemTransactions, tx)
+		}
+	}
+
+	for _, stmt := range input.BankStatements {
+		if stmt.Type == domain.Debit ||
+			(stmt.Type == "" && (stmt.Amount.IsNegative() || (stmt.Amount.IsZero() && debitIDs[stmt.TrxRefID]))) {
+			debits.BankStatements = append(debits.BankStatements, stmt)
+		} else {
+			credits.BankStatements = append(credits.BankStatements, stmt)
+		}
+	}
+
+	return debits, credits
+}
+
+// bankDirection returns the statement's explicit type, or derives it from
+// the amount sign
+func bankDirection(stmt domain.BankStatement) domain.TransactionType {
+	if stmt.Type != "" {
+		return stmt.Type
+	}
+	if stmt.Amount.IsNegative() {
+		return domain.Debit
+	}
+	return domain.Credit
+}
+
+// MergeOutputs combines several reconciliation outputs into one
+func MergeOutputs(outputs ...*ReconciliationOutput) *ReconciliationOutput {
+	merged := newReconciliationOutput()
+	for _, output := range outputs {
+		if output != nil {
+			appendOutput(merged, output)
+		}
+	}
+	return merged
+}
+
+func newReconciliationOutput() *ReconciliationOutput {
+	return &ReconciliationOutput{
+		Matched:             make([]MatchedPair, 0),
+		UnmatchedSystem:     make([]domain.Transaction, 0),
+		UnmatchedBank:       make([]domain.BankStatement, 0),
+		Discrepancies:       make([]DiscrepancyPair, 0),
+		DateMismatches:      make([]MatchedPair, 0),
+		CurrencyMismatches:  make([]MatchedPair, 0),
+		DirectionMismatches: make([]MatchedPair, 0),
+		Duplicates:          make([]domain.Transaction, 0),
+		DuplicateBank:       make([]domain.BankStatement, 0),
+		MalformedSystem:     make([]domain.Transaction, 0),
+		MalformedBank:       make([]domain.BankStatement, 0),
+		AmbiguousSystem:     make([]domain.Transaction, 0),
+		AmbiguousBank:       make([]domain.BankStatement, 0),
+	}
+}
+
+// appendOutput appends every list and count of src to dst
+func appendOutput(dst, src *ReconciliationOutput) {
+	dst.Matched = append(dst.Matched, src.Matched...)
+	dst.UnmatchedSystem = append(dst.UnmatchedSystem, src.UnmatchedSystem...)
+	dst.UnmatchedBank = append(dst.UnmatchedBank, src.UnmatchedBank...)
+	dst.Discrepancies = append(dst.Discrepancies, src.Discrepancies...)
+	dst.DateMismatches = append(dst.DateMismatches, src.DateMismatches...)
+	dst.CurrencyMismatches = append(dst.CurrencyMismatches, src.CurrencyMismatches...)
+	dst.DirectionMismatches = append(dst.DirectionMismatches, src.DirectionMismatches...)
+	dst.Duplicates = append(dst.Duplicates, src.Duplicates...)
+	dst.DuplicateBank = append(dst.DuplicateBank, src.DuplicateBank...)
+	dst.MalformedSystem = append(dst.MalformedSystem, src.MalformedSystem...)
+	dst.MalformedBank = append(dst.MalformedBank, src.MalformedBank...)
+	dst.AmbiguousSystem = append(dst.AmbiguousSystem, src.AmbiguousSystem...)
+	dst.AmbiguousBank = append(dst.AmbiguousBank, src.AmbiguousBank...)
+	dst.ExcludedSystem += src.ExcludedSystem
+	dst.ExcludedBank += src.ExcludedBank
+}
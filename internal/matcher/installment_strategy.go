@@ -0,0 +1,164 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// DefaultInstallmentPattern reads TX001-1, TX001-2, ... as installments of TX001
+const DefaultInstallmentPattern = `^(.+)-\d+$`
+
+// StatementGrouper is implemented by strategies that pair a system
+// transaction with several bank statements at once. The engine replaces the
+// statements with the groups before indexing them; a group left unmatched is
+// reported as the statements it was built from.
+type StatementGrouper interface {
+	GroupStatements(statements []domain.BankStatement) []domain.BankStatement
+}
+
+// InstallmentMatchStrategy matches a system transaction the bank paid in
+// installments, each carrying a reference derived from the transaction's,
+// such as TX001-1 and TX001-2 for TX001. Installments of one source sharing a
+// parent reference are summed into one statement dated by the first of them
+// and matched against the parent by ID, so a group that falls short of the
+// system amount is reported as a discrepancy. Statements whose reference does
+// not follow Pattern are matched as they are, by exact ID.
+type InstallmentMatchStrategy struct {
+	ReferenceField
+	// Pattern captures the parent reference in its "parent" group, or in
+	// its first group when it has none by that name
+	Pattern *regexp.Regexp
+	parent  int
+}
+
+// NewInstallmentMatchStrategy reads installment references with pattern,
+// DefaultInstallmentPattern when nil
+func NewInstallmentMatchStrategy(pattern *regexp.Regexp) *InstallmentMatchStrategy {
+	if pattern == nil {
+		pattern = regexp.MustCompile(DefaultInstallmentPattern)
+	}
+	parent := pattern.SubexpIndex("parent")
+	if parent < 0 {
+		parent = 1
+	}
+	return &InstallmentMatchStrategy{Pattern: pattern, parent: parent}
+}
+
+// CompileInstallmentPattern compiles an installment reference pattern,
+// which must capture the parent reference
+func CompileInstallmentPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid installment pattern '%s': %w", pattern, err)
+	}
+	if re.NumSubexp() == 0 {
+		return nil, fmt.Errorf("invalid installment pattern '%s': no group captures the parent reference", pattern)
+	}
+	return re, nil
+}
+
+func (s *InstallmentMatchStrategy) Name() string { return StrategyInstallment }
+
+func (s *InstallmentMatchStrategy) Match(systemTx domain.Transaction, bankStmt domain.BankStatement) bool {
+	return s.SystemReference(systemTx) == bankStmt.TrxRefID
+}
+
+// ParentReference returns the parent reference of an installment, or false
+// when ref does not follow the pattern
+func (s *InstallmentMatchStrategy) ParentReference(ref string) (string, bool) {
+	match := s.Pattern.FindStringSubmatch(ref)
+	if match == nil || s.parent >= len(match) || match[s.parent] == "" {
+		return "", false
+	}
+	return match[s.parent], true
+}
+
+// GroupStatements merges the installments of each source and parent, in
+// place of the first of them, into a statement referencing the parent
+func (s *InstallmentMatchStrategy) GroupStatements(statements []domain.BankStatement) []domain.BankStatement {
+	grouped := make([]domain.BankStatement, 0, len(statements))
+	groups := make(map[string]int)
+	for _, stmt := range statements {
+		parent, ok := s.ParentReference(stmt.TrxRefID)
+		if !ok {
+			grouped = append(grouped, stmt)
+			continue
+		}
+		key := stmt.Source + "\xff" + parent
+		idx, seen := groups[key]
+		if !seen {
+			groups[key] = len(grouped)
+			group := stmt
+			group.TrxRefID = parent
+			group.Installments = []domain.BankStatement{stmt}
+			grouped = append(grouped, group)
+			continue
+		}
+		group := &grouped[idx]
+		addStatement(group, stmt)
+		group.Installments = append(group.Installments, stmt)
+		if stmt.Date.Before(group.Date) {
+			group.Date = stmt.Date
+		}
+	}
+	return grouped
+}
+
+// groupStatements lets a StatementGrouper strategy merge statements before
+// they are indexed
+func (e *ReconciliationEngine) groupStatements(statements []domain.BankStatement) []domain.BankStatement {
+	if grouper, ok := e.strategy.(StatementGrouper); ok {
+		return grouper.GroupStatements(statements)
+	}
+	return statements
+}
+
+// ungrouped returns the statements a group was built from, or stmt itself
+func ungrouped(stmt domain.BankStatement) []domain.BankStatement {
+	if len(stmt.Installments) > 0 {
+		return stmt.Installments
+	}
+	return []domain.BankStatement{stmt}
+}
+
+// InstallmentMatches lists the system transactions paired with installment
+// groups, with the installments paying each
+func InstallmentMatches(output *ReconciliationOutput) []domain.InstallmentMatch {
+	matches := make([]domain.InstallmentMatch, 0)
+	add := func(status domain.MatchStatus, sysTx domain.Transaction, bankStmt domain.BankStatement) {
+		if len(bankStmt.Installments) == 0 {
+			return
+		}
+		expected, paid := sysTx.Amount.Abs(), bankStmt.Amount.Abs()
+		matches = append(matches, domain.InstallmentMatch{
+			TrxID:        sysTx.TrxID,
+			BankSource:   bankStmt.Source,
+			MatchStatus:  status,
+			SystemAmount: sysTx.Amount,
+			PaidAmount:   bankStmt.Amount,
+			Outstanding:  decimal.Max(expected.Sub(paid), decimal.Zero),
+			Partial:      paid.LessThan(expected),
+			Installments: bankStmt.Installments,
+		})
+	}
+	for _, pair := range output.Matched {
+		add(domain.Matched, pair.SystemTx, pair.BankStmt)
+	}
+	for _, pair := range output.Discrepancies {
+		add(domain.Discrepancy, pair.SystemTx, pair.BankStmt)
+	}
+	for _, pair := range output.DateMismatches {
+		add(domain.DateMismatch, pair.SystemTx, pair.BankStmt)
+	}
+	for _, pair := range output.CurrencyMismatches {
+		add(domain.CurrencyMismatch, pair.SystemTx, pair.BankStmt)
+	}
+	for _, pair := range output.DirectionMismatches {
+		add(domain.DirectionMismatch, pair.SystemTx, pair.BankStmt)
+	}
+	return matches
+}
@@ -0,0 +1,62 @@
+package matcher
+
+import (
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/metrics"
+)
+
+// recordMetrics observes a pass's matching time and counts its results by
+// status and bank source
+func recordMetrics(output *ReconciliationOutput, elapsed time.Duration) {
+	metrics.MatchDuration.WithLabelValues().Observe(elapsed.Seconds())
+
+	counts := make(map[[2]string]int)
+	add := func(status domain.MatchStatus, source string) {
+		counts[[2]string{string(status), source}]++
+	}
+	for _, p := range output.Matched {
+		add(domain.Matched, p.BankStmt.Source)
+	}
+	for _, p := range output.Discrepancies {
+		add(domain.Discrepancy, p.BankStmt.Source)
+	}
+	for _, p := range output.DateMismatches {
+		add(domain.DateMismatch, p.BankStmt.Source)
+	}
+	for _, p := range output.CurrencyMismatches {
+		add(domain.CurrencyMismatch, p.BankStmt.Source)
+	}
+	for _, p := range output.DirectionMismatches {
+		add(domain.DirectionMismatch, p.BankStmt.Source)
+	}
+	for range output.UnmatchedSystem {
+		add(domain.UnmatchedSystem, "")
+	}
+	for _, stmt := range output.UnmatchedBank {
+		add(domain.UnmatchedBank, stmt.Source)
+	}
+	for range output.Duplicates {
+		add(domain.DuplicateSystem, "")
+	}
+	for _, stmt := range output.DuplicateBank {
+		add(domain.DuplicateBank, stmt.Source)
+	}
+	for range output.MalformedSystem {
+		add(domain.MalformedReference, "")
+	}
+	for _, stmt := range output.MalformedBank {
+		add(domain.MalformedReference, stmt.Source)
+	}
+	for range output.AmbiguousSystem {
+		add(domain.AmbiguousMatch, "")
+	}
+	for _, stmt := range output.AmbiguousBank {
+		add(domain.AmbiguousMatch, stmt.Source)
+	}
+
+	for key, n := range counts {
+		metrics.MatchResults.WithLabelValues(key[0], key[1]).Add(float64(n))
+	}
+}
@@ -0,0 +1,75 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"recon-engine/internal/domain"
+)
+
+// KeyNormalizer is implemented by strategies that compare IDs in a canonical
+// form. The engine keys its bank map and lookups on the normalized ID while
+// keeping the raw values on the matched records.
+type KeyNormalizer interface {
+	NormalizeKey(id string) string
+}
+
+// RegexTransform replaces every match of Pattern with Replacement
+type RegexTransform struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// NormalizedMatchStrategy matches IDs after applying regex transforms,
+// uppercasing and dropping non-alphanumeric characters on both sides
+type NormalizedMatchStrategy struct {
+	ReferenceField
+	Transforms       []RegexTransform
+	Uppercase        bool
+	AlphanumericOnly bool
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// NewNormalizedMatchStrategy creates a strategy that applies the given
+// transforms in order, then uppercases and strips non-alphanumerics
+func NewNormalizedMatchStrategy(transforms ...RegexTransform) *NormalizedMatchStrategy {
+	return &NormalizedMatchStrategy{
+		Transforms:       transforms,
+		Uppercase:        true,
+		AlphanumericOnly: true,
+	}
+}
+
+// StripPatterns compiles patterns into transforms that remove their matches,
+// e.g. `^REF-` to strip a prefix or `-\d+$` to strip a suffix
+func StripPatterns(patterns ...string) ([]RegexTransform, error) {
+	transforms := make([]RegexTransform, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid normalization pattern '%s': %w", pattern, err)
+		}
+		transforms = append(transforms, RegexTransform{Pattern: re})
+	}
+	return transforms, nil
+}
+
+func (s *NormalizedMatchStrategy) Match(systemTx domain.Transaction, bankStmt domain.BankStatement) bool {
+	return s.NormalizeKey(s.SystemReference(systemTx)) == s.NormalizeKey(bankStmt.TrxRefID)
+}
+
+func (s *NormalizedMatchStrategy) NormalizeKey(id string) string {
+	key := strings.TrimSpace(id)
+	for _, t := range s.Transforms {
+		key = t.Pattern.ReplaceAllString(key, t.Replacement)
+	}
+	if s.Uppercase {
+		key = strings.ToUpper(key)
+	}
+	if s.AlphanumericOnly {
+		key = nonAlphanumeric.ReplaceAllString(key, "")
+	}
+	return key
+}
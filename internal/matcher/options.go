@@ -0,0 +1,54 @@
+package matcher
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// EngineOption configures optional behaviour of the ReconciliationEngine
+type EngineOption func(*ReconciliationEngine)
+
+// WithAmountBounds excludes statements and transactions whose absolute amount
+// falls outside [min, max] before matching. A nil bound is not applied.
+func WithAmountBounds(min, max *decimal.Decimal) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.minAmount = min
+		e.maxAmount = max
+	}
+}
+
+// WithDateWindow requires a bank statement to be dated within window of the
+// system transaction for an ID match to count. Pairs outside the window are
+// reported as DATE_MISMATCH. A zero window disables the check.
+func WithDateWindow(window time.Duration) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.dateWindow = window
+	}
+}
+
+// WithResultEnrichment copies the system transaction's type and created_at
+// onto results built by BuildResults
+func WithResultEnrichment(enabled bool) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.enrichResults = enabled
+	}
+}
+
+// WithUnsignedAmounts compares amount magnitudes and directions separately
+// instead of signed amounts. The system direction is the transaction type;
+// the bank direction is the statement type, or its sign when the file has
+// none. Pairs with equal magnitudes but opposite directions are reported as
+// DIRECTION_MISMATCH rather than as a discrepancy of twice the amount.
+func WithUnsignedAmounts(enabled bool) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.unsignedAmounts = enabled
+	}
+}
+
+// WithDuplicatePolicy sets how duplicate bank reference IDs are resolved
+func WithDuplicatePolicy(policy DuplicatePolicy) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.duplicatePolicy = policy
+	}
+}
@@ -0,0 +1,108 @@
+package matcher
+
+import (
+	"runtime"
+	"sync"
+
+	"recon-engine/internal/domain"
+)
+
+// minWorkerChunk is the fewest system transactions handed to one worker;
+// smaller batches are matched on the calling goroutine
+const minWorkerChunk = 5000
+
+// WithWorkers sets how many goroutines match system transactions. n <= 0
+// uses runtime.NumCPU() and 1 matches sequentially. Strategies must be safe
+// for concurrent use when more than one worker runs.
+func WithWorkers(n int) EngineOption {
+	return func(e *ReconciliationEngine) {
+		if n <= 0 {
+			n = runtime.NumCPU()
+		}
+		e.workers = n
+	}
+}
+
+// matchBatch matches system transactions against the bank map and appends
+// the outcome to output in input order, whatever the number of workers.
+//
+// System duplicates are removed first, so each remaining transaction owns a
+// distinct reference key and claims only within its own bucket. Workers
+// therefore never touch the same claimed flags and the shared map needs no
+// locking. Transactions matched on order_id may share a key unless
+// WithUniqueClaims set the contested ones aside, and a CandidateIndexer
+// probes buckets shared by many transactions; both run sequentially.
+func (e *ReconciliationEngine) matchBatch(m *bankMap, batch []domain.Transaction, seen map[string]bool, output *ReconciliationOutput) {
+	unique := make([]domain.Transaction, 0, len(batch))
+	for _, sysTx := range batch {
+		if e.duplicateSystem(seen, sysTx) {
+			output.Duplicates = append(output.Duplicates, sysTx)
+			continue
+		}
+		unique = append(unique, sysTx)
+	}
+	unique = e.contestedClaims(m, unique, output)
+
+	if indexer, ok := e.strategy.(UniqueCandidateIndexer); ok {
+		e.matchUnique(m, indexer, unique, output)
+		return
+	}
+
+	workers := e.workerCount(len(unique))
+	if workers <= 1 {
+		e.matchChunk(m, unique, output)
+		return
+	}
+
+	// Contiguous chunks merged in order keep the output deterministic
+	chunk := (len(unique) + workers - 1) / workers
+	shards := make([]*ReconciliationOutput, 0, workers)
+	var wg sync.WaitGroup
+	for lo := 0; lo < len(unique); lo += chunk {
+		hi := lo + chunk
+		if hi > len(unique) {
+			hi = len(unique)
+		}
+		shard := newReconciliationOutput()
+		shards = append(shards, shard)
+
+		wg.Add(1)
+		go func(part []domain.Transaction) {
+			defer wg.Done()
+			e.matchChunk(m, part, shard)
+		}(unique[lo:hi])
+	}
+	wg.Wait()
+
+	for _, shard := range shards {
+		appendOutput(output, shard)
+	}
+}
+
+// matchChunk claims a bank statement for each transaction and classifies the pair
+func (e *ReconciliationEngine) matchChunk(m *bankMap, transactions []domain.Transaction, output *ReconciliationOutput) {
+	for _, sysTx := range transactions {
+		// Try to find matching bank statement; a hit marks it as matched
+		bankStmt, found := e.claim(m, sysTx)
+		if !found {
+			output.UnmatchedSystem = append(output.UnmatchedSystem, sysTx)
+			continue
+		}
+
+		e.classifyPair(sysTx, bankStmt, output)
+	}
+}
+
+func (e *ReconciliationEngine) workerCount(size int) int {
+	if _, indexed := e.strategy.(CandidateIndexer); indexed {
+		return 1
+	}
+	if e.sharesReferences() && !e.checksClaims() {
+		return 1
+	}
+	workers := e.workers
+	if limit := size / minWorkerChunk; workers > limit {
+		workers = limit
+	}
+	return workers
+}
@@ -2,7 +2,8 @@ package matcher
 
 import (
 	"fmt"
-	"sync"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -17,25 +18,55 @@ type MatchingStrategy interface {
 }
 
 // ExactMatchStrategy matches by exact ID
-type ExactMatchStrategy struct{}
+type ExactMatchStrategy struct {
+	ReferenceField
+}
 
 func (s *ExactMatchStrategy) Match(systemTx domain.Transaction, bankStmt domain.BankStatement) bool {
-	return systemTx.TrxID == bankStmt.TrxRefID
+	return s.SystemReference(systemTx) == bankStmt.TrxRefID
 }
 
 // ReconciliationEngine performs the reconciliation using hash-based matching
 type ReconciliationEngine struct {
-	strategy MatchingStrategy
-	mu       sync.RWMutex
+	strategy   MatchingStrategy
+	minAmount  *decimal.Decimal
+	maxAmount  *decimal.Decimal
+	dateWindow time.Duration
+	// enrichResults copies transaction metadata onto built results
+	enrichResults   bool
+	duplicatePolicy DuplicatePolicy
+	// unsignedAmounts compares magnitudes and directions separately
+	unsignedAmounts bool
+	// workers is the number of goroutines matching system transactions
+	workers int
+	// referenceFormats are checked before matching
+	referenceFormats ReferenceFormats
+	// currencyScales round amounts before comparison; nil compares them as given
+	currencyScales *CurrencyScales
+	// bpsTolerance matches pairs whose amounts differ by a few basis points
+	bpsTolerance BasisPointTolerance
+	// bankConventions normalize the sign of bank amounts by source
+	bankConventions BankAmountConventions
+	// uniqueClaims reports transactions contesting a bank reference as ambiguous
+	uniqueClaims bool
+	// strategyName tags the pairs this engine matches
+	strategyName string
 }
 
-func NewReconciliationEngine(strategy MatchingStrategy) *ReconciliationEngine {
+func NewReconciliationEngine(strategy MatchingStrategy, opts ...EngineOption) *ReconciliationEngine {
 	if strategy == nil {
 		strategy = &ExactMatchStrategy{}
 	}
-	return &ReconciliationEngine{
-		strategy: strategy,
+	e := &ReconciliationEngine{
+		strategy:        strategy,
+		strategyName:    strategyName(strategy),
+		duplicatePolicy: DuplicateFirst,
+		workers:         runtime.NumCPU(),
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	return e
 }
 
 // ReconciliationInput contains all input data for reconciliation
@@ -52,23 +83,50 @@ type ReconciliationOutput struct {
 	UnmatchedSystem []domain.Transaction
 	UnmatchedBank   []domain.BankStatement
 	Discrepancies   []DiscrepancyPair
+	DateMismatches  []MatchedPair // ID matches posted outside the date window
+	// ID matches whose currencies differ
+	CurrencyMismatches []MatchedPair
+	// ID matches whose directions differ; only with unsigned amounts
+	DirectionMismatches []MatchedPair
+	ExcludedSystem      int // System transactions dropped by the amount bounds
+	ExcludedBank        int // Bank statements dropped by the amount bounds
+	// System transactions repeating an earlier TrxID; only the first is matched
+	Duplicates []domain.Transaction
+	// Bank statements repeating a TrxRefID that is matched or already reported
+	DuplicateBank []domain.BankStatement
+	// Items whose reference failed the configured format; they are not matched
+	MalformedSystem []domain.Transaction
+	MalformedBank   []domain.BankStatement
+	// Items a UniqueCandidateIndexer found tied with others; they are not matched
+	AmbiguousSystem []domain.Transaction
+	AmbiguousBank   []domain.BankStatement
 }
 
 // MatchedPair represents a matched transaction
 type MatchedPair struct {
 	SystemTx domain.Transaction
 	BankStmt domain.BankStatement
+	// MatchedVia names the strategy that paired them
+	MatchedVia string
+	// Confidence rates the pairing from 0 to 1; see ScoringStrategy
+	Confidence float64
 }
 
 // DiscrepancyPair represents a transaction with amount discrepancy
 type DiscrepancyPair struct {
 	SystemTx    domain.Transaction
 	BankStmt    domain.BankStatement
-	Discrepancy decimal.Decimal
+	Discrepancy decimal.Decimal // Absolute difference
+	// SignedDiscrepancy is system minus bank: positive when the bank
+	// reported less than the system, negative when it reported more
+	SignedDiscrepancy decimal.Decimal
+	MatchedVia        string
+	Confidence        float64
 }
 
 // Reconcile performs the two-phase reconciliation process
 func (e *ReconciliationEngine) Reconcile(input ReconciliationInput) (*ReconciliationOutput, error) {
+	started := time.Now()
 	logger.GetLogger().WithFields(map[string]interface{}{
 		"system_count": len(input.SystemTransactions),
 		"bank_count":   len(input.BankStatements),
@@ -76,95 +134,252 @@ func (e *ReconciliationEngine) Reconcile(input ReconciliationInput) (*Reconcilia
 		"end_date":     input.EndDate,
 	}).Info("Starting reconciliation")
 
-	// Phase 1: Build hash maps for O(1) lookup
-	bankMap := e.buildBankMap(input.BankStatements)
-
-	output := &ReconciliationOutput{
-		Matched:         make([]MatchedPair, 0),
-		UnmatchedSystem: make([]domain.Transaction, 0),
-		UnmatchedBank:   make([]domain.BankStatement, 0),
-		Discrepancies:   make([]DiscrepancyPair, 0),
+	output := newReconciliationOutput()
+
+	// Drop out-of-scope items before matching
+	systemTransactions, excludedSystem := e.filterTransactionsByAmount(input.SystemTransactions)
+	bankStatements, excludedBank := e.filterStatementsByAmount(input.BankStatements)
+	output.ExcludedSystem = excludedSystem
+	output.ExcludedBank = excludedBank
+	systemTransactions, output.MalformedSystem = e.filterMalformedTransactions(systemTransactions)
+	bankStatements, output.MalformedBank = e.filterMalformedStatements(bankStatements)
+	bankStatements = e.NormalizeBankAmounts(bankStatements)
+	bankStatements, err := e.resolveDuplicates(bankStatements)
+	if err != nil {
+		return nil, err
 	}
 
+	// Phase 1: Build hash maps for O(1) lookup
+	stages := e.stages()
+	bankStatements = stages[0].groupStatements(bankStatements)
+	bankMap := stages[0].buildBankMap(bankStatements)
+
 	// Phase 2: Match and categorize
-	matchedBankIDs := make(map[string]bool)
+	seen := make(map[string]bool, len(systemTransactions))
+	stages[0].matchBatch(bankMap, systemTransactions, seen, output)
 
-	// Iterate through system transactions
-	for _, sysTx := range input.SystemTransactions {
-		// Try to find matching bank statement
-		bankStmt, found := bankMap[sysTx.TrxID]
+	// Find unmatched and duplicate bank statements
+	output.UnmatchedBank, output.DuplicateBank = stages[0].unclaimed(bankMap, bankStatements)
+	output.AmbiguousBank = stages[0].ambiguousStatements(bankMap, bankStatements)
 
-		if !found {
-			// Unmatched in system
-			output.UnmatchedSystem = append(output.UnmatchedSystem, sysTx)
-			continue
-		}
+	// Later strategies of a chain only see what earlier ones left
+	matchLeftovers(stages[1:], output)
+	recordMetrics(output, time.Since(started))
 
-		// Mark as matched
-		matchedBankIDs[bankStmt.TrxRefID] = true
-
-		// Check for amount discrepancy
-		systemAmount := e.normalizeAmount(sysTx)
-		discrepancy := systemAmount.Sub(bankStmt.Amount).Abs()
-
-		if !discrepancy.IsZero() {
-			// Amount mismatch
-			output.Discrepancies = append(output.Discrepancies, DiscrepancyPair{
-				SystemTx:    sysTx,
-				BankStmt:    bankStmt,
-				Discrepancy: discrepancy,
-			})
-		} else {
-			// Perfect match
-			output.Matched = append(output.Matched, MatchedPair{
-				SystemTx: sysTx,
-				BankStmt: bankStmt,
-			})
-		}
+	logger.GetLogger().WithFields(map[string]interface{}{
+		"matched":              len(output.Matched),
+		"unmatched_system":     len(output.UnmatchedSystem),
+		"unmatched_bank":       len(output.UnmatchedBank),
+		"discrepancies":        len(output.Discrepancies),
+		"date_mismatches":      len(output.DateMismatches),
+		"currency_mismatches":  len(output.CurrencyMismatches),
+		"direction_mismatches": len(output.DirectionMismatches),
+		"duplicate_system":     len(output.Duplicates),
+		"duplicate_bank":       len(output.DuplicateBank),
+		"malformed_system":     len(output.MalformedSystem),
+		"malformed_bank":       len(output.MalformedBank),
+		"ambiguous_system":     len(output.AmbiguousSystem),
+		"ambiguous_bank":       len(output.AmbiguousBank),
+		"excluded_system":      output.ExcludedSystem,
+		"excluded_bank":        output.ExcludedBank,
+	}).Info("Reconciliation completed")
+
+	return output, nil
+}
+
+// classifyPair categorizes a system transaction and the bank statement sharing its ID
+func (e *ReconciliationEngine) classifyPair(sysTx domain.Transaction, bankStmt domain.BankStatement, output *ReconciliationOutput) {
+	confidence := e.confidence(sysTx, bankStmt)
+
+	// Amounts in different currencies are not comparable
+	if !sameCurrency(sysTx.Currency, bankStmt.Currency) {
+		output.CurrencyMismatches = append(output.CurrencyMismatches, MatchedPair{
+			SystemTx:   sysTx,
+			BankStmt:   bankStmt,
+			MatchedVia: e.strategyName,
+			Confidence: confidence,
+		})
+		return
 	}
 
-	// Find unmatched bank statements
-	for _, bankStmt := range input.BankStatements {
-		if !matchedBankIDs[bankStmt.TrxRefID] {
-			output.UnmatchedBank = append(output.UnmatchedBank, bankStmt)
-		}
+	// IDs agree but the bank posted outside the allowed window
+	if e.dateWindow > 0 && dateGap(sysTx.TransactionTime, bankStmt.Date) > e.dateWindow {
+		output.DateMismatches = append(output.DateMismatches, MatchedPair{
+			SystemTx:   sysTx,
+			BankStmt:   bankStmt,
+			MatchedVia: e.strategyName,
+			Confidence: confidence,
+		})
+		return
 	}
 
-	logger.GetLogger().WithFields(map[string]interface{}{
-		"matched":          len(output.Matched),
-		"unmatched_system": len(output.UnmatchedSystem),
-		"unmatched_bank":   len(output.UnmatchedBank),
-		"discrepancies":    len(output.Discrepancies),
-	}).Info("Reconciliation completed")
+	// With unsigned amounts a reversed posting is its own problem, not an
+	// amount discrepancy
+	if e.unsignedAmounts && !e.unsignedStatement(bankStmt) && sysTx.Type.Direction() != bankDirection(bankStmt) {
+		output.DirectionMismatches = append(output.DirectionMismatches, MatchedPair{
+			SystemTx:   sysTx,
+			BankStmt:   bankStmt,
+			MatchedVia: e.strategyName,
+			Confidence: confidence,
+		})
+		return
+	}
 
-	return output, nil
+	// Check for amount discrepancy. decimal has no negative zero, so a
+	// "-0.00" on either side compares equal to zero.
+	signed := e.signedGap(sysTx, bankStmt)
+	discrepancy := signed.Abs()
+
+	if !discrepancy.IsZero() && !e.withinBpsTolerance(discrepancy, sysTx, bankStmt) {
+		// Amount mismatch
+		output.Discrepancies = append(output.Discrepancies, DiscrepancyPair{
+			SystemTx:          sysTx,
+			BankStmt:          bankStmt,
+			Discrepancy:       discrepancy,
+			SignedDiscrepancy: signed,
+			MatchedVia:        e.strategyName,
+			Confidence:        confidence,
+		})
+		return
+	}
+
+	// Perfect match, or within the basis point tolerance
+	output.Matched = append(output.Matched, MatchedPair{
+		SystemTx:   sysTx,
+		BankStmt:   bankStmt,
+		MatchedVia: e.strategyName,
+		Confidence: confidence,
+	})
 }
 
-// buildSystemMap creates a hash map indexed by transaction ID
+// sameCurrency treats an unknown (empty) currency as compatible with any other
+func sameCurrency(a, b string) bool {
+	return a == "" || b == "" || strings.EqualFold(a, b)
+}
+
+// dateGap returns the absolute distance between the calendar days of a and b
+func dateGap(a, b time.Time) time.Duration {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	gap := dayA.Sub(dayB)
+	if gap < 0 {
+		return -gap
+	}
+	return gap
+}
+
+// buildSystemMap creates a hash map indexed by transaction ID, keeping the
+// first transaction when an ID repeats
 func (e *ReconciliationEngine) buildSystemMap(transactions []domain.Transaction) map[string]domain.Transaction {
 	systemMap := make(map[string]domain.Transaction, len(transactions))
 	for _, tx := range transactions {
-		systemMap[tx.TrxID] = tx
+		if _, exists := systemMap[tx.TrxID]; !exists {
+			systemMap[tx.TrxID] = tx
+		}
 	}
 	return systemMap
 }
 
-// buildBankMap creates a hash map indexed by reference ID
-func (e *ReconciliationEngine) buildBankMap(statements []domain.BankStatement) map[string]domain.BankStatement {
-	bankMap := make(map[string]domain.BankStatement, len(statements))
+// duplicateSystem reports whether a transaction with the same (normalized)
+// ID has already been seen, recording tx otherwise. Transactions without an
+// ID are never duplicates.
+func (e *ReconciliationEngine) duplicateSystem(seen map[string]bool, tx domain.Transaction) bool {
+	if tx.TrxID == "" {
+		return false
+	}
+	key := e.key(tx.TrxID)
+	if seen[key] {
+		return true
+	}
+	seen[key] = true
+	return false
+}
+
+// key returns the lookup key for an ID under the configured strategy
+func (e *ReconciliationEngine) key(id string) string {
+	if normalizer, ok := e.strategy.(KeyNormalizer); ok {
+		return normalizer.NormalizeKey(id)
+	}
+	return id
+}
+
+// bankKey returns the bucket a bank statement is indexed under
+func (e *ReconciliationEngine) bankKey(stmt domain.BankStatement) string {
+	if indexer, ok := e.strategy.(CandidateIndexer); ok {
+		return indexer.BankKey(stmt)
+	}
+	return e.key(stmt.TrxRefID)
+}
+
+// withinAmountBounds reports whether the absolute amount lies inside the configured bounds
+func (e *ReconciliationEngine) withinAmountBounds(amount decimal.Decimal) bool {
+	magnitude := amount.Abs()
+	if e.minAmount != nil && magnitude.LessThan(*e.minAmount) {
+		return false
+	}
+	if e.maxAmount != nil && magnitude.GreaterThan(*e.maxAmount) {
+		return false
+	}
+	return true
+}
+
+// filterTransactionsByAmount drops system transactions outside the amount bounds
+func (e *ReconciliationEngine) filterTransactionsByAmount(transactions []domain.Transaction) ([]domain.Transaction, int) {
+	if e.minAmount == nil && e.maxAmount == nil {
+		return transactions, 0
+	}
+	filtered := make([]domain.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if e.withinAmountBounds(tx.Amount) {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered, len(transactions) - len(filtered)
+}
+
+// filterStatementsByAmount drops bank statements outside the amount bounds
+func (e *ReconciliationEngine) filterStatementsByAmount(statements []domain.BankStatement) ([]domain.BankStatement, int) {
+	if e.minAmount == nil && e.maxAmount == nil {
+		return statements, 0
+	}
+	filtered := make([]domain.BankStatement, 0, len(statements))
 	for _, stmt := range statements {
-		// If duplicate, keep the first one (or implement your own logic)
-		if _, exists := bankMap[stmt.TrxRefID]; !exists {
-			bankMap[stmt.TrxRefID] = stmt
+		if e.withinAmountBounds(stmt.Amount) {
+			filtered = append(filtered, stmt)
 		}
 	}
-	return bankMap
+	return filtered, len(statements) - len(filtered)
 }
 
 // normalizeAmount converts transaction amount based on type
-// DEBIT should be negative, CREDIT should be positive
+// DEBIT, REFUND and CHARGEBACK should be negative, CREDIT should be positive
 func (e *ReconciliationEngine) normalizeAmount(tx domain.Transaction) decimal.Decimal {
-	if tx.Type == domain.Debit {
+	return signedAmount(tx)
+}
+
+// amountGap is the absolute difference between a pair's amounts, rounded to
+// the currency scale when configured: of the signed amounts by default, of
+// the magnitudes with unsigned amounts
+func (e *ReconciliationEngine) amountGap(sysTx domain.Transaction, bankStmt domain.BankStatement) decimal.Decimal {
+	return e.signedGap(sysTx, bankStmt).Abs()
+}
+
+// signedGap is the system amount minus the bank amount, compared as amountGap does
+func (e *ReconciliationEngine) signedGap(sysTx domain.Transaction, bankStmt domain.BankStatement) decimal.Decimal {
+	if e.unsignedAmounts {
+		system, bank := e.roundPair(sysTx, bankStmt, sysTx.Amount.Abs(), bankStmt.Amount.Abs())
+		return system.Sub(bank)
+	}
+	system, bank := e.roundPair(sysTx, bankStmt, e.normalizeAmount(sysTx), e.bankAmount(sysTx, bankStmt))
+	return system.Sub(bank)
+}
+
+func signedAmount(tx domain.Transaction) decimal.Decimal {
+	switch {
+	case tx.Type.IsReversal():
+		// Reversals may be stored signed or unsigned; either way money goes out
+		return tx.Amount.Abs().Neg()
+	case tx.Type == domain.Debit:
 		return tx.Amount.Neg()
 	}
 	return tx.Amount
@@ -186,21 +401,94 @@ func (e *ReconciliationEngine) BuildResults(jobID string, output *Reconciliation
 			MatchStatus:     domain.Matched,
 			BankSource:      &matched.BankStmt.Source,
 			TransactionDate: &matched.SystemTx.TransactionTime,
+			Currency:        ptrString(matched.SystemTx.Currency),
+			Description:     ptrString(matched.SystemTx.Description),
+			BankCurrency:    ptrString(matched.BankStmt.Currency),
+			BankDescription: ptrString(matched.BankStmt.Description),
+			MatchedVia:      ptrString(matched.MatchedVia),
+			Confidence:      ptrFloat(matched.Confidence),
 		})
 	}
 
 	// Discrepancies
 	for _, disc := range output.Discrepancies {
+		results = append(results, domain.ReconciliationResult{
+			JobID:             jobID,
+			TrxID:             &disc.SystemTx.TrxID,
+			TrxRefID:          &disc.BankStmt.TrxRefID,
+			SystemAmount:      &disc.SystemTx.Amount,
+			BankAmount:        &disc.BankStmt.Amount,
+			Discrepancy:       &disc.Discrepancy,
+			SignedDiscrepancy: &disc.SignedDiscrepancy,
+			MatchStatus:       domain.Discrepancy,
+			BankSource:        &disc.BankStmt.Source,
+			TransactionDate:   &disc.SystemTx.TransactionTime,
+			Currency:          ptrString(disc.SystemTx.Currency),
+			Description:       ptrString(disc.SystemTx.Description),
+			BankCurrency:      ptrString(disc.BankStmt.Currency),
+			BankDescription:   ptrString(disc.BankStmt.Description),
+			MatchedVia:        ptrString(disc.MatchedVia),
+			Confidence:        ptrFloat(disc.Confidence),
+		})
+	}
+
+	// Date mismatches
+	for _, dm := range output.DateMismatches {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxID:           &dm.SystemTx.TrxID,
+			TrxRefID:        &dm.BankStmt.TrxRefID,
+			SystemAmount:    &dm.SystemTx.Amount,
+			BankAmount:      &dm.BankStmt.Amount,
+			MatchStatus:     domain.DateMismatch,
+			BankSource:      &dm.BankStmt.Source,
+			TransactionDate: &dm.SystemTx.TransactionTime,
+			Currency:        ptrString(dm.SystemTx.Currency),
+			Description:     ptrString(dm.SystemTx.Description),
+			BankCurrency:    ptrString(dm.BankStmt.Currency),
+			BankDescription: ptrString(dm.BankStmt.Description),
+			MatchedVia:      ptrString(dm.MatchedVia),
+			Confidence:      ptrFloat(dm.Confidence),
+		})
+	}
+
+	// Currency mismatches
+	for _, cm := range output.CurrencyMismatches {
 		results = append(results, domain.ReconciliationResult{
 			JobID:           jobID,
-			TrxID:           &disc.SystemTx.TrxID,
-			TrxRefID:        &disc.BankStmt.TrxRefID,
-			SystemAmount:    &disc.SystemTx.Amount,
-			BankAmount:      &disc.BankStmt.Amount,
-			Discrepancy:     &disc.Discrepancy,
-			MatchStatus:     domain.Discrepancy,
-			BankSource:      &disc.BankStmt.Source,
-			TransactionDate: &disc.SystemTx.TransactionTime,
+			TrxID:           &cm.SystemTx.TrxID,
+			TrxRefID:        &cm.BankStmt.TrxRefID,
+			SystemAmount:    &cm.SystemTx.Amount,
+			BankAmount:      &cm.BankStmt.Amount,
+			MatchStatus:     domain.CurrencyMismatch,
+			BankSource:      &cm.BankStmt.Source,
+			TransactionDate: &cm.SystemTx.TransactionTime,
+			Currency:        ptrString(cm.SystemTx.Currency),
+			Description:     ptrString(cm.SystemTx.Description),
+			BankCurrency:    ptrString(cm.BankStmt.Currency),
+			BankDescription: ptrString(cm.BankStmt.Description),
+			MatchedVia:      ptrString(cm.MatchedVia),
+			Confidence:      ptrFloat(cm.Confidence),
+		})
+	}
+
+	// Direction mismatches
+	for _, dm := range output.DirectionMismatches {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxID:           &dm.SystemTx.TrxID,
+			TrxRefID:        &dm.BankStmt.TrxRefID,
+			SystemAmount:    &dm.SystemTx.Amount,
+			BankAmount:      &dm.BankStmt.Amount,
+			MatchStatus:     domain.DirectionMismatch,
+			BankSource:      &dm.BankStmt.Source,
+			TransactionDate: &dm.SystemTx.TransactionTime,
+			Currency:        ptrString(dm.SystemTx.Currency),
+			Description:     ptrString(dm.SystemTx.Description),
+			BankCurrency:    ptrString(dm.BankStmt.Currency),
+			BankDescription: ptrString(dm.BankStmt.Description),
+			MatchedVia:      ptrString(dm.MatchedVia),
+			Confidence:      ptrFloat(dm.Confidence),
 		})
 	}
 
@@ -212,6 +500,8 @@ func (e *ReconciliationEngine) BuildResults(jobID string, output *Reconciliation
 			SystemAmount:    &sys.Amount,
 			MatchStatus:     domain.UnmatchedSystem,
 			TransactionDate: &sys.TransactionTime,
+			Currency:        ptrString(sys.Currency),
+			Description:     ptrString(sys.Description),
 		})
 	}
 
@@ -224,12 +514,141 @@ func (e *ReconciliationEngine) BuildResults(jobID string, output *Reconciliation
 			MatchStatus:     domain.UnmatchedBank,
 			BankSource:      &bank.Source,
 			TransactionDate: &bank.Date,
+			BankCurrency:    ptrString(bank.Currency),
+			BankDescription: ptrString(bank.Description),
+		})
+	}
+
+	// Duplicate system transactions
+	for _, dup := range output.Duplicates {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxID:           &dup.TrxID,
+			SystemAmount:    &dup.Amount,
+			MatchStatus:     domain.DuplicateSystem,
+			TransactionDate: &dup.TransactionTime,
+			Currency:        ptrString(dup.Currency),
+			Description:     ptrString(dup.Description),
 		})
 	}
 
+	// Duplicate bank statements
+	for _, dup := range output.DuplicateBank {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxRefID:        &dup.TrxRefID,
+			BankAmount:      &dup.Amount,
+			MatchStatus:     domain.DuplicateBank,
+			BankSource:      &dup.Source,
+			TransactionDate: &dup.Date,
+			BankCurrency:    ptrString(dup.Currency),
+			BankDescription: ptrString(dup.Description),
+		})
+	}
+
+	// References that failed the configured format
+	for _, tx := range output.MalformedSystem {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxID:           &tx.TrxID,
+			SystemAmount:    &tx.Amount,
+			MatchStatus:     domain.MalformedReference,
+			TransactionDate: &tx.TransactionTime,
+			Currency:        ptrString(tx.Currency),
+			Description:     ptrString(tx.Description),
+		})
+	}
+	for _, stmt := range output.MalformedBank {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxRefID:        &stmt.TrxRefID,
+			BankAmount:      &stmt.Amount,
+			MatchStatus:     domain.MalformedReference,
+			BankSource:      &stmt.Source,
+			TransactionDate: &stmt.Date,
+			BankCurrency:    ptrString(stmt.Currency),
+			BankDescription: ptrString(stmt.Description),
+		})
+	}
+
+	// Items tied between several candidates
+	for _, tx := range output.AmbiguousSystem {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxID:           &tx.TrxID,
+			SystemAmount:    &tx.Amount,
+			MatchStatus:     domain.AmbiguousMatch,
+			TransactionDate: &tx.TransactionTime,
+			Currency:        ptrString(tx.Currency),
+			Description:     ptrString(tx.Description),
+		})
+	}
+	for _, stmt := range output.AmbiguousBank {
+		results = append(results, domain.ReconciliationResult{
+			JobID:           jobID,
+			TrxRefID:        &stmt.TrxRefID,
+			BankAmount:      &stmt.Amount,
+			MatchStatus:     domain.AmbiguousMatch,
+			BankSource:      &stmt.Source,
+			TransactionDate: &stmt.Date,
+			BankCurrency:    ptrString(stmt.Currency),
+			BankDescription: ptrString(stmt.Description),
+		})
+	}
+
+	if e.enrichResults {
+		e.enrichResultsWith(results, output)
+	}
+
 	return results
 }
 
+// enrichResultsWith copies type and created_at from the system transactions
+// that produced each result
+func (e *ReconciliationEngine) enrichResultsWith(results []domain.ReconciliationResult, output *ReconciliationOutput) {
+	systemByID := make(map[string]*domain.Transaction)
+	for i := range output.Matched {
+		systemByID[output.Matched[i].SystemTx.TrxID] = &output.Matched[i].SystemTx
+	}
+	for i := range output.Discrepancies {
+		systemByID[output.Discrepancies[i].SystemTx.TrxID] = &output.Discrepancies[i].SystemTx
+	}
+	for i := range output.DateMismatches {
+		systemByID[output.DateMismatches[i].SystemTx.TrxID] = &output.DateMismatches[i].SystemTx
+	}
+	for i := range output.CurrencyMismatches {
+		systemByID[output.CurrencyMismatches[i].SystemTx.TrxID] = &output.CurrencyMismatches[i].SystemTx
+	}
+	for i := range output.DirectionMismatches {
+		systemByID[output.DirectionMismatches[i].SystemTx.TrxID] = &output.DirectionMismatches[i].SystemTx
+	}
+	for i := range output.UnmatchedSystem {
+		systemByID[output.UnmatchedSystem[i].TrxID] = &output.UnmatchedSystem[i]
+	}
+	for i := range output.MalformedSystem {
+		systemByID[output.MalformedSystem[i].TrxID] = &output.MalformedSystem[i]
+	}
+	for i := range output.AmbiguousSystem {
+		systemByID[output.AmbiguousSystem[i].TrxID] = &output.AmbiguousSystem[i]
+	}
+
+	for i := range results {
+		// Duplicates share their ID with the kept transaction, whose
+		// metadata the lookup would copy
+		if results[i].TrxID == nil || results[i].MatchStatus == domain.DuplicateSystem {
+			continue
+		}
+		tx, ok := systemByID[*results[i].TrxID]
+		if !ok {
+			continue
+		}
+		results[i].TransactionType = &tx.Type
+		if !tx.CreatedAt.IsZero() {
+			results[i].TransactionCreatedAt = &tx.CreatedAt
+		}
+	}
+}
+
 // CalculateDiscrepancyTotal calculates sum of all discrepancies
 func (e *ReconciliationEngine) CalculateDiscrepancyTotal(output *ReconciliationOutput) decimal.Decimal {
 	total := decimal.Zero
@@ -239,19 +658,41 @@ func (e *ReconciliationEngine) CalculateDiscrepancyTotal(output *ReconciliationO
 	return total
 }
 
+// CalculateNetDiscrepancy sums the signed discrepancies, netting out
+// opposing ones: positive when the system is ahead of the bank overall
+func (e *ReconciliationEngine) CalculateNetDiscrepancy(output *ReconciliationOutput) decimal.Decimal {
+	total := decimal.Zero
+	for _, disc := range output.Discrepancies {
+		total = total.Add(disc.SignedDiscrepancy)
+	}
+	return total
+}
+
 func ptrDecimal(d decimal.Decimal) *decimal.Decimal {
 	return &d
 }
 
+func ptrFloat(f float64) *float64 {
+	return &f
+}
+
+// ptrString returns nil for an empty string so it is stored as NULL
+func ptrString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 // StreamingReconciliationEngine performs reconciliation in batches for large datasets
 type StreamingReconciliationEngine struct {
 	*ReconciliationEngine
 	batchSize int
 }
 
-func NewStreamingReconciliationEngine(strategy MatchingStrategy, batchSize int) *StreamingReconciliationEngine {
+func NewStreamingReconciliationEngine(strategy MatchingStrategy, batchSize int, opts ...EngineOption) *StreamingReconciliationEngine {
 	return &StreamingReconciliationEngine{
-		ReconciliationEngine: NewReconciliationEngine(strategy),
+		ReconciliationEngine: NewReconciliationEngine(strategy, opts...),
 		batchSize:            batchSize,
 	}
 }
@@ -261,53 +702,49 @@ func (e *StreamingReconciliationEngine) ReconcileStreaming(
 	systemBatches <-chan []domain.Transaction,
 	bankStatements []domain.BankStatement,
 ) (*ReconciliationOutput, error) {
+	started := time.Now()
 
-	// Build bank map once (assuming bank statements fit in memory)
-	bankMap := e.buildBankMap(bankStatements)
-	matchedBankIDs := make(map[string]bool)
+	output := newReconciliationOutput()
 
-	output := &ReconciliationOutput{
-		Matched:         make([]MatchedPair, 0),
-		UnmatchedSystem: make([]domain.Transaction, 0),
-		UnmatchedBank:   make([]domain.BankStatement, 0),
-		Discrepancies:   make([]DiscrepancyPair, 0),
+	bankStatements, output.ExcludedBank = e.filterStatementsByAmount(bankStatements)
+	bankStatements, output.MalformedBank = e.filterMalformedStatements(bankStatements)
+	bankStatements = e.NormalizeBankAmounts(bankStatements)
+	bankStatements, err := e.resolveDuplicates(bankStatements)
+	if err != nil {
+		return nil, err
 	}
 
-	// Process system transactions in batches
+	// Build bank map once (assuming bank statements fit in memory)
+	stages := e.stages()
+	bankStatements = stages[0].groupStatements(bankStatements)
+	bankMap := stages[0].buildBankMap(bankStatements)
+
+	// Process system transactions in batches; duplicates are tracked across
+	// batches. A strategy deciding ties needs every transaction at once.
+	seen := make(map[string]bool)
+	deferred := stages[0].defersMatching()
+	var pending []domain.Transaction
 	for batch := range systemBatches {
-		for _, sysTx := range batch {
-			bankStmt, found := bankMap[sysTx.TrxID]
-
-			if !found {
-				output.UnmatchedSystem = append(output.UnmatchedSystem, sysTx)
-				continue
-			}
-
-			matchedBankIDs[bankStmt.TrxRefID] = true
-			systemAmount := e.normalizeAmount(sysTx)
-			discrepancy := systemAmount.Sub(bankStmt.Amount).Abs()
-
-			if !discrepancy.IsZero() {
-				output.Discrepancies = append(output.Discrepancies, DiscrepancyPair{
-					SystemTx:    sysTx,
-					BankStmt:    bankStmt,
-					Discrepancy: discrepancy,
-				})
-			} else {
-				output.Matched = append(output.Matched, MatchedPair{
-					SystemTx: sysTx,
-					BankStmt: bankStmt,
-				})
-			}
-		}
-	}
+		batch, excluded := e.filterTransactionsByAmount(batch)
+		output.ExcludedSystem += excluded
+		batch, malformed := e.filterMalformedTransactions(batch)
+		output.MalformedSystem = append(output.MalformedSystem, malformed...)
 
-	// Find unmatched bank statements
-	for _, bankStmt := range bankStatements {
-		if !matchedBankIDs[bankStmt.TrxRefID] {
-			output.UnmatchedBank = append(output.UnmatchedBank, bankStmt)
+		if deferred {
+			pending = append(pending, batch...)
+			continue
 		}
+		stages[0].matchBatch(bankMap, batch, seen, output)
 	}
+	if deferred {
+		stages[0].matchBatch(bankMap, pending, seen, output)
+	}
+
+	// Find unmatched and duplicate bank statements
+	output.UnmatchedBank, output.DuplicateBank = stages[0].unclaimed(bankMap, bankStatements)
+	output.AmbiguousBank = stages[0].ambiguousStatements(bankMap, bankStatements)
+	matchLeftovers(stages[1:], output)
+	recordMetrics(output, time.Since(started))
 
 	return output, nil
 }
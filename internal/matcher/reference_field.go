@@ -0,0 +1,74 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+
+	"recon-engine/internal/domain"
+)
+
+// ReferenceField names the system transaction field the ID strategies match
+// bank references against
+type ReferenceField string
+
+const (
+	// ReferenceTrxID matches on trx_id, the default
+	ReferenceTrxID ReferenceField = "trx_id"
+	// ReferenceOrderID matches on order_id, falling back to trx_id for
+	// transactions without one
+	ReferenceOrderID ReferenceField = "order_id"
+)
+
+// ParseReferenceField validates a field name, defaulting to ReferenceTrxID
+func ParseReferenceField(name string) (ReferenceField, error) {
+	switch field := ReferenceField(strings.ToLower(strings.TrimSpace(name))); field {
+	case "":
+		return ReferenceTrxID, nil
+	case ReferenceTrxID, ReferenceOrderID:
+		return field, nil
+	default:
+		return "", fmt.Errorf("unknown reference field: %s (use %s or %s)", name, ReferenceTrxID, ReferenceOrderID)
+	}
+}
+
+// SystemReferencer is implemented by strategies that match bank references
+// against a configurable transaction field. The ID strategies embed a
+// ReferenceField; its zero value matches on trx_id.
+type SystemReferencer interface {
+	SystemReference(tx domain.Transaction) string
+}
+
+// SystemReference returns the value of the field on tx, or its trx_id when
+// the field is empty
+func (f ReferenceField) SystemReference(tx domain.Transaction) string {
+	if f == ReferenceOrderID && tx.OrderID != "" {
+		return tx.OrderID
+	}
+	return tx.TrxID
+}
+
+// sharesReferences reports whether transactions with distinct trx_ids may
+// look up the same bank reference
+func (f ReferenceField) sharesReferences() bool {
+	return f == ReferenceOrderID
+}
+
+// sharedReferencer is implemented by strategies embedding a ReferenceField
+type sharedReferencer interface {
+	sharesReferences() bool
+}
+
+// sharesReferences reports whether the engine's strategy may look up one
+// bank reference for several transactions
+func (e *ReconciliationEngine) sharesReferences() bool {
+	referencer, ok := e.strategy.(sharedReferencer)
+	return ok && referencer.sharesReferences()
+}
+
+// reference returns the value of sysTx that bank references are looked up by
+func (e *ReconciliationEngine) reference(sysTx domain.Transaction) string {
+	if referencer, ok := e.strategy.(SystemReferencer); ok {
+		return referencer.SystemReference(sysTx)
+	}
+	return sysTx.TrxID
+}
@@ -0,0 +1,74 @@
+package matcher
+
+import (
+	"regexp"
+
+	"recon-engine/internal/domain"
+)
+
+// AnySource keys the bank reference pattern applied to sources without one
+const AnySource = "*"
+
+// ReferenceFormats are the reference formats expected before matching. A nil
+// pattern, or a bank source with no entry and no AnySource fallback, is not
+// checked.
+type ReferenceFormats struct {
+	System *regexp.Regexp
+	// Bank holds patterns by bank source (file name)
+	Bank map[string]*regexp.Regexp
+}
+
+// WithReferenceFormats sets aside transactions and statements whose
+// reference does not match the expected format as MALFORMED_REFERENCE, so
+// format drift is reported as such rather than as unmatched items
+func WithReferenceFormats(formats ReferenceFormats) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.referenceFormats = formats
+	}
+}
+
+func (f ReferenceFormats) bankPattern(source string) *regexp.Regexp {
+	if pattern, ok := f.Bank[source]; ok {
+		return pattern
+	}
+	return f.Bank[AnySource]
+}
+
+// filterMalformedTransactions splits transactions into well-formed and
+// malformed by TrxID
+func (e *ReconciliationEngine) filterMalformedTransactions(transactions []domain.Transaction) ([]domain.Transaction, []domain.Transaction) {
+	pattern := e.referenceFormats.System
+	malformed := make([]domain.Transaction, 0)
+	if pattern == nil {
+		return transactions, malformed
+	}
+
+	valid := make([]domain.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if pattern.MatchString(tx.TrxID) {
+			valid = append(valid, tx)
+		} else {
+			malformed = append(malformed, tx)
+		}
+	}
+	return valid, malformed
+}
+
+// filterMalformedStatements splits statements into well-formed and malformed
+// by TrxRefID, using the pattern of each statement's source
+func (e *ReconciliationEngine) filterMalformedStatements(statements []domain.BankStatement) ([]domain.BankStatement, []domain.BankStatement) {
+	malformed := make([]domain.BankStatement, 0)
+	if len(e.referenceFormats.Bank) == 0 {
+		return statements, malformed
+	}
+
+	valid := make([]domain.BankStatement, 0, len(statements))
+	for _, stmt := range statements {
+		if pattern := e.referenceFormats.bankPattern(stmt.Source); pattern == nil || pattern.MatchString(stmt.TrxRefID) {
+			valid = append(valid, stmt)
+		} else {
+			malformed = append(malformed, stmt)
+		}
+	}
+	return valid, malformed
+}
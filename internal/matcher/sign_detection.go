@@ -0,0 +1,139 @@
+package matcher
+
+import (
+	"maps"
+	"math"
+
+	"recon-engine/internal/domain"
+)
+
+// A convention is only detected from at least minSignPairs ID pairs, and
+// only when it explains minSignAgreement of them and no other does as well
+const (
+	minSignPairs     = 5
+	minSignAgreement = 0.9
+)
+
+// signCandidates are the conventions detection chooses between
+var signCandidates = []BankAmountConvention{BankAmountDebitsNegative, BankAmountDebitsPositive, BankAmountAlwaysPositive}
+
+// signTally counts ID pairs by the direction of the system transaction and
+// whether the bank amount is negative
+type signTally struct {
+	creditPositive, creditNegative int
+	debitPositive, debitNegative   int
+}
+
+func (t *signTally) add(systemDebit, bankNegative bool) {
+	switch {
+	case systemDebit && bankNegative:
+		t.debitNegative++
+	case systemDebit:
+		t.debitPositive++
+	case bankNegative:
+		t.creditNegative++
+	default:
+		t.creditPositive++
+	}
+}
+
+func (t *signTally) pairs() int {
+	return t.creditPositive + t.creditNegative + t.debitPositive + t.debitNegative
+}
+
+// explained counts the pairs whose bank sign convention predicts
+func (t *signTally) explained(convention BankAmountConvention) int {
+	switch convention {
+	case BankAmountDebitsPositive:
+		return t.creditNegative + t.debitPositive
+	case BankAmountAlwaysPositive:
+		return t.creditPositive + t.debitPositive
+	}
+	return t.creditPositive + t.debitNegative
+}
+
+// detect picks the convention explaining the most pairs, falling back to
+// BankAmountDebitsNegative, which changes nothing, when the signal is weak or
+// two conventions explain the pairs equally, e.g. when every pair is a credit
+func (t *signTally) detect() domain.SignConvention {
+	pairs := t.pairs()
+	detection := domain.SignConvention{Convention: string(BankAmountDebitsNegative), Pairs: pairs}
+	if pairs == 0 {
+		return detection
+	}
+
+	best, tied := BankAmountDebitsNegative, false
+	bestCount := t.explained(best)
+	for _, candidate := range signCandidates[1:] {
+		switch count := t.explained(candidate); {
+		case count > bestCount:
+			best, bestCount, tied = candidate, count, false
+		case count == bestCount:
+			tied = true
+		}
+	}
+	if pairs >= minSignPairs && float64(bestCount) >= minSignAgreement*float64(pairs) && !tied {
+		detection.Convention = string(best)
+		detection.Detected = true
+	}
+	explained := t.explained(BankAmountConvention(detection.Convention))
+	detection.Agreement = math.Round(float64(explained)/float64(pairs)*1000) / 1000
+	return detection
+}
+
+// DetectBankAmountConventions resolves the sources configured as
+// BankAmountAuto by pairing their statements with system transactions of
+// the same reference and comparing signs: banks writing debits positive
+// disagree in both directions, and banks writing magnitudes only on debits.
+// It returns an engine normalizing with the detected conventions and the
+// outcome for each auto source in input. An engine without auto sources is
+// returned as it is.
+func (e *ReconciliationEngine) DetectBankAmountConventions(input ReconciliationInput) (*ReconciliationEngine, map[string]domain.SignConvention) {
+	tallies := make(map[string]*signTally)
+	for _, stmt := range input.BankStatements {
+		if _, ok := tallies[stmt.Source]; !ok && e.bankConventions.source(stmt.Source) == BankAmountAuto {
+			tallies[stmt.Source] = &signTally{}
+		}
+	}
+	if len(tallies) == 0 && e.bankConventions.Default != BankAmountAuto {
+		return e, nil
+	}
+
+	systemByKey := make(map[string]domain.Transaction, len(input.SystemTransactions))
+	for _, tx := range input.SystemTransactions {
+		key := e.key(e.reference(tx))
+		if _, seen := systemByKey[key]; !seen {
+			systemByKey[key] = tx
+		}
+	}
+	for _, stmt := range input.BankStatements {
+		tally, ok := tallies[stmt.Source]
+		// Typed statements are signed by the parser already
+		if !ok || stmt.Type != "" || stmt.Amount.IsZero() {
+			continue
+		}
+		tx, ok := systemByKey[e.key(stmt.TrxRefID)]
+		if !ok || tx.Amount.IsZero() {
+			continue
+		}
+		tally.add(signedAmount(tx).IsNegative(), stmt.Amount.IsNegative())
+	}
+
+	resolved := BankAmountConventions{Default: e.bankConventions.Default, BySource: maps.Clone(e.bankConventions.BySource)}
+	if resolved.Default == BankAmountAuto {
+		resolved.Default = BankAmountDebitsNegative
+	}
+	if resolved.BySource == nil {
+		resolved.BySource = make(map[string]BankAmountConvention, len(tallies))
+	}
+	detections := make(map[string]domain.SignConvention, len(tallies))
+	for source, tally := range tallies {
+		detection := tally.detect()
+		detections[source] = detection
+		resolved.BySource[source] = BankAmountConvention(detection.Convention)
+	}
+
+	scoped := *e
+	scoped.bankConventions = resolved
+	return &scoped, detections
+}
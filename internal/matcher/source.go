@@ -0,0 +1,100 @@
+package matcher
+
+import (
+	"sort"
+
+	"recon-engine/internal/domain"
+)
+
+// SplitBySource partitions the bank statements by Source, pairing each
+// group with every system transaction
+func SplitBySource(input ReconciliationInput) map[string]ReconciliationInput {
+	inputs := make(map[string]ReconciliationInput)
+	for _, stmt := range input.BankStatements {
+		sourceInput, ok := inputs[stmt.Source]
+		if !ok {
+			sourceInput = ReconciliationInput{
+				SystemTransactions: input.SystemTransactions,
+				StartDate:          input.StartDate,
+				EndDate:            input.EndDate,
+			}
+		}
+		sourceInput.BankStatements = append(sourceInput.BankStatements, stmt)
+		inputs[stmt.Source] = sourceInput
+	}
+	return inputs
+}
+
+// ReconcileBySource reconciles each bank source on its own against the whole
+// system set, so a system transaction can only match within one bank's
+// statements at a time
+func (e *ReconciliationEngine) ReconcileBySource(input ReconciliationInput) (map[string]*ReconciliationOutput, error) {
+	outputs := make(map[string]*ReconciliationOutput)
+	for source, sourceInput := range SplitBySource(input) {
+		output, err := e.Reconcile(sourceInput)
+		if err != nil {
+			return nil, err
+		}
+		outputs[source] = output
+	}
+	return outputs, nil
+}
+
+// MergeSourceOutputs combines per-source outputs, in source order, into one.
+// Bank-side lists are concatenated. A system transaction is unmatched only
+// when no source matched it, and ambiguous when some source found it tied and
+// none matched it. System-side duplicates, malformed and excluded items,
+// which every pass shares, are taken once.
+func MergeSourceOutputs(outputs map[string]*ReconciliationOutput) *ReconciliationOutput {
+	merged := newReconciliationOutput()
+	sources := make([]string, 0, len(outputs))
+	for source := range outputs {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	unmatchedIn := make(map[string]int)
+	ambiguousIn := make(map[string]bool)
+	for i, source := range sources {
+		output := outputs[source]
+		merged.Matched = append(merged.Matched, output.Matched...)
+		merged.UnmatchedBank = append(merged.UnmatchedBank, output.UnmatchedBank...)
+		merged.Discrepancies = append(merged.Discrepancies, output.Discrepancies...)
+		merged.DateMismatches = append(merged.DateMismatches, output.DateMismatches...)
+		merged.CurrencyMismatches = append(merged.CurrencyMismatches, output.CurrencyMismatches...)
+		merged.DirectionMismatches = append(merged.DirectionMismatches, output.DirectionMismatches...)
+		merged.DuplicateBank = append(merged.DuplicateBank, output.DuplicateBank...)
+		merged.MalformedBank = append(merged.MalformedBank, output.MalformedBank...)
+		merged.AmbiguousBank = append(merged.AmbiguousBank, output.AmbiguousBank...)
+		merged.ExcludedBank += output.ExcludedBank
+		for _, tx := range output.UnmatchedSystem {
+			unmatchedIn[tx.TrxID]++
+		}
+		for _, tx := range output.AmbiguousSystem {
+			unmatchedIn[tx.TrxID]++
+			ambiguousIn[tx.TrxID] = true
+		}
+		if i == 0 {
+			merged.Duplicates = append(merged.Duplicates, output.Duplicates...)
+			merged.MalformedSystem = append(merged.MalformedSystem, output.MalformedSystem...)
+			merged.ExcludedSystem = output.ExcludedSystem
+		}
+	}
+
+	if len(sources) > 0 {
+		first := outputs[sources[0]]
+		for _, leftover := range [][]domain.Transaction{first.UnmatchedSystem, first.AmbiguousSystem} {
+			for _, tx := range leftover {
+				if unmatchedIn[tx.TrxID] != len(sources) {
+					continue
+				}
+				if ambiguousIn[tx.TrxID] {
+					merged.AmbiguousSystem = append(merged.AmbiguousSystem, tx)
+				} else {
+					merged.UnmatchedSystem = append(merged.UnmatchedSystem, tx)
+				}
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,79 @@
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Strategy names accepted by NewStrategy
+const (
+	StrategyExact       = "exact"
+	StrategyTolerance   = "tolerance"
+	StrategyNormalized  = "normalized"
+	StrategyAmountDate  = "amount_date"
+	StrategyInstallment = "installment"
+)
+
+// ErrUnknownStrategy is returned by NewStrategy for an unsupported name
+var ErrUnknownStrategy = errors.New("unknown matching strategy")
+
+// StrategyConfig holds the settings strategies are built from
+type StrategyConfig struct {
+	// AmountTolerance and DateWindow bound a tolerance match; DateWindow
+	// also bounds an amount_date match
+	AmountTolerance decimal.Decimal
+	DateWindow      time.Duration
+	// Transforms are applied to IDs by the normalized strategy
+	Transforms []RegexTransform
+	// InstallmentPattern reads installment references for the installment
+	// strategy; nil uses DefaultInstallmentPattern
+	InstallmentPattern *regexp.Regexp
+	// ReferenceField is the transaction field the exact, normalized and
+	// installment strategies match bank references against; empty is trx_id
+	ReferenceField ReferenceField
+}
+
+// NewStrategy builds the named matching strategy; an empty name is exact.
+// "tolerance_window" is accepted as an alias of "tolerance". A comma-separated
+// list such as "exact,normalized" builds a ChainedMatchStrategy.
+func NewStrategy(name string, cfg StrategyConfig) (MatchingStrategy, error) {
+	if strings.Contains(name, ",") {
+		names := strings.Split(name, ",")
+		strategies := make([]MatchingStrategy, 0, len(names))
+		for _, n := range names {
+			if strings.TrimSpace(n) == "" {
+				return nil, fmt.Errorf("%w: empty name in chain %q", ErrUnknownStrategy, name)
+			}
+			strategy, err := NewStrategy(n, cfg)
+			if err != nil {
+				return nil, err
+			}
+			strategies = append(strategies, strategy)
+		}
+		return NewChainedMatchStrategy(strategies...), nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", StrategyExact:
+		return &ExactMatchStrategy{ReferenceField: cfg.ReferenceField}, nil
+	case StrategyTolerance, "tolerance_window":
+		return NewToleranceWindowStrategy(cfg.AmountTolerance, cfg.DateWindow), nil
+	case StrategyNormalized:
+		strategy := NewNormalizedMatchStrategy(cfg.Transforms...)
+		strategy.ReferenceField = cfg.ReferenceField
+		return strategy, nil
+	case StrategyAmountDate:
+		return NewAmountDateMatchStrategy(cfg.DateWindow), nil
+	case StrategyInstallment:
+		strategy := NewInstallmentMatchStrategy(cfg.InstallmentPattern)
+		strategy.ReferenceField = cfg.ReferenceField
+		return strategy, nil
+	default:
+		return nil, fmt.Errorf("%w: %s (use %s, %s, %s, %s or %s)", ErrUnknownStrategy, name, StrategyExact, StrategyTolerance, StrategyNormalized, StrategyAmountDate, StrategyInstallment)
+	}
+}
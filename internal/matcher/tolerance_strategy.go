@@ -0,0 +1,67 @@
+package matcher
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// CandidateIndexer is implemented by strategies that pair records on
+// something other than the reference ID. The engine buckets bank statements
+// under BankKey and, for each system transaction, probes every bucket returned
+// by SystemKeys. Each candidate is checked with Match, the closest by amount
+// wins, and a bank statement can be claimed only once.
+type CandidateIndexer interface {
+	BankKey(stmt domain.BankStatement) string
+	SystemKeys(tx domain.Transaction) []string
+}
+
+// ToleranceWindowStrategy matches a system transaction to a bank statement
+// when the amounts differ by at most AmountTolerance and the dates are at
+// most DateWindow apart, regardless of reference IDs. Pairs inside the
+// tolerance that are not exact are still reported as discrepancies so the
+// difference stays visible.
+type ToleranceWindowStrategy struct {
+	AmountTolerance decimal.Decimal
+	DateWindow      time.Duration
+}
+
+func NewToleranceWindowStrategy(amountTolerance decimal.Decimal, dateWindow time.Duration) *ToleranceWindowStrategy {
+	return &ToleranceWindowStrategy{
+		AmountTolerance: amountTolerance.Abs(),
+		DateWindow:      dateWindow,
+	}
+}
+
+func (s *ToleranceWindowStrategy) Match(systemTx domain.Transaction, bankStmt domain.BankStatement) bool {
+	if signedAmount(systemTx).Sub(bankStmt.Amount).Abs().GreaterThan(s.AmountTolerance) {
+		return false
+	}
+	return dateGap(systemTx.TransactionTime, bankStmt.Date) <= s.DateWindow
+}
+
+// BankKey buckets statements by calendar day
+func (s *ToleranceWindowStrategy) BankKey(stmt domain.BankStatement) string {
+	return dayKey(stmt.Date)
+}
+
+// SystemKeys returns every day bucket within the date window of tx
+func (s *ToleranceWindowStrategy) SystemKeys(tx domain.Transaction) []string {
+	days := int(s.DateWindow / (24 * time.Hour))
+	if s.DateWindow%(24*time.Hour) != 0 {
+		days++
+	}
+
+	day := time.Date(tx.TransactionTime.Year(), tx.TransactionTime.Month(), tx.TransactionTime.Day(), 0, 0, 0, 0, time.UTC)
+	keys := make([]string, 0, 2*days+1)
+	for offset := -days; offset <= days; offset++ {
+		keys = append(keys, dayKey(day.AddDate(0, 0, offset)))
+	}
+	return keys
+}
+
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,64 @@
+package matcher
+
+import (
+	"recon-engine/internal/domain"
+)
+
+// WithUniqueClaims checks that at most one system transaction looks up each
+// bank reference. Without it, distinct transactions sharing a reference, such
+// as two trx_ids with the same order_id, would all claim the same statement.
+// With it, they are reported as AMBIGUOUS_MATCH along with the statements
+// under that reference and left for review. Transactions without a reference
+// and CandidateIndexer strategies, which claim each statement once, are not
+// checked.
+func WithUniqueClaims(enabled bool) EngineOption {
+	return func(e *ReconciliationEngine) {
+		e.uniqueClaims = enabled
+	}
+}
+
+// checksClaims reports whether contested claims are checked under the
+// engine's strategy
+func (e *ReconciliationEngine) checksClaims() bool {
+	_, indexed := e.strategy.(CandidateIndexer)
+	return e.uniqueClaims && !indexed
+}
+
+// contestedClaims takes the transactions looking up the same bank reference
+// out of matching, flags them and the statements under the reference as
+// ambiguous, and returns the others in order. A reference no statement
+// carries is not contested; its transactions are simply unmatched.
+func (e *ReconciliationEngine) contestedClaims(m *bankMap, transactions []domain.Transaction, output *ReconciliationOutput) []domain.Transaction {
+	if !e.checksClaims() {
+		return transactions
+	}
+
+	claimants := make(map[string]int, len(transactions))
+	for _, sysTx := range transactions {
+		if ref := e.reference(sysTx); ref != "" {
+			claimants[e.key(ref)]++
+		}
+	}
+
+	uncontested := make([]domain.Transaction, 0, len(transactions))
+	flagged := make(map[string]bool)
+	for _, sysTx := range transactions {
+		ref := e.reference(sysTx)
+		key := e.key(ref)
+		if ref == "" || claimants[key] < 2 || len(m.candidates[key]) == 0 {
+			uncontested = append(uncontested, sysTx)
+			continue
+		}
+		output.AmbiguousSystem = append(output.AmbiguousSystem, sysTx)
+		if flagged[key] {
+			continue
+		}
+		flagged[key] = true
+		for idx := range m.candidates[key] {
+			if !m.claimed[key][idx] {
+				m.flagAmbiguous(candidateSlot{key: key, idx: idx})
+			}
+		}
+	}
+	return uncontested
+}
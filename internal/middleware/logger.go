@@ -18,7 +18,7 @@ func Logger() gin.HandlerFunc {
 		endTime := time.Now()
 		latency := endTime.Sub(startTime)
 
-		logger.GetLogger().WithFields(map[string]interface{}{
+		logger.FromContext(c).WithFields(map[string]interface{}{
 			"status":     c.Writer.Status(),
 			"method":     c.Request.Method,
 			"path":       c.Request.URL.Path,
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/response"
+)
+
+// APIKeyHeader identifies a client for per-key rate limiting
+const APIKeyHeader = "X-API-Key"
+
+// RateLimitKey names the client a request is counted against
+type RateLimitKey func(c *gin.Context) string
+
+// ClientIPKey limits each client IP separately
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// APIKeyKey limits each X-API-Key separately, falling back to the client IP
+// for requests without one
+func APIKeyKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return "key:" + key
+	}
+	return ClientIPKey(c)
+}
+
+// RateLimiter is an in-memory token bucket per client. Each bucket holds up
+// to burst tokens and refills at rate tokens per second.
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter allows perMinute requests a minute per client, with bursts
+// of up to burst requests. A burst below 1 is raised to 1.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from key's bucket. When none is left it returns false
+// and how long until the next token.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+	bucket.updated = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets that have refilled, which behave like new ones, so
+// clients that went away do not pile up
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit rejects requests over limiter's rate with 429 and a Retry-After
+// header. A nil limiter lets every request through.
+func RateLimit(limiter *RateLimiter, key RateLimitKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+		allowed, wait := limiter.Allow(key(c))
+		if !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			logger.FromContext(c).WithField("retry_after", retryAfter).Warn("Rate limit exceeded")
+			response.TooManyRequests(c, retryAfter, fmt.Sprintf("retry after %d seconds", retryAfter))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -13,7 +13,7 @@ func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.GetLogger().WithField("error", err).Error("Panic recovered")
+				logger.FromContext(c).WithField("error", err).Error("Panic recovered")
 				response.InternalError(c, "Internal server error", "An unexpected error occurred")
 				c.Abort()
 			}
@@ -29,7 +29,7 @@ func ErrorHandler() gin.HandlerFunc {
 		// Handle any errors that were set during request processing
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
-			logger.GetLogger().WithError(err.Err).Error("Request error")
+			logger.FromContext(c).WithError(err.Err).Error("Request error")
 
 			// Only send error response if not already sent
 			if c.Writer.Status() == http.StatusOK {
@@ -0,0 +1,136 @@
+// Package migrate applies versioned SQL migrations and records each one in
+// schema_migrations so it runs only once
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"recon-engine/pkg/logger"
+)
+
+// lockID is the Postgres advisory lock held while migrating, so instances
+// starting together do not apply the same migration twice
+const lockID = 7315604289
+
+// Migration is one versioned SQL file, named like 001_init_schema.sql
+type Migration struct {
+	Version string
+	Name    string
+	SQL     string
+}
+
+// Load reads the .sql files at the root of fsys in version order. The
+// version is the file name up to the first underscore and must be unique.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	seen := make(map[string]string)
+	for _, name := range names {
+		version, _, ok := strings.Cut(strings.TrimSuffix(name, path.Ext(name)), "_")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("migration %s: name must be VERSION_description.sql", name)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("migration %s: version %s is already used by %s", name, version, other)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(content)})
+	}
+	return migrations, nil
+}
+
+// Up applies the migrations not yet recorded in schema_migrations, each in
+// its own transaction, and returns the names of those applied
+func Up(db *sql.DB, migrations []Migration) ([]string, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Advisory locks belong to the session, so lock and unlock on one connection
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version VARCHAR(32) PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := apply(ctx, conn, m); err != nil {
+			return ran, fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+		logger.GetLogger().WithField("migration", m.Name).Info("Applied migration")
+		ran = append(ran, m.Name)
+	}
+	return ran, nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func apply(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Without arguments the file runs as one simple query, so it may hold
+	// several statements
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceRows identifies opening and closing balance lines in bank
+// statements. A row is a balance line when the value of Column matches
+// Opening or Closing; Column may be a marker column such as "description" or
+// the amount column itself. Balance lines are excluded from matching.
+type BalanceRows struct {
+	Column  string
+	Opening *regexp.Regexp
+	Closing *regexp.Regexp
+	// Validate checks that opening + transactions = closing for each file
+	// that has balance lines, failing the file otherwise
+	Validate bool
+}
+
+// WithBalanceRows excludes opening and closing balance lines from bank
+// statements, optionally validating the carried balance
+func WithBalanceRows(rows BalanceRows) ParserOption {
+	return func(o *parserOptions) {
+		if rows.Column != "" && (rows.Opening != nil || rows.Closing != nil) {
+			o.balanceRows = &rows
+		}
+	}
+}
+
+// balanceCheck tracks the balance lines and transaction total of one file
+type balanceCheck struct {
+	rows             *BalanceRows
+	opening, closing *decimal.Decimal
+	openingLine      int
+	closingLine      int
+	total            decimal.Decimal
+	decimalComma     bool
+}
+
+func (o parserOptions) newBalanceCheck() *balanceCheck {
+	return &balanceCheck{rows: o.balanceRows, total: decimal.Zero, decimalComma: o.decimalComma}
+}
+
+// balanceRow reports whether record is a balance line, recording its amount
+func (b *balanceCheck) balanceRow(record []string, columnMap map[string]int, lineNumber int) (bool, error) {
+	if b.rows == nil {
+		return false, nil
+	}
+	idx, ok := columnMap[strings.ToLower(strings.TrimSpace(b.rows.Column))]
+	if !ok || idx >= len(record) {
+		return false, nil
+	}
+
+	value := strings.TrimSpace(record[idx])
+	opening := b.rows.Opening != nil && b.rows.Opening.MatchString(value)
+	if !opening && (b.rows.Closing == nil || !b.rows.Closing.MatchString(value)) {
+		return false, nil
+	}
+
+	amount, err := balanceAmount(record, columnMap, b.decimalComma)
+	if err != nil && b.rows.Validate {
+		return true, fmt.Errorf("invalid balance amount at line %d: %w", lineNumber, err)
+	}
+	if opening {
+		b.opening, b.openingLine = amount, lineNumber
+	} else {
+		b.closing, b.closingLine = amount, lineNumber
+	}
+	return true, nil
+}
+
+// balanceAmount reads a balance line's amount, ignoring any text around the
+// number such as "Opening balance: 1,000.00"
+func balanceAmount(record []string, columnMap map[string]int, decimalComma bool) (*decimal.Decimal, error) {
+	idx, ok := columnMap["amount"]
+	if !ok || idx >= len(record) {
+		return nil, fmt.Errorf("no amount column")
+	}
+	raw := strings.TrimSpace(record[idx])
+	if decimalComma {
+		raw = fromDecimalComma(raw)
+	} else {
+		raw = strings.ReplaceAll(raw, ",", "")
+	}
+	number := balanceNumber.FindString(raw)
+	if number == "" {
+		return nil, fmt.Errorf("no number in '%s'", record[idx])
+	}
+	amount, err := decimal.NewFromString(number)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
+
+var balanceNumber = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+func (b *balanceCheck) add(amount decimal.Decimal) {
+	if b.rows != nil {
+		b.total = b.total.Add(amount)
+	}
+}
+
+// validate checks opening + transactions = closing when enabled and the
+// file carries balance lines
+func (b *balanceCheck) validate() error {
+	if b.rows == nil || !b.rows.Validate || (b.opening == nil && b.closing == nil) {
+		return nil
+	}
+	if b.opening == nil {
+		return fmt.Errorf("balance check failed: closing balance at line %d has no opening balance", b.closingLine)
+	}
+	if b.closing == nil {
+		return fmt.Errorf("balance check failed: opening balance at line %d has no closing balance", b.openingLine)
+	}
+	if expected := b.opening.Add(b.total); !expected.Equal(*b.closing) {
+		return fmt.Errorf("balance check failed: opening %s + transactions %s = %s, closing balance is %s",
+			b.opening.StringFixed(2), b.total.StringFixed(2), expected.StringFixed(2), b.closing.StringFixed(2))
+	}
+	return nil
+}
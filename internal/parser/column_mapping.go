@@ -0,0 +1,35 @@
+package parser
+
+import "strings"
+
+// ColumnMapping maps a bank's own header names to the canonical column names
+// (trx_ref_id, amount, date, currency). Keys are matched case-insensitively.
+type ColumnMapping map[string]string
+
+// apply returns a copy of columnMap with aliased headers renamed to their
+// canonical name. A canonical header already present in the file wins over
+// an alias.
+func (m ColumnMapping) apply(columnMap map[string]int) map[string]int {
+	if len(m) == 0 {
+		return columnMap
+	}
+
+	resolved := make(map[string]int, len(columnMap))
+	for col, idx := range columnMap {
+		resolved[col] = idx
+	}
+
+	for alias, canonical := range m {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		idx, ok := columnMap[alias]
+		if !ok {
+			continue
+		}
+		canonical = strings.ToLower(strings.TrimSpace(canonical))
+		if _, exists := columnMap[canonical]; !exists {
+			delete(resolved, alias)
+			resolved[canonical] = idx
+		}
+	}
+	return resolved
+}
@@ -4,10 +4,8 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/shopspring/decimal"
 
@@ -22,65 +20,85 @@ type BankStatementParser interface {
 
 // CSVBankStatementParser implements streaming CSV parser
 type CSVBankStatementParser struct {
-	source string // Bank identifier
+	source  string // Bank identifier
+	mapping ColumnMapping
+	opts    parserOptions
 }
 
-func NewCSVBankStatementParser(source string) *CSVBankStatementParser {
-	return &CSVBankStatementParser{source: source}
+func NewCSVBankStatementParser(source string, opts ...ParserOption) *CSVBankStatementParser {
+	return &CSVBankStatementParser{source: source, opts: newParserOptions(opts)}
+}
+
+// NewCSVBankStatementParserWithMapping creates a parser for a source whose
+// header uses its own column names
+func NewCSVBankStatementParserWithMapping(source string, mapping ColumnMapping, opts ...ParserOption) *CSVBankStatementParser {
+	return &CSVBankStatementParser{source: source, mapping: mapping, opts: newParserOptions(opts)}
 }
 
 // Parse reads CSV file in streaming mode and processes in batches
 func (p *CSVBankStatementParser) Parse(filePath string, batchSize int, callback func([]domain.BankStatement) error) error {
-	file, err := os.Open(filePath)
+	file, err := p.opts.openCSV(filePath)
 	if err != nil {
 		logger.GetLogger().WithError(err).WithField("file", filePath).Error("Failed to open file")
 		return fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.LazyQuotes = true
-	reader.TrimLeadingSpace = true
+	reader := p.opts.newBankCSVReader(file)
 
 	// Read header
-	header, err := reader.Read()
+	header, lineNumber, err := p.opts.readHeader(reader.Read, func(row []string) bool {
+		return validateColumns(p.mapping.apply(mapColumns(row)))
+	})
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to read CSV header")
 		return fmt.Errorf("failed to read header: %w", err)
 	}
+	reader.FieldsPerRecord = len(header)
 
 	// Map header columns
-	columnMap := mapColumns(header)
+	columnMap := p.mapping.apply(mapColumns(header))
 	if !validateColumns(columnMap) {
 		return fmt.Errorf("invalid CSV format: missing required columns (trx_ref_id, amount, date)")
 	}
+	p.opts.reportColumns(header, columnMap, bankColumns)
 
 	batch := make([]domain.BankStatement, 0, batchSize)
-	lineNumber := 1
+	balances := p.opts.newBalanceCheck()
 
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
+		lineNumber++
+
+		// Balance lines are often narrower than the header, so they are
+		// recognised before a row width error is handled
+		if isBalance, err := balances.balanceRow(record, columnMap, lineNumber); err != nil {
+			return err
+		} else if isBalance {
+			continue
+		}
+
 		if err != nil {
 			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to read CSV row, skipping")
-			lineNumber++
+			p.opts.reportSkipped(lineNumber, rawRecord(record, p.opts.delimiter), err)
 			continue
 		}
 
-		lineNumber++
-
 		statement, err := p.parseRecord(record, columnMap, lineNumber)
 		if err != nil {
 			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to parse record, skipping")
+			p.opts.reportSkipped(lineNumber, rawRecord(record, p.opts.delimiter), err)
 			continue
 		}
 
+		balances.add(statement.Amount)
 		batch = append(batch, *statement)
 
 		if len(batch) >= batchSize {
-			if err := callback(batch); err != nil {
+			if err := deliver(p.opts, callback, batch); err != nil {
 				return err
 			}
 			batch = make([]domain.BankStatement, 0, batchSize)
@@ -89,12 +107,12 @@ func (p *CSVBankStatementParser) Parse(filePath string, batchSize int, callback
 
 	// Process remaining items
 	if len(batch) > 0 {
-		if err := callback(batch); err != nil {
+		if err := deliver(p.opts, callback, batch); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return balances.validate()
 }
 
 func (p *CSVBankStatementParser) parseRecord(record []string, columnMap map[string]int, lineNumber int) (*domain.BankStatement, error) {
@@ -108,25 +126,43 @@ func (p *CSVBankStatementParser) parseRecord(record []string, columnMap map[stri
 		return nil, fmt.Errorf("empty trx_ref_id at line %d", lineNumber)
 	}
 
-	// Parse amount
-	amountStr := strings.TrimSpace(record[columnMap["amount"]])
-	amount, err := decimal.NewFromString(amountStr)
+	// Parse amount, from separate debit and credit columns when there is no amount column
+	amount, direction, err := p.parseAmountColumns(record, columnMap, lineNumber)
 	if err != nil {
-		return nil, fmt.Errorf("invalid amount '%s' at line %d: %w", amountStr, lineNumber, err)
+		return nil, err
 	}
 
 	// Parse date - try multiple formats
 	dateStr := strings.TrimSpace(record[columnMap["date"]])
-	date, err := parseDate(dateStr)
+	date, err := p.opts.parseDate(dateStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid date '%s' at line %d: %w", dateStr, lineNumber, err)
 	}
 
+	// An explicit direction column makes the amount a magnitude; the stored
+	// amount is still signed so sign-based matching keeps working
+	if idx, ok := columnMap["type"]; ok && idx < len(record) {
+		value := strings.ToUpper(strings.TrimSpace(record[idx]))
+		switch bankDirectionFlags[value] {
+		case "":
+			if value != "" {
+				return nil, fmt.Errorf("invalid type '%s' at line %d", value, lineNumber)
+			}
+		case domain.Debit:
+			direction, amount = domain.Debit, amount.Abs().Neg()
+		case domain.Credit:
+			direction, amount = domain.Credit, amount.Abs()
+		}
+	}
+
 	return &domain.BankStatement{
-		TrxRefID: trxRefID,
-		Amount:   amount,
-		Date:     date,
-		Source:   p.source,
+		TrxRefID:    trxRefID,
+		Amount:      amount,
+		Date:        date,
+		Source:      p.source,
+		Currency:    p.opts.currency(record, columnMap),
+		Type:        direction,
+		Description: description(record, columnMap),
 	}, nil
 }
 
@@ -139,44 +175,101 @@ func mapColumns(header []string) map[string]int {
 	return columnMap
 }
 
-func validateColumns(columnMap map[string]int) bool {
-	requiredColumns := []string{"trx_ref_id", "amount", "date"}
-	for _, col := range requiredColumns {
-		if _, exists := columnMap[col]; !exists {
-			return false
-		}
+// description reads the optional free-text description column
+func description(record []string, columnMap map[string]int) string {
+	if idx, ok := columnMap["description"]; ok && idx < len(record) {
+		return strings.TrimSpace(record[idx])
 	}
-	return true
+	return ""
 }
 
-func parseDate(dateStr string) (time.Time, error) {
-	formats := []string{
-		"2006-01-02",
-		"2006-01-02 15:04:05",
-		"02/01/2006",
-		"01/02/2006",
-		"2006/01/02",
-		time.RFC3339,
+// currency reads the optional currency column, falling back to the default
+func (o parserOptions) currency(record []string, columnMap map[string]int) string {
+	if idx, ok := columnMap["currency"]; ok && idx < len(record) {
+		if currency := strings.ToUpper(strings.TrimSpace(record[idx])); currency != "" {
+			return currency
+		}
 	}
+	return o.defaultCurrency
+}
+
+// bankDirectionFlags maps the type column values banks use to a direction
+var bankDirectionFlags = map[string]domain.TransactionType{
+	"DEBIT": domain.Debit, "D": domain.Debit, "DR": domain.Debit,
+	"CREDIT": domain.Credit, "C": domain.Credit, "CR": domain.Credit,
+}
 
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return t, nil
+// parseAmountColumns reads the amount column, or else the debit and credit
+// columns, where a row fills in one of the two with a magnitude. Amounts from
+// separate columns are signed with debits negative and carry their direction.
+func (p *CSVBankStatementParser) parseAmountColumns(record []string, columnMap map[string]int, lineNumber int) (decimal.Decimal, domain.TransactionType, error) {
+	if idx, ok := columnMap["amount"]; ok {
+		amountStr := strings.TrimSpace(record[idx])
+		amount, err := p.opts.parseAmount(amountStr)
+		if err != nil {
+			return decimal.Zero, "", fmt.Errorf("invalid amount '%s' at line %d: %w", amountStr, lineNumber, err)
 		}
+		return amount, "", nil
 	}
 
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+	debit, err := p.optionalAmount(record, columnMap["debit"], lineNumber)
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+	credit, err := p.optionalAmount(record, columnMap["credit"], lineNumber)
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+	switch {
+	case !debit.IsZero() && !credit.IsZero():
+		return decimal.Zero, "", fmt.Errorf("both debit and credit set at line %d", lineNumber)
+	case !debit.IsZero():
+		return debit.Abs().Neg(), domain.Debit, nil
+	default:
+		return credit.Abs(), domain.Credit, nil
+	}
+}
+
+// optionalAmount parses an amount cell that may be left empty
+func (p *CSVBankStatementParser) optionalAmount(record []string, idx, lineNumber int) (decimal.Decimal, error) {
+	value := strings.TrimSpace(record[idx])
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	amount, err := p.opts.parseAmount(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount '%s' at line %d: %w", value, lineNumber, err)
+	}
+	return amount, nil
+}
+
+// validateColumns checks for the reference and date columns and either an
+// amount column or both a debit and a credit column
+func validateColumns(columnMap map[string]int) bool {
+	for _, col := range []string{"trx_ref_id", "date"} {
+		if _, exists := columnMap[col]; !exists {
+			return false
+		}
+	}
+	if _, exists := columnMap["amount"]; exists {
+		return true
+	}
+	_, debit := columnMap["debit"]
+	_, credit := columnMap["credit"]
+	return debit && credit
 }
 
 // TransactionCSVParser for parsing system transactions from CSV
-type TransactionCSVParser struct{}
+type TransactionCSVParser struct {
+	opts parserOptions
+}
 
-func NewTransactionCSVParser() *TransactionCSVParser {
-	return &TransactionCSVParser{}
+func NewTransactionCSVParser(opts ...ParserOption) *TransactionCSVParser {
+	return &TransactionCSVParser{opts: newParserOptions(opts)}
 }
 
 func (p *TransactionCSVParser) Parse(filePath string, batchSize int, callback func([]domain.Transaction) error) error {
-	file, err := os.Open(filePath)
+	file, err := p.opts.openCSV(filePath)
 	if err != nil {
 		logger.GetLogger().WithError(err).WithField("file", filePath).Error("Failed to open file")
 		return fmt.Errorf("failed to open file: %w", err)
@@ -186,20 +279,26 @@ func (p *TransactionCSVParser) Parse(filePath string, batchSize int, callback fu
 	reader := csv.NewReader(file)
 	reader.LazyQuotes = true
 	reader.TrimLeadingSpace = true
+	// Preamble rows may have any width; the header fixes it below
+	reader.FieldsPerRecord = -1
+	p.opts.setComment(reader)
 
 	// Read header
-	header, err := reader.Read()
+	header, lineNumber, err := p.opts.readHeader(reader.Read, func(row []string) bool {
+		return validateTransactionColumns(mapColumns(row))
+	})
 	if err != nil {
 		return fmt.Errorf("failed to read header: %w", err)
 	}
+	reader.FieldsPerRecord = len(header)
 
 	columnMap := mapColumns(header)
 	if !validateTransactionColumns(columnMap) {
 		return fmt.Errorf("invalid CSV format: missing required columns")
 	}
+	p.opts.reportColumns(header, columnMap, transactionColumns)
 
 	batch := make([]domain.Transaction, 0, batchSize)
-	lineNumber := 1
 
 	for {
 		record, err := reader.Read()
@@ -209,6 +308,7 @@ func (p *TransactionCSVParser) Parse(filePath string, batchSize int, callback fu
 		if err != nil {
 			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to read CSV row, skipping")
 			lineNumber++
+			p.opts.reportSkipped(lineNumber, rawRecord(record, ','), err)
 			continue
 		}
 
@@ -217,13 +317,14 @@ func (p *TransactionCSVParser) Parse(filePath string, batchSize int, callback fu
 		transaction, err := p.parseTransactionRecord(record, columnMap, lineNumber)
 		if err != nil {
 			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to parse record, skipping")
+			p.opts.reportSkipped(lineNumber, rawRecord(record, ','), err)
 			continue
 		}
 
 		batch = append(batch, *transaction)
 
 		if len(batch) >= batchSize {
-			if err := callback(batch); err != nil {
+			if err := deliver(p.opts, callback, batch); err != nil {
 				return err
 			}
 			batch = make([]domain.Transaction, 0, batchSize)
@@ -231,7 +332,7 @@ func (p *TransactionCSVParser) Parse(filePath string, batchSize int, callback fu
 	}
 
 	if len(batch) > 0 {
-		if err := callback(batch); err != nil {
+		if err := deliver(p.opts, callback, batch); err != nil {
 			return err
 		}
 	}
@@ -251,23 +352,30 @@ func (p *TransactionCSVParser) parseTransactionRecord(record []string, columnMap
 		return nil, fmt.Errorf("invalid amount: %w", err)
 	}
 
-	typeStr := strings.ToUpper(strings.TrimSpace(record[columnMap["type"]]))
-	if typeStr != string(domain.Debit) && typeStr != string(domain.Credit) {
-		return nil, fmt.Errorf("invalid transaction type: %s", typeStr)
+	txType := domain.TransactionType(strings.ToUpper(strings.TrimSpace(record[columnMap["type"]])))
+	if !txType.Valid() {
+		return nil, fmt.Errorf("invalid transaction type: %s", txType)
 	}
 
 	timeStr := strings.TrimSpace(record[columnMap["transaction_time"]])
-	transactionTime, err := parseDate(timeStr)
+	transactionTime, err := p.opts.parseDate(timeStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid transaction_time: %w", err)
 	}
 
-	return &domain.Transaction{
+	tx := &domain.Transaction{
 		TrxID:           trxID,
 		Amount:          amount,
-		Type:            domain.TransactionType(typeStr),
+		Type:            txType,
+		Currency:        p.opts.currency(record, columnMap),
 		TransactionTime: transactionTime,
-	}, nil
+	}
+	// order_id is optional, an external reference matched in place of trx_id
+	if idx, ok := columnMap["order_id"]; ok && idx < len(record) {
+		tx.OrderID = strings.TrimSpace(record[idx])
+	}
+	tx.Description = description(record, columnMap)
+	return tx, nil
 }
 
 func validateTransactionColumns(columnMap map[string]int) bool {
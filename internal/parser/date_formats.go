@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultDateFormats are the layouts every parser accepts, tried in order.
+// Of the two slash layouts, the day-first one is tried first unless the
+// parser is configured month-first.
+var defaultDateFormats = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"02/01/2006",
+	"01/02/2006",
+	"2006/01/02",
+	time.RFC3339,
+}
+
+// WithDateFormats accepts extra Go time layouts, such as "Jan 2, 2006",
+// tried after the built-in ones
+func WithDateFormats(formats ...string) ParserOption {
+	return func(o *parserOptions) {
+		for _, format := range formats {
+			if format = strings.TrimSpace(format); format != "" {
+				o.dateFormats = append(o.dateFormats, format)
+			}
+		}
+	}
+}
+
+// WithDayFirst decides how 03/04/2024 is read: as 3 April when enabled, the
+// default, or as March 4 when disabled. A date only valid one way, such as
+// 25/12/2024, is still read that way.
+func WithDayFirst(enabled bool) ParserOption {
+	return func(o *parserOptions) {
+		o.monthFirst = !enabled
+	}
+}
+
+// WithDateLayout reads every date with layout alone, such as "01/02/2006"
+// for a bank writing month first, so no date is read the wrong way round.
+// An empty layout keeps the built-in and WithDateFormats layouts.
+func WithDateLayout(layout string) ParserOption {
+	return func(o *parserOptions) {
+		o.dateLayout = strings.TrimSpace(layout)
+	}
+}
+
+// dateLayouts returns the explicit layout when one is set, and otherwise
+// the built-in layouts in the configured order followed by the custom ones
+func (o parserOptions) dateLayouts() []string {
+	if o.dateLayout != "" {
+		return []string{o.dateLayout}
+	}
+	layouts := make([]string, 0, len(defaultDateFormats)+len(o.dateFormats))
+	layouts = append(layouts, defaultDateFormats...)
+	if o.monthFirst {
+		layouts[2], layouts[3] = layouts[3], layouts[2]
+	}
+	return append(layouts, o.dateFormats...)
+}
+
+// parseDate reads a date in the first layout that fits
+func (o parserOptions) parseDate(dateStr string) (time.Time, error) {
+	for _, layout := range o.layouts {
+		if t, err := time.Parse(layout, dateStr); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// WithDelimiter sets the field separator of bank statement CSVs, such as ';'
+// or '\t'. Zero, quotes and line breaks are ignored and keep the comma.
+func WithDelimiter(delimiter rune) ParserOption {
+	return func(o *parserOptions) {
+		if ValidDelimiter(delimiter) {
+			o.delimiter = delimiter
+		}
+	}
+}
+
+// ValidDelimiter reports whether r can separate CSV fields
+func ValidDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && r != utf8.RuneError
+}
+
+// WithComment skips CSV lines starting with comment, such as '#', in bank
+// and system files. Zero, quotes and line breaks are ignored, as is a comment
+// character equal to the delimiter.
+func WithComment(comment rune) ParserOption {
+	return func(o *parserOptions) {
+		if ValidDelimiter(comment) {
+			o.comment = comment
+		}
+	}
+}
+
+// WithDecimalComma reads bank statement CSV amounts written with a decimal
+// comma and dot thousands separators, as in 1.234,56
+func WithDecimalComma(enabled bool) ParserOption {
+	return func(o *parserOptions) {
+		o.decimalComma = enabled
+	}
+}
+
+// newBankCSVReader returns a lenient CSV reader using the bank delimiter.
+// Preamble rows may have any width; callers fix it once the header is read.
+func (o parserOptions) newBankCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if o.delimiter != 0 {
+		reader.Comma = o.delimiter
+	}
+	o.setComment(reader)
+	return reader
+}
+
+// setComment makes reader skip comment lines unless they would clash with
+// its delimiter
+func (o parserOptions) setComment(reader *csv.Reader) {
+	if o.comment != 0 && o.comment != reader.Comma {
+		reader.Comment = o.comment
+	}
+}
+
+// parseAmount reads a bank statement amount in the configured number format
+func (o parserOptions) parseAmount(value string) (decimal.Decimal, error) {
+	if o.decimalComma {
+		value = fromDecimalComma(value)
+	}
+	return decimal.NewFromString(value)
+}
+
+// fromDecimalComma rewrites 1.234,56 as 1234.56
+func fromDecimalComma(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileOpener opens an input file, given as a local path or a URL, for
+// streaming reads
+type FileOpener interface {
+	Open(path string) (io.ReadCloser, error)
+}
+
+// LocalFileOpener opens paths on the local filesystem
+type LocalFileOpener struct{}
+
+func (LocalFileOpener) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// SchemeOpener opens URLs with the opener registered for their scheme, such
+// as "s3" for s3://bucket/key, and plain paths with Default (the local
+// filesystem when nil)
+type SchemeOpener struct {
+	Schemes map[string]FileOpener
+	Default FileOpener
+}
+
+func (o SchemeOpener) Open(path string) (io.ReadCloser, error) {
+	scheme, ok := URLScheme(path)
+	if !ok {
+		if o.Default == nil {
+			return LocalFileOpener{}.Open(path)
+		}
+		return o.Default.Open(path)
+	}
+	opener, found := o.Schemes[scheme]
+	if !found {
+		return nil, fmt.Errorf("unsupported file URL scheme %q", scheme)
+	}
+	return opener.Open(path)
+}
+
+// URLScheme returns the lower-cased scheme of a path written as a URL
+func URLScheme(path string) (string, bool) {
+	scheme, _, found := strings.Cut(path, "://")
+	if !found || scheme == "" || strings.ContainsAny(scheme, `/\`) {
+		return "", false
+	}
+	return strings.ToLower(scheme), true
+}
+
+// WithFileOpener reads input files through opener instead of the local
+// filesystem
+func WithFileOpener(opener FileOpener) ParserOption {
+	return func(o *parserOptions) {
+		if opener != nil {
+			o.opener = opener
+		}
+	}
+}
+
+// OpenZip opens a zip container, a bank archive or an .xlsx workbook. Local
+// files are read in place; URLs are read into memory, since zip needs random
+// access.
+func OpenZip(path string, opts ...ParserOption) (*zip.Reader, io.Closer, error) {
+	return newParserOptions(opts).openZip(path)
+}
+
+func (o parserOptions) openZip(path string) (*zip.Reader, io.Closer, error) {
+	if o.onLocalDisk(path) {
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &archive.Reader, archive, nil
+	}
+
+	file, err := o.opener.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return archive, nopCloser{}, nil
+}
+
+// onLocalDisk reports whether the opener reads path from the local filesystem
+func (o parserOptions) onLocalDisk(path string) bool {
+	switch opener := o.opener.(type) {
+	case LocalFileOpener:
+		return true
+	case SchemeOpener:
+		_, remote := URLScheme(path)
+		_, local := opener.Default.(LocalFileOpener)
+		return !remote && (opener.Default == nil || local)
+	}
+	return false
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+// maxJSONLLineSize bounds one line of a JSON-lines file
+const maxJSONLLineSize = 1 << 20
+
+// errMissingJSONColumns reports a JSON line skipped for lacking a required key
+var errMissingJSONColumns = errors.New("missing trx_ref_id, amount or date")
+
+// JSONLBankStatementParser reads bank statements from newline-delimited JSON,
+// one object per line with the CSV column names as keys:
+//
+//	{"trx_ref_id": "TX001", "amount": 100.50, "date": "2024-01-15"}
+//
+// Amounts may be numbers or strings. Blank lines are ignored.
+type JSONLBankStatementParser struct {
+	records *CSVBankStatementParser // Shares column and record parsing with the CSV parser
+}
+
+func NewJSONLBankStatementParser(source string, opts ...ParserOption) *JSONLBankStatementParser {
+	return NewJSONLBankStatementParserWithMapping(source, nil, opts...)
+}
+
+// NewJSONLBankStatementParserWithMapping creates a parser for a source whose
+// objects use their own key names
+func NewJSONLBankStatementParserWithMapping(source string, mapping ColumnMapping, opts ...ParserOption) *JSONLBankStatementParser {
+	records := NewCSVBankStatementParserWithMapping(source, mapping, opts...)
+	// JSON numbers are always in dot notation
+	records.opts.decimalComma = false
+	return &JSONLBankStatementParser{records: records}
+}
+
+// Parse reads the file line by line and processes in batches
+func (p *JSONLBankStatementParser) Parse(filePath string, batchSize int, callback func([]domain.BankStatement) error) error {
+	file, err := p.records.opts.openCSV(filePath)
+	if err != nil {
+		logger.GetLogger().WithError(err).WithField("file", filePath).Error("Failed to open file")
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+
+	batch := make([]domain.BankStatement, 0, batchSize)
+	balances := p.records.opts.newBalanceCheck()
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		record, columnMap, err := jsonRecord(line)
+		if err != nil {
+			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to read JSON line, skipping")
+			p.records.opts.reportSkipped(lineNumber, string(line), err)
+			continue
+		}
+		columnMap = p.records.mapping.apply(columnMap)
+
+		if isBalance, err := balances.balanceRow(record, columnMap, lineNumber); err != nil {
+			return err
+		} else if isBalance {
+			continue
+		}
+
+		if !validateColumns(columnMap) {
+			logger.GetLogger().WithField("line", lineNumber).Warn("JSON line is missing trx_ref_id, amount or date, skipping")
+			p.records.opts.reportSkipped(lineNumber, string(line), errMissingJSONColumns)
+			continue
+		}
+
+		statement, err := p.records.parseRecord(record, columnMap, lineNumber)
+		if err != nil {
+			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to parse record, skipping")
+			p.records.opts.reportSkipped(lineNumber, string(line), err)
+			continue
+		}
+
+		balances.add(statement.Amount)
+		batch = append(batch, *statement)
+
+		if len(batch) >= batchSize {
+			if err := deliver(p.records.opts, callback, batch); err != nil {
+				return err
+			}
+			batch = make([]domain.BankStatement, 0, batchSize)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read line %d: %w", lineNumber+1, err)
+	}
+
+	// Process remaining items
+	if len(batch) > 0 {
+		if err := deliver(p.records.opts, callback, batch); err != nil {
+			return err
+		}
+	}
+
+	return balances.validate()
+}
+
+// jsonRecord flattens one JSON object into a record and its column map, as
+// if its keys were a CSV header. Null values read as empty.
+func jsonRecord(line []byte) ([]string, map[string]int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, nil, fmt.Errorf("unexpected data after JSON object")
+	}
+
+	record := make([]string, 0, len(object))
+	columnMap := make(map[string]int, len(object))
+	for key, value := range object {
+		var text string
+		switch v := value.(type) {
+		case nil:
+		case string:
+			text = v
+		case json.Number, bool:
+			text = fmt.Sprint(v)
+		default:
+			return nil, nil, fmt.Errorf("field %q is not a string, number or boolean", key)
+		}
+		columnMap[strings.ToLower(strings.TrimSpace(key))] = len(record)
+		record = append(record, text)
+	}
+	return record, columnMap, nil
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// gzipMagic are the first two bytes of every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openCSV opens a CSV file for reading, decompressing it on the fly when it
+// has a .gz extension or starts with the gzip magic bytes
+func (o parserOptions) openCSV(filePath string) (io.ReadCloser, error) {
+	file, err := o.opener.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(len(gzipMagic))
+	compressed := string(magic) == string(gzipMagic)
+	if !compressed && !strings.EqualFold(filepath.Ext(filePath), ".gz") {
+		return &csvFile{Reader: buffered, closers: []io.Closer{file}}, nil
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("invalid gzip file: %w", err)
+	}
+	return &csvFile{Reader: gz, closers: []io.Closer{gz, file}}, nil
+}
+
+// csvFile reads a possibly decompressed file and closes every layer
+type csvFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *csvFile) Close() error {
+	var first error
+	for _, c := range f.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"recon-engine/pkg/logger"
+)
+
+// ParserOption configures optional behaviour shared by the parsers
+type ParserOption func(*parserOptions)
+
+type parserOptions struct {
+	maxRetries  int
+	backoff     time.Duration
+	isTransient func(error) bool
+	// defaultCurrency fills rows without a currency column or value
+	defaultCurrency string
+	// skipRows is the number of preamble rows before the header
+	skipRows int
+	// detectHeader searches for the first row holding the required columns
+	detectHeader bool
+	// balanceRows identifies bank statement balance lines; nil keeps every row
+	balanceRows *BalanceRows
+	// delimiter separates bank CSV fields; zero keeps the comma
+	delimiter rune
+	// comment starts CSV lines that are skipped; zero disables comments
+	comment rune
+	// decimalComma reads bank CSV amounts as 1.234,56
+	decimalComma bool
+	// dateFormats are tried after the built-in layouts; monthFirst reads
+	// 03/04/2024 as March 4
+	dateFormats []string
+	monthFirst  bool
+	// dateLayout, when set, is the only layout dates are read with
+	dateLayout string
+	// layouts is every accepted date layout in order, set once options apply
+	layouts []string
+	// opener reads input files; the local filesystem by default
+	opener FileOpener
+	// report, when set, collects the resolved columns and skipped rows, up
+	// to maxRowErrors of them
+	report       *ParseReport
+	maxRowErrors int
+}
+
+// maxHeaderScanRows bounds the search for a header row
+const maxHeaderScanRows = 100
+
+func newParserOptions(opts []ParserOption) parserOptions {
+	o := parserOptions{
+		isTransient:  IsTransient,
+		opener:       LocalFileOpener{},
+		maxRowErrors: defaultMaxRowErrors,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.layouts = o.dateLayouts()
+	return o
+}
+
+// WithCallbackRetry retries a failing batch callback up to maxRetries times,
+// doubling the wait from backoff between attempts. Only errors the classifier
+// reports as transient are retried; a nil classifier uses IsTransient.
+func WithCallbackRetry(maxRetries int, backoff time.Duration, classifier func(error) bool) ParserOption {
+	return func(o *parserOptions) {
+		o.maxRetries = maxRetries
+		o.backoff = backoff
+		if classifier != nil {
+			o.isTransient = classifier
+		}
+	}
+}
+
+// WithDefaultCurrency sets the currency used when a row has none
+func WithDefaultCurrency(currency string) ParserOption {
+	return func(o *parserOptions) {
+		o.defaultCurrency = strings.ToUpper(strings.TrimSpace(currency))
+	}
+}
+
+// WithSkipRows skips n leading rows, such as report titles or account
+// details, before reading the header
+func WithSkipRows(n int) ParserOption {
+	return func(o *parserOptions) {
+		if n > 0 {
+			o.skipRows = n
+		}
+	}
+}
+
+// WithHeaderDetection treats the first row (after any skipped rows) that
+// contains all required columns as the header, so preambles of varying
+// length are ignored. Only the first 100 rows are searched.
+func WithHeaderDetection(enabled bool) ParserOption {
+	return func(o *parserOptions) {
+		o.detectHeader = enabled
+	}
+}
+
+// readHeader consumes the preamble and returns the header row along with its
+// 1-based row number. next reads one row; isHeader checks a candidate when
+// header detection is enabled.
+func (o parserOptions) readHeader(next func() ([]string, error), isHeader func([]string) bool) ([]string, int, error) {
+	row := 0
+	for ; row < o.skipRows; row++ {
+		if _, err := next(); err != nil {
+			return nil, row, err
+		}
+	}
+
+	for {
+		header, err := next()
+		if err != nil {
+			return nil, row, err
+		}
+		row++
+		if !o.detectHeader || isHeader(header) {
+			return header, row, nil
+		}
+		if row-o.skipRows >= maxHeaderScanRows {
+			return nil, row, fmt.Errorf("no header row found in the first %d rows", maxHeaderScanRows)
+		}
+	}
+}
+
+// TransientError marks an error returned from a callback as safe to retry
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient is the default error classifier. It treats TransientError and
+// errors exposing Temporary() or Timeout() (e.g. net.Error) as transient.
+func IsTransient(err error) bool {
+	var transient *TransientError
+	if errors.As(err, &transient) {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	return false
+}
+
+// deliver hands a batch to the callback, retrying transient failures
+func deliver[T any](o parserOptions, callback func([]T) error, batch []T) error {
+	wait := o.backoff
+	for attempt := 0; ; attempt++ {
+		err := callback(batch)
+		if err == nil {
+			return nil
+		}
+		if attempt >= o.maxRetries || !o.isTransient(err) {
+			return err
+		}
+
+		logger.GetLogger().WithError(err).WithFields(map[string]interface{}{
+			"attempt":    attempt + 1,
+			"batch_size": len(batch),
+		}).Warn("Batch callback failed, retrying")
+
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
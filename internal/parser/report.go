@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"recon-engine/internal/domain"
+)
+
+// defaultMaxRowErrors caps the skipped rows kept in a ParseReport unless
+// WithMaxRowErrors says otherwise; maxRawRowLength caps each row's raw text
+const (
+	defaultMaxRowErrors = 20
+	maxRawRowLength     = 512
+)
+
+// bankColumns and transactionColumns are the canonical columns the parsers read
+var (
+	bankColumns        = []string{"trx_ref_id", "amount", "debit", "credit", "date", "currency", "type", "description"}
+	transactionColumns = []string{"trx_id", "amount", "type", "transaction_time", "currency", "order_id", "description"}
+)
+
+// ParseReport collects what a parse saw besides its rows: the header column
+// each canonical column was read from and the malformed rows it skipped
+type ParseReport struct {
+	// Columns maps canonical names to header names; JSON Lines files have
+	// no header and leave it empty
+	Columns map[string]string
+	// SkippedRows counts every skipped row; RowErrors holds the first of
+	// them, 20 by default
+	SkippedRows int
+	RowErrors   []domain.RowError
+}
+
+// WithParseReport fills report as a file is parsed. Use a report per file.
+func WithParseReport(report *ParseReport) ParserOption {
+	return func(o *parserOptions) {
+		o.report = report
+	}
+}
+
+// WithMaxRowErrors sets how many skipped rows a ParseReport keeps. Rows
+// past the limit are still counted; n <= 0 keeps the default of 20.
+func WithMaxRowErrors(n int) ParserOption {
+	return func(o *parserOptions) {
+		if n > 0 {
+			o.maxRowErrors = n
+		}
+	}
+}
+
+// reportColumns records the header name each of the canonical columns was
+// read from
+func (o parserOptions) reportColumns(header []string, columnMap map[string]int, canonical []string) {
+	if o.report == nil {
+		return
+	}
+	o.report.Columns = make(map[string]string)
+	for _, name := range canonical {
+		if idx, ok := columnMap[name]; ok && idx < len(header) {
+			o.report.Columns[name] = header[idx]
+		}
+	}
+}
+
+// reportSkipped records a row skipped because of err along with its raw
+// text, truncated to maxRawRowLength bytes
+func (o parserOptions) reportSkipped(line int, raw string, err error) {
+	if o.report == nil {
+		return
+	}
+	o.report.SkippedRows++
+	if len(o.report.RowErrors) < o.maxRowErrors {
+		o.report.RowErrors = append(o.report.RowErrors, domain.RowError{Line: line, Raw: truncateRaw(raw), Error: err.Error()})
+	}
+}
+
+// rawRecord rebuilds the text of a parsed CSV or XLSX row
+func rawRecord(record []string, delimiter rune) string {
+	if delimiter == 0 {
+		delimiter = ','
+	}
+	return strings.Join(record, string(delimiter))
+}
+
+func truncateRaw(raw string) string {
+	if len(raw) <= maxRawRowLength {
+		return raw
+	}
+	cut := maxRawRowLength
+	for cut > 0 && !utf8.RuneStart(raw[cut]) {
+		cut--
+	}
+	return raw[:cut]
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// SourceFingerprint recognises a bank's files by their content rather than
+// their name. Headers must all appear in one row (case-insensitively); when
+// MarkerColumn is set it must be in that row too, and its value in the first
+// data row below must equal MarkerValue. A fingerprint with neither never
+// matches.
+type SourceFingerprint struct {
+	Source       string
+	Headers      []string
+	MarkerColumn string
+	MarkerValue  string
+}
+
+func (f SourceFingerprint) match(rows [][]string) bool {
+	required := make([]string, 0, len(f.Headers)+1)
+	for _, header := range f.Headers {
+		required = append(required, strings.ToLower(strings.TrimSpace(header)))
+	}
+	marker := strings.ToLower(strings.TrimSpace(f.MarkerColumn))
+	if marker != "" {
+		required = append(required, marker)
+	}
+	if len(required) == 0 {
+		return false
+	}
+
+	for i, row := range rows {
+		columns := mapColumns(row)
+		if !hasColumns(columns, required) {
+			continue
+		}
+		if marker == "" {
+			return true
+		}
+		idx := columns[marker]
+		for _, data := range rows[i+1:] {
+			if isBlankRecord(data) {
+				continue
+			}
+			return idx < len(data) && strings.EqualFold(strings.TrimSpace(data[idx]), strings.TrimSpace(f.MarkerValue))
+		}
+		return false
+	}
+	return false
+}
+
+func hasColumns(columns map[string]int, names []string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectSource reads the first rows of a CSV (optionally gzipped) or XLSX
+// bank file and returns the source of the first matching fingerprint.
+// Preamble rows are fine: up to 100 rows are searched for the header. CSV
+// files are split with the WithDelimiter option, if given.
+func DetectSource(filePath string, fingerprints []SourceFingerprint, opts ...ParserOption) (string, bool, error) {
+	if len(fingerprints) == 0 {
+		return "", false, nil
+	}
+
+	rows, err := leadingRows(filePath, maxHeaderScanRows+1, newParserOptions(opts))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", filepath.Base(filePath), err)
+	}
+	for _, fingerprint := range fingerprints {
+		if fingerprint.match(rows) {
+			return fingerprint.Source, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// leadingRows returns up to n rows from the start of a bank file
+func leadingRows(filePath string, n int, o parserOptions) ([][]string, error) {
+	next, closeFile, err := openRows(filePath, o)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile()
+
+	rows := make([][]string, 0, n)
+	for len(rows) < n {
+		row, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// openRows returns a row reader for the file's format
+func openRows(filePath string, o parserOptions) (func() ([]string, error), func() error, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".xlsx") {
+		workbook, closer, err := o.openZip(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		sheet, err := openFirstSheet(workbook)
+		if err != nil {
+			closer.Close()
+			return nil, nil, err
+		}
+		next := func() ([]string, error) {
+			cells, _, err := sheet.next()
+			return cellValues(cells), err
+		}
+		return next, func() error {
+			sheet.Close()
+			return closer.Close()
+		}, nil
+	}
+
+	file, err := o.openCSV(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := o.newBankCSVReader(file)
+	return reader.Read, file.Close, nil
+}
@@ -0,0 +1,386 @@
+package parser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+// XLSXBankStatementParser reads bank statements from the first sheet of an
+// Excel workbook. Rows are streamed, so large sheets are never fully loaded.
+type XLSXBankStatementParser struct {
+	records *CSVBankStatementParser // Shares column and record parsing with the CSV parser
+}
+
+func NewXLSXBankStatementParser(source string, opts ...ParserOption) *XLSXBankStatementParser {
+	return NewXLSXBankStatementParserWithMapping(source, nil, opts...)
+}
+
+// NewXLSXBankStatementParserWithMapping creates a parser for a source whose
+// header uses its own column names
+func NewXLSXBankStatementParserWithMapping(source string, mapping ColumnMapping, opts ...ParserOption) *XLSXBankStatementParser {
+	records := NewCSVBankStatementParserWithMapping(source, mapping, opts...)
+	// Numeric cells are always stored in dot notation
+	records.opts.decimalComma = false
+	// Serial date cells become serialDateLayout text, which must stay
+	// readable when the source has an explicit layout
+	if records.opts.dateLayout != "" {
+		records.opts.layouts = append(records.opts.layouts, serialDateLayout)
+	}
+	return &XLSXBankStatementParser{records: records}
+}
+
+// Parse reads the first sheet in streaming mode and processes in batches
+func (p *XLSXBankStatementParser) Parse(filePath string, batchSize int, callback func([]domain.BankStatement) error) error {
+	workbook, closer, err := p.records.opts.openZip(filePath)
+	if err != nil {
+		logger.GetLogger().WithError(err).WithField("file", filePath).Error("Failed to open file")
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer closer.Close()
+
+	sheet, err := openFirstSheet(workbook)
+	if err != nil {
+		return fmt.Errorf("failed to open sheet: %w", err)
+	}
+	defer sheet.Close()
+
+	// Read header
+	var header []xlsxCell
+	_, _, err = p.records.opts.readHeader(func() ([]string, error) {
+		cells, _, err := sheet.next()
+		header = cells
+		return cellValues(cells), err
+	}, func(row []string) bool {
+		return validateColumns(p.records.mapping.apply(mapColumns(row)))
+	})
+	if err == io.EOF {
+		return fmt.Errorf("failed to read header: sheet is empty")
+	}
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to read XLSX header")
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+
+	// Map header columns
+	columnMap := p.records.mapping.apply(mapColumns(cellValues(header)))
+	if !validateColumns(columnMap) {
+		return fmt.Errorf("invalid XLSX format: missing required columns (trx_ref_id, amount, date)")
+	}
+	p.records.opts.reportColumns(cellValues(header), columnMap, bankColumns)
+	dateIdx := columnMap["date"]
+
+	batch := make([]domain.BankStatement, 0, batchSize)
+	balances := p.records.opts.newBalanceCheck()
+
+	for {
+		cells, lineNumber, err := sheet.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read sheet: %w", err)
+		}
+
+		record := cellValues(padCells(cells, len(header)))
+		if isBlankRecord(record) {
+			continue
+		}
+
+		// Date cells are usually stored as Excel serial numbers
+		if dateIdx < len(cells) && cells[dateIdx].numeric {
+			if date, err := excelSerialToTime(record[dateIdx]); err == nil {
+				record[dateIdx] = date.Format(serialDateLayout)
+			}
+		}
+
+		if isBalance, err := balances.balanceRow(record, columnMap, lineNumber); err != nil {
+			return err
+		} else if isBalance {
+			continue
+		}
+
+		statement, err := p.records.parseRecord(record, columnMap, lineNumber)
+		if err != nil {
+			logger.GetLogger().WithError(err).WithField("line", lineNumber).Warn("Failed to parse record, skipping")
+			p.records.opts.reportSkipped(lineNumber, rawRecord(record, ','), err)
+			continue
+		}
+
+		balances.add(statement.Amount)
+		batch = append(batch, *statement)
+
+		if len(batch) >= batchSize {
+			if err := deliver(p.records.opts, callback, batch); err != nil {
+				return err
+			}
+			batch = make([]domain.BankStatement, 0, batchSize)
+		}
+	}
+
+	// Process remaining items
+	if len(batch) > 0 {
+		if err := deliver(p.records.opts, callback, batch); err != nil {
+			return err
+		}
+	}
+
+	return balances.validate()
+}
+
+// xlsxCell is a resolved cell value; numeric is set for number-typed cells
+type xlsxCell struct {
+	value   string
+	numeric bool
+}
+
+type xlsxSheet struct {
+	file    io.ReadCloser
+	decoder *xml.Decoder
+	shared  []string
+	rows    int
+}
+
+// openFirstSheet locates the first worksheet through the workbook
+// relationships and prepares a streaming reader over its rows
+func openFirstSheet(workbook *zip.Reader) (*xlsxSheet, error) {
+	files := make(map[string]*zip.File, len(workbook.File))
+	for _, f := range workbook.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := readSharedStrings(files)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("worksheet %s not found", sheetPath)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxSheet{file: rc, decoder: xml.NewDecoder(rc), shared: shared}, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", fmt.Errorf("worksheet relationship %s not found", workbook.Sheets[0].ID)
+}
+
+// readSharedStrings loads the shared string table; it is optional
+func readSharedStrings(files map[string]*zip.File) ([]string, error) {
+	if _, ok := files["xl/sharedStrings.xml"]; !ok {
+		return nil, nil
+	}
+
+	var sst struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err := decodeZipXML(files, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+func decodeZipXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%s not found", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// xlsxText is rich or plain text from a shared or inline string
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, run := range t.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R      string   `xml:"r,attr"`
+		T      string   `xml:"t,attr"`
+		V      string   `xml:"v"`
+		Inline xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// next returns the cells of the next row along with its 1-based row number
+func (s *xlsxSheet) next() ([]xlsxCell, int, error) {
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := s.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, 0, err
+		}
+
+		s.rows++
+		lineNumber := row.R
+		if lineNumber == 0 {
+			lineNumber = s.rows
+		}
+
+		var cells []xlsxCell
+		for i, c := range row.Cells {
+			idx := i
+			if c.R != "" {
+				idx = columnIndex(c.R)
+			}
+			for len(cells) <= idx {
+				cells = append(cells, xlsxCell{})
+			}
+			cells[idx] = s.resolve(c.T, c.V, c.Inline)
+		}
+		return cells, lineNumber, nil
+	}
+}
+
+func (s *xlsxSheet) resolve(cellType, value string, inline xlsxText) xlsxCell {
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(value)
+		if err != nil || idx < 0 || idx >= len(s.shared) {
+			return xlsxCell{}
+		}
+		return xlsxCell{value: s.shared[idx]}
+	case "inlineStr":
+		return xlsxCell{value: inline.String()}
+	case "", "n":
+		return xlsxCell{value: value, numeric: value != ""}
+	default:
+		return xlsxCell{value: value}
+	}
+}
+
+func (s *xlsxSheet) Close() error {
+	return s.file.Close()
+}
+
+// columnIndex converts a cell reference such as "AB12" to a 0-based column
+func columnIndex(ref string) int {
+	idx := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		idx = idx*26 + int(r-'A'+1)
+	}
+	return idx - 1
+}
+
+// serialDateLayout is the layout Excel serial dates are rewritten in
+const serialDateLayout = "2006-01-02 15:04:05"
+
+// excelSerialToTime converts an Excel serial date (1900 date system)
+func excelSerialToTime(serial string) (time.Time, error) {
+	days, err := strconv.ParseFloat(serial, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return epoch.Add(time.Duration(days * 24 * float64(time.Hour))).Round(time.Second), nil
+}
+
+func padCells(cells []xlsxCell, width int) []xlsxCell {
+	for len(cells) < width {
+		cells = append(cells, xlsxCell{})
+	}
+	return cells
+}
+
+func cellValues(cells []xlsxCell) []string {
+	values := make([]string, len(cells))
+	for i, c := range cells {
+		values[i] = c.value
+	}
+	return values
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
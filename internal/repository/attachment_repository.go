@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	ListByResultID(ctx context.Context, resultID int) ([]domain.Attachment, error)
+	// ResultExists reports whether a reconciliation result with the id exists
+	ResultExists(ctx context.Context, resultID int) (bool, error)
+}
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	query := `
+		INSERT INTO result_attachments (result_id, file_name, content_type, size_bytes, description, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		attachment.ResultID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		sql.NullString{String: attachment.Description, Valid: attachment.Description != ""},
+		attachment.StorageKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to create result attachment")
+		return err
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) ListByResultID(ctx context.Context, resultID int) ([]domain.Attachment, error) {
+	query := `
+		SELECT id, result_id, file_name, content_type, size_bytes, description, storage_key, created_at
+		FROM result_attachments
+		WHERE result_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, resultID)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to list result attachments")
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := make([]domain.Attachment, 0)
+	for rows.Next() {
+		var attachment domain.Attachment
+		var description sql.NullString
+		if err := rows.Scan(
+			&attachment.ID,
+			&attachment.ResultID,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.Size,
+			&description,
+			&attachment.StorageKey,
+			&attachment.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		attachment.Description = description.String
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (r *attachmentRepository) ResultExists(ctx context.Context, resultID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM reconciliation_results WHERE id = $1)`, resultID).Scan(&exists)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to look up reconciliation result")
+		return false, err
+	}
+	return exists, nil
+}
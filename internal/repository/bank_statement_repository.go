@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+// BankStatementRepository date ranges are half-open: start <= date < end
+type BankStatementRepository interface {
+	BulkCreate(ctx context.Context, statements []domain.BankStatement) error
+	GetByDateRangeStream(ctx context.Context, startDate, endDate time.Time, batchSize int, callback func([]domain.BankStatement) error) error
+	// GetByDateRangeAndSource returns the stored statements of one bank
+	// source; an empty source matches every source
+	GetByDateRangeAndSource(ctx context.Context, startDate, endDate time.Time, source string) ([]domain.BankStatement, error)
+}
+
+const bankStatementSelectColumns = `trx_ref_id, amount, statement_date, source, currency, type, job_id, description`
+
+type bankStatementRepository struct {
+	db               *sql.DB
+	progressInterval int
+}
+
+func NewBankStatementRepository(db *sql.DB, opts ...RepositoryOption) BankStatementRepository {
+	o := newRepositoryOptions(opts)
+	return &bankStatementRepository{db: db, progressInterval: o.progressInterval}
+}
+
+func (r *bankStatementRepository) BulkCreate(ctx context.Context, statements []domain.BankStatement) error {
+	if len(statements) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO bank_statements (trx_ref_id, amount, statement_date, source, currency, type, job_id, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to prepare statement")
+		return err
+	}
+	defer stmt.Close()
+
+	progress := logger.NewProgress("bulk_create_bank_statements", r.progressInterval)
+	for _, statement := range statements {
+		_, err = stmt.ExecContext(
+			ctx,
+			statement.TrxRefID,
+			statement.Amount,
+			statement.Date,
+			statement.Source,
+			nullIfEmpty(statement.Currency),
+			nullIfEmpty(string(statement.Type)),
+			nullIfEmpty(statement.JobID),
+			nullIfEmpty(statement.Description),
+		)
+		progress.Add(1)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.GetLogger().WithError(err).WithField("trx_ref_id", statement.TrxRefID).Error("Failed to insert bank statement")
+			continue
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	progress.Done()
+
+	return nil
+}
+
+func (r *bankStatementRepository) GetByDateRangeAndSource(ctx context.Context, startDate, endDate time.Time, source string) ([]domain.BankStatement, error) {
+	query := `
+		SELECT ` + bankStatementSelectColumns + `
+		FROM bank_statements
+		WHERE statement_date >= $1 AND statement_date < $2 AND ($3 = '' OR source = $3)
+		ORDER BY statement_date, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, source)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to query bank statements")
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := make([]domain.BankStatement, 0)
+	for rows.Next() {
+		statement, err := scanBankStatement(rows)
+		if err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to scan bank statement")
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, rows.Err()
+}
+
+// GetByDateRangeStream processes bank statements in batches to avoid loading all into memory
+func (r *bankStatementRepository) GetByDateRangeStream(ctx context.Context, startDate, endDate time.Time, batchSize int, callback func([]domain.BankStatement) error) error {
+	query := `
+		SELECT ` + bankStatementSelectColumns + `
+		FROM bank_statements
+		WHERE statement_date >= $1 AND statement_date < $2
+		ORDER BY statement_date, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to query bank statements")
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]domain.BankStatement, 0, batchSize)
+	for rows.Next() {
+		stmt, err := scanBankStatement(rows)
+		if err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to scan bank statement")
+			continue
+		}
+
+		batch = append(batch, stmt)
+
+		if len(batch) >= batchSize {
+			if err := callback(batch); err != nil {
+				return err
+			}
+			batch = make([]domain.BankStatement, 0, batchSize)
+		}
+	}
+
+	// Process remaining items
+	if len(batch) > 0 {
+		if err := callback(batch); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// scanBankStatement reads a row selected with bankStatementSelectColumns
+func scanBankStatement(row rowScanner) (domain.BankStatement, error) {
+	var statement domain.BankStatement
+	var currency, txType, jobID, description sql.NullString
+	err := row.Scan(
+		&statement.TrxRefID,
+		&statement.Amount,
+		&statement.Date,
+		&statement.Source,
+		&currency,
+		&txType,
+		&jobID,
+		&description,
+	)
+	statement.Currency = currency.String
+	statement.Type = domain.TransactionType(txType.String)
+	statement.JobID = jobID.String
+	statement.Description = description.String
+	return statement, err
+}
+
+func nullIfEmpty(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
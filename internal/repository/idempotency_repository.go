@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"recon-engine/pkg/logger"
+)
+
+type IdempotencyRepository interface {
+	// Claim records key as starting jobID until expiresAt. When an unexpired
+	// claim on key exists it is left alone and its job ID is returned;
+	// otherwise the returned job ID is empty.
+	Claim(ctx context.Context, key, jobID string, expiresAt time.Time) (string, error)
+	// Release removes the claim on key so the request can be submitted again
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Claim(ctx context.Context, key, jobID string, expiresAt time.Time) (string, error) {
+	// An expired claim is taken over in place; a live one makes the upsert a
+	// no-op that returns no row
+	query := `
+		INSERT INTO idempotency_keys (idempotency_key, job_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO UPDATE
+		SET job_id = EXCLUDED.job_id, created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $4
+		RETURNING job_id
+	`
+
+	var claimed string
+	err := r.db.QueryRowContext(ctx, query, key, jobID, expiresAt, time.Now()).Scan(&claimed)
+	if err == nil {
+		return "", nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.GetLogger().WithError(err).Error("Failed to claim idempotency key")
+		return "", err
+	}
+
+	var existing string
+	err = r.db.QueryRowContext(ctx, `SELECT job_id FROM idempotency_keys WHERE idempotency_key = $1`, key).Scan(&existing)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to read idempotency key")
+		return "", err
+	}
+	return existing, nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = $1`, key); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to release idempotency key")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+// RepositoryOption configures optional repository behaviour
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	progressInterval int
+	retry            retryPolicy
+}
+
+func newRepositoryOptions(opts []RepositoryOption) repositoryOptions {
+	var o repositoryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithProgressInterval logs bulk insert progress every interval rows.
+// Zero disables progress logging.
+func WithProgressInterval(interval int) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.progressInterval = interval
+	}
+}
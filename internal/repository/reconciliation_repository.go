@@ -1,51 +1,201 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"recon-engine/internal/domain"
 	"recon-engine/pkg/logger"
 )
 
 type ReconciliationRepository interface {
-	CreateJob(job *domain.ReconciliationJob) error
-	UpdateJob(job *domain.ReconciliationJob) error
-	GetJobByID(jobID string) (*domain.ReconciliationJob, error)
-	CreateResult(result *domain.ReconciliationResult) error
-	BulkCreateResults(results []domain.ReconciliationResult) error
-	GetResultsByJobID(jobID string) ([]domain.ReconciliationResult, error)
-	GetResultsByJobIDAndStatus(jobID string, status domain.MatchStatus) ([]domain.ReconciliationResult, error)
+	CreateJob(ctx context.Context, job *domain.ReconciliationJob) error
+	UpdateJob(ctx context.Context, job *domain.ReconciliationJob) error
+	GetJobByID(ctx context.Context, jobID string) (*domain.ReconciliationJob, error)
+	// ListCompletedJobs returns completed jobs whose date range lies within
+	// startDate and endDate, oldest first
+	ListCompletedJobs(ctx context.Context, startDate, endDate time.Time) ([]domain.ReconciliationJob, error)
+	// ListJobs returns one page of jobs matching filter, newest first, and
+	// the total number of matching jobs
+	ListJobs(ctx context.Context, filter domain.JobFilter, limit, offset int) ([]domain.ReconciliationJob, int, error)
+	// DeleteJob soft-deletes a job that is not processing and removes its results
+	DeleteJob(ctx context.Context, jobID string) error
+	CreateResult(ctx context.Context, result *domain.ReconciliationResult) error
+	BulkCreateResults(ctx context.Context, results []domain.ReconciliationResult) error
+	GetResultsByJobID(ctx context.Context, jobID string) ([]domain.ReconciliationResult, error)
+	GetResultsByJobIDStream(ctx context.Context, jobID string, batchSize int, callback func([]domain.ReconciliationResult) error) error
+	// QueryResults returns one page of the job's results matching filter and
+	// the total number of matching rows
+	QueryResults(ctx context.Context, jobID string, filter domain.ResultFilter, limit, offset int) ([]domain.ReconciliationResult, int, error)
+	// QueryResultsAfter returns up to limit of the job's results matching
+	// filter that come after the cursor, for keyset paging
+	QueryResultsAfter(ctx context.Context, jobID string, filter domain.ResultFilter, after *domain.ResultCursor, limit int) ([]domain.ReconciliationResult, error)
+	// GetResultCountsByStatus counts the job's results per bank source and status
+	GetResultCountsByStatus(ctx context.Context, jobID string) ([]domain.ResultCount, error)
+	// ReplaceUnmatchedResults deletes the completed job's unmatched results
+	// with removedIDs, stores added and adds delta to the job's
+	// totals in one transaction, returning the updated job
+	ReplaceUnmatchedResults(ctx context.Context, jobID string, removedIDs []int, added []domain.ReconciliationResult, delta domain.JobTotalsDelta) (*domain.ReconciliationJob, error)
+}
+
+// ErrResultsChanged is returned when results to replace were already
+// removed, e.g. by a concurrent reprocess; nothing is changed
+var ErrResultsChanged = errors.New("reconciliation results changed while being replaced")
+
+const (
+	resultSelectColumns = `id, job_id, trx_id, trx_ref_id, system_amount, bank_amount,
+			   discrepancy, signed_discrepancy, match_status, bank_source, transaction_date,
+			   transaction_type, transaction_created_at, currency, bank_currency, description, bank_description,
+			   matched_via, confidence, manual_match, reviewed_by, chain_hash, created_at`
+
+	resultInsertColumns = `job_id, trx_id, trx_ref_id, system_amount, bank_amount,
+			discrepancy, signed_discrepancy, match_status, bank_source, transaction_date,
+			transaction_type, transaction_created_at, currency, bank_currency, description, bank_description,
+			matched_via, confidence, manual_match, reviewed_by, chain_hash`
+
+	resultInsertPlaceholders = `$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21`
+)
+
+// resultInsertArgs returns the values for resultInsertColumns in order
+func resultInsertArgs(result *domain.ReconciliationResult) []interface{} {
+	return []interface{}{
+		result.JobID,
+		result.TrxID,
+		result.TrxRefID,
+		result.SystemAmount,
+		result.BankAmount,
+		result.Discrepancy,
+		result.SignedDiscrepancy,
+		result.MatchStatus,
+		result.BankSource,
+		result.TransactionDate,
+		result.TransactionType,
+		result.TransactionCreatedAt,
+		result.Currency,
+		result.BankCurrency,
+		result.Description,
+		result.BankDescription,
+		result.MatchedVia,
+		result.Confidence,
+		result.ManualMatch,
+		result.ReviewedBy,
+		result.ChainHash,
+	}
+}
+
+const jobSelectColumns = `id, job_id, start_date, end_date, status,
+			   total_processed, total_matched, total_unmatched, total_discrepancies,
+			   net_discrepancy, error_message, result_chain_head, result_chain_version, exceptions_only, created_at, updated_at`
+
+// scanJob reads a row selected with jobSelectColumns
+func scanJob(row rowScanner) (*domain.ReconciliationJob, error) {
+	var job domain.ReconciliationJob
+	err := row.Scan(
+		&job.ID,
+		&job.JobID,
+		&job.StartDate,
+		&job.EndDate,
+		&job.Status,
+		&job.TotalProcessed,
+		&job.TotalMatched,
+		&job.TotalUnmatched,
+		&job.TotalDiscrepancies,
+		&job.NetDiscrepancy,
+		&job.ErrorMessage,
+		&job.ResultChainHead,
+		&job.ResultChainVersion,
+		&job.ExceptionsOnly,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	return &job, err
+}
+
+// chainVersion is the version column of job; jobs without a chain keep the
+// column default
+func chainVersion(job *domain.ReconciliationJob) int {
+	if job.ResultChainVersion == 0 {
+		return 1
+	}
+	return job.ResultChainVersion
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanResult reads a row selected with resultSelectColumns
+func scanResult(row rowScanner) (domain.ReconciliationResult, error) {
+	var result domain.ReconciliationResult
+	err := row.Scan(
+		&result.ID,
+		&result.JobID,
+		&result.TrxID,
+		&result.TrxRefID,
+		&result.SystemAmount,
+		&result.BankAmount,
+		&result.Discrepancy,
+		&result.SignedDiscrepancy,
+		&result.MatchStatus,
+		&result.BankSource,
+		&result.TransactionDate,
+		&result.TransactionType,
+		&result.TransactionCreatedAt,
+		&result.Currency,
+		&result.BankCurrency,
+		&result.Description,
+		&result.BankDescription,
+		&result.MatchedVia,
+		&result.Confidence,
+		&result.ManualMatch,
+		&result.ReviewedBy,
+		&result.ChainHash,
+		&result.CreatedAt,
+	)
+	return result, err
 }
 
 type reconciliationRepository struct {
-	db *sql.DB
+	db               *sql.DB
+	progressInterval int
+	retry            retryPolicy
 }
 
-func NewReconciliationRepository(db *sql.DB) ReconciliationRepository {
-	return &reconciliationRepository{db: db}
+func NewReconciliationRepository(db *sql.DB, opts ...RepositoryOption) ReconciliationRepository {
+	o := newRepositoryOptions(opts)
+	return &reconciliationRepository{db: db, progressInterval: o.progressInterval, retry: o.retry}
 }
 
-func (r *reconciliationRepository) CreateJob(job *domain.ReconciliationJob) error {
+func (r *reconciliationRepository) CreateJob(ctx context.Context, job *domain.ReconciliationJob) error {
 	query := `
 		INSERT INTO reconciliation_jobs (
 			job_id, start_date, end_date, status,
-			total_processed, total_matched, total_unmatched, total_discrepancies
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			total_processed, total_matched, total_unmatched, total_discrepancies,
+			net_discrepancy, exceptions_only
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
-		query,
-		job.JobID,
-		job.StartDate,
-		job.EndDate,
-		job.Status,
-		job.TotalProcessed,
-		job.TotalMatched,
-		job.TotalUnmatched,
-		job.TotalDiscrepancies,
-	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	err := r.retry.do(ctx, "create_job", func() error {
+		return r.db.QueryRowContext(
+			ctx,
+			query,
+			job.JobID,
+			job.StartDate,
+			job.EndDate,
+			job.Status,
+			job.TotalProcessed,
+			job.TotalMatched,
+			job.TotalUnmatched,
+			job.TotalDiscrepancies,
+			job.NetDiscrepancy,
+			job.ExceptionsOnly,
+		).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	})
 
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to create reconciliation job")
@@ -55,24 +205,32 @@ func (r *reconciliationRepository) CreateJob(job *domain.ReconciliationJob) erro
 	return nil
 }
 
-func (r *reconciliationRepository) UpdateJob(job *domain.ReconciliationJob) error {
+func (r *reconciliationRepository) UpdateJob(ctx context.Context, job *domain.ReconciliationJob) error {
 	query := `
 		UPDATE reconciliation_jobs
 		SET status = $1, total_processed = $2, total_matched = $3,
-			total_unmatched = $4, total_discrepancies = $5, error_message = $6
-		WHERE job_id = $7
+			total_unmatched = $4, total_discrepancies = $5, net_discrepancy = $6,
+			error_message = $7, result_chain_head = $8, result_chain_version = $9
+		WHERE job_id = $10
 	`
 
-	_, err := r.db.Exec(
-		query,
-		job.Status,
-		job.TotalProcessed,
-		job.TotalMatched,
-		job.TotalUnmatched,
-		job.TotalDiscrepancies,
-		job.ErrorMessage,
-		job.JobID,
-	)
+	err := r.retry.do(ctx, "update_job", func() error {
+		_, err := r.db.ExecContext(
+			ctx,
+			query,
+			job.Status,
+			job.TotalProcessed,
+			job.TotalMatched,
+			job.TotalUnmatched,
+			job.TotalDiscrepancies,
+			job.NetDiscrepancy,
+			job.ErrorMessage,
+			job.ResultChainHead,
+			chainVersion(job),
+			job.JobID,
+		)
+		return err
+	})
 
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to update reconciliation job")
@@ -82,31 +240,14 @@ func (r *reconciliationRepository) UpdateJob(job *domain.ReconciliationJob) erro
 	return nil
 }
 
-func (r *reconciliationRepository) GetJobByID(jobID string) (*domain.ReconciliationJob, error) {
+func (r *reconciliationRepository) GetJobByID(ctx context.Context, jobID string) (*domain.ReconciliationJob, error) {
 	query := `
-		SELECT id, job_id, start_date, end_date, status,
-			   total_processed, total_matched, total_unmatched, total_discrepancies,
-			   error_message, created_at, updated_at
+		SELECT ` + jobSelectColumns + `
 		FROM reconciliation_jobs
-		WHERE job_id = $1
+		WHERE job_id = $1 AND deleted_at IS NULL
 	`
 
-	var job domain.ReconciliationJob
-	err := r.db.QueryRow(query, jobID).Scan(
-		&job.ID,
-		&job.JobID,
-		&job.StartDate,
-		&job.EndDate,
-		&job.Status,
-		&job.TotalProcessed,
-		&job.TotalMatched,
-		&job.TotalUnmatched,
-		&job.TotalDiscrepancies,
-		&job.ErrorMessage,
-		&job.CreatedAt,
-		&job.UpdatedAt,
-	)
-
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, jobID))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("reconciliation job not found")
 	}
@@ -115,30 +256,131 @@ func (r *reconciliationRepository) GetJobByID(jobID string) (*domain.Reconciliat
 		return nil, err
 	}
 
-	return &job, nil
+	return job, nil
+}
+
+func (r *reconciliationRepository) ListCompletedJobs(ctx context.Context, startDate, endDate time.Time) ([]domain.ReconciliationJob, error) {
+	query := `
+		SELECT ` + jobSelectColumns + `
+		FROM reconciliation_jobs
+		WHERE status = $1 AND start_date >= $2 AND end_date <= $3 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.Completed, startDate, endDate)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to list reconciliation jobs")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []domain.ReconciliationJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (r *reconciliationRepository) ListJobs(ctx context.Context, filter domain.JobFilter, limit, offset int) ([]domain.ReconciliationJob, int, error) {
+	where := `WHERE deleted_at IS NULL`
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+	if filter.CreatedFrom != nil {
+		args = append(args, *filter.CreatedFrom)
+		where += fmt.Sprintf(` AND created_at >= $%d`, len(args))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		where += fmt.Sprintf(` AND created_at < $%d`, len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reconciliation_jobs `+where, args...).Scan(&total); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to count reconciliation jobs")
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM reconciliation_jobs
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, jobSelectColumns, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to list reconciliation jobs")
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := make([]domain.ReconciliationJob, 0, limit)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to scan reconciliation job")
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, total, rows.Err()
+}
+
+func (r *reconciliationRepository) DeleteJob(ctx context.Context, jobID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	// The status check is repeated here so a job that started processing
+	// after the caller looked at it is left alone
+	res, err := tx.ExecContext(ctx, `
+		UPDATE reconciliation_jobs
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE job_id = $1 AND deleted_at IS NULL AND status <> $2
+	`, jobID, domain.Processing)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to delete reconciliation job")
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("reconciliation job not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reconciliation_results WHERE job_id = $1`, jobID); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to delete reconciliation results")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
 }
 
-func (r *reconciliationRepository) CreateResult(result *domain.ReconciliationResult) error {
+func (r *reconciliationRepository) CreateResult(ctx context.Context, result *domain.ReconciliationResult) error {
 	query := `
-		INSERT INTO reconciliation_results (
-			job_id, trx_id, trx_ref_id, system_amount, bank_amount,
-			discrepancy, match_status, bank_source, transaction_date
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO reconciliation_results (` + resultInsertColumns + `)
+		VALUES (` + resultInsertPlaceholders + `)
 		RETURNING id, created_at
 	`
 
-	err := r.db.QueryRow(
-		query,
-		result.JobID,
-		result.TrxID,
-		result.TrxRefID,
-		result.SystemAmount,
-		result.BankAmount,
-		result.Discrepancy,
-		result.MatchStatus,
-		result.BankSource,
-		result.TransactionDate,
-	).Scan(&result.ID, &result.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, resultInsertArgs(result)...).Scan(&result.ID, &result.CreatedAt)
 
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to create reconciliation result")
@@ -148,23 +390,28 @@ func (r *reconciliationRepository) CreateResult(result *domain.ReconciliationRes
 	return nil
 }
 
-func (r *reconciliationRepository) BulkCreateResults(results []domain.ReconciliationResult) error {
+func (r *reconciliationRepository) BulkCreateResults(ctx context.Context, results []domain.ReconciliationResult) error {
 	if len(results) == 0 {
 		return nil
 	}
+	// The rows go in one transaction, so a failed attempt inserts nothing
+	// and the whole batch can be sent again
+	return r.retry.do(ctx, "bulk_create_results", func() error {
+		return r.bulkCreateResults(ctx, results)
+	})
+}
 
-	tx, err := r.db.Begin()
+func (r *reconciliationRepository) bulkCreateResults(ctx context.Context, results []domain.ReconciliationResult) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to begin transaction")
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO reconciliation_results (
-			job_id, trx_id, trx_ref_id, system_amount, bank_amount,
-			discrepancy, match_status, bank_source, transaction_date
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO reconciliation_results (`+resultInsertColumns+`)
+		VALUES (`+resultInsertPlaceholders+`)
 	`)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to prepare statement")
@@ -172,19 +419,19 @@ func (r *reconciliationRepository) BulkCreateResults(results []domain.Reconcilia
 	}
 	defer stmt.Close()
 
+	progress := logger.NewProgress("bulk_create_results", r.progressInterval)
 	for _, result := range results {
-		_, err = stmt.Exec(
-			result.JobID,
-			result.TrxID,
-			result.TrxRefID,
-			result.SystemAmount,
-			result.BankAmount,
-			result.Discrepancy,
-			result.MatchStatus,
-			result.BankSource,
-			result.TransactionDate,
-		)
+		_, err = stmt.ExecContext(ctx, resultInsertArgs(&result)...)
+		progress.Add(1)
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The transaction is lost with the connection; give up on it
+			// so the batch is retried whole
+			if IsTransientError(err) {
+				return err
+			}
 			logger.GetLogger().WithError(err).Error("Failed to insert reconciliation result")
 			continue
 		}
@@ -194,20 +441,20 @@ func (r *reconciliationRepository) BulkCreateResults(results []domain.Reconcilia
 		logger.GetLogger().WithError(err).Error("Failed to commit transaction")
 		return err
 	}
+	progress.Done()
 
 	return nil
 }
 
-func (r *reconciliationRepository) GetResultsByJobID(jobID string) ([]domain.ReconciliationResult, error) {
+func (r *reconciliationRepository) GetResultsByJobID(ctx context.Context, jobID string) ([]domain.ReconciliationResult, error) {
 	query := `
-		SELECT id, job_id, trx_id, trx_ref_id, system_amount, bank_amount,
-			   discrepancy, match_status, bank_source, transaction_date, created_at
+		SELECT ` + resultSelectColumns + `
 		FROM reconciliation_results
 		WHERE job_id = $1
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.Query(query, jobID)
+	rows, err := r.db.QueryContext(ctx, query, jobID)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to query reconciliation results")
 		return nil, err
@@ -216,20 +463,7 @@ func (r *reconciliationRepository) GetResultsByJobID(jobID string) ([]domain.Rec
 
 	var results []domain.ReconciliationResult
 	for rows.Next() {
-		var result domain.ReconciliationResult
-		err := rows.Scan(
-			&result.ID,
-			&result.JobID,
-			&result.TrxID,
-			&result.TrxRefID,
-			&result.SystemAmount,
-			&result.BankAmount,
-			&result.Discrepancy,
-			&result.MatchStatus,
-			&result.BankSource,
-			&result.TransactionDate,
-			&result.CreatedAt,
-		)
+		result, err := scanResult(rows)
 		if err != nil {
 			logger.GetLogger().WithError(err).Error("Failed to scan reconciliation result")
 			continue
@@ -240,38 +474,73 @@ func (r *reconciliationRepository) GetResultsByJobID(jobID string) ([]domain.Rec
 	return results, nil
 }
 
-func (r *reconciliationRepository) GetResultsByJobIDAndStatus(jobID string, status domain.MatchStatus) ([]domain.ReconciliationResult, error) {
-	query := `
-		SELECT id, job_id, trx_id, trx_ref_id, system_amount, bank_amount,
-			   discrepancy, match_status, bank_source, transaction_date, created_at
+func (r *reconciliationRepository) QueryResults(ctx context.Context, jobID string, filter domain.ResultFilter, limit, offset int) ([]domain.ReconciliationResult, int, error) {
+	where, args := resultFilterWhere(jobID, filter)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reconciliation_results `+where, args...).Scan(&total); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to count reconciliation results")
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM reconciliation_results
-		WHERE job_id = $1 AND match_status = $2
-		ORDER BY created_at
-	`
+		%s
+		ORDER BY created_at, id
+		LIMIT $%d OFFSET $%d
+	`, resultSelectColumns, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to query reconciliation results")
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := make([]domain.ReconciliationResult, 0, limit)
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to scan reconciliation result")
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, total, rows.Err()
+}
+
+// QueryResultsAfter returns up to limit results matching filter that follow
+// after in (created_at, id) order, or the first ones when after is nil. The
+// row comparison seeks through idx_reconciliation_results_job_created (or
+// idx_reconciliation_results_job_status_created when filtering by status)
+// instead of skipping rows like OFFSET, so deep pages cost the same as the first.
+func (r *reconciliationRepository) QueryResultsAfter(ctx context.Context, jobID string, filter domain.ResultFilter, after *domain.ResultCursor, limit int) ([]domain.ReconciliationResult, error) {
+	where, args := resultFilterWhere(jobID, filter)
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		where += fmt.Sprintf(` AND (created_at, id) > ($%d, $%d)`, len(args)-1, len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM reconciliation_results
+		%s
+		ORDER BY created_at, id
+		LIMIT $%d
+	`, resultSelectColumns, where, len(args)+1)
 
-	rows, err := r.db.Query(query, jobID, status)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit)...)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to query reconciliation results")
 		return nil, err
 	}
 	defer rows.Close()
 
-	var results []domain.ReconciliationResult
+	results := make([]domain.ReconciliationResult, 0, limit)
 	for rows.Next() {
-		var result domain.ReconciliationResult
-		err := rows.Scan(
-			&result.ID,
-			&result.JobID,
-			&result.TrxID,
-			&result.TrxRefID,
-			&result.SystemAmount,
-			&result.BankAmount,
-			&result.Discrepancy,
-			&result.MatchStatus,
-			&result.BankSource,
-			&result.TransactionDate,
-			&result.CreatedAt,
-		)
+		result, err := scanResult(rows)
 		if err != nil {
 			logger.GetLogger().WithError(err).Error("Failed to scan reconciliation result")
 			continue
@@ -279,5 +548,205 @@ func (r *reconciliationRepository) GetResultsByJobIDAndStatus(jobID string, stat
 		results = append(results, result)
 	}
 
-	return results, nil
+	return results, rows.Err()
+}
+
+// resultFilterWhere builds the WHERE clause and arguments selecting a job's
+// results matching filter
+func resultFilterWhere(jobID string, filter domain.ResultFilter) (string, []interface{}) {
+	where := `WHERE job_id = $1`
+	args := []interface{}{jobID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(` AND match_status = $%d`, len(args))
+	}
+	if filter.BankSource != "" {
+		args = append(args, filter.BankSource)
+		where += fmt.Sprintf(` AND bank_source = $%d`, len(args))
+	}
+	if filter.TrxID != "" {
+		args = append(args, filter.TrxID)
+		where += fmt.Sprintf(` AND trx_id = $%d`, len(args))
+	}
+	if filter.TrxRefID != "" {
+		args = append(args, filter.TrxRefID)
+		where += fmt.Sprintf(` AND trx_ref_id = $%d`, len(args))
+	}
+	if filter.MinAmount != nil {
+		args = append(args, *filter.MinAmount)
+		where += fmt.Sprintf(` AND ABS(COALESCE(system_amount, bank_amount)) >= $%d`, len(args))
+	}
+	if filter.MaxAmount != nil {
+		args = append(args, *filter.MaxAmount)
+		where += fmt.Sprintf(` AND ABS(COALESCE(system_amount, bank_amount)) <= $%d`, len(args))
+	}
+	if filter.MinDiscrepancy != nil {
+		args = append(args, *filter.MinDiscrepancy)
+		where += fmt.Sprintf(` AND ABS(discrepancy) >= $%d`, len(args))
+	}
+	if filter.MinConfidence != nil {
+		args = append(args, *filter.MinConfidence)
+		where += fmt.Sprintf(` AND confidence >= $%d`, len(args))
+	}
+	if filter.UnmatchedBefore != nil {
+		args = append(args, *filter.UnmatchedBefore)
+		where += fmt.Sprintf(` AND match_status IN ('UNMATCHED_SYSTEM', 'UNMATCHED_BANK') AND transaction_date < $%d`, len(args))
+	}
+	return where, args
+}
+
+func (r *reconciliationRepository) GetResultCountsByStatus(ctx context.Context, jobID string) ([]domain.ResultCount, error) {
+	query := `
+		SELECT COALESCE(bank_source, ''), match_status, COUNT(*)
+		FROM reconciliation_results
+		WHERE job_id = $1
+		GROUP BY COALESCE(bank_source, ''), match_status
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, jobID)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to count reconciliation results")
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]domain.ResultCount, 0)
+	for rows.Next() {
+		var count domain.ResultCount
+		if err := rows.Scan(&count.BankSource, &count.MatchStatus, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// GetResultsByJobIDStream processes a job's results in batches to avoid loading all into memory
+func (r *reconciliationRepository) GetResultsByJobIDStream(ctx context.Context, jobID string, batchSize int, callback func([]domain.ReconciliationResult) error) error {
+	query := `
+		SELECT ` + resultSelectColumns + `
+		FROM reconciliation_results
+		WHERE job_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, jobID)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to query reconciliation results")
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]domain.ReconciliationResult, 0, batchSize)
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to scan reconciliation result")
+			continue
+		}
+
+		batch = append(batch, result)
+
+		if len(batch) >= batchSize {
+			if err := callback(batch); err != nil {
+				return err
+			}
+			batch = make([]domain.ReconciliationResult, 0, batchSize)
+		}
+	}
+
+	// Process remaining items
+	if len(batch) > 0 {
+		if err := callback(batch); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *reconciliationRepository) ReplaceUnmatchedResults(
+	ctx context.Context,
+	jobID string,
+	removedIDs []int,
+	added []domain.ReconciliationResult,
+	delta domain.JobTotalsDelta,
+) (*domain.ReconciliationJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Updating the job first locks its row, so replacements of one job's
+	// results run one after another
+	job, err := scanJob(tx.QueryRowContext(ctx, `
+		UPDATE reconciliation_jobs
+		SET total_processed = total_processed + $1, total_matched = total_matched + $2,
+			total_unmatched = total_unmatched + $3, total_discrepancies = total_discrepancies + $4,
+			net_discrepancy = net_discrepancy + $5
+		WHERE job_id = $6 AND deleted_at IS NULL AND status = $7
+		RETURNING `+jobSelectColumns,
+		delta.Processed, delta.Matched, delta.Unmatched, delta.TotalDiscrepancies, delta.NetDiscrepancy,
+		jobID, domain.Completed,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("completed reconciliation job not found")
+	}
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to update reconciliation job totals")
+		return nil, err
+	}
+
+	remove, err := tx.PrepareContext(ctx, `
+		DELETE FROM reconciliation_results
+		WHERE job_id = $1 AND id = $2 AND match_status IN ($3, $4)
+	`)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to prepare statement")
+		return nil, err
+	}
+	defer remove.Close()
+
+	for _, id := range removedIDs {
+		res, err := remove.ExecContext(ctx, jobID, id, domain.UnmatchedSystem, domain.UnmatchedBank)
+		if err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to delete reconciliation result")
+			return nil, err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if affected == 0 {
+			return nil, ErrResultsChanged
+		}
+	}
+
+	insert, err := tx.PrepareContext(ctx, `
+		INSERT INTO reconciliation_results (`+resultInsertColumns+`)
+		VALUES (`+resultInsertPlaceholders+`)
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to prepare statement")
+		return nil, err
+	}
+	defer insert.Close()
+
+	// Unlike BulkCreateResults, a failed insert aborts: the removed results
+	// must not disappear without their replacements
+	for i := range added {
+		if err := insert.QueryRowContext(ctx, resultInsertArgs(&added[i])...).Scan(&added[i].ID, &added[i].CreatedAt); err != nil {
+			logger.GetLogger().WithError(err).Error("Failed to insert reconciliation result")
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to commit transaction")
+		return nil, err
+	}
+	return job, nil
 }
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"recon-engine/pkg/logger"
+)
+
+// transientCodes are the Postgres error codes worth retrying: the server was
+// briefly unable to take the connection or the transaction lost a conflict
+var transientCodes = map[pq.ErrorCode]bool{
+	"53300": true, // too_many_connections
+	"57P03": true, // cannot_connect_now
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// IsTransientError reports whether a failed write may succeed if repeated:
+// connection exceptions (class 08), too many connections, a server still
+// starting up, serialization failures, deadlocks and dropped connections
+func IsTransientError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return transientCodes[pqErr.Code] || strings.HasPrefix(string(pqErr.Code), "08")
+}
+
+// retryPolicy repeats writes failing with transient errors, doubling the
+// wait from backoff up to maxBackoff with jitter between attempts
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// WithRetry makes up to maxAttempts tries of each job and result write,
+// waiting from backoff, doubling up to maxBackoff, between attempts. One
+// attempt (the default) disables retries.
+func WithRetry(maxAttempts int, backoff, maxBackoff time.Duration) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.retry = retryPolicy{maxAttempts: maxAttempts, backoff: backoff, maxBackoff: maxBackoff}
+	}
+}
+
+// do runs write until it succeeds, fails permanently, runs out of attempts
+// or ctx is done. write must be safe to repeat: a failed attempt must leave
+// nothing behind, as a rolled-back transaction does.
+func (p retryPolicy) do(ctx context.Context, operation string, write func() error) error {
+	wait := p.backoff
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err == nil || attempt >= p.maxAttempts || !IsTransientError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := jitter(wait)
+		logger.GetLogger().WithError(err).WithFields(map[string]interface{}{
+			"operation": operation,
+			"attempt":   attempt,
+			"delay":     delay.String(),
+		}).Warn("Transient database error, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		wait *= 2
+		if p.maxBackoff > 0 && wait > p.maxBackoff {
+			wait = p.maxBackoff
+		}
+	}
+}
+
+// jitter picks a wait between half and all of wait, so writers that failed
+// together do not retry together
+func jitter(wait time.Duration) time.Duration {
+	if wait <= 1 {
+		return wait
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -9,35 +10,46 @@ import (
 	"recon-engine/pkg/logger"
 )
 
+// TransactionRepository date ranges are half-open: start <= transaction_time < end
 type TransactionRepository interface {
-	Create(tx *domain.Transaction) error
-	BulkCreate(transactions []domain.Transaction) error
-	GetByTrxID(trxID string) (*domain.Transaction, error)
-	GetByDateRange(startDate, endDate time.Time) ([]domain.Transaction, error)
-	GetByDateRangeStream(startDate, endDate time.Time, batchSize int, callback func([]domain.Transaction) error) error
+	Create(ctx context.Context, tx *domain.Transaction) error
+	// BulkCreate reports whether each row was inserted, skipped as a
+	// duplicate or failed
+	BulkCreate(ctx context.Context, transactions []domain.Transaction) (*domain.BulkCreateReport, error)
+	GetByTrxID(ctx context.Context, trxID string) (*domain.Transaction, error)
+	// Update overwrites the amount, type, time, order ID and description of the transaction with tx.TrxID
+	Update(ctx context.Context, tx *domain.Transaction) error
+	Delete(ctx context.Context, trxID string) error
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]domain.Transaction, error)
+	GetByDateRangeStream(ctx context.Context, startDate, endDate time.Time, batchSize int, callback func([]domain.Transaction) error) error
 }
 
 type transactionRepository struct {
-	db *sql.DB
+	db               *sql.DB
+	progressInterval int
 }
 
-func NewTransactionRepository(db *sql.DB) TransactionRepository {
-	return &transactionRepository{db: db}
+func NewTransactionRepository(db *sql.DB, opts ...RepositoryOption) TransactionRepository {
+	o := newRepositoryOptions(opts)
+	return &transactionRepository{db: db, progressInterval: o.progressInterval}
 }
 
-func (r *transactionRepository) Create(tx *domain.Transaction) error {
+func (r *transactionRepository) Create(ctx context.Context, tx *domain.Transaction) error {
 	query := `
-		INSERT INTO transactions (trx_id, amount, type, transaction_time)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO transactions (trx_id, amount, type, transaction_time, order_id, description)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(
+		ctx,
 		query,
 		tx.TrxID,
 		tx.Amount,
 		tx.Type,
 		tx.TransactionTime,
+		tx.OrderID,
+		tx.Description,
 	).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt)
 
 	if err != nil {
@@ -48,64 +60,110 @@ func (r *transactionRepository) Create(tx *domain.Transaction) error {
 	return nil
 }
 
-func (r *transactionRepository) BulkCreate(transactions []domain.Transaction) error {
+// BulkCreate inserts the transactions in one database transaction and
+// reports each row. A trx_id already stored, or repeated earlier in the
+// batch, is skipped. A row the database rejects is rolled back to its
+// savepoint and reported as failed without aborting the others.
+func (r *transactionRepository) BulkCreate(ctx context.Context, transactions []domain.Transaction) (*domain.BulkCreateReport, error) {
+	report := &domain.BulkCreateReport{Rows: make([]domain.BulkRowOutcome, 0, len(transactions))}
 	if len(transactions) == 0 {
-		return nil
+		return report, nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to begin transaction")
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO transactions (trx_id, amount, type, transaction_time)
-		VALUES ($1, $2, $3, $4)
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO transactions (trx_id, amount, type, transaction_time, order_id, description)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
 		ON CONFLICT (trx_id) DO NOTHING
 	`)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to prepare statement")
-		return err
+		return nil, err
 	}
 	defer stmt.Close()
 
-	for _, transaction := range transactions {
-		_, err = stmt.Exec(
-			transaction.TrxID,
-			transaction.Amount,
-			transaction.Type,
-			transaction.TransactionTime,
-		)
+	progress := logger.NewProgress("bulk_create_transactions", r.progressInterval)
+	for i, transaction := range transactions {
+		outcome, err := r.insertRow(ctx, tx, stmt, transaction)
 		if err != nil {
-			logger.GetLogger().WithError(err).WithField("trx_id", transaction.TrxID).Error("Failed to insert transaction")
-			continue // Continue with next transaction instead of breaking
+			return nil, err
 		}
+		outcome.Index = i
+		report.Add(outcome)
+		progress.Add(1)
 	}
 
 	if err := tx.Commit(); err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to commit transaction")
-		return err
+		return nil, err
 	}
+	progress.Done()
 
-	return nil
+	return report, nil
 }
 
-func (r *transactionRepository) GetByTrxID(trxID string) (*domain.Transaction, error) {
+// insertRow inserts one transaction behind a savepoint, since a failed
+// statement would otherwise abort the whole database transaction. The error
+// is set only when the batch cannot go on.
+func (r *transactionRepository) insertRow(ctx context.Context, tx *sql.Tx, stmt *sql.Stmt, transaction domain.Transaction) (domain.BulkRowOutcome, error) {
+	outcome := domain.BulkRowOutcome{TrxID: transaction.TrxID, Status: domain.BulkRowInserted}
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_row"); err != nil {
+		return outcome, err
+	}
+
+	res, err := stmt.ExecContext(
+		ctx,
+		transaction.TrxID,
+		transaction.Amount,
+		transaction.Type,
+		transaction.TransactionTime,
+		transaction.OrderID,
+		transaction.Description,
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			return outcome, ctx.Err()
+		}
+		logger.GetLogger().WithError(err).WithField("trx_id", transaction.TrxID).Error("Failed to insert transaction")
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_row"); rollbackErr != nil {
+			return outcome, rollbackErr
+		}
+		outcome.Status = domain.BulkRowFailed
+		outcome.Reason = err.Error()
+		return outcome, nil
+	}
+
+	if affected, err := res.RowsAffected(); err != nil {
+		return outcome, err
+	} else if affected == 0 {
+		outcome.Status = domain.BulkRowDuplicate
+	}
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_row")
+	return outcome, err
+}
+
+func (r *transactionRepository) GetByTrxID(ctx context.Context, trxID string) (*domain.Transaction, error) {
 	query := `
-		SELECT id, trx_id, amount, type, transaction_time, created_at, updated_at
+		SELECT id, trx_id, amount, type, transaction_time, COALESCE(order_id, ''), COALESCE(description, ''), created_at, updated_at
 		FROM transactions
 		WHERE trx_id = $1
 	`
 
 	var tx domain.Transaction
-	err := r.db.QueryRow(query, trxID).Scan(
+	err := r.db.QueryRowContext(ctx, query, trxID).Scan(
 		&tx.ID,
 		&tx.TrxID,
 		&tx.Amount,
 		&tx.Type,
 		&tx.TransactionTime,
+		&tx.OrderID,
+		&tx.Description,
 		&tx.CreatedAt,
 		&tx.UpdatedAt,
 	)
@@ -121,15 +179,60 @@ func (r *transactionRepository) GetByTrxID(trxID string) (*domain.Transaction, e
 	return &tx, nil
 }
 
-func (r *transactionRepository) GetByDateRange(startDate, endDate time.Time) ([]domain.Transaction, error) {
+func (r *transactionRepository) Update(ctx context.Context, tx *domain.Transaction) error {
+	query := `
+		UPDATE transactions
+		SET amount = $2, type = $3, transaction_time = $4, order_id = NULLIF($5, ''), description = NULLIF($6, '')
+		WHERE trx_id = $1
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		tx.TrxID,
+		tx.Amount,
+		tx.Type,
+		tx.TransactionTime,
+		tx.OrderID,
+		tx.Description,
+	).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("transaction not found")
+	}
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to update transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) Delete(ctx context.Context, trxID string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM transactions WHERE trx_id = $1`, trxID)
+	if err != nil {
+		logger.GetLogger().WithError(err).Error("Failed to delete transaction")
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("transaction not found")
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]domain.Transaction, error) {
 	query := `
-		SELECT id, trx_id, amount, type, transaction_time, created_at, updated_at
+		SELECT id, trx_id, amount, type, transaction_time, COALESCE(order_id, ''), COALESCE(description, ''), created_at, updated_at
 		FROM transactions
-		WHERE transaction_time >= $1 AND transaction_time <= $2
+		WHERE transaction_time >= $1 AND transaction_time < $2
 		ORDER BY transaction_time
 	`
 
-	rows, err := r.db.Query(query, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to query transactions")
 		return nil, err
@@ -145,6 +248,8 @@ func (r *transactionRepository) GetByDateRange(startDate, endDate time.Time) ([]
 			&tx.Amount,
 			&tx.Type,
 			&tx.TransactionTime,
+			&tx.OrderID,
+			&tx.Description,
 			&tx.CreatedAt,
 			&tx.UpdatedAt,
 		)
@@ -159,15 +264,15 @@ func (r *transactionRepository) GetByDateRange(startDate, endDate time.Time) ([]
 }
 
 // GetByDateRangeStream processes transactions in batches to avoid loading all into memory
-func (r *transactionRepository) GetByDateRangeStream(startDate, endDate time.Time, batchSize int, callback func([]domain.Transaction) error) error {
+func (r *transactionRepository) GetByDateRangeStream(ctx context.Context, startDate, endDate time.Time, batchSize int, callback func([]domain.Transaction) error) error {
 	query := `
-		SELECT id, trx_id, amount, type, transaction_time, created_at, updated_at
+		SELECT id, trx_id, amount, type, transaction_time, COALESCE(order_id, ''), COALESCE(description, ''), created_at, updated_at
 		FROM transactions
-		WHERE transaction_time >= $1 AND transaction_time <= $2
+		WHERE transaction_time >= $1 AND transaction_time < $2
 		ORDER BY transaction_time
 	`
 
-	rows, err := r.db.Query(query, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to query transactions")
 		return err
@@ -183,6 +288,8 @@ func (r *transactionRepository) GetByDateRangeStream(startDate, endDate time.Tim
 			&tx.Amount,
 			&tx.Type,
 			&tx.TransactionTime,
+			&tx.OrderID,
+			&tx.Description,
 			&tx.CreatedAt,
 			&tx.UpdatedAt,
 		)
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+	"recon-engine/pkg/logger"
+)
+
+// defaultMaxArchiveSize caps the uncompressed size of one bank archive at 1 GB
+const defaultMaxArchiveSize int64 = 1 << 30
+
+// archiveEntryTypes are the bank file extensions read from inside an archive
+var archiveEntryTypes = map[string]bool{".csv": true, ".xlsx": true, ".jsonl": true, ".ndjson": true}
+
+// WithMaxArchiveSize limits the total uncompressed bytes extracted from a
+// single bank archive, guarding against zip bombs
+func WithMaxArchiveSize(bytes int64) ServiceOption {
+	return func(s *reconciliationService) {
+		if bytes > 0 {
+			s.maxArchiveSize = bytes
+		}
+	}
+}
+
+// bankFile is a bank file to load and the name it is reported under
+type bankFile struct {
+	path string
+	name string
+}
+
+// expandBankFiles replaces each .zip in paths with the bank files it holds,
+// extracted to a temporary directory, so every entry becomes its own bank
+// source named after the entry. Entries are reported as archive/entry.
+// Unreadable archives are logged and returned in failed, like unreadable bank
+// files. cleanup removes the extracted files.
+func (s *reconciliationService) expandBankFiles(paths []string) (expanded []bankFile, failed []domain.FileLoadReport, cleanup func()) {
+	var tempDirs []string
+	cleanup = func() {
+		for _, dir := range tempDirs {
+			os.RemoveAll(dir)
+		}
+	}
+
+	for _, p := range paths {
+		if !strings.EqualFold(filepath.Ext(p), ".zip") {
+			expanded = append(expanded, bankFile{path: p, name: p})
+			continue
+		}
+
+		dir, err := os.MkdirTemp("", "recon-archive-*")
+		if err != nil {
+			logger.GetLogger().WithError(err).WithField("file", p).Warn("Failed to create archive directory")
+			failed = append(failed, domain.FileLoadReport{File: p, Error: err.Error()})
+			continue
+		}
+		tempDirs = append(tempDirs, dir)
+
+		entries, err := extractArchive(p, dir, s.maxArchiveSize, s.parserOpts)
+		if err != nil {
+			logger.GetLogger().WithError(err).WithField("file", p).Warn("Failed to expand bank archive")
+			failed = append(failed, domain.FileLoadReport{File: p, Error: err.Error()})
+			continue
+		}
+		logger.GetLogger().WithFields(map[string]interface{}{
+			"file":    p,
+			"entries": len(entries),
+		}).Info("Expanded bank archive")
+		expanded = append(expanded, entries...)
+	}
+	return expanded, failed, cleanup
+}
+
+// extractArchive writes the bank files in the zip at archivePath under dir
+// and returns them in archive order. Each entry gets its own
+// subdirectory so entries with the same base name in different folders keep
+// their names without colliding. An archive given by URL is read through the
+// parsers' file opener.
+func extractArchive(archivePath, dir string, maxSize int64, opts []parser.ParserOption) ([]bankFile, error) {
+	archive, closer, err := parser.OpenZip(archivePath, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer closer.Close()
+
+	var files []bankFile
+	remaining := maxSize
+	for i, entry := range archive.File {
+		if !isBankEntry(entry) {
+			continue
+		}
+
+		entryDir := filepath.Join(dir, strconv.Itoa(i))
+		if err := os.Mkdir(entryDir, 0o700); err != nil {
+			return nil, err
+		}
+		target := filepath.Join(entryDir, path.Base(entry.Name))
+
+		written, err := extractEntry(entry, target, remaining)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+		}
+		remaining -= written
+		files = append(files, bankFile{path: target, name: archivePath + "/" + entry.Name})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("archive contains no .csv, .xlsx or .jsonl files")
+	}
+	return files, nil
+}
+
+// isBankEntry skips directories, nested archives and OS metadata such as
+// __MACOSX folders and dot files
+func isBankEntry(entry *zip.File) bool {
+	if entry.FileInfo().IsDir() || strings.HasPrefix(entry.Name, "__MACOSX/") {
+		return false
+	}
+	name := path.Base(entry.Name)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return archiveEntryTypes[strings.ToLower(path.Ext(name))]
+}
+
+// extractEntry copies one entry to target, failing once more than limit
+// bytes have been read; the size in the zip header is not trusted
+func extractEntry(entry *zip.File, target string, limit int64) (int64, error) {
+	src, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+
+	written, err := io.Copy(dst, io.LimitReader(src, limit+1))
+	if err != nil {
+		return 0, err
+	}
+	if written > limit {
+		return 0, fmt.Errorf("archive exceeds the %d byte uncompressed limit", limit)
+	}
+	return written, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/repository"
+	"recon-engine/internal/storage"
+	"recon-engine/pkg/logger"
+)
+
+// ErrResultNotFound is returned when an attachment targets an unknown result
+var ErrResultNotFound = errors.New("reconciliation result not found")
+
+type AttachmentService interface {
+	Upload(ctx context.Context, resultID int, fileName, contentType, description string, content io.Reader) (*domain.Attachment, error)
+	List(ctx context.Context, resultID int) ([]domain.Attachment, error)
+}
+
+type attachmentService struct {
+	repo  repository.AttachmentRepository
+	store storage.FileStore
+}
+
+func NewAttachmentService(repo repository.AttachmentRepository, store storage.FileStore) AttachmentService {
+	return &attachmentService{repo: repo, store: store}
+}
+
+// Upload stores the file and records its metadata against the result
+func (s *attachmentService) Upload(ctx context.Context, resultID int, fileName, contentType, description string, content io.Reader) (*domain.Attachment, error) {
+	if err := s.requireResult(ctx, resultID); err != nil {
+		return nil, err
+	}
+
+	fileName = filepath.Base(strings.TrimSpace(fileName))
+	if fileName == "." || fileName == string(filepath.Separator) {
+		return nil, fmt.Errorf("file name is required")
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// The stored name is generated so uploads never collide or escape the store
+	key := path.Join("results", fmt.Sprint(resultID), uuid.New().String()+strings.ToLower(filepath.Ext(fileName)))
+	size, err := s.store.Save(key, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment := &domain.Attachment{
+		ResultID:    resultID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		Description: strings.TrimSpace(description),
+		StorageKey:  key,
+	}
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		if delErr := s.store.Delete(key); delErr != nil {
+			logger.GetLogger().WithError(delErr).WithField("key", key).Warn("Failed to remove orphaned attachment")
+		}
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	logger.GetLogger().WithFields(map[string]interface{}{
+		"result_id":     resultID,
+		"attachment_id": attachment.ID,
+		"size":          size,
+	}).Info("Attachment uploaded")
+
+	return attachment, nil
+}
+
+func (s *attachmentService) List(ctx context.Context, resultID int) ([]domain.Attachment, error) {
+	if err := s.requireResult(ctx, resultID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByResultID(ctx, resultID)
+}
+
+func (s *attachmentService) requireResult(ctx context.Context, resultID int) error {
+	exists, err := s.repo.ResultExists(ctx, resultID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrResultNotFound
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+	"recon-engine/pkg/logger"
+	"recon-engine/pkg/tracing"
+)
+
+// defaultBankFileWorkers is how many bank files are parsed at once unless
+// WithBankFileWorkers says otherwise
+const defaultBankFileWorkers = 4
+
+// WithBankFileWorkers sets how many bank files a reconcile parses at once.
+// n <= 0 uses runtime.NumCPU() and 1 loads them one after another.
+func WithBankFileWorkers(n int) ServiceOption {
+	return func(s *reconciliationService) {
+		if n <= 0 {
+			n = runtime.NumCPU()
+		}
+		s.bankFileWorkers = n
+	}
+}
+
+// bankFileLoad is the outcome of loading one bank file
+type bankFileLoad struct {
+	statements []domain.BankStatement
+	report     domain.FileLoadReport
+}
+
+// loadBankFiles loads the statements of every bank file, counting each zip
+// archive entry as a file. A file that fails is reported and skipped.
+// Files are parsed by up to bankFileWorkers goroutines, but statements and
+// reports are merged in file order, so duplicate references resolve the
+// same way for any worker count.
+func (s *reconciliationService) loadBankFiles(ctx context.Context, bankFilePaths []string) ([]domain.BankStatement, []domain.FileLoadReport) {
+	bankFiles, loadReport, cleanup := s.expandBankFiles(bankFilePaths)
+	defer cleanup()
+
+	loads := make([]bankFileLoad, len(bankFiles))
+	workers := min(s.bankFileWorkers, len(bankFiles))
+	if workers <= 1 {
+		for i, bankFile := range bankFiles {
+			loads[i] = s.loadBankFile(ctx, bankFile)
+		}
+	} else {
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					loads[i] = s.loadBankFile(ctx, bankFiles[i])
+				}
+			}()
+		}
+		for i := range bankFiles {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	var statements []domain.BankStatement
+	for _, load := range loads {
+		statements = append(statements, load.statements...)
+		loadReport = append(loadReport, load.report)
+	}
+	return statements, loadReport
+}
+
+// loadBankFile parses one bank file, reporting its rows or why it failed
+func (s *reconciliationService) loadBankFile(ctx context.Context, bankFile bankFile) bankFileLoad {
+	_, span := tracing.Start(ctx, "reconcile.load_bank_file", attribute.String("file", bankFile.name))
+	source := s.bankSource(bankFile.path)
+	var parseReport parser.ParseReport
+	scoped := *s
+	scoped.parserOpts = s.reportingParserOpts(&parseReport)
+	statements, err := scoped.loadBankStatementsFromFile(bankFile.path, source)
+	span.SetAttributes(attribute.String("source", source), attribute.Int("rows", len(statements)))
+	tracing.End(span, err)
+
+	report := domain.FileLoadReport{File: bankFile.name, Source: source, SkippedRows: parseReport.SkippedRows, RowErrors: parseReport.RowErrors}
+	if err != nil {
+		logger.GetLogger().WithError(err).WithField("file", bankFile.name).Warn("Failed to load bank statements")
+		report.Error = err.Error()
+		return bankFileLoad{report: report}
+	}
+	report.Rows = len(statements)
+	report.Empty = report.Rows == 0
+	return bankFileLoad{statements: statements, report: report}
+}
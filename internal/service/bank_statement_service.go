@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/repository"
+)
+
+type BankStatementService interface {
+	// GetByDateRangeAndSource lists stored statements with startDate <= date
+	// < endDate; an empty source lists every source
+	GetByDateRangeAndSource(ctx context.Context, startDate, endDate time.Time, source string) ([]domain.BankStatement, error)
+}
+
+type bankStatementService struct {
+	repo repository.BankStatementRepository
+}
+
+func NewBankStatementService(repo repository.BankStatementRepository) BankStatementService {
+	return &bankStatementService{repo: repo}
+}
+
+func (s *bankStatementService) GetByDateRangeAndSource(ctx context.Context, startDate, endDate time.Time, source string) ([]domain.BankStatement, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date cannot be after end date")
+	}
+	return s.repo.GetByDateRangeAndSource(ctx, startDate, endDate, source)
+}
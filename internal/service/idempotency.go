@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/repository"
+	"recon-engine/pkg/logger"
+)
+
+// ErrIdempotencyKeyInUse is returned when a request repeats an idempotency
+// key whose job is still processing
+var ErrIdempotencyKeyInUse = errors.New("a request with this idempotency key is still processing")
+
+// WithIdempotencyStore lets ForIdempotencyKey deduplicate submissions. A key
+// is remembered for ttl after the job it started was created.
+func WithIdempotencyStore(repo repository.IdempotencyRepository, ttl time.Duration) ServiceOption {
+	return func(s *reconciliationService) {
+		s.idempotencyRepo = repo
+		s.idempotencyTTL = ttl
+	}
+}
+
+func (s *reconciliationService) ForIdempotencyKey(key string) ReconciliationService {
+	if key == "" || s.idempotencyRepo == nil {
+		return s
+	}
+	scoped := *s
+	scoped.idempotencyKey = key
+	return &scoped
+}
+
+// claimIdempotencyKey records the key for jobID, returning the job ID of an
+// earlier unexpired submission with the same key instead
+func (s *reconciliationService) claimIdempotencyKey(ctx context.Context, jobID string) (string, error) {
+	if s.idempotencyKey == "" {
+		return "", nil
+	}
+	return s.idempotencyRepo.Claim(ctx, s.idempotencyKey, jobID, time.Now().Add(s.idempotencyTTL))
+}
+
+// releaseIdempotencyKey lets a request whose job failed be submitted again.
+// It runs even when ctx is done, since a timeout is often why the job failed.
+func (s *reconciliationService) releaseIdempotencyKey(ctx context.Context) {
+	if s.idempotencyKey == "" {
+		return
+	}
+	if err := s.idempotencyRepo.Release(context.WithoutCancel(ctx), s.idempotencyKey); err != nil {
+		logger.GetLogger().WithError(err).Warn("Failed to release idempotency key")
+	}
+}
+
+// replayJob answers a repeated submission with the summary of the job the
+// first one started
+func (s *reconciliationService) replayJob(ctx context.Context, jobID string) (*domain.ReconciliationSummary, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == domain.Processing {
+		return nil, ErrIdempotencyKeyInUse
+	}
+
+	summary, err := s.GetJobSummary(ctx, jobID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	summary.Replayed = true
+	logger.GetLogger().WithField("job_id", jobID).Info("Replaying reconciliation for repeated idempotency key")
+	return summary, nil
+}
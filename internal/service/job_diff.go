@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"recon-engine/internal/domain"
+)
+
+// DiffJobs compares the results of jobID with those of otherJobID, usually
+// a later run. Only the days both jobs cover are compared, so a difference
+// in date ranges does not show up as items appearing or disappearing.
+func (s *reconciliationService) DiffJobs(ctx context.Context, jobID, otherJobID string) (*domain.JobDiff, error) {
+	job, err := s.completedJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	other, err := s.completedJob(ctx, otherJobID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &domain.JobDiff{
+		JobID:                 jobID,
+		OtherJobID:            otherJobID,
+		NewlyMatched:          make([]domain.JobDiffItem, 0),
+		NewlyUnmatched:        make([]domain.JobDiffItem, 0),
+		StillUnmatched:        make([]domain.JobDiffItem, 0),
+		ResolvedDiscrepancies: make([]domain.JobDiffItem, 0),
+	}
+	overlap := newDateOverlap(job, other)
+	if overlap != nil {
+		diff.OverlapStart, diff.OverlapEnd = &overlap.start, &overlap.end
+	}
+
+	// The first result recorded for an item is its own; duplicates follow it
+	previous := make(map[string]domain.MatchStatus)
+	err = s.reconRepo.GetResultsByJobIDStream(ctx, jobID, s.batchSize, func(batch []domain.ReconciliationResult) error {
+		for _, result := range batch {
+			if !overlap.covers(result) {
+				diff.OutOfRange++
+				continue
+			}
+			for _, key := range diffKeys(result) {
+				if _, seen := previous[key]; !seen {
+					previous[key] = result.MatchStatus
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results of job %s: %w", jobID, err)
+	}
+
+	err = s.reconRepo.GetResultsByJobIDStream(ctx, otherJobID, s.batchSize, func(batch []domain.ReconciliationResult) error {
+		for _, result := range batch {
+			if !overlap.covers(result) {
+				diff.OutOfRange++
+				continue
+			}
+			addToDiff(diff, previousStatus(previous, result), result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results of job %s: %w", otherJobID, err)
+	}
+
+	return diff, nil
+}
+
+// completedJob loads a job, failing with ErrJobNotCompleted unless it completed
+func (s *reconciliationService) completedJob(ctx context.Context, jobID string) (*domain.ReconciliationJob, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.Completed {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotCompleted, jobID)
+	}
+	return job, nil
+}
+
+// dateOverlap holds the days two jobs both cover, end inclusive
+type dateOverlap struct {
+	start, end time.Time
+}
+
+// newDateOverlap returns nil when the date ranges of a and b do not overlap
+func newDateOverlap(a, b *domain.ReconciliationJob) *dateOverlap {
+	overlap := &dateOverlap{start: a.StartDate, end: a.EndDate}
+	if b.StartDate.After(overlap.start) {
+		overlap.start = b.StartDate
+	}
+	if b.EndDate.Before(overlap.end) {
+		overlap.end = b.EndDate
+	}
+	if overlap.start.After(overlap.end) {
+		return nil
+	}
+	return overlap
+}
+
+// covers reports whether a result falls within the overlap. Results without
+// a date cannot be placed and are always compared.
+func (o *dateOverlap) covers(result domain.ReconciliationResult) bool {
+	if o == nil {
+		return false
+	}
+	if result.TransactionDate == nil {
+		return true
+	}
+	return !result.TransactionDate.Before(o.start) && result.TransactionDate.Before(domain.DayAfter(o.end))
+}
+
+// diffKeys identifies the system transaction and bank statement a result
+// covers. Bank statements are keyed without their amount, which may be what
+// changed; statements without a reference cannot be followed across jobs.
+func diffKeys(result domain.ReconciliationResult) []string {
+	var keys []string
+	if result.TrxID != nil {
+		keys = append(keys, "system\xff"+*result.TrxID)
+	}
+	if result.TrxRefID != nil && *result.TrxRefID != "" {
+		source := ""
+		if result.BankSource != nil {
+			source = *result.BankSource
+		}
+		keys = append(keys, "bank\xff"+source+"\xff"+*result.TrxRefID)
+	}
+	return keys
+}
+
+// previousStatus returns the first job's status for the item of result, or
+// "" when that job has no result for it
+func previousStatus(previous map[string]domain.MatchStatus, result domain.ReconciliationResult) domain.MatchStatus {
+	for _, key := range diffKeys(result) {
+		if status, ok := previous[key]; ok {
+			return status
+		}
+	}
+	return ""
+}
+
+func addToDiff(diff *domain.JobDiff, before domain.MatchStatus, result domain.ReconciliationResult) {
+	item := domain.JobDiffItem{
+		TrxID:          result.TrxID,
+		TrxRefID:       result.TrxRefID,
+		BankSource:     result.BankSource,
+		PreviousStatus: before,
+		Status:         result.MatchStatus,
+	}
+
+	switch {
+	case result.MatchStatus == domain.Matched && before == domain.Discrepancy:
+		diff.ResolvedDiscrepancies = append(diff.ResolvedDiscrepancies, item)
+	case result.MatchStatus == domain.Matched && before != "" && before != domain.Matched:
+		diff.NewlyMatched = append(diff.NewlyMatched, item)
+	case unmatchedStatus(result.MatchStatus) && unmatchedStatus(before):
+		diff.StillUnmatched = append(diff.StillUnmatched, item)
+	case unmatchedStatus(result.MatchStatus):
+		diff.NewlyUnmatched = append(diff.NewlyUnmatched, item)
+	}
+}
+
+func unmatchedStatus(status domain.MatchStatus) bool {
+	return status == domain.UnmatchedSystem || status == domain.UnmatchedBank
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+// MatchedViaManual tags the results reviewers pair by hand
+const MatchedViaManual = "manual"
+
+var (
+	// ErrNotUnmatched is returned when a manual match names a reference the
+	// job has no unmatched result for, e.g. because it is already matched
+	ErrNotUnmatched = errors.New("no unmatched result")
+	// ErrAmbiguousManualMatch is returned when several unmatched bank results
+	// share the reference and no bank source picks one
+	ErrAmbiguousManualMatch = errors.New("several unmatched bank results share the reference")
+)
+
+// ManualMatch pairs a completed job's UNMATCHED_SYSTEM result for match.TrxID
+// with its UNMATCHED_BANK result for match.TrxRefID. Both are replaced by one
+// MATCHED result flagged as manual, and the job's totals move one pair from
+// unmatched to matched. The amounts need not agree: the reviewer's pairing
+// stands and both amounts are kept on the result.
+func (s *reconciliationService) ManualMatch(ctx context.Context, jobID string, match domain.ManualMatch) (*domain.ManualMatchResult, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.Completed {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotCompleted, jobID)
+	}
+	if job.ResultChainHead != nil {
+		return nil, ErrJobChained
+	}
+
+	system, err := s.unmatchedResult(ctx, jobID, domain.ResultFilter{Status: domain.UnmatchedSystem, TrxID: match.TrxID})
+	if err != nil {
+		return nil, fmt.Errorf("%w for trx_id %s", err, match.TrxID)
+	}
+	bank, err := s.unmatchedResult(ctx, jobID, domain.ResultFilter{Status: domain.UnmatchedBank, TrxRefID: match.TrxRefID, BankSource: match.BankSource})
+	if err != nil {
+		return nil, fmt.Errorf("%w for trx_ref_id %s", err, match.TrxRefID)
+	}
+
+	result := manualMatchResult(jobID, system, bank, match.ReviewedBy)
+	delta := domain.JobTotalsDelta{Matched: 1, Unmatched: -2, TotalDiscrepancies: decimal.Zero, NetDiscrepancy: decimal.Zero}
+	added := []domain.ReconciliationResult{result}
+	updated, err := s.reconRepo.ReplaceUnmatchedResults(ctx, jobID, []int{system.ID, bank.ID}, added, delta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace results: %w", err)
+	}
+	s.summaries.invalidate(jobID)
+	s.replaceResults(ctx, job, []ResultReplacement{{Removed: []domain.ReconciliationResult{system, bank}, Added: added[0]}})
+
+	logger.GetLogger().WithFields(map[string]interface{}{
+		"job_id":      jobID,
+		"trx_id":      match.TrxID,
+		"trx_ref_id":  match.TrxRefID,
+		"reviewed_by": match.ReviewedBy,
+	}).Info("Manually matched unmatched results")
+
+	return &domain.ManualMatchResult{JobID: jobID, Result: added[0], Job: updated}, nil
+}
+
+// unmatchedResult returns the job's only unmatched result matching filter
+func (s *reconciliationService) unmatchedResult(ctx context.Context, jobID string, filter domain.ResultFilter) (domain.ReconciliationResult, error) {
+	results, total, err := s.reconRepo.QueryResults(ctx, jobID, filter, 2, 0)
+	if err != nil {
+		return domain.ReconciliationResult{}, fmt.Errorf("failed to load unmatched results: %w", err)
+	}
+	switch {
+	case total == 0 || len(results) == 0:
+		return domain.ReconciliationResult{}, ErrNotUnmatched
+	case total > 1 && filter.Status == domain.UnmatchedBank && filter.BankSource == "":
+		return domain.ReconciliationResult{}, ErrAmbiguousManualMatch
+	}
+	return results[0], nil
+}
+
+// manualMatchResult joins the system side of one unmatched result with the
+// bank side of the other
+func manualMatchResult(jobID string, system, bank domain.ReconciliationResult, reviewedBy string) domain.ReconciliationResult {
+	zero := decimal.Zero
+	via := MatchedViaManual
+	return domain.ReconciliationResult{
+		JobID:                jobID,
+		TrxID:                system.TrxID,
+		TrxRefID:             bank.TrxRefID,
+		SystemAmount:         system.SystemAmount,
+		BankAmount:           bank.BankAmount,
+		Discrepancy:          &zero,
+		MatchStatus:          domain.Matched,
+		BankSource:           bank.BankSource,
+		TransactionDate:      system.TransactionDate,
+		TransactionType:      system.TransactionType,
+		TransactionCreatedAt: system.TransactionCreatedAt,
+		Currency:             system.Currency,
+		BankCurrency:         bank.BankCurrency,
+		Description:          system.Description,
+		BankDescription:      bank.BankDescription,
+		MatchedVia:           &via,
+		ManualMatch:          true,
+		ReviewedBy:           &reviewedBy,
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+// Notifier delivers a payload to a callback URL, e.g. a *webhook.Client
+type Notifier interface {
+	Post(url string, payload interface{}) error
+}
+
+// WithNotifier posts a domain.JobNotification to the callback URL set with
+// ForCallback when a job completes or fails
+func WithNotifier(notifier Notifier) ServiceOption {
+	return func(s *reconciliationService) {
+		s.notifier = notifier
+	}
+}
+
+func (s *reconciliationService) ForCallback(url string) ReconciliationService {
+	if url == "" {
+		return s
+	}
+	scoped := *s
+	scoped.callbackURL = url
+	return &scoped
+}
+
+// notifyJob posts the job's outcome in the background; a failed delivery is
+// logged and does not affect the job
+func (s *reconciliationService) notifyJob(run *jobRun, status domain.JobStatus, errorMsg string) {
+	if run.dryRun || s.callbackURL == "" || s.notifier == nil {
+		return
+	}
+
+	job := run.job
+	notification := domain.JobNotification{
+		JobID:              job.JobID,
+		Status:             status,
+		StartDate:          job.StartDate,
+		EndDate:            job.EndDate,
+		TotalProcessed:     job.TotalProcessed,
+		TotalMatched:       job.TotalMatched,
+		TotalUnmatched:     job.TotalUnmatched,
+		TotalDiscrepancies: job.TotalDiscrepancies,
+		NetDiscrepancy:     job.NetDiscrepancy,
+		ErrorMessage:       errorMsg,
+	}
+	url, notifier := s.callbackURL, s.notifier
+	go func() {
+		if err := notifier.Post(url, notification); err != nil {
+			logger.GetLogger().WithError(err).WithField("job_id", notification.JobID).Warn("Failed to deliver job callback")
+			return
+		}
+		logger.GetLogger().WithField("job_id", notification.JobID).Info("Job callback delivered")
+	}()
+}
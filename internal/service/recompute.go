@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/logger"
+)
+
+// RecomputeJobTotals recounts the completed job's stored results by status
+// and rewrites its matched, unmatched and discrepancy totals when they have
+// drifted from them. total_processed counts input rows, including those
+// excluded by amount bounds that left no result, and is kept as stored.
+func (s *reconciliationService) RecomputeJobTotals(ctx context.Context, jobID string) (*domain.JobRecompute, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.Completed {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotCompleted, jobID)
+	}
+
+	recompute := &domain.JobRecompute{
+		JobID:    jobID,
+		Previous: jobTotals(job),
+		Totals:   domain.JobTotals{TotalDiscrepancies: decimal.Zero, NetDiscrepancy: decimal.Zero},
+	}
+	err = s.reconRepo.GetResultsByJobIDStream(ctx, jobID, s.batchSize, func(batch []domain.ReconciliationResult) error {
+		for _, result := range batch {
+			recompute.TotalResults++
+			addToTotals(&recompute.Totals, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results: %w", err)
+	}
+
+	if job.ExceptionsOnly {
+		// Matched pairs were never stored, so only the job counted them
+		recompute.Totals.TotalMatched = job.TotalMatched
+	}
+
+	recompute.Job = job
+	if recompute.Totals.Equal(recompute.Previous) {
+		return recompute, nil
+	}
+
+	job.TotalMatched = recompute.Totals.TotalMatched
+	job.TotalUnmatched = recompute.Totals.TotalUnmatched
+	job.TotalDiscrepancies = recompute.Totals.TotalDiscrepancies
+	job.NetDiscrepancy = recompute.Totals.NetDiscrepancy
+	if err := s.reconRepo.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+	s.summaries.invalidate(jobID)
+	recompute.Changed = true
+
+	logger.GetLogger().WithFields(map[string]interface{}{
+		"job_id":             jobID,
+		"previous_matched":   recompute.Previous.TotalMatched,
+		"matched":            job.TotalMatched,
+		"previous_unmatched": recompute.Previous.TotalUnmatched,
+		"unmatched":          job.TotalUnmatched,
+	}).Warn("Job totals drifted from its results and were recomputed")
+	return recompute, nil
+}
+
+func jobTotals(job *domain.ReconciliationJob) domain.JobTotals {
+	return domain.JobTotals{
+		TotalMatched:       job.TotalMatched,
+		TotalUnmatched:     job.TotalUnmatched,
+		TotalDiscrepancies: job.TotalDiscrepancies,
+		NetDiscrepancy:     job.NetDiscrepancy,
+	}
+}
+
+// addToTotals counts a result the way a completed job's totals count the
+// matcher's output
+func addToTotals(totals *domain.JobTotals, result domain.ReconciliationResult) {
+	switch result.MatchStatus {
+	case domain.Matched:
+		totals.TotalMatched++
+	case domain.UnmatchedSystem, domain.UnmatchedBank:
+		totals.TotalUnmatched++
+	case domain.Discrepancy:
+		if result.Discrepancy != nil {
+			totals.TotalDiscrepancies = totals.TotalDiscrepancies.Add(*result.Discrepancy)
+		}
+		if result.SignedDiscrepancy != nil {
+			totals.NetDiscrepancy = totals.NetDiscrepancy.Add(*result.SignedDiscrepancy)
+		}
+	}
+}
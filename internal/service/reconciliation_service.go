@@ -1,102 +1,432 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
 
 	"recon-engine/internal/domain"
 	"recon-engine/internal/matcher"
 	"recon-engine/internal/parser"
 	"recon-engine/internal/repository"
 	"recon-engine/pkg/logger"
+	"recon-engine/pkg/metrics"
+	"recon-engine/pkg/tracing"
 )
 
 type ReconciliationService interface {
-	Reconcile(systemFilePath string, bankFilePaths []string, startDate, endDate time.Time) (*domain.ReconciliationSummary, error)
-	GetJobStatus(jobID string) (*domain.ReconciliationJob, error)
-	GetJobSummary(jobID string) (*domain.ReconciliationSummary, error)
+	// Reconcile matches the transactions of systemFilePaths, merged, or of
+	// the database when there are none, against the bank files. A dry run
+	// matches and summarizes without persisting a job or results.
+	Reconcile(ctx context.Context, systemFilePaths []string, bankFilePaths []string, startDate, endDate time.Time, dryRun bool) (*domain.ReconciliationSummary, error)
+	// ReconcileFromDatabase matches the system transactions and bank
+	// statements stored in the database; a dry run persists nothing, as
+	// with Reconcile
+	ReconcileFromDatabase(ctx context.Context, startDate, endDate time.Time, dryRun bool) (*domain.ReconciliationSummary, error)
+	// ForStrategy returns the service matching with the named strategy; an
+	// empty name keeps the configured one
+	ForStrategy(name string) (ReconciliationService, error)
+	// ForBatchSize returns the service reading, parsing and saving in
+	// batches of size; zero keeps the configured size
+	ForBatchSize(size int) (ReconciliationService, error)
+	// ForLedgerBalance returns the service checking each summary's lines
+	// against the account's opening and closing balances; both must be
+	// given, and with neither the receiver is kept
+	ForLedgerBalance(opening, closing *decimal.Decimal) (ReconciliationService, error)
+	// ForPersistMatched returns the service storing only the exceptions of
+	// the jobs it runs when persist is false; totals still count matches
+	ForPersistMatched(persist bool) ReconciliationService
+	// ForFailureThresholds returns the service failing the jobs it runs
+	// when the share of discrepant ID pairs or of unmatched rows is above
+	// the given ratio, from 0 to 1; results are still saved. Nil leaves a
+	// ratio unchecked, and with neither the receiver is kept.
+	ForFailureThresholds(maxDiscrepancyRatio, maxUnmatchedRatio *float64) (ReconciliationService, error)
+	// ForCallback returns the service notifying url when a job it runs
+	// completes or fails; an empty url keeps the receiver
+	ForCallback(url string) ReconciliationService
+	// ForIdempotencyKey returns the service answering a repeat of key with the
+	// job the first submission started; an empty key keeps the receiver
+	ForIdempotencyKey(key string) ReconciliationService
+	GetJobStatus(ctx context.Context, jobID string) (*domain.ReconciliationJob, error)
+	// ListJobs returns one page of jobs matching filter, newest first. Pages are 1-based.
+	ListJobs(ctx context.Context, filter domain.JobFilter, page, size int) (*domain.JobPage, error)
+	// DeleteJob removes a job and its results unless the job is processing
+	DeleteJob(ctx context.Context, jobID string) error
+	// RerunJob reconciles the job's date range again from the database as a new job
+	RerunJob(ctx context.Context, jobID string) (*domain.ReconciliationSummary, error)
+	// GetUnmatchedBank pages through a job's UNMATCHED_BANK results of
+	// source, or of each source with any when source is empty
+	GetUnmatchedBank(ctx context.Context, jobID, source string, page, size int) (*domain.UnmatchedBankPages, error)
+	// GetJobSummary lists only the discrepancies of at least minDiscrepancy
+	// and the paired results scored at least minConfidence when they are
+	// set; the totals always cover every result
+	GetJobSummary(ctx context.Context, jobID string, minDiscrepancy *decimal.Decimal, minConfidence *float64) (*domain.ReconciliationSummary, error)
+	// GetJobStats returns a job's totals and result counts without loading results
+	GetJobStats(ctx context.Context, jobID string) (*domain.JobStats, error)
+	// RollupJobs consolidates the results of completed jobs, e.g. for a month-end close
+	RollupJobs(ctx context.Context, jobIDs []string) (*domain.RollupSummary, error)
+	// RollupDateRange consolidates the completed jobs within a date range
+	RollupDateRange(ctx context.Context, startDate, endDate time.Time) (*domain.RollupSummary, error)
+	// GetJobResults returns one page of a job's results matching filter. Pages are 1-based.
+	GetJobResults(ctx context.Context, jobID string, filter domain.ResultFilter, page, size int) (*domain.ResultPage, error)
+	// GetJobResultsAfter returns the job's results matching filter that
+	// follow cursor, or the first ones when cursor is nil, without counting
+	// or skipping rows
+	GetJobResultsAfter(ctx context.Context, jobID string, filter domain.ResultFilter, cursor *domain.ResultCursor, size int) (*domain.ResultCursorPage, error)
+	StreamJobResults(ctx context.Context, jobID string, callback func([]domain.ReconciliationResult) error) error
+	PageJobResults(ctx context.Context, jobID string, callback func([]domain.ReconciliationResult) error) error
+	// VerifyJobResults checks a job's stored results against its hash chain
+	VerifyJobResults(ctx context.Context, jobID string) (*domain.ChainVerification, error)
+	// RecomputeJobTotals rewrites a completed job's totals from its stored
+	// results when they have drifted
+	RecomputeJobTotals(ctx context.Context, jobID string) (*domain.JobRecompute, error)
+	// ReprocessUnmatched retries a completed job's unmatched system results
+	// against late bank files and replaces those that now match
+	ReprocessUnmatched(ctx context.Context, jobID string, bankFilePaths []string) (*domain.ReprocessSummary, error)
+	// ManualMatch replaces a completed job's unmatched system and bank
+	// results a reviewer has paired with one manual MATCHED result
+	ManualMatch(ctx context.Context, jobID string, match domain.ManualMatch) (*domain.ManualMatchResult, error)
+	// FlushSinks waits until the results already handed to the result sinks
+	// are delivered, or ctx is done
+	FlushSinks(ctx context.Context) error
+	// DiffJobs compares two completed jobs over the days both cover
+	DiffJobs(ctx context.Context, jobID, otherJobID string) (*domain.JobDiff, error)
+	// ValidateFiles parses input files and reports their row counts, skipped
+	// rows, columns and date ranges without matching or persisting
+	ValidateFiles(ctx context.Context, systemFilePaths, bankFilePaths []string) (*domain.ValidationReport, error)
 }
 
+// ErrJobProcessing is returned when a job that is still running is deleted
+var ErrJobProcessing = errors.New("reconciliation job is still processing")
+
+// ErrEmptyInput is returned when the system files, or every bank file that
+// loaded, parsed without error but had no data rows
+var ErrEmptyInput = errors.New("input files have no data rows")
+
 type reconciliationService struct {
-	txRepo    repository.TransactionRepository
-	reconRepo repository.ReconciliationRepository
-	engine    *matcher.ReconciliationEngine
-	batchSize int
+	txRepo     repository.TransactionRepository
+	reconRepo  repository.ReconciliationRepository
+	bankRepo   repository.BankStatementRepository
+	engine     *matcher.ReconciliationEngine
+	strategy   matcher.MatchingStrategy
+	engineOpts []matcher.EngineOption
+	parserOpts []parser.ParserOption
+	batchSize  int
+	// strategyConfig builds strategies chosen per request
+	strategyConfig matcher.StrategyConfig
+	// splitByDirection reconciles debits and credits in independent passes
+	splitByDirection bool
+	// streaming reads database system transactions in batches during matching
+	streaming bool
+	// perSource reconciles each bank source on its own against the system set
+	perSource bool
+	// columnMappings holds header aliases per bank source (file name)
+	columnMappings map[string]parser.ColumnMapping
+	// dateLayouts holds the explicit date layout per bank source
+	dateLayouts map[string]string
+	// sourceFingerprints identify a bank file's source by its content
+	sourceFingerprints []parser.SourceFingerprint
+	// maxArchiveSize caps the uncompressed bytes read from one bank archive
+	maxArchiveSize int64
+	// bankFileWorkers is how many bank files are parsed at once
+	bankFileWorkers int
+	// metrics receives job started, failed and completed events
+	metrics metrics.JobRecorder
+	// persistBankStatements stores bank statements loaded from files
+	persistBankStatements bool
+	// exceptionsOnly skips storing MATCHED results
+	exceptionsOnly bool
+	// skipRepositoryResults leaves results out of the results table
+	skipRepositoryResults bool
+	// sinks receive each completed job's results besides the results table,
+	// one delivery after another through sinkQueue, each within sinkTimeout
+	sinks       []ResultSink
+	sinkTimeout time.Duration
+	sinkQueue   *sinkQueue
+	// summaries caches completed jobs' summaries; nil when disabled
+	summaries *summaryCache
+	// maxDiscrepancyRatio and maxUnmatchedRatio fail jobs above them
+	maxDiscrepancyRatio *float64
+	maxUnmatchedRatio   *float64
+	// notifier posts job outcomes to callbackURL
+	notifier    Notifier
+	callbackURL string
+	// hashChain links persisted results by hash, signed with chainKey when set
+	hashChain bool
+	chainKey  []byte
+	// idempotencyRepo remembers idempotencyKey for idempotencyTTL
+	idempotencyRepo repository.IdempotencyRepository
+	idempotencyTTL  time.Duration
+	idempotencyKey  string
+	// openingBalance and closingBalance, when set, are checked against the
+	// reconciled lines
+	openingBalance *decimal.Decimal
+	closingBalance *decimal.Decimal
+}
+
+// ServiceOption configures optional behaviour of the reconciliation service
+type ServiceOption func(*reconciliationService)
+
+// WithDateWindow enables date-proximity checking on ID matches
+func WithDateWindow(window time.Duration) ServiceOption {
+	return WithEngineOptions(matcher.WithDateWindow(window))
+}
+
+// WithStrategy replaces the default exact ID matching strategy
+func WithStrategy(strategy matcher.MatchingStrategy) ServiceOption {
+	return func(s *reconciliationService) {
+		s.strategy = strategy
+	}
+}
+
+// WithStrategyConfig sets the tolerance and normalization settings used
+// when a request names its own strategy
+func WithStrategyConfig(cfg matcher.StrategyConfig) ServiceOption {
+	return func(s *reconciliationService) {
+		s.strategyConfig = cfg
+	}
+}
+
+// WithEngineOptions passes options through to the reconciliation engine
+func WithEngineOptions(opts ...matcher.EngineOption) ServiceOption {
+	return func(s *reconciliationService) {
+		s.engineOpts = append(s.engineOpts, opts...)
+	}
+}
+
+// WithSplitByDirection reconciles debits and credits separately and reports
+// a sub-summary for each alongside the combined totals
+func WithSplitByDirection(enabled bool) ServiceOption {
+	return func(s *reconciliationService) {
+		s.splitByDirection = enabled
+	}
+}
+
+// WithStreaming matches system transactions from the database batch by batch
+// as they are read instead of loading the date range into memory first. The
+// match outcome is still collected for the whole job and its results saved
+// once matching ends. It does not apply to system CSV files or to
+// split-by-direction passes.
+func WithStreaming(enabled bool) ServiceOption {
+	return func(s *reconciliationService) {
+		s.streaming = enabled
+	}
+}
+
+// WithPerSource reconciles each bank source separately against every system
+// transaction and nests a summary per source in the job summary. It takes
+// precedence over WithSplitByDirection and WithStreaming.
+func WithPerSource(enabled bool) ServiceOption {
+	return func(s *reconciliationService) {
+		s.perSource = enabled
+	}
+}
+
+// WithBankStatementRepository enables reconciling against bank statements
+// stored in the database
+func WithBankStatementRepository(repo repository.BankStatementRepository) ServiceOption {
+	return func(s *reconciliationService) {
+		s.bankRepo = repo
+	}
+}
+
+// WithBankStatementPersistence stores the bank statements loaded from files,
+// tagged with the job, so later jobs can reconcile them from the database. It
+// needs WithBankStatementRepository.
+func WithBankStatementPersistence(enabled bool) ServiceOption {
+	return func(s *reconciliationService) {
+		s.persistBankStatements = enabled
+	}
+}
+
+// WithParserOptions passes options through to the file parsers
+func WithParserOptions(opts ...parser.ParserOption) ServiceOption {
+	return func(s *reconciliationService) {
+		s.parserOpts = append(s.parserOpts, opts...)
+	}
+}
+
+// WithColumnMappings sets the CSV header aliases used for each bank source,
+// keyed by bank file name
+func WithColumnMappings(mappings map[string]parser.ColumnMapping) ServiceOption {
+	return func(s *reconciliationService) {
+		s.columnMappings = mappings
+	}
+}
+
+// WithDateLayouts sets the date layout each bank source is read with, keyed
+// by source; sources without one auto-detect their dates
+func WithDateLayouts(layouts map[string]string) ServiceOption {
+	return func(s *reconciliationService) {
+		s.dateLayouts = layouts
+	}
+}
+
+// WithSourceFingerprints detects a bank file's source from its headers or a
+// marker column, in order, before falling back to the file name
+func WithSourceFingerprints(fingerprints []parser.SourceFingerprint) ServiceOption {
+	return func(s *reconciliationService) {
+		s.sourceFingerprints = fingerprints
+	}
+}
+
+// WithMetricsRecorder replaces the Prometheus job metrics, e.g. with
+// metrics.Recorders(metrics.Prometheus, statsd) to report to both
+func WithMetricsRecorder(recorder metrics.JobRecorder) ServiceOption {
+	return func(s *reconciliationService) {
+		if recorder != nil {
+			s.metrics = recorder
+		}
+	}
 }
 
 func NewReconciliationService(
 	txRepo repository.TransactionRepository,
 	reconRepo repository.ReconciliationRepository,
 	batchSize int,
+	opts ...ServiceOption,
 ) ReconciliationService {
-	return &reconciliationService{
-		txRepo:    txRepo,
-		reconRepo: reconRepo,
-		engine:    matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}),
-		batchSize: batchSize,
+	s := &reconciliationService{
+		txRepo:          txRepo,
+		reconRepo:       reconRepo,
+		strategy:        &matcher.ExactMatchStrategy{},
+		batchSize:       batchSize,
+		maxArchiveSize:  defaultMaxArchiveSize,
+		bankFileWorkers: defaultBankFileWorkers,
+		metrics:         metrics.Prometheus,
+		sinkTimeout:     defaultSinkTimeout,
+		sinkQueue:       &sinkQueue{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.engine = matcher.NewReconciliationEngine(s.strategy, s.engineOpts...)
+	return s
+}
+
+func (s *reconciliationService) ForStrategy(name string) (ReconciliationService, error) {
+	if name == "" {
+		return s, nil
+	}
+	strategy, err := matcher.NewStrategy(name, s.strategyConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *s
+	scoped.strategy = strategy
+	scoped.engine = matcher.NewReconciliationEngine(strategy, s.engineOpts...)
+	return &scoped, nil
+}
+
+// MaxBatchSize caps the batch size a single request may ask for
+const MaxBatchSize = 100000
+
+func (s *reconciliationService) ForBatchSize(size int) (ReconciliationService, error) {
+	if size == 0 {
+		return s, nil
+	}
+	if size < 0 || size > MaxBatchSize {
+		return nil, fmt.Errorf("batch size must be between 1 and %d", MaxBatchSize)
+	}
+
+	scoped := *s
+	scoped.batchSize = size
+	return &scoped, nil
+}
+
+func (s *reconciliationService) ForLedgerBalance(opening, closing *decimal.Decimal) (ReconciliationService, error) {
+	if opening == nil && closing == nil {
+		return s, nil
+	}
+	if opening == nil || closing == nil {
+		return nil, fmt.Errorf("opening and closing balances must be given together")
+	}
+
+	scoped := *s
+	scoped.openingBalance = opening
+	scoped.closingBalance = closing
+	return &scoped, nil
+}
+
+func (s *reconciliationService) ForPersistMatched(persist bool) ReconciliationService {
+	if persist {
+		return s
+	}
+	scoped := *s
+	scoped.exceptionsOnly = true
+	return &scoped
 }
 
 func (s *reconciliationService) Reconcile(
-	systemFilePath string,
+	ctx context.Context,
+	systemFilePaths []string,
 	bankFilePaths []string,
 	startDate, endDate time.Time,
+	dryRun bool,
 ) (*domain.ReconciliationSummary, error) {
-	// Create reconciliation job
-	jobID := uuid.New().String()
-	job := &domain.ReconciliationJob{
-		JobID:              jobID,
-		StartDate:          startDate,
-		EndDate:            endDate,
-		Status:             domain.Processing,
-		TotalDiscrepancies: decimal.Zero,
+	run, err := s.createJob(ctx, startDate, endDate, inputFile, dryRun)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := s.reconRepo.CreateJob(job); err != nil {
-		return nil, fmt.Errorf("failed to create job: %w", err)
+	if run.replayOf != "" {
+		return s.replayJob(ctx, run.replayOf)
 	}
 
-	logger.GetLogger().WithField("job_id", jobID).Info("Starting reconciliation job")
+	// The end date is inclusive of its whole day; everything below uses the
+	// exclusive bound so boundary instants are neither dropped nor double-counted
+	endBefore := domain.DayAfter(endDate)
 
-	// Load system transactions from database
-	systemTransactions, err := s.txRepo.GetByDateRange(startDate, endDate)
-	if err != nil {
-		s.updateJobStatus(jobID, domain.Failed, err.Error())
-		return nil, fmt.Errorf("failed to load system transactions: %w", err)
-	}
+	// Database transactions are streamed during matching instead of loaded
+	// here; the per-direction passes need them all in memory
+	streaming := s.streaming && len(systemFilePaths) == 0 && !s.splitByDirection && !s.perSource
 
-	// If system file path is provided, load from CSV instead
-	if systemFilePath != "" {
-		systemTransactions, err = s.loadSystemTransactionsFromCSV(systemFilePath)
+	// Load system transactions from database
+	var systemTransactions []domain.Transaction
+	if !streaming && len(systemFilePaths) == 0 {
+		systemTransactions, err = s.txRepo.GetByDateRange(ctx, startDate, endBefore)
 		if err != nil {
-			s.updateJobStatus(jobID, domain.Failed, err.Error())
-			return nil, fmt.Errorf("failed to load system transactions from CSV: %w", err)
+			s.failJob(ctx, run, err.Error())
+			return nil, fmt.Errorf("failed to load system transactions: %w", err)
 		}
 	}
 
-	// Load bank statements from all CSV files
-	var allBankStatements []domain.BankStatement
-	for _, bankFilePath := range bankFilePaths {
-		bankStatements, err := s.loadBankStatementsFromCSV(bankFilePath)
+	// If system files are provided, load from CSV instead
+	var crossFileDuplicates []domain.DuplicateTransaction
+	var systemReport []domain.FileLoadReport
+	if len(systemFilePaths) > 0 {
+		systemTransactions, crossFileDuplicates, systemReport, err = s.loadSystemTransactionsFromCSV(ctx, systemFilePaths)
+		if err == nil && len(systemTransactions) == 0 {
+			err = fmt.Errorf("%w: %s", ErrEmptyInput, emptyFiles(systemReport))
+		}
 		if err != nil {
-			logger.GetLogger().WithError(err).WithField("file", bankFilePath).Warn("Failed to load bank statements")
-			continue
+			s.failJob(ctx, run, err.Error())
+			return nil, fmt.Errorf("failed to load system transactions from CSV: %w", err)
+		}
+		if len(crossFileDuplicates) > 0 {
+			logger.GetLogger().WithField("duplicates", len(crossFileDuplicates)).Warn("Transaction IDs found in more than one system file")
 		}
-		allBankStatements = append(allBankStatements, bankStatements...)
 	}
 
+	allBankStatements, loadReport := s.loadBankFiles(ctx, bankFilePaths)
 	if len(allBankStatements) == 0 {
-		s.updateJobStatus(jobID, domain.Failed, "no bank statements loaded")
-		return nil, fmt.Errorf("no bank statements loaded")
+		err := noBankStatements(loadReport)
+		s.failJob(ctx, run, err.Error())
+		return nil, err
 	}
+	s.saveBankStatements(ctx, run, allBankStatements)
 
 	// Filter by date range
-	systemTransactions = s.filterByDateRange(systemTransactions, startDate, endDate)
-	allBankStatements = s.filterBankStatementsByDateRange(allBankStatements, startDate, endDate)
+	systemTransactions = s.filterByDateRange(systemTransactions, startDate, endBefore)
+	allBankStatements = s.filterBankStatementsByDateRange(allBankStatements, startDate, endBefore)
 
 	// Perform reconciliation
 	reconInput := matcher.ReconciliationInput{
@@ -107,93 +437,833 @@ func (s *reconciliationService) Reconcile(
 	}
 
 	if err := matcher.ValidateReconciliationInput(reconInput); err != nil {
-		s.updateJobStatus(jobID, domain.Failed, err.Error())
+		s.failJob(ctx, run, err.Error())
 		return nil, err
 	}
 
-	output, err := s.engine.Reconcile(reconInput)
+	// Sources set to auto are normalized by the signs of their ID pairs;
+	// streamed system transactions arrive too late to detect from
+	engine, signConventions := s.engine.DetectBankAmountConventions(reconInput)
+	if engine != s.engine {
+		scoped := *s
+		scoped.engine = engine
+		s = &scoped
+	}
+
+	var output *matcher.ReconciliationOutput
+	var debits, credits *domain.DirectionSummary
+	var sourceOutputs map[string]*matcher.ReconciliationOutput
+	var balance *domain.BalanceCheck
+	systemCount := len(systemTransactions)
+	matchCtx, span := tracing.Start(ctx, "reconcile.match",
+		attribute.Int("system.rows", systemCount),
+		attribute.Int("bank.rows", len(allBankStatements)),
+		attribute.Bool("streaming", streaming),
+	)
+	switch {
+	case streaming:
+		var stream systemStream
+		output, stream, err = s.reconcileSystemStream(matchCtx, startDate, endBefore, allBankStatements)
+		systemCount = stream.count
+		span.SetAttributes(attribute.Int("system.rows", systemCount))
+		balance = s.streamedBalance(stream, allBankStatements)
+	case s.perSource:
+		sourceOutputs, err = s.engine.ReconcileBySource(reconInput)
+		if err == nil {
+			output = matcher.MergeSourceOutputs(sourceOutputs)
+		}
+	case s.splitByDirection:
+		output, debits, credits, err = s.reconcileByDirection(reconInput)
+	default:
+		output, err = s.engine.Reconcile(reconInput)
+	}
+	if output != nil {
+		span.SetAttributes(attribute.Int("matched", len(output.Matched)))
+	}
+	tracing.End(span, err)
 	if err != nil {
-		s.updateJobStatus(jobID, domain.Failed, err.Error())
+		s.failJob(ctx, run, err.Error())
 		return nil, fmt.Errorf("reconciliation failed: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		s.failJob(ctx, run, err.Error())
+		return nil, err
+	}
+	if balance == nil {
+		balance = s.engine.ReconcileBalances(reconInput)
+	}
+
+	summary := s.completeJob(ctx, run, output, systemCount+len(allBankStatements))
+	summary.BalanceDiscrepancy = balance
+	summary.LedgerBalance = s.checkLedgerBalance(summary.JobID, output)
+	summary.Debits = debits
+	summary.Credits = credits
+	if sourceOutputs != nil {
+		summary.BySource = s.sourceSummaries(summary.JobID, reconInput, sourceOutputs)
+	}
+	summary.SignConventions = signConventions
+	summary.FileLoadReport = loadReport
+	summary.SystemFileReport = systemReport
+	summary.CrossFileDuplicates = crossFileDuplicates
+	for _, report := range loadReport {
+		if report.Error != "" {
+			summary.Incomplete = true
+		}
+	}
+	if summary.Incomplete {
+		logger.GetLogger().WithField("job_id", summary.JobID).Warn("Reconciliation completed without every bank file")
+	}
+	if empty := emptyFiles(append(slices.Clip(systemReport), loadReport...)); empty != "" {
+		logger.GetLogger().WithFields(map[string]interface{}{
+			"job_id": summary.JobID,
+			"files":  empty,
+		}).Warn("Some input files have no data rows")
+	}
+
+	return summary, nil
+}
+
+// ReconcileFromDatabase reconciles system transactions against bank
+// statements stored in the database. Both sides are read in batches; system
+// transactions are matched as they stream in, while bank statements are held
+// in memory for the hash index.
+func (s *reconciliationService) ReconcileFromDatabase(ctx context.Context, startDate, endDate time.Time, dryRun bool) (*domain.ReconciliationSummary, error) {
+	if s.bankRepo == nil {
+		return nil, fmt.Errorf("bank statement repository is not configured")
+	}
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date must be before or equal to end date")
+	}
+
+	run, err := s.createJob(ctx, startDate, endDate, inputDatabase, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if run.replayOf != "" {
+		return s.replayJob(ctx, run.replayOf)
+	}
+	endBefore := domain.DayAfter(endDate)
+
+	var bankStatements []domain.BankStatement
+	err = s.bankRepo.GetByDateRangeStream(ctx, startDate, endBefore, s.batchSize, func(batch []domain.BankStatement) error {
+		bankStatements = append(bankStatements, batch...)
+		return nil
+	})
+	if err != nil {
+		s.failJob(ctx, run, err.Error())
+		return nil, fmt.Errorf("failed to load bank statements: %w", err)
+	}
+
+	if len(bankStatements) == 0 {
+		s.failJob(ctx, run, "no bank statements loaded")
+		return nil, fmt.Errorf("no bank statements loaded")
+	}
+
+	matchCtx, span := tracing.Start(ctx, "reconcile.match",
+		attribute.Int("bank.rows", len(bankStatements)),
+		attribute.Bool("streaming", true),
+	)
+	output, stream, err := s.reconcileSystemStream(matchCtx, startDate, endBefore, bankStatements)
+	if err == nil {
+		span.SetAttributes(attribute.Int("system.rows", stream.count), attribute.Int("matched", len(output.Matched)))
+	}
+	tracing.End(span, err)
+	if err != nil {
+		s.failJob(ctx, run, err.Error())
+		return nil, fmt.Errorf("reconciliation failed: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		s.failJob(ctx, run, err.Error())
+		return nil, err
+	}
+
+	summary := s.completeJob(ctx, run, output, stream.count+len(bankStatements))
+	summary.BalanceDiscrepancy = s.streamedBalance(stream, bankStatements)
+	summary.LedgerBalance = s.checkLedgerBalance(summary.JobID, output)
+	return summary, nil
+}
+
+// systemStream tallies the system transactions read by reconcileSystemStream
+type systemStream struct {
+	count int
+	net   decimal.Decimal
+}
+
+// reconcileSystemStream matches the system transactions stored between
+// startDate and endBefore against bankStatements, reading them in batches
+// rather than all at once. It returns how many transactions were read and
+// their net total.
+func (s *reconciliationService) reconcileSystemStream(ctx context.Context,
+	startDate, endBefore time.Time,
+	bankStatements []domain.BankStatement,
+) (*matcher.ReconciliationOutput, systemStream, error) {
+	engine := matcher.NewStreamingReconciliationEngine(s.strategy, s.batchSize, s.engineOpts...)
+
+	systemBatches := make(chan []domain.Transaction)
+	streamErr := make(chan error, 1)
+	stream := systemStream{net: decimal.Zero}
+	go func() {
+		defer close(systemBatches)
+		streamErr <- s.txRepo.GetByDateRangeStream(ctx, startDate, endBefore, s.batchSize, func(batch []domain.Transaction) error {
+			stream.count += len(batch)
+			stream.net = stream.net.Add(engine.SystemNet(batch))
+			systemBatches <- batch
+			return nil
+		})
+	}()
+
+	output, err := engine.ReconcileStreaming(systemBatches, bankStatements)
+	if err != nil {
+		// Drain the reader so its goroutine can finish
+		for range systemBatches {
+		}
+		return nil, systemStream{}, err
+	}
+	if err := <-streamErr; err != nil {
+		return nil, systemStream{}, err
+	}
+	return output, stream, nil
+}
+
+// streamedBalance compares the net of streamed system transactions with
+// that of the bank statements
+func (s *reconciliationService) streamedBalance(stream systemStream, bankStatements []domain.BankStatement) *domain.BalanceCheck {
+	bankNet, unsigned := s.engine.BankNet(bankStatements)
+	return domain.NewBalanceCheck(stream.net, bankNet, unsigned)
+}
+
+// checkLedgerBalance checks output against the requested opening and
+// closing balances; it returns nil when none were given
+func (s *reconciliationService) checkLedgerBalance(jobID string, output *matcher.ReconciliationOutput) *domain.LedgerBalance {
+	if s.openingBalance == nil || s.closingBalance == nil {
+		return nil
+	}
+	check := s.engine.CheckLedgerBalance(*s.openingBalance, *s.closingBalance, output)
+	if !check.Balanced {
+		logger.GetLogger().WithFields(map[string]interface{}{
+			"job_id":     jobID,
+			"expected":   check.Expected.String(),
+			"actual":     check.Actual.String(),
+			"difference": check.Difference.String(),
+		}).Warn("Closing balance differs from the reconciled lines")
+	}
+	return check
+}
+
+// Inputs label job metrics by where bank statements come from
+const (
+	inputFile     = "file"
+	inputDatabase = "database"
+)
+
+// jobRun tracks a reconciliation job from creation to completion
+type jobRun struct {
+	job     *domain.ReconciliationJob
+	input   string
+	started time.Time
+	dryRun  bool
+	// replayOf is the job started by an earlier request with the same
+	// idempotency key; no new job was created
+	replayOf string
+}
+
+// createJob registers a new job in PROCESSING state. A dry-run job is kept
+// in memory only and has no job ID.
+func (s *reconciliationService) createJob(ctx context.Context, startDate, endDate time.Time, input string, dryRun bool) (*jobRun, error) {
+	run := &jobRun{input: input, started: time.Now(), dryRun: dryRun}
+	job := &domain.ReconciliationJob{
+		StartDate:          startDate,
+		EndDate:            endDate,
+		Status:             domain.Processing,
+		TotalDiscrepancies: decimal.Zero,
+		NetDiscrepancy:     decimal.Zero,
+		ExceptionsOnly:     s.exceptionsOnly,
+	}
+
+	run.job = job
+
+	if dryRun {
+		logger.GetLogger().Info("Starting dry-run reconciliation")
+		return run, nil
+	}
+
+	job.JobID = uuid.New().String()
+	existing, err := s.claimIdempotencyKey(ctx, job.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if existing != "" {
+		run.replayOf = existing
+		return run, nil
+	}
+
+	if err := s.reconRepo.CreateJob(ctx, job); err != nil {
+		s.releaseIdempotencyKey(ctx)
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	s.metrics.JobStarted(input)
+
+	logger.GetLogger().WithField("job_id", job.JobID).Info("Starting reconciliation job")
+	return run, nil
+}
+
+// failJob marks a persisted job as failed. It runs even when ctx is done,
+// since a cancelled request is one of the ways a job fails.
+func (s *reconciliationService) failJob(ctx context.Context, run *jobRun, errorMsg string) {
+	if run.dryRun {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	s.metrics.JobFailed(run.input)
+	s.updateJobStatus(ctx, run.job.JobID, domain.Failed, errorMsg)
+	s.releaseIdempotencyKey(ctx)
+	s.notifyJob(run, domain.Failed, errorMsg)
+}
+
+// completeJob persists the results of a finished reconciliation, marks the
+// job completed and returns its summary. A dry run skips persistence; the
+// summary is built from the in-memory results either way.
+// Once matching has finished the results are stored in full, so a request
+// cancelled now never leaves a job half saved.
+func (s *reconciliationService) completeJob(ctx context.Context, run *jobRun, output *matcher.ReconciliationOutput, totalProcessed int) *domain.ReconciliationSummary {
+	ctx = context.WithoutCancel(ctx)
+	job, jobID, dryRun := run.job, run.job.JobID, run.dryRun
+
+	if output.ExcludedSystem > 0 || output.ExcludedBank > 0 {
+		logger.GetLogger().WithFields(map[string]interface{}{
+			"job_id":          jobID,
+			"excluded_system": output.ExcludedSystem,
+			"excluded_bank":   output.ExcludedBank,
+		}).Info("Items excluded by amount bounds")
+	}
 
 	// Save results
 	results := s.engine.BuildResults(jobID, output)
-	if err := s.reconRepo.BulkCreateResults(results); err != nil {
-		logger.GetLogger().WithError(err).Error("Failed to save results")
+	if !dryRun {
+		stored := results
+		if s.exceptionsOnly {
+			stored = exceptionResults(results)
+		}
+		if s.hashChain {
+			head := s.chainResults(jobID, stored)
+			job.ResultChainHead = &head
+			job.ResultChainVersion = chainVersion
+		}
+		s.writeResults(ctx, job, stored)
 	}
 
 	// Update job status
 	totalDiscrepancies := s.engine.CalculateDiscrepancyTotal(output)
-	job.TotalProcessed = len(systemTransactions) + len(allBankStatements)
+	job.TotalProcessed = totalProcessed
 	job.TotalMatched = len(output.Matched)
 	job.TotalUnmatched = len(output.UnmatchedSystem) + len(output.UnmatchedBank)
 	job.TotalDiscrepancies = totalDiscrepancies
+	job.NetDiscrepancy = s.engine.CalculateNetDiscrepancy(output)
 	job.Status = domain.Completed
+	breach := s.thresholdBreach(job, output)
+	if breach != "" {
+		job.Status = domain.Failed
+		job.ErrorMessage = &breach
+	}
 
-	if err := s.reconRepo.UpdateJob(job); err != nil {
+	if dryRun {
+		logger.GetLogger().Info("Dry-run reconciliation completed")
+		summary := s.buildSummary(job, output, results)
+		summary.DryRun = true
+		summary.FailureReason = breach
+		return summary
+	}
+
+	if err := s.reconRepo.UpdateJob(ctx, job); err != nil {
 		logger.GetLogger().WithError(err).Error("Failed to update job")
 	}
 
-	// Build summary
-	summary := s.buildSummary(jobID, output, job)
+	if breach != "" {
+		logger.GetLogger().WithFields(map[string]interface{}{
+			"job_id": jobID,
+			"reason": breach,
+		}).Warn("Reconciliation job failed its thresholds")
+		s.metrics.JobFailed(run.input)
+		s.releaseIdempotencyKey(ctx)
+		s.notifyJob(run, domain.Failed, breach)
+		summary := s.buildSummary(job, output, results)
+		summary.FailureReason = breach
+		return summary
+	}
+
+	discrepancy, _ := totalDiscrepancies.Float64()
+	s.metrics.JobCompleted(run.input, time.Since(run.started), job.TotalMatched, job.TotalUnmatched, discrepancy)
 
 	logger.GetLogger().WithField("job_id", jobID).Info("Reconciliation job completed")
+	s.notifyJob(run, domain.Completed, "")
 
-	return summary, nil
+	return s.buildSummary(job, output, results)
+}
+
+// exceptionResults returns the results other than MATCHED, in order
+func exceptionResults(results []domain.ReconciliationResult) []domain.ReconciliationResult {
+	exceptions := make([]domain.ReconciliationResult, 0, len(results))
+	for _, result := range results {
+		if result.MatchStatus != domain.Matched {
+			exceptions = append(exceptions, result)
+		}
+	}
+	return exceptions
+}
+
+// saveResults stores results in batches of batchSize, each committed on its
+// own, so a large job never holds one long insert transaction
+func (s *reconciliationService) saveResults(ctx context.Context, jobID string, results []domain.ReconciliationResult) {
+	size := s.batchSize
+	if size <= 0 {
+		size = len(results)
+	}
+	for start := 0; start < len(results); start += size {
+		end := start + size
+		if end > len(results) {
+			end = len(results)
+		}
+		batchCtx, span := tracing.Start(ctx, "reconcile.bulk_create_results",
+			attribute.String("job_id", jobID),
+			attribute.Int("rows", end-start),
+		)
+		err := s.reconRepo.BulkCreateResults(batchCtx, results[start:end])
+		tracing.End(span, err)
+		if err != nil {
+			logger.GetLogger().WithError(err).WithField("job_id", jobID).Error("Failed to save results")
+		}
+	}
+}
+
+// saveBankStatements stores every statement loaded for a job, including
+// those outside its date range, as a record of what the bank sent
+func (s *reconciliationService) saveBankStatements(ctx context.Context, run *jobRun, statements []domain.BankStatement) {
+	if run.dryRun || !s.persistBankStatements || s.bankRepo == nil {
+		return
+	}
+	for i := range statements {
+		statements[i].JobID = run.job.JobID
+	}
+	if err := s.bankRepo.BulkCreate(ctx, statements); err != nil {
+		logger.GetLogger().WithError(err).WithField("job_id", run.job.JobID).Error("Failed to save bank statements")
+	}
+}
+
+// reconcileByDirection runs separate debit and credit passes and merges them
+func (s *reconciliationService) reconcileByDirection(
+	input matcher.ReconciliationInput,
+) (*matcher.ReconciliationOutput, *domain.DirectionSummary, *domain.DirectionSummary, error) {
+	// Flipped signs decide the pass a statement goes to
+	input.BankStatements = s.engine.NormalizeBankAmounts(input.BankStatements)
+	debitInput, creditInput := matcher.SplitByDirection(input)
+
+	debitOutput, err := s.engine.Reconcile(debitInput)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("debit pass failed: %w", err)
+	}
+
+	creditOutput, err := s.engine.Reconcile(creditInput)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("credit pass failed: %w", err)
+	}
+
+	output := matcher.MergeOutputs(debitOutput, creditOutput)
+	return output, s.directionSummary(debitInput, debitOutput), s.directionSummary(creditInput, creditOutput), nil
+}
+
+// sourceSummaries summarizes each bank source's pass on its own, counting
+// every system transaction as processed by each
+func (s *reconciliationService) sourceSummaries(
+	jobID string,
+	input matcher.ReconciliationInput,
+	outputs map[string]*matcher.ReconciliationOutput,
+) map[string]*domain.ReconciliationSummary {
+	bankCounts := make(map[string]int, len(outputs))
+	for _, stmt := range input.BankStatements {
+		bankCounts[stmt.Source]++
+	}
+
+	summaries := make(map[string]*domain.ReconciliationSummary, len(outputs))
+	for source, output := range outputs {
+		job := &domain.ReconciliationJob{
+			JobID:              jobID,
+			TotalProcessed:     len(input.SystemTransactions) + bankCounts[source],
+			TotalMatched:       len(output.Matched),
+			TotalUnmatched:     len(output.UnmatchedSystem) + len(output.UnmatchedBank),
+			TotalDiscrepancies: s.engine.CalculateDiscrepancyTotal(output),
+			NetDiscrepancy:     s.engine.CalculateNetDiscrepancy(output),
+		}
+		summaries[source] = s.buildSummary(job, output, s.engine.BuildResults(jobID, output))
+	}
+	return summaries
+}
+
+func (s *reconciliationService) directionSummary(input matcher.ReconciliationInput, output *matcher.ReconciliationOutput) *domain.DirectionSummary {
+	return &domain.DirectionSummary{
+		TotalProcessed:     len(input.SystemTransactions) + len(input.BankStatements),
+		TotalMatched:       len(output.Matched),
+		TotalUnmatched:     len(output.UnmatchedSystem) + len(output.UnmatchedBank),
+		TotalDiscrepancies: s.engine.CalculateDiscrepancyTotal(output),
+		NetDiscrepancy:     s.engine.CalculateNetDiscrepancy(output),
+	}
+}
+
+func (s *reconciliationService) GetJobStatus(ctx context.Context, jobID string) (*domain.ReconciliationJob, error) {
+	return s.reconRepo.GetJobByID(ctx, jobID)
+}
+
+func (s *reconciliationService) ListJobs(ctx context.Context, filter domain.JobFilter, page, size int) (*domain.JobPage, error) {
+	jobs, total, err := s.reconRepo.ListJobs(ctx, filter, size, (page-1)*size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return &domain.JobPage{
+		Jobs:       jobs,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
+	}, nil
+}
+
+func (s *reconciliationService) DeleteJob(ctx context.Context, jobID string) error {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.Status == domain.Processing {
+		return ErrJobProcessing
+	}
+
+	if err := s.reconRepo.DeleteJob(ctx, jobID); err != nil {
+		return err
+	}
+	s.summaries.invalidate(jobID)
+	logger.GetLogger().WithField("job_id", jobID).Info("Reconciliation job deleted")
+	return nil
 }
 
-func (s *reconciliationService) GetJobStatus(jobID string) (*domain.ReconciliationJob, error) {
-	return s.reconRepo.GetJobByID(jobID)
+// RerunJob reuses the stored date range. Bank files are not retained with a
+// job, so the rerun reads bank statements from the database.
+func (s *reconciliationService) RerunJob(ctx context.Context, jobID string) (*domain.ReconciliationSummary, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.GetLogger().WithField("job_id", jobID).Info("Re-running reconciliation job")
+	s.summaries.invalidate(jobID)
+	// A re-run stores what the original job stored
+	scoped := *s
+	scoped.exceptionsOnly = scoped.exceptionsOnly || job.ExceptionsOnly
+	return scoped.ReconcileFromDatabase(ctx, job.StartDate, job.EndDate, false)
 }
 
-func (s *reconciliationService) GetJobSummary(jobID string) (*domain.ReconciliationSummary, error) {
-	job, err := s.reconRepo.GetJobByID(jobID)
+func (s *reconciliationService) GetJobSummary(ctx context.Context, jobID string, minDiscrepancy *decimal.Decimal, minConfidence *float64) (*domain.ReconciliationSummary, error) {
+	key := newSummaryKey(jobID, minDiscrepancy, minConfidence)
+	if summary, ok := s.summaries.get(key); ok {
+		return summary, nil
+	}
+
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
 	if err != nil {
 		return nil, err
 	}
 
-	discrepancies, _ := s.reconRepo.GetResultsByJobIDAndStatus(jobID, domain.Discrepancy)
-	unmatchedSystem, _ := s.reconRepo.GetResultsByJobIDAndStatus(jobID, domain.UnmatchedSystem)
-	unmatchedBank, _ := s.reconRepo.GetResultsByJobIDAndStatus(jobID, domain.UnmatchedBank)
+	var results []domain.ReconciliationResult
+	truncated := false
+	for _, status := range summaryStatuses {
+		filter := domain.ResultFilter{Status: status}
+		if status == domain.Discrepancy {
+			filter.MinDiscrepancy = minDiscrepancy
+		}
+		if pairedStatuses[status] {
+			filter.MinConfidence = minConfidence
+		}
+		statusResults, total, err := s.reconRepo.QueryResults(ctx, jobID, filter, summaryResultLimit, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s results: %w", status, err)
+		}
+		results = append(results, statusResults...)
+		truncated = truncated || total > len(statusResults)
+	}
+
+	summary := newSummary(job, results)
+	summary.Truncated = summary.Truncated || truncated
+	// The listed results may be capped, so age every unmatched result by count
+	if summary.UnmatchedAging, err = s.unmatchedAging(ctx, job); err != nil {
+		return nil, err
+	}
+	// Only a completed job's results are settled
+	if job.Status == domain.Completed {
+		s.summaries.put(key, summary)
+	}
+	return summary, nil
+}
 
-	// Group unmatched bank by source
-	unmatchedBankBySource := make(map[string][]domain.ReconciliationResult)
-	for _, result := range unmatchedBank {
-		source := "unknown"
-		if result.BankSource != nil {
-			source = *result.BankSource
+// unmatchedAging buckets a job's stored unmatched results by counting those
+// older than each bucket boundary
+func (s *reconciliationService) unmatchedAging(ctx context.Context, job *domain.ReconciliationJob) (*domain.AgingBuckets, error) {
+	ran := runDate(job)
+	counts := make([]int, 0, len(domain.AgingBoundaries)+1)
+	for _, minAge := range append([]int{0}, domain.AgingBoundaries...) {
+		before := domain.AgedBefore(ran, minAge)
+		_, total, err := s.reconRepo.QueryResults(ctx, job.JobID, domain.ResultFilter{UnmatchedBefore: &before}, 0, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count unmatched results: %w", err)
 		}
-		unmatchedBankBySource[source] = append(unmatchedBankBySource[source], result)
+		counts = append(counts, total)
+	}
+	if counts[0] == 0 {
+		return nil, nil
+	}
+	return &domain.AgingBuckets{
+		Days0To1:  counts[0] - counts[1],
+		Days2To7:  counts[1] - counts[2],
+		Days8To30: counts[2] - counts[3],
+		Over30:    counts[3],
+	}, nil
+}
+
+// runDate is the day a job's unmatched results age against: the day it was
+// created, or today for a dry run that was never stored
+func runDate(job *domain.ReconciliationJob) time.Time {
+	if job.CreatedAt.IsZero() {
+		return time.Now()
 	}
+	return job.CreatedAt
+}
+
+// setAge records the age on the job's run date of each unmatched result
+func setAge(result *domain.ReconciliationResult, ran time.Time) (int, bool) {
+	if !domain.IsUnmatched(result.MatchStatus) || result.TransactionDate == nil {
+		return 0, false
+	}
+	age := domain.AgeDays(*result.TransactionDate, ran)
+	result.AgeDays = &age
+	return age, true
+}
 
-	return &domain.ReconciliationSummary{
-		JobID:              jobID,
+func (s *reconciliationService) GetJobStats(ctx context.Context, jobID string) (*domain.JobStats, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.reconRepo.GetResultCountsByStatus(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count results: %w", err)
+	}
+
+	stats := &domain.JobStats{
+		JobID:              job.JobID,
+		Status:             job.Status,
+		StartDate:          job.StartDate,
+		EndDate:            job.EndDate,
 		TotalProcessed:     job.TotalProcessed,
 		TotalMatched:       job.TotalMatched,
 		TotalUnmatched:     job.TotalUnmatched,
 		TotalDiscrepancies: job.TotalDiscrepancies,
-		UnmatchedSystem:    unmatchedSystem,
-		UnmatchedBank:      unmatchedBankBySource,
-		Discrepancies:      discrepancies,
+		NetDiscrepancy:     job.NetDiscrepancy,
+		StatusCounts:       make(map[domain.MatchStatus]int),
+		BySource:           make(map[string]map[domain.MatchStatus]int),
+	}
+	for _, count := range counts {
+		stats.TotalResults += count.Count
+		stats.StatusCounts[count.MatchStatus] += count.Count
+		if count.BankSource == "" {
+			continue
+		}
+		source, ok := stats.BySource[count.BankSource]
+		if !ok {
+			source = make(map[domain.MatchStatus]int)
+			stats.BySource[count.BankSource] = source
+		}
+		source[count.MatchStatus] += count.Count
+	}
+	return stats, nil
+}
+
+func (s *reconciliationService) GetJobResults(ctx context.Context, jobID string, filter domain.ResultFilter, page, size int) (*domain.ResultPage, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resultPage(ctx, job, filter, page, size)
+}
+
+// resultPage returns one page of job's results matching filter
+func (s *reconciliationService) resultPage(ctx context.Context, job *domain.ReconciliationJob, filter domain.ResultFilter, page, size int) (*domain.ResultPage, error) {
+	ran := runDate(job)
+	results, total, err := s.reconRepo.QueryResults(ctx, job.JobID, ageFilter(filter, ran), size, (page-1)*size)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		setAge(&results[i], ran)
+	}
+
+	return &domain.ResultPage{
+		Results:    results,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: (total + size - 1) / size,
 	}, nil
 }
 
-func (s *reconciliationService) loadSystemTransactionsFromCSV(filePath string) ([]domain.Transaction, error) {
-	parser := parser.NewTransactionCSVParser()
+func (s *reconciliationService) GetJobResultsAfter(ctx context.Context, jobID string, filter domain.ResultFilter, cursor *domain.ResultCursor, size int) (*domain.ResultCursorPage, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page follows
+	ran := runDate(job)
+	results, err := s.reconRepo.QueryResultsAfter(ctx, jobID, ageFilter(filter, ran), cursor, size+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ResultCursorPage{Size: size}
+	if len(results) > size {
+		results = results[:size]
+		page.NextCursor = domain.CursorAfter(results[size-1]).Encode()
+	}
+	for i := range results {
+		setAge(&results[i], ran)
+	}
+	page.Results = results
+	return page, nil
+}
+
+// ageFilter turns the filter's MinAgeDays into the UnmatchedBefore date the
+// repository compares against
+func ageFilter(filter domain.ResultFilter, ran time.Time) domain.ResultFilter {
+	if filter.MinAgeDays > 0 {
+		before := domain.AgedBefore(ran, filter.MinAgeDays)
+		filter.UnmatchedBefore = &before
+	}
+	return filter
+}
+
+// StreamJobResults hands a job's persisted results to callback in batches
+func (s *reconciliationService) StreamJobResults(ctx context.Context, jobID string, callback func([]domain.ReconciliationResult) error) error {
+	if _, err := s.reconRepo.GetJobByID(ctx, jobID); err != nil {
+		return err
+	}
+	return s.reconRepo.GetResultsByJobIDStream(ctx, jobID, s.batchSize, callback)
+}
+
+// PageJobResults hands a job's results to callback one keyset page of
+// batchSize at a time, with unmatched ages set. Each page is a short query,
+// so no connection is held open while callback writes a slow export.
+func (s *reconciliationService) PageJobResults(ctx context.Context, jobID string, callback func([]domain.ReconciliationResult) error) error {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	ran := runDate(job)
+	var cursor *domain.ResultCursor
+	for {
+		results, err := s.reconRepo.QueryResultsAfter(ctx, jobID, domain.ResultFilter{}, cursor, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		for i := range results {
+			setAge(&results[i], ran)
+		}
+		if err := callback(results); err != nil {
+			return err
+		}
+		if len(results) < s.batchSize {
+			return nil
+		}
+		next := domain.CursorAfter(results[len(results)-1])
+		cursor = &next
+	}
+}
+
+// loadSystemTransactionsFromCSV concatenates the transactions of every
+// system file and reports the IDs that appear in more than one of them
+func (s *reconciliationService) loadSystemTransactionsFromCSV(ctx context.Context, filePaths []string) ([]domain.Transaction, []domain.DuplicateTransaction, []domain.FileLoadReport, error) {
+	_, span := tracing.Start(ctx, "reconcile.load_system_files", attribute.Int("files", len(filePaths)))
 	var transactions []domain.Transaction
+	filesByID := make(map[string][]string)
+	loadReport := make([]domain.FileLoadReport, 0, len(filePaths))
 
-	err := parser.Parse(filePath, s.batchSize, func(batch []domain.Transaction) error {
-		transactions = append(transactions, batch...)
-		return nil
-	})
+	for _, filePath := range filePaths {
+		var parseReport parser.ParseReport
+		parser := parser.NewTransactionCSVParser(s.reportingParserOpts(&parseReport)...)
+		name := filepath.Base(filePath)
+		inFile := make(map[string]bool)
+		loaded := len(transactions)
 
-	return transactions, err
+		err := parser.Parse(filePath, s.batchSize, func(batch []domain.Transaction) error {
+			for _, tx := range batch {
+				if !inFile[tx.TrxID] {
+					inFile[tx.TrxID] = true
+					filesByID[tx.TrxID] = append(filesByID[tx.TrxID], name)
+				}
+			}
+			transactions = append(transactions, batch...)
+			return nil
+		})
+		if err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			tracing.End(span, err)
+			return nil, nil, nil, err
+		}
+		rows := len(transactions) - loaded
+		loadReport = append(loadReport, domain.FileLoadReport{
+			File: name, Rows: rows, Empty: rows == 0, SkippedRows: parseReport.SkippedRows, RowErrors: parseReport.RowErrors,
+		})
+	}
+
+	var duplicates []domain.DuplicateTransaction
+	for _, tx := range transactions {
+		if files := filesByID[tx.TrxID]; len(files) > 1 {
+			duplicates = append(duplicates, domain.DuplicateTransaction{TrxID: tx.TrxID, Files: files})
+			delete(filesByID, tx.TrxID)
+		}
+	}
+	span.SetAttributes(attribute.Int("rows", len(transactions)), attribute.Int("duplicates", len(duplicates)))
+	tracing.End(span, nil)
+	return transactions, duplicates, loadReport, nil
+}
+
+// noBankStatements explains why no bank statements loaded: ErrEmptyInput
+// when every file parsed but had no data rows, rather than failing to load
+func noBankStatements(loadReport []domain.FileLoadReport) error {
+	if len(loadReport) == 0 {
+		return fmt.Errorf("no bank statements loaded")
+	}
+	for _, report := range loadReport {
+		if !report.Empty {
+			return fmt.Errorf("no bank statements loaded")
+		}
+	}
+	return fmt.Errorf("no bank statements loaded: %w: %s", ErrEmptyInput, emptyFiles(loadReport))
 }
 
-func (s *reconciliationService) loadBankStatementsFromCSV(filePath string) ([]domain.BankStatement, error) {
-	source := extractBankSource(filePath)
-	parser := parser.NewCSVBankStatementParser(source)
+// emptyFiles lists the files of loadReport that had no data rows
+func emptyFiles(loadReport []domain.FileLoadReport) string {
+	var names []string
+	for _, report := range loadReport {
+		if report.Empty {
+			names = append(names, report.File)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (s *reconciliationService) loadBankStatementsFromFile(filePath, source string) ([]domain.BankStatement, error) {
+	parser := s.bankStatementParser(filePath, source)
 	var statements []domain.BankStatement
 
 	err := parser.Parse(filePath, s.batchSize, func(batch []domain.BankStatement) error {
@@ -204,30 +1274,67 @@ func (s *reconciliationService) loadBankStatementsFromCSV(filePath string) ([]do
 	return statements, err
 }
 
-func (s *reconciliationService) filterByDateRange(transactions []domain.Transaction, startDate, endDate time.Time) []domain.Transaction {
+// bankStatementParser picks the parser for a bank file from its extension
+func (s *reconciliationService) bankStatementParser(filePath, source string) parser.BankStatementParser {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == ".gz" {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(filePath, filepath.Ext(filePath))))
+	}
+	opts := s.parserOpts
+	if layout, ok := s.dateLayouts[source]; ok {
+		opts = append(slices.Clip(opts), parser.WithDateLayout(layout))
+	}
+	switch ext {
+	case ".xlsx":
+		return parser.NewXLSXBankStatementParserWithMapping(source, s.columnMappings[source], opts...)
+	case ".jsonl", ".ndjson":
+		return parser.NewJSONLBankStatementParserWithMapping(source, s.columnMappings[source], opts...)
+	}
+	return parser.NewCSVBankStatementParserWithMapping(source, s.columnMappings[source], opts...)
+}
+
+// bankSource identifies a bank file by content when fingerprints are
+// configured and one matches, and by its file name otherwise
+func (s *reconciliationService) bankSource(filePath string) string {
+	source, found, err := parser.DetectSource(filePath, s.sourceFingerprints, s.parserOpts...)
+	if err != nil {
+		logger.GetLogger().WithError(err).WithField("file", filePath).Warn("Failed to detect bank source, using file name")
+	}
+	if !found {
+		return extractBankSource(filePath)
+	}
+
+	logger.GetLogger().WithFields(map[string]interface{}{
+		"file":   filepath.Base(filePath),
+		"source": source,
+	}).Debug("Detected bank source from file content")
+	return source
+}
+
+// filterByDateRange keeps transactions with startDate <= time < endBefore
+func (s *reconciliationService) filterByDateRange(transactions []domain.Transaction, startDate, endBefore time.Time) []domain.Transaction {
 	filtered := make([]domain.Transaction, 0)
 	for _, tx := range transactions {
-		if (tx.TransactionTime.Equal(startDate) || tx.TransactionTime.After(startDate)) &&
-			(tx.TransactionTime.Equal(endDate) || tx.TransactionTime.Before(endDate)) {
+		if !tx.TransactionTime.Before(startDate) && tx.TransactionTime.Before(endBefore) {
 			filtered = append(filtered, tx)
 		}
 	}
 	return filtered
 }
 
-func (s *reconciliationService) filterBankStatementsByDateRange(statements []domain.BankStatement, startDate, endDate time.Time) []domain.BankStatement {
+// filterBankStatementsByDateRange keeps statements with startDate <= date < endBefore
+func (s *reconciliationService) filterBankStatementsByDateRange(statements []domain.BankStatement, startDate, endBefore time.Time) []domain.BankStatement {
 	filtered := make([]domain.BankStatement, 0)
 	for _, stmt := range statements {
-		if (stmt.Date.Equal(startDate) || stmt.Date.After(startDate)) &&
-			(stmt.Date.Equal(endDate) || stmt.Date.Before(endDate)) {
+		if !stmt.Date.Before(startDate) && stmt.Date.Before(endBefore) {
 			filtered = append(filtered, stmt)
 		}
 	}
 	return filtered
 }
 
-func (s *reconciliationService) updateJobStatus(jobID string, status domain.JobStatus, errorMsg string) {
-	job, err := s.reconRepo.GetJobByID(jobID)
+func (s *reconciliationService) updateJobStatus(ctx context.Context, jobID string, status domain.JobStatus, errorMsg string) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
 	if err != nil {
 		return
 	}
@@ -237,81 +1344,102 @@ func (s *reconciliationService) updateJobStatus(jobID string, status domain.JobS
 		job.ErrorMessage = &errorMsg
 	}
 
-	s.reconRepo.UpdateJob(job)
+	s.reconRepo.UpdateJob(ctx, job)
 }
 
-func (s *reconciliationService) buildSummary(jobID string, output *matcher.ReconciliationOutput, job *domain.ReconciliationJob) *domain.ReconciliationSummary {
-	// Convert matched pairs to results
-	matched := make([]domain.ReconciliationResult, len(output.Matched))
-	for i, m := range output.Matched {
-		matched[i] = domain.ReconciliationResult{
-			JobID:           jobID,
-			TrxID:           &m.SystemTx.TrxID,
-			TrxRefID:        &m.BankStmt.TrxRefID,
-			SystemAmount:    &m.SystemTx.Amount,
-			BankAmount:      &m.BankStmt.Amount,
-			MatchStatus:     domain.Matched,
-			BankSource:      &m.BankStmt.Source,
-			TransactionDate: &m.SystemTx.TransactionTime,
-		}
-	}
+// summaryResultLimit caps each result list embedded in a summary
+const summaryResultLimit = 1000
 
-	// Convert discrepancies
-	discrepancies := make([]domain.ReconciliationResult, len(output.Discrepancies))
-	for i, d := range output.Discrepancies {
-		discrepancies[i] = domain.ReconciliationResult{
-			JobID:           jobID,
-			TrxID:           &d.SystemTx.TrxID,
-			TrxRefID:        &d.BankStmt.TrxRefID,
-			SystemAmount:    &d.SystemTx.Amount,
-			BankAmount:      &d.BankStmt.Amount,
-			Discrepancy:     &d.Discrepancy,
-			MatchStatus:     domain.Discrepancy,
-			BankSource:      &d.BankStmt.Source,
-			TransactionDate: &d.SystemTx.TransactionTime,
-		}
+// summaryStatuses are the exception statuses listed in a summary
+var summaryStatuses = []domain.MatchStatus{
+	domain.Discrepancy,
+	domain.UnmatchedSystem,
+	domain.UnmatchedBank,
+	domain.DateMismatch,
+	domain.CurrencyMismatch,
+	domain.DirectionMismatch,
+	domain.DuplicateSystem,
+	domain.DuplicateBank,
+	domain.MalformedReference,
+	domain.AmbiguousMatch,
+}
+
+// newSummary builds a summary from job totals, listing exception results by status
+func newSummary(job *domain.ReconciliationJob, results []domain.ReconciliationResult) *domain.ReconciliationSummary {
+	summary := &domain.ReconciliationSummary{
+		JobID:              job.JobID,
+		TotalProcessed:     job.TotalProcessed,
+		TotalMatched:       job.TotalMatched,
+		TotalUnmatched:     job.TotalUnmatched,
+		TotalDiscrepancies: job.TotalDiscrepancies,
+		NetDiscrepancy:     job.NetDiscrepancy,
+		UnmatchedBank:      make(map[string][]domain.ReconciliationResult),
 	}
 
-	// Convert unmatched system
-	unmatchedSystem := make([]domain.ReconciliationResult, len(output.UnmatchedSystem))
-	for i, u := range output.UnmatchedSystem {
-		unmatchedSystem[i] = domain.ReconciliationResult{
-			JobID:           jobID,
-			TrxID:           &u.TrxID,
-			SystemAmount:    &u.Amount,
-			MatchStatus:     domain.UnmatchedSystem,
-			TransactionDate: &u.TransactionTime,
+	ran := runDate(job)
+	var aging domain.AgingBuckets
+	aged := false
+	listed := make(map[domain.MatchStatus]int)
+	for _, result := range results {
+		if age, ok := setAge(&result, ran); ok {
+			aging.Add(age)
+			aged = true
 		}
-	}
+		if listed[result.MatchStatus] >= summaryResultLimit {
+			summary.Truncated = true
+			continue
+		}
+		listed[result.MatchStatus]++
 
-	// Convert and group unmatched bank by source
-	unmatchedBankBySource := make(map[string][]domain.ReconciliationResult)
-	for _, u := range output.UnmatchedBank {
-		result := domain.ReconciliationResult{
-			JobID:           jobID,
-			TrxRefID:        &u.TrxRefID,
-			BankAmount:      &u.Amount,
-			MatchStatus:     domain.UnmatchedBank,
-			BankSource:      &u.Source,
-			TransactionDate: &u.Date,
+		switch result.MatchStatus {
+		case domain.Discrepancy:
+			summary.Discrepancies = append(summary.Discrepancies, result)
+		case domain.UnmatchedSystem:
+			summary.UnmatchedSystem = append(summary.UnmatchedSystem, result)
+		case domain.UnmatchedBank:
+			// Group unmatched bank by source
+			source := "unknown"
+			if result.BankSource != nil {
+				source = *result.BankSource
+			}
+			summary.UnmatchedBank[source] = append(summary.UnmatchedBank[source], result)
+		case domain.DateMismatch:
+			summary.DateMismatches = append(summary.DateMismatches, result)
+		case domain.CurrencyMismatch:
+			summary.CurrencyMismatches = append(summary.CurrencyMismatches, result)
+		case domain.DirectionMismatch:
+			summary.DirectionMismatches = append(summary.DirectionMismatches, result)
+		case domain.DuplicateSystem:
+			summary.DuplicateSystem = append(summary.DuplicateSystem, result)
+		case domain.DuplicateBank:
+			summary.DuplicateBank = append(summary.DuplicateBank, result)
+		case domain.MalformedReference:
+			summary.MalformedReferences = append(summary.MalformedReferences, result)
+		case domain.AmbiguousMatch:
+			summary.AmbiguousMatches = append(summary.AmbiguousMatches, result)
 		}
-		unmatchedBankBySource[u.Source] = append(unmatchedBankBySource[u.Source], result)
 	}
-
-	return &domain.ReconciliationSummary{
-		JobID:              jobID,
-		TotalProcessed:     job.TotalProcessed,
-		TotalMatched:       job.TotalMatched,
-		TotalUnmatched:     job.TotalUnmatched,
-		TotalDiscrepancies: job.TotalDiscrepancies,
-		UnmatchedSystem:    unmatchedSystem,
-		UnmatchedBank:      unmatchedBankBySource,
-		Discrepancies:      discrepancies,
+	if aged {
+		summary.UnmatchedAging = &aging
 	}
+
+	return summary
+}
+
+func (s *reconciliationService) buildSummary(job *domain.ReconciliationJob, output *matcher.ReconciliationOutput, results []domain.ReconciliationResult) *domain.ReconciliationSummary {
+	summary := newSummary(job, results)
+	summary.ExcludedSystem = output.ExcludedSystem
+	summary.ExcludedBank = output.ExcludedBank
+	summary.Installments = matcher.InstallmentMatches(output)
+	return summary
 }
 
 func extractBankSource(filePath string) string {
 	fileName := filepath.Base(filePath)
+	// A gzipped file reports, and is configured, like the plain file
+	if strings.EqualFold(filepath.Ext(fileName), ".gz") {
+		fileName = fileName[:len(fileName)-len(".gz")]
+	}
 	// Extract bank name from filename (e.g., "bank_bca.csv" -> "bca")
 	return fileName
 }
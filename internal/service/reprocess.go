@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/matcher"
+	"recon-engine/internal/repository"
+	"recon-engine/pkg/logger"
+)
+
+// ErrJobChained is returned when reprocessing a job whose results are
+// protected by a hash chain, which replacing results would break
+var ErrJobChained = errors.New("reconciliation job results are hash-chained and cannot be changed")
+
+// ErrResultsChanged is returned when another reprocess of the job replaced
+// the same results first
+var ErrResultsChanged = repository.ErrResultsChanged
+
+// pairedStatuses are the outcomes that pair a system transaction with a bank
+// statement: they carry a confidence and resolve a retried unmatched result
+var pairedStatuses = map[domain.MatchStatus]bool{
+	domain.Matched:           true,
+	domain.Discrepancy:       true,
+	domain.DateMismatch:      true,
+	domain.CurrencyMismatch:  true,
+	domain.DirectionMismatch: true,
+}
+
+// ReprocessUnmatched matches the completed job's UNMATCHED_SYSTEM results
+// against bankFilePaths and replaces those that now pair with a statement
+// by the new results. Bank rows that pair with none are reported but not
+// stored, since they may belong to transactions the job already matched.
+func (s *reconciliationService) ReprocessUnmatched(ctx context.Context, jobID string, bankFilePaths []string) (*domain.ReprocessSummary, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.Completed {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotCompleted, jobID)
+	}
+	if job.ResultChainHead != nil {
+		return nil, ErrJobChained
+	}
+
+	transactions, unmatched, skipped, err := s.unmatchedTransactions(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transactions) == 0 {
+		return &domain.ReprocessSummary{JobID: jobID, Skipped: skipped, Results: []domain.ReconciliationResult{}, Job: job}, nil
+	}
+
+	bankStatements, loadReport := s.loadBankFiles(ctx, bankFilePaths)
+	if len(bankStatements) == 0 {
+		return nil, noBankStatements(loadReport)
+	}
+	s.saveBankStatements(ctx, &jobRun{job: job}, bankStatements)
+	bankStatements = s.filterBankStatementsByDateRange(bankStatements, job.StartDate, domain.DayAfter(job.EndDate))
+
+	output, err := s.engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: transactions,
+		BankStatements:     bankStatements,
+		StartDate:          job.StartDate,
+		EndDate:            job.EndDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reconciliation failed: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Each replacement brings one bank statement into the job's totals
+	delta := domain.JobTotalsDelta{TotalDiscrepancies: decimal.Zero, NetDiscrepancy: decimal.Zero}
+	added := make([]domain.ReconciliationResult, 0)
+	var removedIDs []int
+	var replacements []ResultReplacement
+	for _, result := range s.engine.BuildResults(jobID, output) {
+		if result.TrxID == nil || !pairedStatuses[result.MatchStatus] {
+			continue
+		}
+		removed, ok := unmatched[*result.TrxID]
+		if !ok {
+			continue
+		}
+		added = append(added, result)
+		removedIDs = append(removedIDs, removed.ID)
+		replacements = append(replacements, ResultReplacement{Removed: []domain.ReconciliationResult{removed}, Added: result})
+		delete(unmatched, *result.TrxID)
+
+		delta.Processed++
+		delta.Unmatched--
+		switch result.MatchStatus {
+		case domain.Matched:
+			delta.Matched++
+		case domain.Discrepancy:
+			delta.TotalDiscrepancies = delta.TotalDiscrepancies.Add(*result.Discrepancy)
+			delta.NetDiscrepancy = delta.NetDiscrepancy.Add(*result.SignedDiscrepancy)
+		}
+	}
+
+	updated, err := s.reconRepo.ReplaceUnmatchedResults(ctx, jobID, removedIDs, added, delta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace results: %w", err)
+	}
+	s.summaries.invalidate(jobID)
+	s.replaceResults(ctx, job, replacements)
+
+	logger.GetLogger().WithFields(map[string]interface{}{
+		"job_id":      jobID,
+		"reprocessed": len(transactions),
+		"resolved":    len(removedIDs),
+	}).Info("Reprocessed unmatched results")
+
+	return &domain.ReprocessSummary{
+		JobID:          jobID,
+		Reprocessed:    len(transactions),
+		Resolved:       len(removedIDs),
+		StillUnmatched: len(transactions) - len(removedIDs),
+		Skipped:        skipped,
+		UnmatchedBank:  len(output.UnmatchedBank),
+		Results:        added,
+		FileLoadReport: loadReport,
+		Job:            updated,
+	}, nil
+}
+
+// unmatchedTransactions rebuilds the system transactions behind the job's
+// UNMATCHED_SYSTEM results, which it returns keyed by trx_id. Results are
+// skipped when their transaction type is neither stored on the result nor
+// found in the transactions table.
+func (s *reconciliationService) unmatchedTransactions(ctx context.Context, jobID string) ([]domain.Transaction, map[string]domain.ReconciliationResult, int, error) {
+	var unmatched []domain.ReconciliationResult
+	err := s.reconRepo.GetResultsByJobIDStream(ctx, jobID, s.batchSize, func(batch []domain.ReconciliationResult) error {
+		for _, result := range batch {
+			if result.MatchStatus == domain.UnmatchedSystem && result.TrxID != nil {
+				unmatched = append(unmatched, result)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to load unmatched results: %w", err)
+	}
+
+	transactions := make([]domain.Transaction, 0, len(unmatched))
+	byTrxID := make(map[string]domain.ReconciliationResult, len(unmatched))
+	skipped := 0
+	for _, result := range unmatched {
+		if _, seen := byTrxID[*result.TrxID]; seen {
+			skipped++
+			continue
+		}
+		tx, ok := s.rebuildTransaction(ctx, result)
+		if !ok {
+			skipped++
+			continue
+		}
+		transactions = append(transactions, tx)
+		byTrxID[tx.TrxID] = result
+	}
+	if skipped > 0 {
+		logger.GetLogger().WithFields(map[string]interface{}{
+			"job_id":  jobID,
+			"skipped": skipped,
+		}).Warn("Some unmatched results could not be reprocessed")
+	}
+	return transactions, byTrxID, skipped, nil
+}
+
+// rebuildTransaction recovers the system transaction an unmatched result
+// was built from
+func (s *reconciliationService) rebuildTransaction(ctx context.Context, result domain.ReconciliationResult) (domain.Transaction, bool) {
+	tx := domain.Transaction{TrxID: *result.TrxID, Amount: decimal.Zero}
+	if result.SystemAmount != nil {
+		tx.Amount = *result.SystemAmount
+	}
+	if result.TransactionDate != nil {
+		tx.TransactionTime = *result.TransactionDate
+	}
+	if result.Currency != nil {
+		tx.Currency = *result.Currency
+	}
+	if result.Description != nil {
+		tx.Description = *result.Description
+	}
+	if result.TransactionCreatedAt != nil {
+		tx.CreatedAt = *result.TransactionCreatedAt
+	}
+	if result.TransactionType != nil {
+		tx.Type = *result.TransactionType
+		return tx, true
+	}
+
+	// Results are enriched with the type only when configured; jobs read
+	// from the database can look it up
+	if s.txRepo == nil {
+		return tx, false
+	}
+	stored, err := s.txRepo.GetByTrxID(ctx, tx.TrxID)
+	if err != nil {
+		return tx, false
+	}
+	tx.Type = stored.Type
+	tx.CreatedAt = stored.CreatedAt
+	return tx, true
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/pkg/kafka"
+	"recon-engine/pkg/logger"
+)
+
+// defaultSinkTimeout bounds one delivery to the sinks unless
+// WithSinkTimeout says otherwise
+const defaultSinkTimeout = time.Minute
+
+// ResultSink receives the results of each completed job, besides or instead
+// of the results table, and the later changes to them. An error is logged
+// and never fails the job or the change.
+type ResultSink interface {
+	WriteResults(ctx context.Context, job *domain.ReconciliationJob, results []domain.ReconciliationResult) error
+	// ReplaceResults receives the results a reprocess or manual match
+	// replaced, each with the result that replaced them
+	ReplaceResults(ctx context.Context, job *domain.ReconciliationJob, replacements []ResultReplacement) error
+}
+
+// ResultReplacement is one result added to a completed job in place of
+// the unmatched results in Removed
+type ResultReplacement struct {
+	Removed []domain.ReconciliationResult
+	Added   domain.ReconciliationResult
+}
+
+// WithResultSinks sends the results of each completed job to sinks after
+// they are saved, and their replacements when unmatched results are
+// reprocessed or manually matched. Deliveries run in the background, in the
+// order they were made, so a slow sink never holds up a request. Dry runs
+// send nothing.
+func WithResultSinks(sinks ...ResultSink) ServiceOption {
+	return func(s *reconciliationService) {
+		s.sinks = append(s.sinks, sinks...)
+	}
+}
+
+// WithSinkTimeout bounds each delivery to the sinks, from when it starts;
+// timeout <= 0 keeps the default of one minute
+func WithSinkTimeout(timeout time.Duration) ServiceOption {
+	return func(s *reconciliationService) {
+		if timeout > 0 {
+			s.sinkTimeout = timeout
+		}
+	}
+}
+
+// WithRepositoryResults stores results in the results table, the default.
+// Disabled, results only reach the sinks given with WithResultSinks; jobs
+// and their totals are still stored, but the result endpoints, manual
+// matching and reprocessing find nothing.
+func WithRepositoryResults(enabled bool) ServiceOption {
+	return func(s *reconciliationService) {
+		s.skipRepositoryResults = !enabled
+	}
+}
+
+// FlushSinks waits until the deliveries already handed to the sinks are
+// done, or ctx is
+func (s *reconciliationService) FlushSinks(ctx context.Context) error {
+	return s.sinkQueue.wait(ctx)
+}
+
+// writeResults saves results in the results table, unless disabled, and
+// queues them for each sink
+func (s *reconciliationService) writeResults(ctx context.Context, job *domain.ReconciliationJob, results []domain.ReconciliationResult) {
+	if !s.skipRepositoryResults {
+		s.saveResults(ctx, job.JobID, results)
+	}
+	s.deliver(ctx, job, func(ctx context.Context, sink ResultSink, job *domain.ReconciliationJob) error {
+		return sink.WriteResults(ctx, job, results)
+	})
+}
+
+// replaceResults queues the replacements made to a completed job for each sink
+func (s *reconciliationService) replaceResults(ctx context.Context, job *domain.ReconciliationJob, replacements []ResultReplacement) {
+	if len(replacements) == 0 {
+		return
+	}
+	s.deliver(ctx, job, func(ctx context.Context, sink ResultSink, job *domain.ReconciliationJob) error {
+		return sink.ReplaceResults(ctx, job, replacements)
+	})
+}
+
+// deliver hands a copy of job to send for each sink in the background, under
+// the sink timeout and detached from ctx's cancellation
+func (s *reconciliationService) deliver(ctx context.Context, job *domain.ReconciliationJob, send func(context.Context, ResultSink, *domain.ReconciliationJob) error) {
+	if len(s.sinks) == 0 {
+		return
+	}
+	sinks, timeout, snapshot := s.sinks, s.sinkTimeout, *job
+	ctx = context.WithoutCancel(ctx)
+	s.sinkQueue.enqueue(func() {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		for _, sink := range sinks {
+			if err := send(ctx, sink, &snapshot); err != nil {
+				logger.GetLogger().WithError(err).WithField("job_id", snapshot.JobID).Warn("Failed to write results to sink")
+			}
+		}
+	})
+}
+
+// sinkQueue runs deliveries one after another in the order they were queued
+type sinkQueue struct {
+	mu sync.Mutex
+	// last is closed once the latest queued delivery is done
+	last chan struct{}
+}
+
+func (q *sinkQueue) enqueue(delivery func()) {
+	q.mu.Lock()
+	prev, done := q.last, make(chan struct{})
+	q.last = done
+	q.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		delivery()
+	}()
+}
+
+func (q *sinkQueue) wait(ctx context.Context) error {
+	q.mu.Lock()
+	last := q.last
+	q.mu.Unlock()
+	if last == nil {
+		return nil
+	}
+	select {
+	case <-last:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MessageProducer publishes keyed messages to a topic, e.g. a *kafka.Producer
+type MessageProducer interface {
+	Produce(ctx context.Context, topic string, messages []kafka.Message) error
+}
+
+// kafkaSink emits each exception of a job as a domain.ExceptionEvent
+type kafkaSink struct {
+	producer MessageProducer
+	topic    string
+	now      func() time.Time
+}
+
+// NewKafkaSink publishes every result other than MATCHED to topic as its
+// own domain.ExceptionEvent, keyed by job ID so a job's events stay in order
+func NewKafkaSink(producer MessageProducer, topic string) ResultSink {
+	return &kafkaSink{producer: producer, topic: topic, now: time.Now}
+}
+
+func (k *kafkaSink) WriteResults(ctx context.Context, job *domain.ReconciliationJob, results []domain.ReconciliationResult) error {
+	exceptions := exceptionResults(results)
+	if len(exceptions) == 0 {
+		return nil
+	}
+
+	emitted := k.now().UTC()
+	messages := make([]kafka.Message, len(exceptions))
+	for i, result := range exceptions {
+		event := exceptionEvent(job, result, domain.ExceptionRaised, emitted)
+		event.EventID = fmt.Sprintf("%s:%d", job.JobID, i)
+		messages[i] = kafka.Message{Key: job.JobID, Value: event}
+	}
+	return k.producer.Produce(ctx, k.topic, messages)
+}
+
+// ReplaceResults publishes a resolved event for each replaced exception and
+// a raised event when its replacement is an exception too, such as a
+// reprocessed result that now pairs with a discrepancy
+func (k *kafkaSink) ReplaceResults(ctx context.Context, job *domain.ReconciliationJob, replacements []ResultReplacement) error {
+	emitted := k.now().UTC()
+	var messages []kafka.Message
+	for _, replacement := range replacements {
+		added := replacement.Added
+		for _, removed := range replacement.Removed {
+			event := exceptionEvent(job, removed, domain.ExceptionResolved, emitted)
+			event.EventID = fmt.Sprintf("%s:resolved:%d", job.JobID, removed.ID)
+			event.ResolvedAs = &added.MatchStatus
+			event.ReviewedBy = added.ReviewedBy
+			messages = append(messages, kafka.Message{Key: job.JobID, Value: event})
+		}
+		if added.MatchStatus != domain.Matched && len(replacement.Removed) > 0 {
+			event := exceptionEvent(job, added, domain.ExceptionRaised, emitted)
+			event.EventID = fmt.Sprintf("%s:replaced:%d", job.JobID, replacement.Removed[0].ID)
+			messages = append(messages, kafka.Message{Key: job.JobID, Value: event})
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return k.producer.Produce(ctx, k.topic, messages)
+}
+
+// exceptionEvent describes an exception of job; the caller sets its EventID
+func exceptionEvent(job *domain.ReconciliationJob, result domain.ReconciliationResult, eventType domain.ExceptionEventType, emitted time.Time) domain.ExceptionEvent {
+	return domain.ExceptionEvent{
+		SchemaVersion:     domain.ExceptionEventVersion,
+		EventType:         eventType,
+		JobID:             job.JobID,
+		JobStartDate:      job.StartDate,
+		JobEndDate:        job.EndDate,
+		MatchStatus:       result.MatchStatus,
+		TrxID:             result.TrxID,
+		TrxRefID:          result.TrxRefID,
+		SystemAmount:      result.SystemAmount,
+		BankAmount:        result.BankAmount,
+		Discrepancy:       result.Discrepancy,
+		SignedDiscrepancy: result.SignedDiscrepancy,
+		BankSource:        result.BankSource,
+		TransactionDate:   result.TransactionDate,
+		Currency:          result.Currency,
+		BankCurrency:      result.BankCurrency,
+		Description:       result.Description,
+		BankDescription:   result.BankDescription,
+		EmittedAt:         emitted,
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+var (
+	// ErrJobNotCompleted is returned when a rollup, reprocess, recompute,
+	// manual match or diff names a job that has not completed
+	ErrJobNotCompleted = errors.New("reconciliation job is not completed")
+	// ErrNoJobs is returned when a rollup covers no completed jobs
+	ErrNoJobs = errors.New("no completed reconciliation jobs to roll up")
+)
+
+func (s *reconciliationService) RollupJobs(ctx context.Context, jobIDs []string) (*domain.RollupSummary, error) {
+	jobs := make([]domain.ReconciliationJob, 0, len(jobIDs))
+	seen := make(map[string]bool, len(jobIDs))
+	for _, jobID := range jobIDs {
+		if seen[jobID] {
+			continue
+		}
+		seen[jobID] = true
+
+		job, err := s.reconRepo.GetJobByID(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job.Status != domain.Completed {
+			return nil, fmt.Errorf("%w: %s", ErrJobNotCompleted, jobID)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return s.rollup(ctx, jobs)
+}
+
+func (s *reconciliationService) RollupDateRange(ctx context.Context, startDate, endDate time.Time) (*domain.RollupSummary, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start date must be before or equal to end date")
+	}
+	jobs, err := s.reconRepo.ListCompletedJobs(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return s.rollup(ctx, jobs)
+}
+
+// rollup combines the persisted results of jobs, given oldest first. Jobs are
+// read newest first and a result is dropped when a newer job already covered
+// its system transaction or bank statement, so reruns and overlapping date
+// ranges count once with their latest outcome.
+func (s *reconciliationService) rollup(ctx context.Context, jobs []domain.ReconciliationJob) (*domain.RollupSummary, error) {
+	if len(jobs) == 0 {
+		return nil, ErrNoJobs
+	}
+
+	summary := &domain.RollupSummary{
+		JobIDs:             make([]string, 0, len(jobs)),
+		StartDate:          jobs[0].StartDate,
+		EndDate:            jobs[0].EndDate,
+		TotalDiscrepancies: decimal.Zero,
+		StatusCounts:       make(map[domain.MatchStatus]int),
+		BySource:           make(map[string]*domain.SourceRollup),
+	}
+	for _, job := range jobs {
+		summary.JobIDs = append(summary.JobIDs, job.JobID)
+		if job.StartDate.Before(summary.StartDate) {
+			summary.StartDate = job.StartDate
+		}
+		if job.EndDate.After(summary.EndDate) {
+			summary.EndDate = job.EndDate
+		}
+	}
+
+	covered := make(map[string]bool)
+	for i := len(jobs) - 1; i >= 0; i-- {
+		// Keys only supersede older jobs; duplicates within a job are kept
+		var jobKeys []string
+		err := s.reconRepo.GetResultsByJobIDStream(ctx, jobs[i].JobID, s.batchSize, func(batch []domain.ReconciliationResult) error {
+			for _, result := range batch {
+				keys := rollupKeys(result)
+				if anyCovered(covered, keys) {
+					summary.SupersededResults++
+					continue
+				}
+				jobKeys = append(jobKeys, keys...)
+				addToRollup(summary, result)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load results of job %s: %w", jobs[i].JobID, err)
+		}
+		for _, key := range jobKeys {
+			covered[key] = true
+		}
+	}
+
+	return summary, nil
+}
+
+// rollupKeys identifies the system transaction and bank statement a result covers
+func rollupKeys(result domain.ReconciliationResult) []string {
+	var keys []string
+	if result.TrxID != nil {
+		keys = append(keys, "system\xff"+*result.TrxID)
+	}
+	if result.TrxRefID != nil && result.BankAmount != nil {
+		source := ""
+		if result.BankSource != nil {
+			source = *result.BankSource
+		}
+		keys = append(keys, "bank\xff"+source+"\xff"+*result.TrxRefID+"\xff"+result.BankAmount.String())
+	}
+	return keys
+}
+
+func anyCovered(covered map[string]bool, keys []string) bool {
+	for _, key := range keys {
+		if covered[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// addToRollup counts a result in the totals and, when it has one, its bank source
+func addToRollup(summary *domain.RollupSummary, result domain.ReconciliationResult) {
+	matched := result.MatchStatus == domain.Matched
+	unmatched := result.MatchStatus == domain.UnmatchedSystem || result.MatchStatus == domain.UnmatchedBank
+	discrepancy := decimal.Zero
+	if result.MatchStatus == domain.Discrepancy && result.Discrepancy != nil {
+		discrepancy = *result.Discrepancy
+	}
+
+	summary.TotalResults++
+	summary.StatusCounts[result.MatchStatus]++
+	summary.TotalDiscrepancies = summary.TotalDiscrepancies.Add(discrepancy)
+	if matched {
+		summary.TotalMatched++
+	}
+	if unmatched {
+		summary.TotalUnmatched++
+	}
+
+	if result.BankSource == nil {
+		return
+	}
+	source, ok := summary.BySource[*result.BankSource]
+	if !ok {
+		source = &domain.SourceRollup{TotalDiscrepancies: decimal.Zero}
+		summary.BySource[*result.BankSource] = source
+	}
+	source.TotalResults++
+	source.TotalDiscrepancies = source.TotalDiscrepancies.Add(discrepancy)
+	if matched {
+		source.TotalMatched++
+	}
+	if unmatched {
+		source.TotalUnmatched++
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"recon-engine/internal/domain"
+)
+
+// WithSummaryCache keeps the summaries of up to size completed jobs in
+// memory for ttl, so repeated GetJobSummary calls skip the result queries.
+// The least recently read summary is evicted first. size <= 0 or ttl <= 0
+// disables the cache. Entries are dropped when a job is deleted, re-run,
+// recomputed or has its results changed through this service; other
+// replicas keep theirs until they expire.
+func WithSummaryCache(size int, ttl time.Duration) ServiceOption {
+	return func(s *reconciliationService) {
+		if size <= 0 || ttl <= 0 {
+			s.summaries = nil
+			return
+		}
+		s.summaries = newSummaryCache(size, ttl)
+	}
+}
+
+// summaryKey identifies a summary by its job and the filters it was read with
+type summaryKey struct {
+	jobID   string
+	filters string
+}
+
+func newSummaryKey(jobID string, minDiscrepancy *decimal.Decimal, minConfidence *float64) summaryKey {
+	key := summaryKey{jobID: jobID}
+	if minDiscrepancy != nil {
+		key.filters += "discrepancy=" + minDiscrepancy.String()
+	}
+	if minConfidence != nil {
+		key.filters += fmt.Sprintf(";confidence=%g", *minConfidence)
+	}
+	return key
+}
+
+type summaryEntry struct {
+	key     summaryKey
+	summary *domain.ReconciliationSummary
+	expires time.Time
+}
+
+// summaryCache is an LRU of job summaries whose entries expire after ttl
+type summaryCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[summaryKey]*list.Element
+}
+
+func newSummaryCache(size int, ttl time.Duration) *summaryCache {
+	return &summaryCache{size: size, ttl: ttl, order: list.New(), entries: make(map[summaryKey]*list.Element)}
+}
+
+// get returns a copy of the cached summary, so callers may set its fields
+func (c *summaryCache) get(key summaryKey) (*domain.ReconciliationSummary, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*summaryEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	summary := *entry.summary
+	return &summary, true
+}
+
+func (c *summaryCache) put(key summaryKey, summary *domain.ReconciliationSummary) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stored := *summary
+	entry := &summaryEntry{key: key, summary: &stored, expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*summaryEntry).key)
+	}
+}
+
+// invalidate drops every summary of jobID, whatever its filters
+func (c *summaryCache) invalidate(jobID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, elem := range c.entries {
+		if key.jobID == jobID {
+			c.order.Remove(elem)
+			delete(c.entries, key)
+		}
+	}
+}
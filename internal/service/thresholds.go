@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/matcher"
+)
+
+func (s *reconciliationService) ForFailureThresholds(maxDiscrepancyRatio, maxUnmatchedRatio *float64) (ReconciliationService, error) {
+	if maxDiscrepancyRatio == nil && maxUnmatchedRatio == nil {
+		return s, nil
+	}
+	for _, ratio := range []*float64{maxDiscrepancyRatio, maxUnmatchedRatio} {
+		if ratio != nil && (*ratio < 0 || *ratio > 1) {
+			return nil, fmt.Errorf("ratios must be between 0 and 1")
+		}
+	}
+
+	scoped := *s
+	scoped.maxDiscrepancyRatio = maxDiscrepancyRatio
+	scoped.maxUnmatchedRatio = maxUnmatchedRatio
+	return &scoped, nil
+}
+
+// thresholdBreach explains why a finished job fails its thresholds, or
+// returns "" when it passes. The discrepancy ratio is over the ID pairs
+// found and the unmatched ratio over the rows processed.
+func (s *reconciliationService) thresholdBreach(job *domain.ReconciliationJob, output *matcher.ReconciliationOutput) string {
+	discrepancies := len(output.Discrepancies)
+	if pairs := len(output.Matched) + discrepancies; s.maxDiscrepancyRatio != nil && pairs > 0 {
+		if ratio := float64(discrepancies) / float64(pairs); ratio > *s.maxDiscrepancyRatio {
+			return fmt.Sprintf("%d of %d matched pairs have amount discrepancies (ratio %.3f), above max_discrepancy_ratio %g",
+				discrepancies, pairs, ratio, *s.maxDiscrepancyRatio)
+		}
+	}
+	if s.maxUnmatchedRatio != nil && job.TotalProcessed > 0 {
+		if ratio := float64(job.TotalUnmatched) / float64(job.TotalProcessed); ratio > *s.maxUnmatchedRatio {
+			return fmt.Sprintf("%d of %d rows are unmatched (ratio %.3f), above max_unmatched_ratio %g",
+				job.TotalUnmatched, job.TotalProcessed, ratio, *s.maxUnmatchedRatio)
+		}
+	}
+	return ""
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,12 +12,19 @@ import (
 )
 
 type TransactionService interface {
-	Create(tx *domain.Transaction) error
-	BulkCreate(transactions []domain.Transaction) error
-	GetByTrxID(trxID string) (*domain.Transaction, error)
-	GetByDateRange(startDate, endDate time.Time) ([]domain.Transaction, error)
+	Create(ctx context.Context, tx *domain.Transaction) error
+	// BulkCreate reports the outcome of each row in input order
+	BulkCreate(ctx context.Context, transactions []domain.Transaction) (*domain.BulkCreateReport, error)
+	GetByTrxID(ctx context.Context, trxID string) (*domain.Transaction, error)
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]domain.Transaction, error)
+	// Update validates tx and overwrites the stored transaction with its trx_id
+	Update(ctx context.Context, tx *domain.Transaction) error
+	Delete(ctx context.Context, trxID string) error
 }
 
+// ErrInvalidTransaction wraps the validation failure of an updated transaction
+var ErrInvalidTransaction = errors.New("invalid transaction")
+
 type transactionService struct {
 	repo repository.TransactionRepository
 }
@@ -24,39 +33,75 @@ func NewTransactionService(repo repository.TransactionRepository) TransactionSer
 	return &transactionService{repo: repo}
 }
 
-func (s *transactionService) Create(tx *domain.Transaction) error {
+func (s *transactionService) Create(ctx context.Context, tx *domain.Transaction) error {
 	// Validate transaction
 	if err := s.validate(tx); err != nil {
 		return err
 	}
 
-	return s.repo.Create(tx)
+	return s.repo.Create(ctx, tx)
 }
 
-func (s *transactionService) BulkCreate(transactions []domain.Transaction) error {
-	// Validate all transactions
+// BulkCreate inserts the valid transactions and reports every row, invalid
+// ones as failed with the validation error
+func (s *transactionService) BulkCreate(ctx context.Context, transactions []domain.Transaction) (*domain.BulkCreateReport, error) {
+	report := &domain.BulkCreateReport{Rows: make([]domain.BulkRowOutcome, 0, len(transactions))}
+	valid := make([]domain.Transaction, 0, len(transactions))
+	positions := make([]int, 0, len(transactions))
 	for i, tx := range transactions {
 		if err := s.validate(&tx); err != nil {
 			logger.GetLogger().WithError(err).WithField("index", i).Warn("Invalid transaction, skipping")
+			report.Add(domain.BulkRowOutcome{Index: i, TrxID: tx.TrxID, Status: domain.BulkRowFailed, Reason: err.Error()})
 			continue
 		}
+		valid = append(valid, tx)
+		positions = append(positions, i)
 	}
 
-	return s.repo.BulkCreate(transactions)
+	inserted, err := s.repo.BulkCreate(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	report.Merge(inserted, positions)
+	return report, nil
 }
 
-func (s *transactionService) GetByTrxID(trxID string) (*domain.Transaction, error) {
+func (s *transactionService) GetByTrxID(ctx context.Context, trxID string) (*domain.Transaction, error) {
 	if trxID == "" {
 		return nil, fmt.Errorf("trxID cannot be empty")
 	}
-	return s.repo.GetByTrxID(trxID)
+	return s.repo.GetByTrxID(ctx, trxID)
 }
 
-func (s *transactionService) GetByDateRange(startDate, endDate time.Time) ([]domain.Transaction, error) {
+func (s *transactionService) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]domain.Transaction, error) {
 	if startDate.After(endDate) {
 		return nil, fmt.Errorf("start date cannot be after end date")
 	}
-	return s.repo.GetByDateRange(startDate, endDate)
+	return s.repo.GetByDateRange(ctx, startDate, endDate)
+}
+
+func (s *transactionService) Update(ctx context.Context, tx *domain.Transaction) error {
+	if err := s.validate(tx); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+	}
+
+	if err := s.repo.Update(ctx, tx); err != nil {
+		return err
+	}
+	logger.GetLogger().WithField("trx_id", tx.TrxID).Info("Transaction updated")
+	return nil
+}
+
+func (s *transactionService) Delete(ctx context.Context, trxID string) error {
+	if trxID == "" {
+		return fmt.Errorf("trxID cannot be empty")
+	}
+
+	if err := s.repo.Delete(ctx, trxID); err != nil {
+		return err
+	}
+	logger.GetLogger().WithField("trx_id", trxID).Info("Transaction deleted")
+	return nil
 }
 
 func (s *transactionService) validate(tx *domain.Transaction) error {
@@ -68,12 +113,12 @@ func (s *transactionService) validate(tx *domain.Transaction) error {
 		return fmt.Errorf("amount must be non-zero")
 	}
 
-	if tx.Amount.IsNegative() {
-		return fmt.Errorf("amount must be positive")
+	if !tx.Type.Valid() {
+		return fmt.Errorf("invalid transaction type: %s", tx.Type)
 	}
 
-	if tx.Type != domain.Debit && tx.Type != domain.Credit {
-		return fmt.Errorf("invalid transaction type: %s", tx.Type)
+	if tx.Amount.IsNegative() && !tx.Type.IsReversal() {
+		return fmt.Errorf("amount must be positive")
 	}
 
 	if tx.TransactionTime.IsZero() {
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"recon-engine/internal/domain"
+)
+
+// GetUnmatchedBank returns a page of source's unmatched bank items, or of
+// every source's, each paged on its own. A source with none has an empty
+// page.
+func (s *reconciliationService) GetUnmatchedBank(ctx context.Context, jobID, source string, page, size int) (*domain.UnmatchedBankPages, error) {
+	job, err := s.reconRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []string{source}
+	if source == "" {
+		if sources, err = s.unmatchedBankSources(ctx, jobID); err != nil {
+			return nil, err
+		}
+	}
+
+	pages := &domain.UnmatchedBankPages{JobID: jobID, Sources: make(map[string]*domain.ResultPage, len(sources))}
+	for _, source := range sources {
+		filter := domain.ResultFilter{Status: domain.UnmatchedBank, BankSource: source}
+		if pages.Sources[source], err = s.resultPage(ctx, job, filter, page, size); err != nil {
+			return nil, fmt.Errorf("failed to query unmatched bank results of %s: %w", source, err)
+		}
+	}
+	return pages, nil
+}
+
+// unmatchedBankSources lists the bank sources with unmatched items in jobID
+func (s *reconciliationService) unmatchedBankSources(ctx context.Context, jobID string) ([]string, error) {
+	counts, err := s.reconRepo.GetResultCountsByStatus(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count results: %w", err)
+	}
+	var sources []string
+	for _, count := range counts {
+		if count.MatchStatus == domain.UnmatchedBank && count.BankSource != "" && count.Count > 0 {
+			sources = append(sources, count.BankSource)
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+)
+
+// ValidateFiles parses the system and bank files as a reconcile would,
+// counting rows and noting skipped rows, column mappings and date ranges,
+// without matching or storing anything
+func (s *reconciliationService) ValidateFiles(ctx context.Context, systemFilePaths, bankFilePaths []string) (*domain.ValidationReport, error) {
+	if len(systemFilePaths) == 0 && len(bankFilePaths) == 0 {
+		return nil, fmt.Errorf("no files to validate")
+	}
+
+	report := &domain.ValidationReport{Files: make([]domain.FileValidation, 0, len(systemFilePaths)+len(bankFilePaths))}
+	for _, path := range systemFilePaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Files = append(report.Files, s.validateSystemFile(path))
+	}
+
+	bankFiles, failed, cleanup := s.expandBankFiles(bankFilePaths)
+	defer cleanup()
+	for _, archive := range failed {
+		report.Files = append(report.Files, domain.FileValidation{File: archive.File, Kind: domain.BankFile, Error: archive.Error})
+	}
+	for _, bankFile := range bankFiles {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Files = append(report.Files, s.validateBankFile(bankFile))
+	}
+
+	report.Valid = true
+	for _, file := range report.Files {
+		if file.Error != "" || file.Rows == 0 || file.SkippedRows > 0 {
+			report.Valid = false
+		}
+	}
+	return report, nil
+}
+
+func (s *reconciliationService) validateSystemFile(path string) domain.FileValidation {
+	file := domain.FileValidation{File: path, Kind: domain.SystemFile}
+	var parseReport parser.ParseReport
+	p := parser.NewTransactionCSVParser(s.reportingParserOpts(&parseReport)...)
+	err := p.Parse(path, s.batchSize, func(batch []domain.Transaction) error {
+		for _, tx := range batch {
+			file.AddDate(tx.TransactionTime)
+		}
+		file.Rows += len(batch)
+		return nil
+	})
+	fillValidation(&file, parseReport, err)
+	return file
+}
+
+func (s *reconciliationService) validateBankFile(bankFile bankFile) domain.FileValidation {
+	source := s.bankSource(bankFile.path)
+	file := domain.FileValidation{File: bankFile.name, Kind: domain.BankFile, Source: source}
+
+	var parseReport parser.ParseReport
+	scoped := *s
+	scoped.parserOpts = s.reportingParserOpts(&parseReport)
+	err := scoped.bankStatementParser(bankFile.path, source).Parse(bankFile.path, s.batchSize, func(batch []domain.BankStatement) error {
+		for _, statement := range batch {
+			file.AddDate(statement.Date)
+		}
+		file.Rows += len(batch)
+		return nil
+	})
+	fillValidation(&file, parseReport, err)
+	return file
+}
+
+// reportingParserOpts returns the parser options with report collecting
+// the parse's columns and skipped rows
+func (s *reconciliationService) reportingParserOpts(report *parser.ParseReport) []parser.ParserOption {
+	return append(slices.Clip(s.parserOpts), parser.WithParseReport(report))
+}
+
+func fillValidation(file *domain.FileValidation, report parser.ParseReport, err error) {
+	file.Columns = report.Columns
+	file.SkippedRows = report.SkippedRows
+	file.RowErrors = report.RowErrors
+	if err != nil {
+		file.Error = err.Error()
+	}
+}
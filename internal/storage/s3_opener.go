@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// unsignedPayload is the payload hash of requests whose body is not signed;
+// object reads have no body
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Credentials sign requests with AWS Signature Version 4. Without an access
+// key, requests are sent unsigned, which only public buckets accept.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Opener streams objects given as scheme://bucket/key URLs from Amazon S3
+// or an S3-compatible store such as MinIO or the Google Cloud Storage XML
+// API. The response body is handed to the parsers as it arrives; nothing is
+// downloaded to disk first.
+type S3Opener struct {
+	client      *http.Client
+	region      string
+	credentials S3Credentials
+	// endpoint replaces the regional AWS endpoint, e.g. http://minio:9000
+	endpoint  string
+	pathStyle bool
+	now       func() time.Time
+}
+
+// S3Option configures an S3Opener
+type S3Option func(*S3Opener)
+
+// WithS3Credentials signs requests with credentials
+func WithS3Credentials(credentials S3Credentials) S3Option {
+	return func(o *S3Opener) {
+		o.credentials = credentials
+	}
+}
+
+// WithS3Endpoint sends requests to endpoint, a base URL, instead of AWS.
+// Custom endpoints address buckets by path.
+func WithS3Endpoint(endpoint string) S3Option {
+	return func(o *S3Opener) {
+		if endpoint != "" {
+			o.endpoint = strings.TrimSuffix(endpoint, "/")
+			o.pathStyle = true
+		}
+	}
+}
+
+// WithS3PathStyle addresses buckets as endpoint/bucket/key instead of
+// bucket.endpoint/key
+func WithS3PathStyle(enabled bool) S3Option {
+	return func(o *S3Opener) {
+		o.pathStyle = o.pathStyle || enabled
+	}
+}
+
+// WithS3HTTPClient replaces the HTTP client
+func WithS3HTTPClient(client *http.Client) S3Option {
+	return func(o *S3Opener) {
+		if client != nil {
+			o.client = client
+		}
+	}
+}
+
+// NewS3Opener reads objects from the AWS region, us-east-1 when empty
+func NewS3Opener(region string, opts ...S3Option) *S3Opener {
+	if region == "" {
+		region = "us-east-1"
+	}
+	o := &S3Opener{
+		// No overall timeout: a large file streams for as long as it takes
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 30 * time.Second,
+		}},
+		region: region,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// gcsEndpoint is the S3-compatible XML API of Google Cloud Storage
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// NewGCSOpener reads gs://bucket/key objects through the Cloud Storage XML
+// API, signing with an HMAC key of a service account
+func NewGCSOpener(credentials S3Credentials, opts ...S3Option) *S3Opener {
+	opts = append([]S3Option{WithS3Endpoint(gcsEndpoint), WithS3Credentials(credentials)}, opts...)
+	return NewS3Opener("auto", opts...)
+}
+
+// Open starts a GET of the object and returns its body
+func (o *S3Opener) Open(rawURL string) (io.ReadCloser, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object URL %q: %w", rawURL, err)
+	}
+	bucket, key := parsed.Host, strings.TrimPrefix(parsed.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid object URL %q: use %s://bucket/key", rawURL, parsed.Scheme)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, o.objectURL(bucket, key), nil)
+	if err != nil {
+		return nil, err
+	}
+	o.sign(req)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to read %s: %s", rawURL, s3ErrorCode(resp))
+	}
+	return resp.Body, nil
+}
+
+// objectURL addresses key in bucket, encoding each path segment once
+func (o *S3Opener) objectURL(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	path := strings.Join(segments, "/")
+
+	endpoint := o.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", o.region)
+	}
+	if o.pathStyle {
+		return endpoint + "/" + uriEncode(bucket) + "/" + path
+	}
+	scheme, host, _ := strings.Cut(endpoint, "://")
+	return scheme + "://" + bucket + "." + host + "/" + path
+}
+
+// sign adds a Signature Version 4 Authorization header to req
+func (o *S3Opener) sign(req *http.Request) {
+	if o.credentials.AccessKeyID == "" {
+		return
+	}
+	now := o.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if o.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", o.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := day + "/" + o.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+o.credentials.SecretAccessKey), day)
+	for _, part := range []string{o.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		o.credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3ErrorCode describes a failed response by its status and S3 error code
+func s3ErrorCode(resp *http.Response) string {
+	var body struct {
+		Code string `xml:"Code"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&body); err == nil && body.Code != "" {
+		return resp.Status + " " + body.Code
+	}
+	return resp.Status
+}
+
+// uriEncode percent-encodes everything but the RFC 3986 unreserved characters
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -41,13 +41,13 @@ CREATE TABLE IF NOT EXISTS reconciliation_results (
 );
 
 -- Create indexes for better query performance
-CREATE INDEX idx_transactions_trx_id ON transactions(trx_id);
-CREATE INDEX idx_transactions_time ON transactions(transaction_time);
-CREATE INDEX idx_transactions_time_type ON transactions(transaction_time, type);
-CREATE INDEX idx_reconciliation_jobs_job_id ON reconciliation_jobs(job_id);
-CREATE INDEX idx_reconciliation_jobs_status ON reconciliation_jobs(status);
-CREATE INDEX idx_reconciliation_results_job_id ON reconciliation_results(job_id);
-CREATE INDEX idx_reconciliation_results_match_status ON reconciliation_results(match_status);
+CREATE INDEX IF NOT EXISTS idx_transactions_trx_id ON transactions(trx_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_time ON transactions(transaction_time);
+CREATE INDEX IF NOT EXISTS idx_transactions_time_type ON transactions(transaction_time, type);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_jobs_job_id ON reconciliation_jobs(job_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_jobs_status ON reconciliation_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_results_job_id ON reconciliation_results(job_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_results_match_status ON reconciliation_results(match_status);
 
 -- Create function to update updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -59,8 +59,10 @@ END;
 $$ language 'plpgsql';
 
 -- Create triggers for updated_at
+DROP TRIGGER IF EXISTS update_transactions_updated_at ON transactions;
 CREATE TRIGGER update_transactions_updated_at BEFORE UPDATE ON transactions
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+DROP TRIGGER IF EXISTS update_reconciliation_jobs_updated_at ON reconciliation_jobs;
 CREATE TRIGGER update_reconciliation_jobs_updated_at BEFORE UPDATE ON reconciliation_jobs
 FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
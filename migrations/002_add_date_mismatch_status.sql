@@ -0,0 +1,4 @@
+-- Allow DATE_MISMATCH results produced by date-proximity matching
+ALTER TABLE reconciliation_results DROP CONSTRAINT IF EXISTS reconciliation_results_match_status_check;
+ALTER TABLE reconciliation_results ADD CONSTRAINT reconciliation_results_match_status_check
+    CHECK (match_status IN ('MATCHED', 'UNMATCHED_SYSTEM', 'UNMATCHED_BANK', 'DISCREPANCY', 'DATE_MISMATCH'));
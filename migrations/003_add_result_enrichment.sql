@@ -0,0 +1,3 @@
+-- Optional transaction metadata copied onto results
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS transaction_type VARCHAR(10);
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS transaction_created_at TIMESTAMP;
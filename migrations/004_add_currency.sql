@@ -0,0 +1,7 @@
+-- Currency of the system and bank side of each result
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS currency VARCHAR(3);
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS bank_currency VARCHAR(3);
+
+ALTER TABLE reconciliation_results DROP CONSTRAINT IF EXISTS reconciliation_results_match_status_check;
+ALTER TABLE reconciliation_results ADD CONSTRAINT reconciliation_results_match_status_check
+    CHECK (match_status IN ('MATCHED', 'UNMATCHED_SYSTEM', 'UNMATCHED_BANK', 'DISCREPANCY', 'DATE_MISMATCH', 'CURRENCY_MISMATCH'));
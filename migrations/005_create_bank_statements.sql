@@ -0,0 +1,13 @@
+-- Bank statement lines available for database-sourced reconciliation
+CREATE TABLE IF NOT EXISTS bank_statements (
+    id SERIAL PRIMARY KEY,
+    trx_ref_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(20, 2) NOT NULL,
+    statement_date TIMESTAMP NOT NULL,
+    source VARCHAR(255) NOT NULL,
+    currency VARCHAR(3),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statements_date ON bank_statements(statement_date);
+CREATE INDEX IF NOT EXISTS idx_bank_statements_trx_ref_id ON bank_statements(trx_ref_id);
@@ -0,0 +1,4 @@
+-- Allow DUPLICATE_SYSTEM and DUPLICATE_BANK results for repeated transaction and reference IDs
+ALTER TABLE reconciliation_results DROP CONSTRAINT IF EXISTS reconciliation_results_match_status_check;
+ALTER TABLE reconciliation_results ADD CONSTRAINT reconciliation_results_match_status_check
+    CHECK (match_status IN ('MATCHED', 'UNMATCHED_SYSTEM', 'UNMATCHED_BANK', 'DISCREPANCY', 'DATE_MISMATCH', 'CURRENCY_MISMATCH', 'DUPLICATE_SYSTEM', 'DUPLICATE_BANK'));
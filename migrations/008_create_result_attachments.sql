@@ -0,0 +1,13 @@
+-- Supporting documents attached to reconciliation results
+CREATE TABLE IF NOT EXISTS result_attachments (
+    id SERIAL PRIMARY KEY,
+    result_id INTEGER NOT NULL REFERENCES reconciliation_results(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    description TEXT,
+    storage_key VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_result_attachments_result_id ON result_attachments(result_id);
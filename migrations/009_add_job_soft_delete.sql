@@ -0,0 +1,4 @@
+-- Deleted jobs are kept for auditing but hidden; their results are removed
+ALTER TABLE reconciliation_jobs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_reconciliation_jobs_active ON reconciliation_jobs(job_id) WHERE deleted_at IS NULL;
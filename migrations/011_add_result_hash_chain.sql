@@ -0,0 +1,3 @@
+-- Optional hash chain over a job's results for tamper evidence
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS chain_hash VARCHAR(64);
+ALTER TABLE reconciliation_jobs ADD COLUMN IF NOT EXISTS result_chain_head VARCHAR(64);
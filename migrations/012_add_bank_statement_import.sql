@@ -0,0 +1,6 @@
+-- Bank statements loaded from files are kept, tagged with the job that imported them
+ALTER TABLE bank_statements ADD COLUMN IF NOT EXISTS job_id UUID;
+ALTER TABLE bank_statements ADD COLUMN IF NOT EXISTS type VARCHAR(10);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statements_job_id ON bank_statements(job_id);
+CREATE INDEX IF NOT EXISTS idx_bank_statements_source_date ON bank_statements(source, statement_date);
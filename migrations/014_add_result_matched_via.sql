@@ -0,0 +1,2 @@
+-- Strategy that paired a matched result, e.g. "exact" or "normalized"
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS matched_via VARCHAR(50);
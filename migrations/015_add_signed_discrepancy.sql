@@ -0,0 +1,3 @@
+-- Signed discrepancy (system minus bank) alongside the absolute value
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS signed_discrepancy DECIMAL(20, 2);
+ALTER TABLE reconciliation_jobs ADD COLUMN IF NOT EXISTS net_discrepancy DECIMAL(20, 2) DEFAULT 0;
@@ -0,0 +1,10 @@
+-- Keyset paging of a job's results orders by (created_at, id) and seeks with
+-- (created_at, id) > (last_created_at, last_id); these indexes serve it with
+-- and without a match_status filter instead of a scan and sort
+CREATE INDEX IF NOT EXISTS idx_reconciliation_results_job_created
+    ON reconciliation_results(job_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_results_job_status_created
+    ON reconciliation_results(job_id, match_status, created_at, id);
+
+-- Both lead with job_id, which makes the single-column index redundant
+DROP INDEX IF EXISTS idx_reconciliation_results_job_id;
@@ -0,0 +1,4 @@
+-- Allow AMBIGUOUS_MATCH results for amount and date ties left unmatched
+ALTER TABLE reconciliation_results DROP CONSTRAINT IF EXISTS reconciliation_results_match_status_check;
+ALTER TABLE reconciliation_results ADD CONSTRAINT reconciliation_results_match_status_check
+    CHECK (match_status IN ('MATCHED', 'UNMATCHED_SYSTEM', 'UNMATCHED_BANK', 'DISCREPANCY', 'DATE_MISMATCH', 'CURRENCY_MISMATCH', 'DIRECTION_MISMATCH', 'DUPLICATE_SYSTEM', 'DUPLICATE_BANK', 'MALFORMED_REFERENCE', 'AMBIGUOUS_MATCH'));
@@ -0,0 +1,4 @@
+-- Allow REFUND and CHARGEBACK transactions, whose amounts may be negative
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('DEBIT', 'CREDIT', 'REFUND', 'CHARGEBACK'));
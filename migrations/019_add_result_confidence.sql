@@ -0,0 +1,3 @@
+-- Confidence from 0 to 1 that a paired result's records are the same payment;
+-- NULL for unpaired results
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS confidence NUMERIC(4,3);
@@ -0,0 +1,3 @@
+-- Results a reviewer paired by hand, and who did
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS manual_match BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS reviewed_by VARCHAR(255);
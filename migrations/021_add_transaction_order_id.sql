@@ -0,0 +1,2 @@
+-- Optional external reference some banks report instead of trx_id
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS order_id VARCHAR(255);
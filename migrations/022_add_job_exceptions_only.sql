@@ -0,0 +1,2 @@
+-- Jobs run with persist_matched=false store only their exceptions
+ALTER TABLE reconciliation_jobs ADD COLUMN IF NOT EXISTS exceptions_only BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Per-source listings of unmatched bank items filter on match_status and
+-- bank_source and page in (created_at, id) order
+CREATE INDEX IF NOT EXISTS idx_reconciliation_results_job_status_source
+    ON reconciliation_results(job_id, match_status, bank_source, created_at, id);
@@ -0,0 +1,6 @@
+-- Optional free-text descriptions (merchant name, memo) carried from the
+-- input files to the results for manual review
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE bank_statements ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE reconciliation_results ADD COLUMN IF NOT EXISTS bank_description TEXT;
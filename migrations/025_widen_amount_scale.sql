@@ -0,0 +1,10 @@
+-- Keep up to 4 decimal places so currencies with three-digit minor units
+-- (BHD, KWD) and sub-cent discrepancies are stored as matched
+ALTER TABLE transactions ALTER COLUMN amount TYPE NUMERIC(20, 4);
+ALTER TABLE bank_statements ALTER COLUMN amount TYPE NUMERIC(20, 4);
+ALTER TABLE reconciliation_results ALTER COLUMN system_amount TYPE NUMERIC(20, 4);
+ALTER TABLE reconciliation_results ALTER COLUMN bank_amount TYPE NUMERIC(20, 4);
+ALTER TABLE reconciliation_results ALTER COLUMN discrepancy TYPE NUMERIC(20, 4);
+ALTER TABLE reconciliation_results ALTER COLUMN signed_discrepancy TYPE NUMERIC(20, 4);
+ALTER TABLE reconciliation_jobs ALTER COLUMN total_discrepancies TYPE NUMERIC(20, 4);
+ALTER TABLE reconciliation_jobs ALTER COLUMN net_discrepancy TYPE NUMERIC(20, 4);
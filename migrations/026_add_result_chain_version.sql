@@ -0,0 +1,3 @@
+-- Encoding of a job's result hash chain; chains saved before amounts kept
+-- four decimal places were hashed with two and stay version 1
+ALTER TABLE reconciliation_jobs ADD COLUMN IF NOT EXISTS result_chain_version SMALLINT NOT NULL DEFAULT 1;
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned schema migrations so binaries can
+// apply them without the SQL files on disk
+package migrations
+
+import "embed"
+
+// Files holds every NNN_name.sql migration in this directory
+//
+//go:embed *.sql
+var Files embed.FS
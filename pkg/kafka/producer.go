@@ -0,0 +1,215 @@
+// Package kafka publishes JSON messages to Kafka through a REST Proxy
+// speaking the Confluent v2 API, so the service needs nothing but HTTP to
+// reach the brokers
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	contentType = "application/vnd.kafka.json.v2+json"
+	acceptType  = "application/vnd.kafka.v2+json"
+	// retriableRecord is the proxy's error_code for a record worth resending
+	retriableRecord = 1
+	// defaultBatchSize is how many messages go in one request by default
+	defaultBatchSize = 500
+)
+
+// Message is one record: Key picks the partition, Value is encoded as JSON
+type Message struct {
+	Key   string
+	Value interface{}
+}
+
+// Producer posts messages to a REST Proxy, retrying failed requests and
+// records the proxy reports as retriable
+type Producer struct {
+	baseURL   string
+	http      *http.Client
+	username  string
+	password  string
+	retries   int
+	backoff   time.Duration
+	batchSize int
+}
+
+// Option configures a Producer
+type Option func(*Producer)
+
+// WithTimeout bounds each request; it defaults to 10 seconds
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Producer) {
+		if timeout > 0 {
+			p.http.Timeout = timeout
+		}
+	}
+}
+
+// WithRetries resends a request that fails with a network error, a 429 or a
+// 5xx response, or whose records the proxy could not yet write, up to retries
+// more times, waiting backoff, then twice as long, between attempts
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(p *Producer) {
+		p.retries = retries
+		p.backoff = backoff
+	}
+}
+
+// WithBasicAuth authenticates to the proxy; no credentials are sent without a username
+func WithBasicAuth(username, password string) Option {
+	return func(p *Producer) {
+		p.username = username
+		p.password = password
+	}
+}
+
+// WithBatchSize caps the messages sent in one request; n <= 0 keeps the default of 500
+func WithBatchSize(n int) Option {
+	return func(p *Producer) {
+		if n > 0 {
+			p.batchSize = n
+		}
+	}
+}
+
+// NewProducer posts to the proxy at baseURL, e.g. http://kafka-rest:8082
+func NewProducer(baseURL string, opts ...Option) *Producer {
+	p := &Producer{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		http:      &http.Client{Timeout: 10 * time.Second},
+		batchSize: defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Produce sends messages to topic in order, in requests of up to the batch
+// size. It stops at the first request that still fails once retried and
+// reports how many messages had been written.
+func (p *Producer) Produce(ctx context.Context, topic string, messages []Message) error {
+	for start := 0; start < len(messages); start += p.batchSize {
+		end := start + p.batchSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+		if err := p.send(ctx, topic, messages[start:end]); err != nil {
+			return fmt.Errorf("kafka produce to %s failed after %d of %d messages: %w", topic, start, len(messages), err)
+		}
+	}
+	return nil
+}
+
+// record is a message as the proxy reads it
+type record struct {
+	Key   string      `json:"key,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// offset is the proxy's outcome for one record of a request
+type offset struct {
+	ErrorCode *int    `json:"error_code"`
+	Error     *string `json:"error"`
+}
+
+// send delivers one batch, resending what can be retried
+func (p *Producer) send(ctx context.Context, topic string, messages []Message) error {
+	pending := messages
+	backoff := p.backoff
+	for attempt := 0; ; attempt++ {
+		failed, retry, err := p.post(ctx, topic, pending)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= p.retries {
+			return fmt.Errorf("after %d attempt(s): %w", attempt+1, err)
+		}
+		if failed != nil {
+			pending = failed
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one request. It returns the records to resend when only some
+// failed, and whether the failure is worth retrying.
+func (p *Producer) post(ctx context.Context, topic string, messages []Message) ([]Message, bool, error) {
+	records := make([]record, len(messages))
+	for i, message := range messages {
+		records[i] = record{Key: message.Key, Value: message.Value}
+	}
+	body, err := json.Marshal(map[string][]record{"records": records})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode messages: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", acceptType)
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, resp.Body)
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, fmt.Errorf("proxy returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Offsets []offset `json:"offsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, false, fmt.Errorf("failed to read proxy response: %w", err)
+	}
+	return failedRecords(messages, result.Offsets)
+}
+
+// failedRecords picks the messages the proxy did not write. Only when all
+// of them are retriable is the batch worth resending.
+func failedRecords(messages []Message, offsets []offset) ([]Message, bool, error) {
+	var failed []Message
+	var firstErr string
+	retry := true
+	for i, o := range offsets {
+		if o.ErrorCode == nil || i >= len(messages) {
+			continue
+		}
+		failed = append(failed, messages[i])
+		if firstErr == "" && o.Error != nil {
+			firstErr = *o.Error
+		}
+		if *o.ErrorCode != retriableRecord {
+			retry = false
+		}
+	}
+	if len(failed) == 0 {
+		return nil, false, nil
+	}
+	return failed, retry, fmt.Errorf("%d record(s) not written: %s", len(failed), firstErr)
+}
@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RequestIDHeader carries the correlation ID on requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the context key (and log field) holding the request ID.
+	// It is a plain string so values set with gin's c.Set are found.
+	RequestIDKey = "request_id"
+)
+
+// FromContext returns a log entry tagged with the request ID stored in ctx,
+// so every line logged for one request can be correlated. A *gin.Context can
+// be passed directly.
+func FromContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(GetLogger())
+	if ctx == nil {
+		return entry
+	}
+	if id, ok := ctx.Value(RequestIDKey).(string); ok && id != "" {
+		return entry.WithField(RequestIDKey, id)
+	}
+	return entry
+}
@@ -6,11 +6,52 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Log formats accepted by WithFormat
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
 var Log *logrus.Logger
 
-func Init(level string) {
+// Option configures the formatter set up by Init
+type Option func(*options)
+
+type options struct {
+	format          string
+	timestamps      bool
+	timestampFormat string
+}
+
+// WithFormat selects FormatJSON, the default, or FormatText
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithTimestamps decides whether entries carry a timestamp; they do by default
+func WithTimestamps(enabled bool) Option {
+	return func(o *options) {
+		o.timestamps = enabled
+	}
+}
+
+// WithTimestampFormat sets the Go time layout of timestamps; empty keeps RFC3339
+func WithTimestampFormat(layout string) Option {
+	return func(o *options) {
+		o.timestampFormat = layout
+	}
+}
+
+func Init(level string, opts ...Option) {
+	o := options{format: FormatJSON, timestamps: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	Log = logrus.New()
-	Log.SetFormatter(&logrus.JSONFormatter{})
+	Log.SetFormatter(newFormatter(o))
 	Log.SetOutput(os.Stdout)
 
 	logLevel, err := logrus.ParseLevel(level)
@@ -20,6 +61,20 @@ func Init(level string) {
 	Log.SetLevel(logLevel)
 }
 
+func newFormatter(o options) logrus.Formatter {
+	if o.format == FormatText {
+		return &logrus.TextFormatter{
+			DisableTimestamp: !o.timestamps,
+			FullTimestamp:    true,
+			TimestampFormat:  o.timestampFormat,
+		}
+	}
+	return &logrus.JSONFormatter{
+		DisableTimestamp: !o.timestamps,
+		TimestampFormat:  o.timestampFormat,
+	}
+}
+
 func GetLogger() *logrus.Logger {
 	if Log == nil {
 		Init("info")
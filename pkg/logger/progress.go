@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"time"
+)
+
+// Progress logs a running row count and rate every interval rows. A nil
+// *Progress is valid and does nothing, so callers need no guards when
+// progress logging is disabled.
+type Progress struct {
+	operation string
+	interval  int
+	count     int
+	next      int
+	started   time.Time
+}
+
+// NewProgress returns nil when interval is not positive
+func NewProgress(operation string, interval int) *Progress {
+	if interval <= 0 {
+		return nil
+	}
+	return &Progress{
+		operation: operation,
+		interval:  interval,
+		next:      interval,
+		started:   time.Now(),
+	}
+}
+
+// Add records n more processed rows, logging when an interval is crossed
+func (p *Progress) Add(n int) {
+	if p == nil {
+		return
+	}
+	p.count += n
+	if p.count >= p.next {
+		p.log("Progress")
+		for p.next <= p.count {
+			p.next += p.interval
+		}
+	}
+}
+
+// Done logs the final count and overall rate
+func (p *Progress) Done() {
+	if p == nil {
+		return
+	}
+	p.log("Completed")
+}
+
+func (p *Progress) log(message string) {
+	elapsed := time.Since(p.started)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.count) / elapsed.Seconds()
+	}
+	GetLogger().WithFields(map[string]interface{}{
+		"operation":    p.operation,
+		"rows":         p.count,
+		"elapsed_ms":   elapsed.Milliseconds(),
+		"rows_per_sec": rate,
+	}).Info(message)
+}
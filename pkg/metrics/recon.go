@@ -0,0 +1,129 @@
+package metrics
+
+import "time"
+
+// Reconciliation metrics. The input label is "file" or "database", telling
+// where bank statements came from; bank_source is the bank file (or stored
+// source) a result belongs to and is empty for system-only results.
+var (
+	JobsStarted = NewCounterVec(
+		"recon_jobs_started_total",
+		"Reconciliation jobs started.",
+		"input",
+	)
+	JobsCompleted = NewCounterVec(
+		"recon_jobs_completed_total",
+		"Reconciliation jobs completed.",
+		"input",
+	)
+	JobsFailed = NewCounterVec(
+		"recon_jobs_failed_total",
+		"Reconciliation jobs that failed.",
+		"input",
+	)
+	JobDuration = NewHistogramVec(
+		"recon_job_duration_seconds",
+		"Wall time of a reconciliation job, from loading input to saving results.",
+		[]float64{.1, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		"input",
+	)
+	MatchDuration = NewHistogramVec(
+		"recon_match_duration_seconds",
+		"Time the engine spent matching one reconciliation pass.",
+		nil,
+	)
+	MatchResults = NewCounterVec(
+		"recon_match_results_total",
+		"Reconciliation results by match status and bank source.",
+		"status", "bank_source",
+	)
+	LastRunMatched = NewGaugeVec(
+		"recon_last_run_matched",
+		"Matched pairs in the most recent completed job.",
+		"input",
+	)
+	LastRunUnmatched = NewGaugeVec(
+		"recon_last_run_unmatched",
+		"Unmatched system transactions and bank statements in the most recent completed job.",
+		"input",
+	)
+	LastRunDiscrepancy = NewGaugeVec(
+		"recon_last_run_discrepancy_amount",
+		"Total absolute amount discrepancy in the most recent completed job.",
+		"input",
+	)
+	HTTPRequests = NewCounterVec(
+		"recon_http_requests_total",
+		"HTTP requests by method, route and status code.",
+		"method", "route", "status",
+	)
+	HTTPDuration = NewHistogramVec(
+		"recon_http_request_duration_seconds",
+		"HTTP request latency by method and route.",
+		nil,
+		"method", "route",
+	)
+)
+
+func init() {
+	DefaultRegistry.MustRegister(
+		JobsStarted, JobsCompleted, JobsFailed, JobDuration,
+		MatchDuration, MatchResults,
+		LastRunMatched, LastRunUnmatched, LastRunDiscrepancy,
+		HTTPRequests, HTTPDuration,
+	)
+}
+
+// JobRecorder receives reconciliation job events. The service reports through
+// one recorder so Prometheus, StatsD or both can be configured.
+type JobRecorder interface {
+	JobStarted(input string)
+	JobFailed(input string)
+	JobCompleted(input string, duration time.Duration, matched, unmatched int, discrepancy float64)
+}
+
+// Prometheus records job events in the DefaultRegistry metrics above
+var Prometheus JobRecorder = prometheusRecorder{}
+
+type prometheusRecorder struct{}
+
+func (prometheusRecorder) JobStarted(input string) {
+	JobsStarted.WithLabelValues(input).Inc()
+}
+
+func (prometheusRecorder) JobFailed(input string) {
+	JobsFailed.WithLabelValues(input).Inc()
+}
+
+func (prometheusRecorder) JobCompleted(input string, duration time.Duration, matched, unmatched int, discrepancy float64) {
+	JobsCompleted.WithLabelValues(input).Inc()
+	JobDuration.WithLabelValues(input).Observe(duration.Seconds())
+	LastRunMatched.WithLabelValues(input).Set(float64(matched))
+	LastRunUnmatched.WithLabelValues(input).Set(float64(unmatched))
+	LastRunDiscrepancy.WithLabelValues(input).Set(discrepancy)
+}
+
+// Recorders fans job events out to every recorder in order
+func Recorders(recorders ...JobRecorder) JobRecorder {
+	return multiRecorder(recorders)
+}
+
+type multiRecorder []JobRecorder
+
+func (m multiRecorder) JobStarted(input string) {
+	for _, r := range m {
+		r.JobStarted(input)
+	}
+}
+
+func (m multiRecorder) JobFailed(input string) {
+	for _, r := range m {
+		r.JobFailed(input)
+	}
+}
+
+func (m multiRecorder) JobCompleted(input string, duration time.Duration, matched, unmatched int, discrepancy float64) {
+	for _, r := range m {
+		r.JobCompleted(input, duration, matched, unmatched, discrepancy)
+	}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"recon-engine/pkg/logger"
+)
+
+// StatsD emits job events as StatsD counters, timers and gauges. Each event
+// is sent as one packet of newline-separated metrics, which both StatsD and
+// DogStatsD accept.
+type StatsD struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+	tags   bool
+}
+
+// StatsDOption configures a StatsD recorder
+type StatsDOption func(*StatsD)
+
+// WithStatsDPrefix sets the prefix of every metric name; it defaults to "recon"
+func WithStatsDPrefix(prefix string) StatsDOption {
+	return func(s *StatsD) {
+		s.prefix = strings.TrimSuffix(prefix, ".")
+	}
+}
+
+// WithStatsDTags sends the input as a DogStatsD tag (|#input:file) instead
+// of appending it to the metric name
+func WithStatsDTags(enabled bool) StatsDOption {
+	return func(s *StatsD) {
+		s.tags = enabled
+	}
+}
+
+// NewStatsD writes metrics to w, typically a UDP connection
+func NewStatsD(w io.Writer, opts ...StatsDOption) *StatsD {
+	s := &StatsD{w: w, prefix: "recon"}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// DialStatsD connects to a StatsD agent at addr (host:port) over UDP
+func DialStatsD(addr string, opts ...StatsDOption) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd: %w", err)
+	}
+	return NewStatsD(conn, opts...), nil
+}
+
+// Close closes the underlying connection when it has one
+func (s *StatsD) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *StatsD) JobStarted(input string) {
+	s.send(s.line("jobs.started", input, "1", "c"))
+}
+
+func (s *StatsD) JobFailed(input string) {
+	s.send(s.line("jobs.failed", input, "1", "c"))
+}
+
+func (s *StatsD) JobCompleted(input string, duration time.Duration, matched, unmatched int, discrepancy float64) {
+	s.send(
+		s.line("jobs.completed", input, "1", "c"),
+		s.line("job.duration", input, strconv.FormatInt(duration.Milliseconds(), 10), "ms"),
+		s.line("job.matched", input, strconv.Itoa(matched), "g"),
+		s.line("job.unmatched", input, strconv.Itoa(unmatched), "g"),
+		s.line("job.discrepancy", input, strconv.FormatFloat(discrepancy, 'f', -1, 64), "g"),
+	)
+}
+
+// line formats one metric as name:value|type, with the input as a tag or
+// as the last name segment
+func (s *StatsD) line(name, input, value, kind string) string {
+	if s.prefix != "" {
+		name = s.prefix + "." + name
+	}
+	if s.tags {
+		return name + ":" + value + "|" + kind + "|#input:" + input
+	}
+	return name + "." + input + ":" + value + "|" + kind
+}
+
+// send writes the lines as one packet. Metrics are best effort, so a failed
+// write is logged rather than returned.
+func (s *StatsD) send(lines ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := io.WriteString(s.w, strings.Join(lines, "\n")); err != nil {
+		logger.GetLogger().WithError(err).Debug("Failed to send statsd metrics")
+	}
+}
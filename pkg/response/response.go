@@ -2,6 +2,7 @@ package response
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -51,6 +52,23 @@ func NotFound(c *gin.Context, message string) {
 	Error(c, http.StatusNotFound, "NOT_FOUND", message, "")
 }
 
+func Conflict(c *gin.Context, message, details string) {
+	Error(c, http.StatusConflict, "CONFLICT", message, details)
+}
+
+func PayloadTooLarge(c *gin.Context, details string) {
+	Error(c, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "Request body too large", details)
+}
+
 func ValidationError(c *gin.Context, details string) {
 	Error(c, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "Validation failed", details)
 }
+
+func GatewayTimeout(c *gin.Context, details string) {
+	Error(c, http.StatusGatewayTimeout, "TIMEOUT", "Request timed out", details)
+}
+
+func TooManyRequests(c *gin.Context, retryAfter int, details string) {
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	Error(c, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests", details)
+}
@@ -0,0 +1,131 @@
+// Package tracing sets up OpenTelemetry tracing and opens the spans
+// recorded along the reconcile flow
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans of this service to the tracer
+const instrumentationName = "recon-engine"
+
+// Option configures the tracer provider set up by Init
+type Option func(*options)
+
+type options struct {
+	endpoint    string
+	insecure    bool
+	serviceName string
+	sampleRatio float64
+}
+
+// WithEndpoint sets the OTLP/HTTP collector, as host:port or a base URL whose
+// scheme decides between HTTP and HTTPS; spans are only exported when it is set
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithInsecure exports over plain HTTP instead of HTTPS
+func WithInsecure(insecure bool) Option {
+	return func(o *options) {
+		o.insecure = insecure
+	}
+}
+
+// WithServiceName sets the service.name resource attribute
+func WithServiceName(name string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.serviceName = name
+		}
+	}
+}
+
+// WithSampleRatio samples this fraction of new traces; traces started
+// upstream follow the caller's sampling decision
+func WithSampleRatio(ratio float64) Option {
+	return func(o *options) {
+		o.sampleRatio = ratio
+	}
+}
+
+// Init installs the W3C trace context propagator and, when an endpoint is
+// configured, a tracer provider exporting spans over OTLP/HTTP. The returned
+// function flushes pending spans and stops the exporter.
+func Init(ctx context.Context, opts ...Option) (func(context.Context) error, error) {
+	o := options{serviceName: instrumentationName, sampleRatio: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if o.endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(o.endpoint)}
+	if strings.Contains(o.endpoint, "://") {
+		// A base URL, as in OTEL_EXPORTER_OTLP_ENDPOINT, gets the traces path
+		exporterOpts = []otlptracehttp.Option{otlptracehttp.WithEndpointURL(o.endpoint)}
+		if u, err := url.Parse(o.endpoint); err == nil && strings.Trim(u.Path, "/") == "" {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithURLPath("/v1/traces"))
+		}
+	}
+	if o.insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", o.serviceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of this service from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start opens a span named name as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks span as failed when err is set, then ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the request
+// body, keyed with the client's secret
+const SignatureHeader = "X-Recon-Signature"
+
+// Client posts JSON payloads to callback URLs, retrying failed deliveries
+type Client struct {
+	http    *http.Client
+	secret  []byte
+	retries int
+	backoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTimeout bounds each delivery attempt; it defaults to 10 seconds
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.http.Timeout = timeout
+		}
+	}
+}
+
+// WithRetries retries a delivery that fails with a network error, a 429 or a
+// 5xx response up to retries more times, waiting backoff, then twice as
+// long, between attempts
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithSecret signs each payload in SignatureHeader; no header is sent without one
+func WithSecret(secret []byte) Option {
+	return func(c *Client) {
+		c.secret = secret
+	}
+}
+
+func NewClient(opts ...Option) *Client {
+	c := &Client{http: &http.Client{Timeout: 10 * time.Second}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Post delivers payload as JSON to url, returning the last error once every
+// attempt has failed
+func (c *Client) Post(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.deliver(url, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= c.retries {
+			return fmt.Errorf("webhook delivery failed after %d attempt(s): %w", attempt+1, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// Sign returns the SignatureHeader value for body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver makes one attempt and reports whether a failure is worth retrying
+func (c *Client) deliver(url string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(c.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(c.secret, body))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("callback returned status %d", resp.StatusCode)
+}
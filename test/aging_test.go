@@ -0,0 +1,74 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/service"
+)
+
+func TestAgeDays(t *testing.T) {
+	ran := time.Date(2024, 2, 10, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0, domain.AgeDays(time.Date(2024, 2, 10, 23, 0, 0, 0, time.UTC), ran))
+	assert.Equal(t, 1, domain.AgeDays(time.Date(2024, 2, 9, 23, 59, 0, 0, time.UTC), ran), "calendar days, not 24h periods")
+	assert.Equal(t, 31, domain.AgeDays(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), ran))
+	assert.Equal(t, 0, domain.AgeDays(time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), ran), "future dates do not go negative")
+
+	var buckets domain.AgingBuckets
+	for _, days := range []int{0, 1, 2, 7, 8, 30, 31, 90} {
+		buckets.Add(days)
+	}
+	assert.Equal(t, domain.AgingBuckets{Days0To1: 2, Days2To7: 2, Days8To30: 2, Over30: 2}, buckets)
+
+	// Dated before the cutoff means at least that old
+	before := domain.AgedBefore(ran, 2)
+	assert.Equal(t, 2, domain.AgeDays(before.Add(-time.Nanosecond), ran))
+	assert.Equal(t, 1, domain.AgeDays(before, ran))
+}
+
+func TestReconciliationService_UnmatchedAging(t *testing.T) {
+	today := time.Now().UTC()
+	daysAgo := func(n int) time.Time { return today.AddDate(0, 0, -n) }
+	reconRepo := newMockReconciliationRepository()
+	job := &domain.ReconciliationJob{JobID: "job-aging", Status: domain.Completed}
+	assert.NoError(t, reconRepo.CreateJob(context.Background(), job))
+
+	amount := decimal.NewFromInt(10)
+	for i, age := range []int{0, 3, 10, 45} {
+		date := daysAgo(age)
+		id := string(rune('A' + i))
+		assert.NoError(t, reconRepo.CreateResult(context.Background(), &domain.ReconciliationResult{
+			JobID: job.JobID, TrxID: &id, SystemAmount: &amount, MatchStatus: domain.UnmatchedSystem, TransactionDate: &date,
+		}))
+	}
+	matchedDate := daysAgo(60)
+	assert.NoError(t, reconRepo.CreateResult(context.Background(), &domain.ReconciliationResult{
+		JobID: job.JobID, MatchStatus: domain.Matched, TransactionDate: &matchedDate,
+	}))
+
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100)
+
+	summary, err := svc.GetJobSummary(context.Background(), job.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.AgingBuckets{Days0To1: 1, Days2To7: 1, Days8To30: 1, Over30: 1}, summary.UnmatchedAging)
+	if assert.Len(t, summary.UnmatchedSystem, 4) {
+		assert.Equal(t, 45, *summary.UnmatchedSystem[3].AgeDays)
+	}
+
+	page, err := svc.GetJobResults(context.Background(), job.JobID, domain.ResultFilter{MinAgeDays: 8}, 1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Total, "matched results never age")
+	for _, result := range page.Results {
+		assert.GreaterOrEqual(t, *result.AgeDays, 8)
+	}
+
+	all, err := svc.GetJobResults(context.Background(), job.JobID, domain.ResultFilter{}, 1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, all.Total)
+	assert.Nil(t, all.Results[4].AgeDays)
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+	"recon-engine/internal/storage"
+	"recon-engine/pkg/response"
+)
+
+func newAttachmentRouter(t *testing.T) (*gin.Engine, *mockAttachmentRepository, *storage.LocalFileStore) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &mockAttachmentRepository{results: map[int]bool{7: true}}
+	store := storage.NewLocalFileStore(t.TempDir())
+	h := handler.NewAttachmentHandler(service.NewAttachmentService(repo, store), 1<<20)
+
+	router := gin.New()
+	router.POST("/api/v1/reconcile/results/:id/attachments", h.UploadAttachment)
+	router.GET("/api/v1/reconcile/results/:id/attachments", h.ListAttachments)
+	return router, repo, store
+}
+
+func newAttachmentRequest(t *testing.T, resultID string, fields map[string]string, parts []uploadPart) *http.Request {
+	req := newUploadRequest(t, fields, parts)
+	req.URL.Path = "/api/v1/reconcile/results/" + resultID + "/attachments"
+	return req
+}
+
+func TestAttachments_UploadAndList(t *testing.T) {
+	router, repo, store := newAttachmentRouter(t)
+
+	req := newAttachmentRequest(t, "7", map[string]string{"description": "Bank advice"}, []uploadPart{
+		{"file", "advice.pdf", "application/pdf", "%PDF-1.4 test"},
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var uploaded struct {
+		response.Response
+		Data domain.Attachment `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &uploaded))
+	assert.Equal(t, 7, uploaded.Data.ResultID)
+	assert.Equal(t, "advice.pdf", uploaded.Data.FileName)
+	assert.Equal(t, "application/pdf", uploaded.Data.ContentType)
+	assert.Equal(t, int64(13), uploaded.Data.Size)
+	assert.Equal(t, "Bank advice", uploaded.Data.Description)
+	assert.NotContains(t, rec.Body.String(), "storage_key", "storage location is not exposed")
+
+	// The stored file can be read back by its key
+	assert.Len(t, repo.attachments, 1)
+	f, err := store.Open(repo.attachments[0].StorageKey)
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, "%PDF-1.4 test", string(content))
+	assert.True(t, strings.HasSuffix(repo.attachments[0].StorageKey, ".pdf"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/results/7/attachments", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var listed struct {
+		response.Response
+		Data []domain.Attachment `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	assert.Len(t, listed.Data, 1)
+	assert.Equal(t, uploaded.Data.ID, listed.Data[0].ID)
+}
+
+func TestAttachments_UnknownResult(t *testing.T) {
+	router, repo, _ := newAttachmentRouter(t)
+
+	req := newAttachmentRequest(t, "99", nil, []uploadPart{{"file", "note.txt", "text/plain", "hello"}})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, repo.attachments)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/results/99/attachments", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAttachments_Validation(t *testing.T) {
+	router, _, _ := newAttachmentRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, newAttachmentRequest(t, "abc", nil, []uploadPart{{"file", "note.txt", "text/plain", "hello"}}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, newAttachmentRequest(t, "7", map[string]string{"description": "no file"}, nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestLocalFileStore_RejectsEscapingKeys(t *testing.T) {
+	store := storage.NewLocalFileStore(t.TempDir())
+
+	_, err := store.Save("../outside.txt", strings.NewReader("x"))
+	assert.Error(t, err)
+	_, err = store.Save("/etc/passwd", strings.NewReader("x"))
+	assert.Error(t, err)
+}
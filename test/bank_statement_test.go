@@ -0,0 +1,54 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func TestGetBankStatementsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	bankRepo := &mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "bank_a", JobID: "job-1"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(200.00), Date: day, Source: "bank_b", JobID: "job-1"},
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(300.00), Date: day.AddDate(0, 0, 1), Source: "bank_a"},
+	}}
+	router := gin.New()
+	router.GET("/api/v1/bank-statements", handler.NewBankStatementHandler(service.NewBankStatementService(bankRepo)).GetBankStatements)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/bank-statements?"+query, nil))
+		return rec
+	}
+	list := func(query string) []domain.BankStatement {
+		rec := get(query)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp struct {
+			Data []domain.BankStatement `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	day15 := "start_date=2024-01-15T00:00:00Z&end_date=2024-01-16T00:00:00Z"
+	assert.Len(t, list(day15), 2)
+	statements := list(day15 + "&source=bank_a")
+	assert.Len(t, statements, 1)
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+	assert.Equal(t, "job-1", statements[0].JobID)
+
+	assert.Equal(t, http.StatusBadRequest, get("start_date=2024-01-15&end_date=2024-01-16T00:00:00Z").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, get("source=bank_a").Code)
+}
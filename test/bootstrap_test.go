@@ -0,0 +1,36 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/bootstrap"
+	"recon-engine/internal/config"
+)
+
+func TestReconciliationOptions(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	opts, err := bootstrap.ReconciliationOptions(cfg)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, opts)
+
+	for name, mutate := range map[string]func(*config.Config){
+		"duplicate policy": func(c *config.Config) { c.Matcher.DuplicatePolicy = "newest" },
+		"strategy":         func(c *config.Config) { c.Matcher.Strategy = "psychic" },
+		"reference format": func(c *config.Config) { c.Matcher.SystemReferencePattern = "([" },
+		"balance pattern":  func(c *config.Config) { c.App.BalanceOpeningPattern = "([" },
+		"reference field":  func(c *config.Config) { c.Matcher.ReferenceField = "invoice_id" },
+		"result sink":      func(c *config.Config) { c.Sinks.Sinks = []string{"s3"} },
+		"kafka url":        func(c *config.Config) { c.Sinks.Sinks, c.Sinks.KafkaRESTURL = []string{"kafka"}, "" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.Load()
+			assert.NoError(t, err)
+			mutate(cfg)
+			_, err = bootstrap.ReconciliationOptions(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/config"
+)
+
+func TestLoad_DatabasePool(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 25, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 5, cfg.Database.MaxIdleConns)
+	assert.Zero(t, cfg.Database.ConnMaxLifetime)
+	assert.False(t, cfg.Database.AutoMigrate)
+
+	t.Setenv("DB_MAX_OPEN_CONNS", "200")
+	t.Setenv("DB_MAX_IDLE_CONNS", "50")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "30m")
+	t.Setenv("DB_CONN_MAX_IDLE_TIME", "5m")
+	t.Setenv("DB_AUTO_MIGRATE", "true")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 200, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 50, cfg.Database.MaxIdleConns)
+	assert.Equal(t, 30*time.Minute, cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, 5*time.Minute, cfg.Database.ConnMaxIdleTime)
+	assert.True(t, cfg.Database.AutoMigrate)
+}
+
+func TestLoad_RejectsNegativePoolSettings(t *testing.T) {
+	for key, value := range map[string]string{
+		"DB_MAX_OPEN_CONNS":     "-1",
+		"DB_MAX_IDLE_CONNS":     "many",
+		"DB_CONN_MAX_LIFETIME":  "-1m",
+		"DB_CONN_MAX_IDLE_TIME": "soon",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := config.Load()
+			assert.ErrorContains(t, err, key)
+		})
+	}
+}
+
+func TestLoad_ReconcileRateLimit(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.App.ReconcileRateLimit)
+	assert.Equal(t, 5, cfg.App.ReconcileRateBurst)
+	assert.Equal(t, "ip", cfg.App.ReconcileRateLimitBy)
+
+	t.Setenv("RECONCILE_RATE_LIMIT_BY", "token")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "RECONCILE_RATE_LIMIT_BY")
+}
+
+func TestLoad_RequestTimeout(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.Server.RequestTimeout)
+
+	t.Setenv("REQUEST_TIMEOUT", "30s")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.Server.RequestTimeout)
+
+	t.Setenv("REQUEST_TIMEOUT", "-1s")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "REQUEST_TIMEOUT")
+}
+
+func TestLoad_LogFormat(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "json", cfg.App.LogFormat)
+	assert.True(t, cfg.App.LogTimestamps)
+	assert.Empty(t, cfg.App.LogTimestampFormat)
+
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("LOG_TIMESTAMPS", "false")
+	t.Setenv("LOG_TIMESTAMP_FORMAT", "15:04:05")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "text", cfg.App.LogFormat)
+	assert.False(t, cfg.App.LogTimestamps)
+	assert.Equal(t, "15:04:05", cfg.App.LogTimestampFormat)
+
+	t.Setenv("LOG_FORMAT", "xml")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "LOG_FORMAT")
+
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_TIMESTAMP_FORMAT", "hh:mm")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "LOG_TIMESTAMP_FORMAT")
+}
+
+func TestLoad_Tracing(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Tracing.Endpoint, "export is off by default")
+	assert.Equal(t, "recon-engine", cfg.Tracing.ServiceName)
+	assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "collector:4318")
+	t.Setenv("OTEL_TRACES_SAMPLE_RATIO", "0.25")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "collector:4318", cfg.Tracing.Endpoint)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+
+	t.Setenv("OTEL_TRACES_SAMPLE_RATIO", "1.5")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "OTEL_TRACES_SAMPLE_RATIO")
+}
+
+func TestLoad_DateFormats(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.App.DateFormats)
+	assert.True(t, cfg.App.DateDayFirst)
+
+	t.Setenv("DATE_FORMATS", `["Jan 2, 2006", "02.01.2006"]`)
+	t.Setenv("DATE_DAY_FIRST", "false")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Jan 2, 2006", "02.01.2006"}, cfg.App.DateFormats)
+	assert.False(t, cfg.App.DateDayFirst)
+
+	t.Setenv("DATE_FORMATS", `["YYYY-MM-DD"]`)
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "DATE_FORMATS")
+
+	t.Setenv("DATE_FORMATS", "")
+	t.Setenv("BANK_DATE_LAYOUTS", `{"bank_us.csv": "01/02/2006"}`)
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"bank_us.csv": "01/02/2006"}, cfg.App.BankDateLayouts)
+
+	t.Setenv("BANK_DATE_LAYOUTS", `{"bank_us.csv": "MM/DD/YYYY"}`)
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "BANK_DATE_LAYOUTS")
+}
+
+func TestLoad_Storage(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east-1", cfg.Storage.S3Region)
+	assert.False(t, cfg.Storage.S3PathStyle)
+
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Storage.S3Region)
+
+	t.Setenv("AWS_REGION", "ap-southeast-1")
+	t.Setenv("S3_ENDPOINT", "http://minio:9000")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "ap-southeast-1", cfg.Storage.S3Region)
+	assert.Equal(t, "http://minio:9000", cfg.Storage.S3Endpoint)
+	assert.Equal(t, "AKID", cfg.Storage.AWSAccessKeyID)
+}
+
+func TestLoad_DatabaseRetry(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.Database.RetryAttempts)
+	assert.Equal(t, 100*time.Millisecond, cfg.Database.RetryBackoff)
+	assert.Equal(t, 2*time.Second, cfg.Database.RetryMaxBackoff)
+
+	t.Setenv("DB_RETRY_ATTEMPTS", "0")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "DB_RETRY_ATTEMPTS")
+}
+
+func TestLoad_CSVComment(t *testing.T) {
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.App.CSVComment)
+
+	t.Setenv("CSV_COMMENT_CHAR", "#")
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, '#', cfg.App.CSVComment)
+
+	t.Setenv("CSV_DELIMITER", ";")
+	t.Setenv("CSV_COMMENT_CHAR", ";")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "CSV_COMMENT_CHAR")
+}
+
+func TestLoad_AmountScale(t *testing.T) {
+	t.Setenv("MATCH_CURRENCY_SCALES", `{"JPY": 0, "BHD": 3}`)
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), cfg.Matcher.AmountScale)
+	assert.Equal(t, int32(3), cfg.Matcher.CurrencyScales["BHD"])
+
+	// The amount columns keep four decimal places
+	t.Setenv("MATCH_CURRENCY_SCALES", `{"XYZ": 5}`)
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "MATCH_CURRENCY_SCALES")
+
+	t.Setenv("MATCH_CURRENCY_SCALES", "")
+	t.Setenv("MATCH_AMOUNT_SCALE", "6")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "MATCH_AMOUNT_SCALE")
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+const bankHeader = "trx_ref_id,amount,date\n"
+
+func emptyInputService() service.ReconciliationService {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	return service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+}
+
+func TestReconciliationService_ReportsHeaderOnlyBankFile(t *testing.T) {
+	dir := t.TempDir()
+	good := writeFile(t, dir, "bank_a.csv", bankHeader+"TX001,100.00,2024-01-15\n")
+	empty := writeFile(t, dir, "bank_b.csv", bankHeader)
+
+	summary, err := emptyInputService().Reconcile(context.Background(), nil, []string{good, empty}, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.False(t, summary.Incomplete, "an empty file loaded; it did not fail")
+	assert.Equal(t, []domain.FileLoadReport{
+		{File: good, Source: "bank_a.csv", Rows: 1},
+		{File: empty, Source: "bank_b.csv", Empty: true},
+	}, summary.FileLoadReport)
+}
+
+func TestReconciliationService_RejectsHeaderOnlyBankFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFile(t, dir, "bank_a.csv", bankHeader)
+	second := writeFile(t, dir, "bank_b.csv", bankHeader)
+
+	_, err := emptyInputService().Reconcile(context.Background(), nil, []string{first, second}, lifecycleDay, lifecycleDay, false)
+	assert.ErrorIs(t, err, service.ErrEmptyInput)
+	assert.EqualError(t, err, "no bank statements loaded: input files have no data rows: "+first+", "+second)
+
+	// A file that failed to parse is not reported as empty
+	bad := writeFile(t, dir, "bank_c.csv", "id,value\n1,100\n")
+	_, err = emptyInputService().Reconcile(context.Background(), nil, []string{first, bad}, lifecycleDay, lifecycleDay, false)
+	assert.EqualError(t, err, "no bank statements loaded")
+}
+
+func TestReconciliationService_ReportsHeaderOnlySystemFile(t *testing.T) {
+	dir := t.TempDir()
+	bankFile := writeFile(t, dir, "bank_a.csv", bankHeader+"TX001,100.00,2024-01-15\n")
+	system := writeFile(t, dir, "system_a.csv", "trx_id,amount,type,transaction_time\nTX001,100.00,CREDIT,2024-01-15T10:00:00Z\n")
+	empty := writeFile(t, dir, "system_b.csv", "trx_id,amount,type,transaction_time\n")
+
+	summary, err := emptyInputService().Reconcile(context.Background(), []string{system, empty}, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.FileLoadReport{
+		{File: "system_a.csv", Rows: 1},
+		{File: "system_b.csv", Empty: true},
+	}, summary.SystemFileReport)
+
+	// Reconciling against empty system files alone is refused
+	_, err = emptyInputService().Reconcile(context.Background(), []string{empty}, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+	assert.ErrorIs(t, err, service.ErrEmptyInput)
+	assert.ErrorContains(t, err, "system_b.csv")
+}
+
+func TestReconciliationHandler_EmptyInputFiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(emptyInputService()).Reconcile)
+	post := func(bankFiles ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"bank_file_paths": bankFiles,
+			"start_date":      "2024-01-15",
+			"end_date":        "2024-01-15",
+		})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", strings.NewReader(string(body))))
+		return rec
+	}
+
+	dir := t.TempDir()
+	empty := writeFile(t, dir, "bank_b.csv", bankHeader)
+	rec := post(empty)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no data rows")
+
+	rec = post(writeFile(t, dir, "bank_a.csv", bankHeader+"TX001,100.00,2024-01-15\n"), empty)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "some input files have no data rows")
+}
@@ -0,0 +1,311 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/export"
+	"recon-engine/internal/matcher"
+)
+
+func TestCSVResultWriter_Write(t *testing.T) {
+	trxID := "TX001"
+	refID := "TX001"
+	source := "BankA"
+	systemAmount := decimal.NewFromFloat(100.00)
+	bankAmount := decimal.NewFromFloat(90.50)
+	discrepancy := decimal.NewFromFloat(9.50)
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	results := []domain.ReconciliationResult{
+		{
+			TrxID:           &trxID,
+			TrxRefID:        &refID,
+			SystemAmount:    &systemAmount,
+			BankAmount:      &bankAmount,
+			Discrepancy:     &discrepancy,
+			MatchStatus:     domain.Discrepancy,
+			BankSource:      &source,
+			TransactionDate: &date,
+		},
+		{
+			TrxID:        &trxID,
+			SystemAmount: &systemAmount,
+			MatchStatus:  domain.UnmatchedSystem,
+		},
+	}
+
+	var buf bytes.Buffer
+	writer := export.NewCSVResultWriter(&buf)
+	assert.NoError(t, writer.WriteHeader())
+	assert.NoError(t, writer.Write(results))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, export.ResultColumns, records[0])
+	assert.Equal(t, []string{"DISCREPANCY", "TX001", "TX001", "100.00", "90.50", "9.50", "BankA", "2024-01-15T10:00:00Z", "", "", "", ""}, records[1])
+	assert.Equal(t, []string{"UNMATCHED_SYSTEM", "TX001", "", "100.00", "", "", "", "", "", "", "", ""}, records[2])
+}
+
+func TestCSVResultWriter_IncludesEnrichedFields(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithResultEnrichment(true))
+
+	createdAt := time.Date(2024, 1, 14, 8, 0, 0, 0, time.UTC)
+	txTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	output := &matcher.ReconciliationOutput{
+		Matched: []matcher.MatchedPair{
+			{
+				SystemTx: domain.Transaction{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: txTime, CreatedAt: createdAt},
+				BankStmt: domain.BankStatement{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: txTime, Source: "BankA"},
+			},
+		},
+		UnmatchedBank: []domain.BankStatement{
+			{TrxRefID: "TX999", Amount: decimal.NewFromFloat(50.00), Date: txTime, Source: "BankA"},
+		},
+	}
+
+	results := engine.BuildResults("job-enriched", output)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, domain.Credit, *results[0].TransactionType)
+	assert.True(t, results[0].TransactionCreatedAt.Equal(createdAt))
+	assert.Nil(t, results[1].TransactionType, "Bank-only results have no transaction metadata")
+
+	var buf bytes.Buffer
+	writer := export.NewCSVResultWriter(&buf)
+	assert.NoError(t, writer.WriteHeader())
+	assert.NoError(t, writer.Write(results))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, "CREDIT", records[1][8])
+	assert.Equal(t, "2024-01-14T08:00:00Z", records[1][9])
+	assert.Equal(t, "", records[2][8])
+}
+
+func TestReconciliationEngine_BuildResultsWithoutEnrichment(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+
+	output := &matcher.ReconciliationOutput{
+		UnmatchedSystem: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: time.Now(), CreatedAt: time.Now()},
+		},
+	}
+
+	results := engine.BuildResults("job-plain", output)
+	assert.Nil(t, results[0].TransactionType)
+	assert.Nil(t, results[0].TransactionCreatedAt)
+}
+
+func TestCSVResultWriter_FriendlyFormatter(t *testing.T) {
+	trxID := "TX001"
+	refID := "TX001"
+	source := "BankA"
+	systemAmount := decimal.NewFromFloat(-1234567.5)
+	bankAmount := decimal.NewFromFloat(-1234000)
+	discrepancy := decimal.NewFromFloat(567.5)
+	date := time.Date(2024, 1, 5, 14, 30, 0, 0, time.UTC)
+	debit := domain.Debit
+
+	results := []domain.ReconciliationResult{
+		{
+			TrxID:           &trxID,
+			TrxRefID:        &refID,
+			SystemAmount:    &systemAmount,
+			BankAmount:      &bankAmount,
+			Discrepancy:     &discrepancy,
+			MatchStatus:     domain.Discrepancy,
+			BankSource:      &source,
+			TransactionDate: &date,
+			TransactionType: &debit,
+		},
+		{
+			TrxRefID:    &refID,
+			BankAmount:  &bankAmount,
+			MatchStatus: domain.UnmatchedBank,
+		},
+	}
+
+	locale, err := export.ParseLocale("id-ID")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer := export.NewCSVResultWriter(&buf, export.WithFormatter(export.NewFriendlyFormatter(locale)))
+	assert.NoError(t, writer.WriteHeader())
+	assert.NoError(t, writer.Write(results))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "Status", records[0][0])
+	assert.Equal(t, []string{"Amount Mismatch", "TX001", "TX001", "-1.234.567,50", "-1.234.000,00", "567,50", "BankA", "05/01/2024 14:30", "Debit", "", "", ""}, records[1])
+	assert.Equal(t, "Missing from System", records[2][0])
+}
+
+func TestFriendlyFormatter_Locales(t *testing.T) {
+	amount := decimal.NewFromFloat(1234.5)
+	small := decimal.NewFromFloat(12)
+	date := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	us, err := export.ParseLocale("en-us")
+	assert.NoError(t, err)
+	f := export.NewFriendlyFormatter(us)
+	assert.Equal(t, "1,234.50", f.Decimal(&amount, 2))
+	assert.Equal(t, "12.00", f.Decimal(&small, 2))
+	assert.Equal(t, "01/05/2024 09:00", f.Time(&date))
+
+	de, err := export.ParseLocale("de-DE")
+	assert.NoError(t, err)
+	assert.Equal(t, "05.01.2024 09:00", export.NewFriendlyFormatter(de).Time(&date))
+
+	_, err = export.ParseLocale("xx-XX")
+	assert.Error(t, err)
+}
+
+func TestJournalLines_Balance(t *testing.T) {
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	debit := domain.Debit
+
+	result := func(id string, status domain.MatchStatus, system, bank float64) domain.ReconciliationResult {
+		trxID := id
+		systemAmount := decimal.NewFromFloat(system)
+		bankAmount := decimal.NewFromFloat(bank)
+		return domain.ReconciliationResult{
+			TrxID:           &trxID,
+			TrxRefID:        &trxID,
+			SystemAmount:    &systemAmount,
+			BankAmount:      &bankAmount,
+			MatchStatus:     status,
+			TransactionDate: &date,
+		}
+	}
+
+	zeroDebit := result("TX006", domain.Matched, 0, 0)
+	zeroDebit.TransactionType = &debit
+
+	results := []domain.ReconciliationResult{
+		result("TX001", domain.Matched, 100, 100),
+		result("TX002", domain.Matched, 250, -250),
+		result("TX003", domain.Discrepancy, 90, 100),
+		result("TX004", domain.Discrepancy, 100, 90),
+		result("TX005", domain.Discrepancy, 100, -120),
+		zeroDebit,
+		result("TX007", domain.UnmatchedSystem, 50, 0),
+	}
+
+	accounts := export.DefaultJournalAccounts
+	for _, r := range results {
+		lines := export.JournalLines(r, accounts, 2)
+		if r.MatchStatus == domain.UnmatchedSystem {
+			assert.Empty(t, lines, "unmatched items are not posted")
+			continue
+		}
+
+		debits, credits := decimal.Zero, decimal.Zero
+		for _, line := range lines {
+			debits = debits.Add(line.Debit)
+			credits = credits.Add(line.Credit)
+		}
+		assert.True(t, debits.Equal(credits), "%s: debits %s != credits %s", *r.TrxID, debits, credits)
+	}
+
+	// Money in with the bank over the system amount: the excess is a suspense credit
+	lines := export.JournalLines(results[2], accounts, 2)
+	assert.Equal(t, 3, len(lines))
+	assert.Equal(t, "Bank", lines[0].Account)
+	assert.True(t, lines[0].Debit.Equal(decimal.NewFromInt(100)))
+	assert.Equal(t, "Clearing", lines[1].Account)
+	assert.True(t, lines[1].Credit.Equal(decimal.NewFromInt(90)))
+	assert.Equal(t, "Suspense", lines[2].Account)
+	assert.True(t, lines[2].Credit.Equal(decimal.NewFromInt(10)))
+
+	// Money out reverses the bank and clearing sides
+	lines = export.JournalLines(results[1], accounts, 2)
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, "Clearing", lines[0].Account)
+	assert.True(t, lines[0].Debit.Equal(decimal.NewFromInt(250)))
+	assert.Equal(t, "Bank", lines[1].Account)
+	assert.True(t, lines[1].Credit.Equal(decimal.NewFromInt(250)))
+}
+
+func TestCSVJournalWriter_Write(t *testing.T) {
+	trxID := "TX001"
+	systemAmount := decimal.NewFromFloat(100.00)
+	bankAmount := decimal.NewFromFloat(-95.00)
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	currency := "USD"
+
+	var buf bytes.Buffer
+	writer := export.NewCSVJournalWriter(&buf, export.JournalAccounts{Bank: "1000", Clearing: "2100", Suspense: "9999"})
+	assert.NoError(t, writer.WriteHeader())
+	assert.NoError(t, writer.Write([]domain.ReconciliationResult{{
+		TrxID:           &trxID,
+		TrxRefID:        &trxID,
+		SystemAmount:    &systemAmount,
+		BankAmount:      &bankAmount,
+		MatchStatus:     domain.Discrepancy,
+		TransactionDate: &date,
+		Currency:        &currency,
+	}}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, export.JournalColumns, records[0])
+	assert.Equal(t, []string{"TX001", "2024-01-15", "2100", "100.00", "", "TX001", "Amount mismatch", "USD"}, records[1])
+	assert.Equal(t, []string{"TX001", "2024-01-15", "1000", "", "95.00", "TX001", "Amount mismatch", "USD"}, records[2])
+	assert.Equal(t, []string{"TX001", "2024-01-15", "9999", "", "5.00", "TX001", "Amount mismatch", "USD"}, records[3])
+}
+
+func TestExportWriters_CurrencyScales(t *testing.T) {
+	scales := export.AmountScales{Default: 2, ByCurrency: map[string]int32{"BHD": 3}}
+	bhd, usd := "BHD", "USD"
+	bhdID, usdID := "TX001", "TX002"
+	bhdSystem, bhdBank, bhdDiff := decimal.RequireFromString("1234.567"), decimal.RequireFromString("1234.5"), decimal.RequireFromString("0.067")
+	usdSystem, usdBank, usdDiff := decimal.RequireFromString("10.003"), decimal.RequireFromString("10.006"), decimal.RequireFromString("-0.003")
+
+	results := []domain.ReconciliationResult{
+		{TrxID: &bhdID, TrxRefID: &bhdID, SystemAmount: &bhdSystem, BankAmount: &bhdBank, Discrepancy: &bhdDiff, MatchStatus: domain.Discrepancy, Currency: &bhd},
+		// The bank currency is used when the system side has none
+		{TrxID: &usdID, TrxRefID: &usdID, SystemAmount: &usdSystem, BankAmount: &usdBank, Discrepancy: &usdDiff, MatchStatus: domain.Discrepancy, BankCurrency: &usd},
+	}
+
+	var buf bytes.Buffer
+	writer := export.NewCSVResultWriter(&buf, export.WithAmountScales(scales))
+	assert.NoError(t, writer.Write(results))
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1234.567", "1234.500", "0.067"}, records[0][3:6])
+	assert.Equal(t, []string{"10.00", "10.01"}, records[1][3:5])
+
+	locale, err := export.ParseLocale("en-US")
+	assert.NoError(t, err)
+	buf.Reset()
+	writer = export.NewCSVResultWriter(&buf, export.WithFormatter(export.NewFriendlyFormatter(locale)), export.WithAmountScales(scales))
+	assert.NoError(t, writer.Write(results[:1]))
+	records, err = csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1,234.567", "1,234.500", "0.067"}, records[0][3:6])
+
+	// Every entry balances as printed: 10.006 and 10.003 round to 10.01 and
+	// 10.00, so 0.01 goes to suspense
+	buf.Reset()
+	journal := export.NewCSVJournalWriter(&buf, export.DefaultJournalAccounts, export.WithJournalAmountScales(scales))
+	assert.NoError(t, journal.Write(results))
+	records, err = csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 6) {
+		assert.Equal(t, []string{"Bank", "1234.500", ""}, records[0][2:5])
+		assert.Equal(t, []string{"Clearing", "", "1234.567"}, records[1][2:5])
+		assert.Equal(t, []string{"Suspense", "0.067", ""}, records[2][2:5])
+		assert.Equal(t, []string{"Bank", "10.01", ""}, records[3][2:5])
+		assert.Equal(t, []string{"Clearing", "", "10.00"}, records[4][2:5])
+		assert.Equal(t, []string{"Suspense", "", "0.01"}, records[5][2:5])
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/handler"
+)
+
+// pingerFunc adapts a function to handler.Pinger
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+func probe(t *testing.T, db handler.Pinger, path string) (int, map[string]string) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewHealthHandler(db, 20*time.Millisecond)
+	router := gin.New()
+	router.GET("/livez", h.Live)
+	router.GET("/readyz", h.Ready)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	code, body := probe(t, pingerFunc(func(context.Context) error { return nil }), "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "up", body["database"])
+
+	down := pingerFunc(func(context.Context) error { return errors.New("connection refused") })
+	code, body = probe(t, down, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "down", body["database"])
+	assert.Equal(t, "connection refused", body["error"])
+
+	// A hung database fails the probe once the timeout passes
+	hung := pingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	code, _ = probe(t, hung, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestHealthHandler_LiveIgnoresDatabase(t *testing.T) {
+	down := pingerFunc(func(context.Context) error { return errors.New("connection refused") })
+	code, body := probe(t, down, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "alive", body["status"])
+}
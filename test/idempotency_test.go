@@ -0,0 +1,141 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func newIdempotentService(reconRepo *mockReconciliationRepository, keys *mockIdempotencyRepository, ttl time.Duration) service.ReconciliationService {
+	at := lifecycleDay.Add(10 * time.Hour)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: at},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: at},
+	}}
+	bankRepo := &mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: at, Source: "bank_a"},
+	}}
+	return service.NewReconciliationService(txRepo, reconRepo, 100,
+		service.WithBankStatementRepository(bankRepo), service.WithIdempotencyStore(keys, ttl))
+}
+
+func TestReconciliationService_IdempotencyKeyReplaysJob(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newIdempotentService(reconRepo, newMockIdempotencyRepository(), time.Hour)
+
+	first, err := svc.ForIdempotencyKey("key-1").ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.False(t, first.Replayed)
+
+	repeat, err := svc.ForIdempotencyKey("key-1").ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.True(t, repeat.Replayed)
+	assert.Equal(t, first.JobID, repeat.JobID)
+	assert.Equal(t, first.TotalMatched, repeat.TotalMatched)
+	assert.Equal(t, first.TotalUnmatched, repeat.TotalUnmatched)
+	assert.Len(t, reconRepo.jobs, 1)
+
+	other, err := svc.ForIdempotencyKey("key-2").ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.JobID, other.JobID)
+
+	_, err = svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Len(t, reconRepo.jobs, 3, "requests without a key are never deduplicated")
+}
+
+func TestReconciliationService_IdempotencyKeyExpires(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newIdempotentService(reconRepo, newMockIdempotencyRepository(), time.Millisecond).ForIdempotencyKey("key-1")
+
+	first, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	second, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.False(t, second.Replayed)
+	assert.NotEqual(t, first.JobID, second.JobID)
+}
+
+func TestReconciliationService_FailedJobReleasesIdempotencyKey(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	keys := newMockIdempotencyRepository()
+	svc := newIdempotentService(reconRepo, keys, time.Hour).ForIdempotencyKey("key-1")
+
+	_, err := svc.Reconcile(context.Background(), nil, []string{"/nonexistent/bank.csv"}, lifecycleDay, lifecycleDay, false)
+	assert.Error(t, err)
+	assert.Empty(t, keys.claims)
+
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.False(t, summary.Replayed)
+	assert.Len(t, reconRepo.jobs, 2)
+}
+
+func TestReconciliationService_IdempotencyKeyInUse(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	keys := newMockIdempotencyRepository()
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+	keys.claims["key-1"] = idempotencyClaim{jobID: "running", expiresAt: time.Now().Add(time.Hour)}
+	svc := newIdempotentService(reconRepo, keys, time.Hour)
+
+	_, err := svc.ForIdempotencyKey("key-1").ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.ErrorIs(t, err, service.ErrIdempotencyKeyInUse)
+	assert.Len(t, reconRepo.jobs, 1)
+}
+
+func TestReconcileHandler_IdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	keys := newMockIdempotencyRepository()
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+	keys.claims["busy"] = idempotencyClaim{jobID: "running", expiresAt: time.Now().Add(time.Hour)}
+
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(newIdempotentService(reconRepo, keys, time.Hour)).Reconcile)
+	post := func(key string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"bank_source": "database",
+			"start_date":  "2024-01-15",
+			"end_date":    "2024-01-15",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(handler.IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) domain.ReconciliationSummary {
+		var resp struct {
+			Data domain.ReconciliationSummary `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	first := post("retry-me")
+	assert.Equal(t, http.StatusOK, first.Code)
+	repeat := post("retry-me")
+	assert.Equal(t, http.StatusOK, repeat.Code)
+	assert.Equal(t, decode(first).JobID, decode(repeat).JobID)
+	assert.True(t, decode(repeat).Replayed)
+	assert.Contains(t, repeat.Body.String(), "already submitted")
+
+	assert.Equal(t, http.StatusConflict, post("busy").Code)
+	assert.Equal(t, http.StatusBadRequest, post(strings.Repeat("k", 256)).Code)
+}
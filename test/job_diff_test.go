@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+// seedDiffJob stores a completed job covering start to end with results
+func seedDiffJob(repo *mockReconciliationRepository, jobID string, start, end time.Time, results ...domain.ReconciliationResult) {
+	repo.jobs[jobID] = domain.ReconciliationJob{JobID: jobID, Status: domain.Completed, StartDate: start, EndDate: end}
+	for _, result := range results {
+		result.JobID = jobID
+		repo.results = append(repo.results, result)
+	}
+}
+
+func diffResult(trxID, trxRefID string, status domain.MatchStatus, day time.Time) domain.ReconciliationResult {
+	result := domain.ReconciliationResult{MatchStatus: status, TransactionDate: &day, BankSource: ptr("bank_a")}
+	if trxID != "" {
+		result.TrxID = ptr(trxID)
+		result.SystemAmount = ptrDecimal(decimal.NewFromFloat(100))
+	}
+	if trxRefID != "" {
+		result.TrxRefID = ptr(trxRefID)
+		result.BankAmount = ptrDecimal(decimal.NewFromFloat(100))
+	}
+	return result
+}
+
+func ptr(s string) *string { return &s }
+
+func ptrDecimal(d decimal.Decimal) *decimal.Decimal { return &d }
+
+func diffIDs(items []domain.JobDiffItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		if item.TrxID != nil {
+			ids[i] = *item.TrxID
+		} else {
+			ids[i] = *item.TrxRefID
+		}
+	}
+	return ids
+}
+
+func TestReconciliationService_DiffJobs(t *testing.T) {
+	repo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(repo)
+	jan15 := lifecycleDay
+	jan16 := jan15.AddDate(0, 0, 1)
+	jan17 := jan15.AddDate(0, 0, 2)
+
+	seedDiffJob(repo, "yesterday", jan15, jan16,
+		diffResult("TX001", "", domain.UnmatchedSystem, jan15),
+		diffResult("", "REF002", domain.UnmatchedBank, jan15),
+		diffResult("TX003", "TX003", domain.Discrepancy, jan16),
+		diffResult("TX004", "", domain.UnmatchedSystem, jan16),
+		diffResult("TX005", "TX005", domain.Matched, jan16),
+	)
+	seedDiffJob(repo, "today", jan15, jan17,
+		diffResult("TX001", "REF001", domain.Matched, jan15),
+		// The late system transaction pairs the statement seen yesterday
+		diffResult("TX002", "REF002", domain.Matched, jan15),
+		diffResult("TX003", "TX003", domain.Matched, jan16),
+		diffResult("TX004", "", domain.UnmatchedSystem, jan16),
+		diffResult("TX005", "", domain.UnmatchedSystem, jan16),
+		diffResult("TX006", "", domain.UnmatchedSystem, jan16),
+		// Outside yesterday's range
+		diffResult("TX007", "", domain.UnmatchedSystem, jan17),
+	)
+
+	diff, err := svc.DiffJobs(context.Background(), "yesterday", "today")
+	assert.NoError(t, err)
+	assert.Equal(t, jan15, *diff.OverlapStart)
+	assert.Equal(t, jan16, *diff.OverlapEnd)
+	assert.Equal(t, []string{"TX001", "TX002"}, diffIDs(diff.NewlyMatched))
+	assert.Equal(t, domain.UnmatchedBank, diff.NewlyMatched[1].PreviousStatus)
+	assert.Equal(t, []string{"TX003"}, diffIDs(diff.ResolvedDiscrepancies))
+	assert.Equal(t, []string{"TX004"}, diffIDs(diff.StillUnmatched))
+	assert.Equal(t, []string{"TX005", "TX006"}, diffIDs(diff.NewlyUnmatched))
+	assert.Equal(t, domain.Matched, diff.NewlyUnmatched[0].PreviousStatus)
+	assert.Empty(t, diff.NewlyUnmatched[1].PreviousStatus)
+	assert.Equal(t, 1, diff.OutOfRange)
+}
+
+func TestReconciliationService_DiffJobsWithoutOverlap(t *testing.T) {
+	repo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(repo)
+	jan20 := lifecycleDay.AddDate(0, 0, 5)
+
+	seedDiffJob(repo, "first", lifecycleDay, lifecycleDay, diffResult("TX001", "", domain.UnmatchedSystem, lifecycleDay))
+	seedDiffJob(repo, "second", jan20, jan20, diffResult("TX001", "", domain.UnmatchedSystem, jan20))
+
+	diff, err := svc.DiffJobs(context.Background(), "first", "second")
+	assert.NoError(t, err)
+	assert.Nil(t, diff.OverlapStart)
+	assert.Empty(t, diff.StillUnmatched)
+	assert.Empty(t, diff.NewlyUnmatched)
+	assert.Equal(t, 2, diff.OutOfRange)
+}
+
+func TestReconciliationHandler_DiffJobs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := newMockReconciliationRepository()
+	seedDiffJob(repo, "first", lifecycleDay, lifecycleDay)
+	seedDiffJob(repo, "second", lifecycleDay, lifecycleDay)
+	repo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs/:job_id/diff/:other_id", handler.NewReconciliationHandler(newJobLifecycleService(repo)).DiffJobs)
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, get("/api/v1/reconcile/jobs/first/diff/second"))
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/reconcile/jobs/first/diff/missing"))
+	assert.Equal(t, http.StatusConflict, get("/api/v1/reconcile/jobs/running/diff/second"))
+
+	_, err := newJobLifecycleService(repo).DiffJobs(context.Background(), "first", "running")
+	assert.ErrorIs(t, err, service.ErrJobNotCompleted)
+}
@@ -0,0 +1,500 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func newJobLifecycleService(reconRepo *mockReconciliationRepository, opts ...service.ServiceOption) service.ReconciliationService {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	bankRepo := &mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "bank_a"},
+	}}
+	return service.NewReconciliationService(txRepo, reconRepo, 100, append([]service.ServiceOption{service.WithBankStatementRepository(bankRepo)}, opts...)...)
+}
+
+var lifecycleDay = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+func TestReconciliationService_DeleteJob(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reconRepo.results)
+
+	assert.NoError(t, svc.DeleteJob(context.Background(), summary.JobID))
+	_, err = svc.GetJobStatus(context.Background(), summary.JobID)
+	assert.Error(t, err, "deleted jobs are hidden")
+	assert.Empty(t, reconRepo.results, "results are removed with the job")
+}
+
+func TestReconciliationService_DeleteJobProcessing(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+	svc := newJobLifecycleService(reconRepo)
+
+	assert.ErrorIs(t, svc.DeleteJob(context.Background(), "running"), service.ErrJobProcessing)
+	assert.Contains(t, reconRepo.jobs, "running")
+}
+
+func TestReconciliationService_RerunJob(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+
+	first, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+
+	rerun, err := svc.RerunJob(context.Background(), first.JobID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.JobID, rerun.JobID, "a rerun creates a new job")
+	assert.Equal(t, first.TotalMatched, rerun.TotalMatched)
+	assert.Equal(t, first.TotalUnmatched, rerun.TotalUnmatched)
+
+	job, err := svc.GetJobStatus(context.Background(), rerun.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, lifecycleDay, job.StartDate)
+	assert.Equal(t, lifecycleDay, job.EndDate)
+}
+
+func TestReconciliationService_PersistMatchedFalse(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo).ForPersistMatched(false)
+	ctx := context.Background()
+
+	summary, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.Equal(t, 1, summary.TotalUnmatched)
+
+	results, err := reconRepo.GetResultsByJobID(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results), "only the exception is stored")
+	assert.Equal(t, domain.UnmatchedSystem, results[0].MatchStatus)
+
+	job, err := svc.GetJobStatus(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.True(t, job.ExceptionsOnly)
+	assert.Equal(t, 1, job.TotalMatched)
+
+	recompute, err := svc.RecomputeJobTotals(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.False(t, recompute.Changed, "matches missing from the results are not drift")
+
+	rerun, err := newJobLifecycleService(reconRepo).RerunJob(ctx, summary.JobID)
+	assert.NoError(t, err)
+	results, err = reconRepo.GetResultsByJobID(ctx, rerun.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results), "a re-run keeps storing exceptions only")
+}
+
+func TestReconciliationService_FailureThresholds(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	ctx := context.Background()
+	ratio := func(r float64) *float64 { return &r }
+
+	// One of the three rows processed is unmatched
+	svc, err := newJobLifecycleService(reconRepo).ForFailureThresholds(ratio(0), ratio(0.2))
+	assert.NoError(t, err)
+	summary, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Contains(t, summary.FailureReason, "1 of 3 rows are unmatched")
+	job, err := svc.GetJobStatus(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Failed, job.Status)
+	if assert.NotNil(t, job.ErrorMessage) {
+		assert.Equal(t, summary.FailureReason, *job.ErrorMessage)
+	}
+	results, err := reconRepo.GetResultsByJobID(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results), "results are kept for investigation")
+
+	svc, err = newJobLifecycleService(reconRepo).ForFailureThresholds(nil, ratio(0.5))
+	assert.NoError(t, err)
+	summary, err = svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Empty(t, summary.FailureReason)
+	job, err = svc.GetJobStatus(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Completed, job.Status)
+
+	_, err = newJobLifecycleService(reconRepo).ForFailureThresholds(ratio(1.5), nil)
+	assert.Error(t, err)
+}
+
+func TestReconciliationHandler_FailureThresholds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(newJobLifecycleService(newMockReconciliationRepository())).Reconcile)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"bank_source":"database","start_date":"2024-01-15","end_date":"2024-01-15","max_unmatched_ratio":0.1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "failed its thresholds")
+	assert.Contains(t, rec.Body.String(), `"failure_reason"`)
+
+	rec = post(`{"bank_source":"database","start_date":"2024-01-15","end_date":"2024-01-15","max_discrepancy_ratio":-1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestReconciliationService_SummaryCache(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	ctx := context.Background()
+	first, err := newJobLifecycleService(reconRepo).ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	second, err := newJobLifecycleService(reconRepo).ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	// drift changes a job's stored totals behind the service
+	drift := func(jobID string) {
+		job := reconRepo.jobs[jobID]
+		job.TotalUnmatched = 5
+		reconRepo.jobs[jobID] = job
+	}
+
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100, service.WithSummaryCache(1, time.Minute))
+	summary, err := svc.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	summary.Replayed = true
+	drift(first.JobID)
+	summary, err = svc.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalUnmatched, "a repeated read is served from the cache")
+	assert.False(t, summary.Replayed, "callers get their own copy")
+
+	recompute, err := svc.RecomputeJobTotals(ctx, first.JobID)
+	assert.NoError(t, err)
+	assert.True(t, recompute.Changed)
+	drift(first.JobID)
+	summary, err = svc.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, summary.TotalUnmatched, "a recompute drops the cached summary")
+
+	_, err = svc.GetJobSummary(ctx, second.JobID, nil, nil)
+	assert.NoError(t, err)
+	job := reconRepo.jobs[first.JobID]
+	job.TotalUnmatched = 7
+	reconRepo.jobs[first.JobID] = job
+	summary, err = svc.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, summary.TotalUnmatched, "reading a second job evicted the first")
+
+	_, err = svc.GetJobSummary(ctx, second.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.DeleteJob(ctx, second.JobID))
+	_, err = svc.GetJobSummary(ctx, second.JobID, nil, nil)
+	assert.Error(t, err, "a deleted job is not served from the cache")
+
+	expiring := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100, service.WithSummaryCache(10, 10*time.Millisecond))
+	_, err = expiring.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	reconRepo.jobs[first.JobID] = domain.ReconciliationJob{JobID: first.JobID, Status: domain.Completed}
+	time.Sleep(20 * time.Millisecond)
+	summary, err = expiring.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, summary.TotalUnmatched, "entries expire after the TTL")
+
+	flaky := &failingResultsRepository{mockReconciliationRepository: reconRepo, failures: 1}
+	svc = service.NewReconciliationService(&mockTransactionRepository{}, flaky, 100, service.WithSummaryCache(10, time.Minute))
+	_, err = svc.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.Error(t, err, "a failed results query is returned")
+	job = reconRepo.jobs[first.JobID]
+	job.TotalUnmatched = 9
+	reconRepo.jobs[first.JobID] = job
+	summary, err = svc.GetJobSummary(ctx, first.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, summary.TotalUnmatched, "nothing was cached by the failed read")
+}
+
+// failingResultsRepository fails the next few results queries
+type failingResultsRepository struct {
+	*mockReconciliationRepository
+	failures int
+}
+
+func (r *failingResultsRepository) QueryResults(ctx context.Context, jobID string, filter domain.ResultFilter, limit, offset int) ([]domain.ReconciliationResult, int, error) {
+	if r.failures > 0 {
+		r.failures--
+		return nil, 0, fmt.Errorf("connection reset")
+	}
+	return r.mockReconciliationRepository.QueryResults(ctx, jobID, filter, limit, offset)
+}
+
+func TestReconciliationHandler_DeleteJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	reconRepo.jobs["done"] = domain.ReconciliationJob{JobID: "done", Status: domain.Completed}
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+
+	router := gin.New()
+	router.DELETE("/api/v1/reconcile/jobs/:job_id", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).DeleteJob)
+
+	for jobID, want := range map[string]int{
+		"done":    http.StatusOK,
+		"running": http.StatusConflict,
+		"missing": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/reconcile/jobs/"+jobID, nil))
+		assert.Equal(t, want, rec.Code, jobID)
+	}
+}
+
+func TestReconciliationHandler_ListJobs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	created := func(day int) time.Time { return time.Date(2024, 1, day, 9, 0, 0, 0, time.UTC) }
+	for i, job := range []domain.ReconciliationJob{
+		{JobID: "jan-10", Status: domain.Completed, CreatedAt: created(10)},
+		{JobID: "jan-11", Status: domain.Failed, CreatedAt: created(11)},
+		{JobID: "jan-12", Status: domain.Completed, CreatedAt: created(12)},
+		{JobID: "jan-13", Status: domain.Completed, CreatedAt: created(13)},
+	} {
+		job.ID = i + 1
+		reconRepo.jobs[job.JobID] = job
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).ListJobs)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/jobs?"+query, nil))
+		return rec
+	}
+	list := func(query string) domain.JobPage {
+		rec := get(query)
+		assert.Equal(t, http.StatusOK, rec.Code, query)
+		var resp struct {
+			Data domain.JobPage `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Data
+	}
+	jobIDs := func(page domain.JobPage) []string {
+		var ids []string
+		for _, job := range page.Jobs {
+			ids = append(ids, job.JobID)
+		}
+		return ids
+	}
+
+	page := list("")
+	assert.Equal(t, []string{"jan-13", "jan-12", "jan-11", "jan-10"}, jobIDs(page), "newest first")
+	assert.Equal(t, 4, page.Total)
+
+	page = list("status=COMPLETED&from=2024-01-11&to=2024-01-12")
+	assert.Equal(t, []string{"jan-12"}, jobIDs(page), "to includes its whole day")
+
+	page = list("status=COMPLETED&page=2&size=2")
+	assert.Equal(t, []string{"jan-10"}, jobIDs(page))
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 2, page.TotalPages)
+
+	for _, query := range []string{"status=DONE", "from=11-01-2024", "to=yesterday", "page=0", "size=5000"} {
+		assert.Equal(t, http.StatusBadRequest, get(query).Code, query)
+	}
+}
+
+func TestReconciliationHandler_GetJobResultsFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	reconRepo.jobs["job-1"] = domain.ReconciliationJob{JobID: "job-1", Status: domain.Completed}
+	bankB := "bank_b.csv"
+	for i, amount := range []float64{500, 15000} {
+		amount := decimal.NewFromFloat(amount)
+		reconRepo.results = append(reconRepo.results, domain.ReconciliationResult{
+			ID: i + 1, JobID: "job-1", MatchStatus: domain.Discrepancy, SystemAmount: &amount, BankSource: &bankB,
+		})
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs/:job_id/results", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).GetJobResults)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/jobs/job-1/results?"+query, nil))
+		return rec
+	}
+
+	rec := get("status=DISCREPANCY&bank_source=bank_b.csv&min_amount=10000")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Data domain.ResultPage `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.Total)
+	assert.Equal(t, 2, resp.Data.Results[0].ID)
+
+	rec = get("bank_source=bank_a.csv")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"results":[]`)
+
+	for _, query := range []string{"min_amount=abc", "max_amount=-5", "min_amount=100&max_amount=10"} {
+		assert.Equal(t, http.StatusBadRequest, get(query).Code, query)
+	}
+}
+
+func TestReconciliationHandler_GetJobResultsKeyset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	reconRepo.jobs["job-1"] = domain.ReconciliationJob{JobID: "job-1", Status: domain.Completed}
+	// Batches share created_at, and inserts need not follow it, so ID
+	// breaks ties and insertion order is not the page order
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i, offset := range []int{2, 0, 0, 1, 2, 0, 1, 1, 2, 0} {
+		status := domain.Matched
+		if i%3 == 0 {
+			status = domain.UnmatchedSystem
+		}
+		reconRepo.results = append(reconRepo.results, domain.ReconciliationResult{
+			ID: i + 1, JobID: "job-1", MatchStatus: status, CreatedAt: base.Add(time.Duration(offset) * time.Second),
+		})
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs/:job_id/results", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).GetJobResults)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/jobs/job-1/results?"+query, nil))
+		return rec
+	}
+	pageThrough := func(query string) ([]int, int) {
+		var ids []int
+		pages := 0
+		cursor := ""
+		for {
+			rec := get(query + "&cursor=" + cursor)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var resp struct {
+				Data domain.ResultCursorPage `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			pages++
+			for _, result := range resp.Data.Results {
+				ids = append(ids, result.ID)
+			}
+			if resp.Data.NextCursor == "" || pages > 10 {
+				return ids, pages
+			}
+			cursor = resp.Data.NextCursor
+		}
+	}
+
+	ids, pages := pageThrough("size=3")
+	assert.Equal(t, []int{2, 3, 6, 10, 4, 7, 8, 1, 5, 9}, ids, "ordered by created_at, then id, without gaps or repeats")
+	assert.Equal(t, 4, pages)
+
+	ids, pages = pageThrough("size=2&status=UNMATCHED_SYSTEM")
+	assert.Equal(t, []int{10, 4, 7, 1}, ids)
+	assert.Equal(t, 2, pages, "an exactly full last page has no next cursor")
+
+	cursor := domain.ResultCursor{CreatedAt: base.Add(time.Second), ID: 4}
+	parsed, err := domain.ParseResultCursor(cursor.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	for _, query := range []string{"cursor=not-a-cursor", "cursor=&page=2"} {
+		assert.Equal(t, http.StatusBadRequest, get(query).Code, query)
+	}
+}
+
+func TestReconciliationHandler_GetJobStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	reconRepo.jobs["job-1"] = domain.ReconciliationJob{
+		JobID: "job-1", Status: domain.Completed, TotalMatched: 2, TotalUnmatched: 2,
+		TotalDiscrepancies: decimal.NewFromFloat(50.00), NetDiscrepancy: decimal.NewFromFloat(-50.00),
+	}
+	bankA, bankB := "bank_a.csv", "bank_b.csv"
+	for _, result := range []domain.ReconciliationResult{
+		{MatchStatus: domain.Matched, BankSource: &bankA},
+		{MatchStatus: domain.Matched, BankSource: &bankB},
+		{MatchStatus: domain.Discrepancy, BankSource: &bankA},
+		{MatchStatus: domain.UnmatchedBank, BankSource: &bankB},
+		{MatchStatus: domain.UnmatchedSystem},
+	} {
+		result.JobID = "job-1"
+		reconRepo.results = append(reconRepo.results, result)
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs/:job_id/stats", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).GetJobStats)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/jobs/job-1/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "unmatched_system", "no result rows")
+
+	var resp struct {
+		Data domain.JobStats `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	stats := resp.Data
+	assert.Equal(t, domain.Completed, stats.Status)
+	assert.Equal(t, 2, stats.TotalMatched)
+	assert.True(t, stats.NetDiscrepancy.Equal(decimal.NewFromFloat(-50.00)))
+	assert.Equal(t, 5, stats.TotalResults)
+	assert.Equal(t, map[domain.MatchStatus]int{
+		domain.Matched: 2, domain.Discrepancy: 1, domain.UnmatchedBank: 1, domain.UnmatchedSystem: 1,
+	}, stats.StatusCounts)
+	assert.Equal(t, map[string]map[domain.MatchStatus]int{
+		"bank_a.csv": {domain.Matched: 1, domain.Discrepancy: 1},
+		"bank_b.csv": {domain.Matched: 1, domain.UnmatchedBank: 1},
+	}, stats.BySource)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/jobs/missing/stats", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestReconciliationHandler_RejectsInvalidBatchSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(newJobLifecycleService(newMockReconciliationRepository())).Reconcile)
+
+	for _, size := range []int{-5, service.MaxBatchSize + 1} {
+		body := fmt.Sprintf(`{"bank_source":"database","start_date":"2024-01-15","end_date":"2024-01-15","batch_size":%d}`, size)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, size)
+	}
+}
+
+func TestReconciliationHandler_ReportsLedgerBalance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(newJobLifecycleService(newMockReconciliationRepository())).Reconcile)
+
+	rec := httptest.NewRecorder()
+	body := `{"bank_source":"database","start_date":"2024-01-15","end_date":"2024-01-15","opening_balance":"1000"}`
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "closing_balance is missing")
+
+	rec = httptest.NewRecorder()
+	body = `{"bank_source":"database","start_date":"2024-01-15","end_date":"2024-01-15","opening_balance":"1000","closing_balance":"1100.00"}`
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"balance_check":{"opening":"1000","closing":"1100","expected":"1100","actual":"1100","difference":"0","balanced":true}`)
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/export"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func TestJSONResultWriter_Write(t *testing.T) {
+	trxID := "TX001"
+	var buf bytes.Buffer
+	writer := export.NewJSONResultWriter(&buf)
+	assert.NoError(t, writer.WriteHeader())
+	assert.NoError(t, writer.Write([]domain.ReconciliationResult{{TrxID: &trxID, MatchStatus: domain.UnmatchedSystem}}))
+	assert.NoError(t, writer.Write(nil))
+	assert.NoError(t, writer.Write([]domain.ReconciliationResult{{MatchStatus: domain.UnmatchedBank}}))
+	assert.NoError(t, writer.Close())
+
+	var results []domain.ReconciliationResult
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "TX001", *results[0].TrxID)
+	assert.Equal(t, domain.UnmatchedBank, results[1].MatchStatus)
+
+	buf.Reset()
+	empty := export.NewJSONResultWriter(&buf)
+	assert.NoError(t, empty.WriteHeader())
+	assert.NoError(t, empty.Close())
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestReconciliationService_PageJobResults(t *testing.T) {
+	reconRepo := newManualMatchRepository(t)
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 2)
+
+	var sizes []int
+	var ids []int
+	err := svc.PageJobResults(context.Background(), "job-review", func(batch []domain.ReconciliationResult) error {
+		sizes = append(sizes, len(batch))
+		for _, result := range batch {
+			ids = append(ids, result.ID)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ids, "each result once, in order")
+
+	err = svc.PageJobResults(context.Background(), "missing", func([]domain.ReconciliationResult) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestReconciliationHandler_ExportJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newManualMatchRepository(t)
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs/:job_id/export", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).ExportJobResults)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconcile/jobs/job-review/export?"+query, nil))
+		return rec
+	}
+
+	rec := get("format=json")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	var results []domain.ReconciliationResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	assert.Equal(t, 5, len(results))
+
+	rec = get("format=ndjson&gzip=true")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	body, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	scanner := bufio.NewScanner(body)
+	var lines [][]byte
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, 6, len(lines), "a job line, then a line per result")
+	var header export.NDJSONHeader
+	assert.NoError(t, json.Unmarshal(lines[0], &header))
+	assert.Equal(t, "job-review", header.Job.JobID)
+	var first domain.ReconciliationResult
+	assert.NoError(t, json.Unmarshal(lines[1], &first))
+	assert.Equal(t, "TX001", *first.TrxID)
+
+	assert.Equal(t, http.StatusBadRequest, get("format=json&gzip=maybe").Code)
+	assert.Equal(t, http.StatusBadRequest, get("format=xml").Code)
+
+	// The friendly style only reformats the result CSV
+	assert.Equal(t, http.StatusOK, get("format=csv&style=friendly&locale=de-DE").Code)
+	for _, format := range []string{"journal", "json", "ndjson"} {
+		assert.Equal(t, http.StatusBadRequest, get("format="+format+"&style=friendly").Code, format)
+		assert.Equal(t, http.StatusOK, get("format="+format+"&style=raw").Code, format)
+	}
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/service"
+)
+
+func TestJSONLBankStatementParser_Parse(t *testing.T) {
+	jsonlFile := writeFile(t, t.TempDir(), "bank_test.jsonl", `{"trx_ref_id": "TX001", "amount": 100.50, "date": "2024-01-15"}
+
+{"trx_ref_id": "TX002", "amount": "-200.75", "date": "2024-01-16", "memo": null}
+{"trx_ref_id": "TX003", "amount": 300, "date": "2024-01-17"}
+`)
+
+	var batches [][]domain.BankStatement
+	err := parser.NewJSONLBankStatementParser("TestBank").Parse(jsonlFile, 2, func(batch []domain.BankStatement) error {
+		batches = append(batches, batch)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	if !assert.Equal(t, 2, len(batches)) {
+		return
+	}
+	assert.Equal(t, 2, len(batches[0]))
+	assert.Equal(t, 1, len(batches[1]))
+	assert.Equal(t, "TX001", batches[0][0].TrxRefID)
+	assert.True(t, batches[0][0].Amount.Equal(decimal.NewFromFloat(100.50)))
+	assert.Equal(t, "TestBank", batches[0][0].Source)
+	assert.True(t, batches[0][1].Amount.Equal(decimal.NewFromFloat(-200.75)))
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), batches[0][1].Date)
+	assert.Equal(t, "TX003", batches[1][0].TrxRefID)
+}
+
+func TestJSONLBankStatementParser_SkipsMalformedLines(t *testing.T) {
+	jsonlFile := writeFile(t, t.TempDir(), "bank_test.ndjson", `{"trx_ref_id": "TX001", "amount": 100, "date": "2024-01-15"}
+not json
+{"trx_ref_id": "TX002", "amount": 200
+["TX003", 300, "2024-01-15"]
+{"trx_ref_id": "TX004", "date": "2024-01-15"}
+{"trx_ref_id": "TX005", "amount": {"value": 500}, "date": "2024-01-15"}
+{"trx_ref_id": "TX006", "amount": "abc", "date": "2024-01-15"}
+{"trx_ref_id": "TX007", "amount": 700, "date": "2024-01-15"} {"extra": true}
+{"trx_ref_id": "TX008", "amount": 800, "date": "2024-01-15"}
+`)
+
+	var statements []domain.BankStatement
+	err := parser.NewJSONLBankStatementParser("TestBank").Parse(jsonlFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, "TX001", statements[0].TrxRefID)
+		assert.Equal(t, "TX008", statements[1].TrxRefID)
+	}
+}
+
+func TestJSONLBankStatementParser_ColumnMapping(t *testing.T) {
+	jsonlFile := writeFile(t, t.TempDir(), "bank_aliases.jsonl",
+		`{"Ref_No": "TX001", "value": "1500.25", "posting_date": "2024-01-15"}`+"\n")
+
+	mapping := parser.ColumnMapping{"ref_no": "trx_ref_id", "value": "amount", "posting_date": "date"}
+	var statements []domain.BankStatement
+	err := parser.NewJSONLBankStatementParserWithMapping("TestBank", mapping).Parse(jsonlFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(statements)) {
+		assert.Equal(t, "TX001", statements[0].TrxRefID)
+		assert.True(t, statements[0].Amount.Equal(decimal.NewFromFloat(1500.25)))
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), statements[0].Date)
+	}
+}
+
+func TestReconciliationService_ReconcilesJSONLBankFile(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.50), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(50.00), Type: domain.Debit, TransactionTime: day},
+	}}
+
+	jsonlFile := writeFile(t, t.TempDir(), "bank_a.jsonl", `{"trx_ref_id": "TX001", "amount": 100.5, "date": "2024-01-15"}
+{"trx_ref_id": "TX002", "amount": -50, "date": "2024-01-15"}
+`)
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+
+	summary, err := svc.Reconcile(context.Background(), nil, []string{jsonlFile}, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalMatched)
+	assert.Equal(t, 0, summary.TotalUnmatched)
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/pkg/logger"
+)
+
+func TestProgress_LogsEveryInterval(t *testing.T) {
+	hook := test.NewLocal(logger.GetLogger())
+	defer hook.Reset()
+
+	progress := logger.NewProgress("bulk_create_results", 1000)
+	for i := 0; i < 2500; i++ {
+		progress.Add(1)
+	}
+	progress.Done()
+
+	var progressEntries int
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["operation"] != "bulk_create_results" {
+			continue
+		}
+		assert.Contains(t, entry.Data, "rows_per_sec")
+		if entry.Message == "Progress" {
+			progressEntries++
+		}
+	}
+	assert.Equal(t, 2, progressEntries, "Should log at 1000 and 2000 rows")
+	assert.Equal(t, "Completed", hook.LastEntry().Message)
+	assert.Equal(t, 2500, hook.LastEntry().Data["rows"])
+}
+
+func TestProgress_DisabledIsNoop(t *testing.T) {
+	hook := test.NewLocal(logger.GetLogger())
+	defer hook.Reset()
+
+	progress := logger.NewProgress("bulk_create_results", 0)
+	assert.Nil(t, progress)
+	progress.Add(10)
+	progress.Done()
+
+	assert.Empty(t, hook.AllEntries())
+}
+
+func TestInit_Formats(t *testing.T) {
+	defer logger.Init("info")
+
+	logger.Init("debug")
+	jsonFormatter, ok := logger.GetLogger().Formatter.(*logrus.JSONFormatter)
+	assert.True(t, ok, "JSON should be the default format")
+	assert.False(t, jsonFormatter.DisableTimestamp)
+	assert.Equal(t, logrus.DebugLevel, logger.GetLogger().GetLevel())
+
+	logger.Init("info", logger.WithFormat(logger.FormatText), logger.WithTimestampFormat("2006-01-02 15:04:05"))
+	textFormatter, ok := logger.GetLogger().Formatter.(*logrus.TextFormatter)
+	assert.True(t, ok)
+	assert.False(t, textFormatter.DisableTimestamp)
+	assert.Equal(t, "2006-01-02 15:04:05", textFormatter.TimestampFormat)
+
+	logger.Init("info", logger.WithTimestamps(false))
+	jsonFormatter, ok = logger.GetLogger().Formatter.(*logrus.JSONFormatter)
+	assert.True(t, ok)
+	assert.True(t, jsonFormatter.DisableTimestamp)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+// newManualMatchRepository holds a completed job with one matched pair, an
+// unmatched system result and unmatched bank results, two of them sharing
+// a reference across sources
+func newManualMatchRepository(t *testing.T) *mockReconciliationRepository {
+	reconRepo := newMockReconciliationRepository()
+	ctx := context.Background()
+	assert.NoError(t, reconRepo.CreateJob(ctx, &domain.ReconciliationJob{
+		JobID: "job-review", Status: domain.Completed, TotalProcessed: 6, TotalMatched: 1, TotalUnmatched: 4,
+	}))
+	str := func(s string) *string { return &s }
+	amount := func(f float64) *decimal.Decimal { d := decimal.NewFromFloat(f); return &d }
+	for _, result := range []domain.ReconciliationResult{
+		{TrxID: str("TX001"), TrxRefID: str("TX001"), SystemAmount: amount(100), BankAmount: amount(100), MatchStatus: domain.Matched, BankSource: str("bank_a")},
+		{TrxID: str("TX002"), SystemAmount: amount(200), MatchStatus: domain.UnmatchedSystem, Currency: str("IDR"), TransactionDate: &lifecycleDay},
+		{TrxRefID: str("TX-0002"), BankAmount: amount(200), MatchStatus: domain.UnmatchedBank, BankSource: str("bank_a")},
+		{TrxRefID: str("REF9"), BankAmount: amount(50), MatchStatus: domain.UnmatchedBank, BankSource: str("bank_a")},
+		{TrxRefID: str("REF9"), BankAmount: amount(50), MatchStatus: domain.UnmatchedBank, BankSource: str("bank_b")},
+	} {
+		result.JobID = "job-review"
+		assert.NoError(t, reconRepo.CreateResult(ctx, &result))
+	}
+	return reconRepo
+}
+
+func TestReconciliationService_ManualMatch(t *testing.T) {
+	reconRepo := newManualMatchRepository(t)
+	svc := newJobLifecycleService(reconRepo)
+	ctx := context.Background()
+
+	matched, err := svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX002", TrxRefID: "TX-0002", ReviewedBy: "alice"})
+	assert.NoError(t, err)
+	result := matched.Result
+	assert.Equal(t, domain.Matched, result.MatchStatus)
+	assert.True(t, result.ManualMatch)
+	assert.Equal(t, "alice", *result.ReviewedBy)
+	assert.Equal(t, service.MatchedViaManual, *result.MatchedVia)
+	assert.Equal(t, "TX002", *result.TrxID)
+	assert.Equal(t, "TX-0002", *result.TrxRefID)
+	assert.Equal(t, "bank_a", *result.BankSource)
+	assert.Equal(t, "IDR", *result.Currency)
+	assert.Equal(t, lifecycleDay, *result.TransactionDate)
+
+	assert.Equal(t, 2, matched.Job.TotalMatched)
+	assert.Equal(t, 2, matched.Job.TotalUnmatched)
+	assert.Equal(t, 6, matched.Job.TotalProcessed)
+
+	unmatched, _, err := reconRepo.QueryResults(ctx, "job-review", domain.ResultFilter{Status: domain.UnmatchedSystem}, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, unmatched, "the unmatched system result is replaced")
+
+	recompute, err := svc.RecomputeJobTotals(ctx, "job-review")
+	assert.NoError(t, err)
+	assert.False(t, recompute.Changed, "the totals follow the replaced results")
+
+	// Neither side can be matched a second time
+	_, err = svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX002", TrxRefID: "REF9", BankSource: "bank_a", ReviewedBy: "bob"})
+	assert.ErrorIs(t, err, service.ErrNotUnmatched)
+	_, err = svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX001", TrxRefID: "TX-0002", ReviewedBy: "bob"})
+	assert.ErrorIs(t, err, service.ErrNotUnmatched)
+}
+
+func TestReconciliationService_ManualMatchRefusals(t *testing.T) {
+	reconRepo := newManualMatchRepository(t)
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+	svc := newJobLifecycleService(reconRepo)
+	ctx := context.Background()
+
+	_, err := svc.ManualMatch(ctx, "running", domain.ManualMatch{TrxID: "TX002", TrxRefID: "TX-0002", ReviewedBy: "alice"})
+	assert.ErrorIs(t, err, service.ErrJobNotCompleted)
+
+	_, err = svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX002", TrxRefID: "REF9", ReviewedBy: "alice"})
+	assert.ErrorIs(t, err, service.ErrAmbiguousManualMatch)
+
+	matched, err := svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX002", TrxRefID: "REF9", BankSource: "bank_b", ReviewedBy: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "bank_b", *matched.Result.BankSource)
+
+	chain := "head"
+	job := reconRepo.jobs["job-review"]
+	job.ResultChainHead = &chain
+	reconRepo.jobs["job-review"] = job
+	_, err = svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX002", TrxRefID: "TX-0002", ReviewedBy: "alice"})
+	assert.ErrorIs(t, err, service.ErrJobChained)
+}
+
+func TestReconciliationHandler_ManualMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newManualMatchRepository(t)
+	router := gin.New()
+	router.POST("/api/v1/reconcile/jobs/:job_id/manual-match", handler.NewReconciliationHandler(newJobLifecycleService(reconRepo)).ManualMatch)
+	post := func(jobID, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/jobs/"+jobID+"/manual-match", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	body := `{"trx_id": "TX002", "trx_ref_id": "TX-0002", "reviewed_by": "alice"}`
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post("job-review", `{"trx_id": "TX002", "trx_ref_id": "TX-0002"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post("job-review", `{"trx_id": "TX002", "trx_ref_id": "REF9", "reviewed_by": "alice"}`).Code)
+	assert.Equal(t, http.StatusNotFound, post("missing", body).Code)
+
+	rec := post("job-review", body)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp struct {
+		Data domain.ManualMatchResult `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Result.ManualMatch)
+	assert.Equal(t, 2, resp.Data.Job.TotalMatched)
+
+	assert.Equal(t, http.StatusConflict, post("job-review", body).Code, "a result is matched once")
+}
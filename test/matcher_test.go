@@ -1,6 +1,8 @@
 package test
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
@@ -126,3 +128,1504 @@ func TestValidateReconciliationInput(t *testing.T) {
 	err = matcher.ValidateReconciliationInput(invalidInput)
 	assert.Error(t, err)
 }
+
+func TestReconciliationEngine_AmountBounds(t *testing.T) {
+	min := decimal.NewFromFloat(10.00)
+	max := decimal.NewFromFloat(1000.00)
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithAmountBounds(&min, &max))
+
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(5.00), Type: domain.Credit, TransactionTime: now},   // Below min
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(5000.00), Type: domain.Debit, TransactionTime: now}, // Above max
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(1000.00), Type: domain.Debit, TransactionTime: now}, // On the bound
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(5.00), Date: now, Source: "BankA"},     // Below min
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(-5000.00), Date: now, Source: "BankA"}, // Above max
+		{TrxRefID: "TX004", Amount: decimal.NewFromFloat(-1000.00), Date: now, Source: "BankB"},
+		{TrxRefID: "TX005", Amount: decimal.NewFromFloat(-0.50), Date: now, Source: "BankB"}, // Below min
+	}
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(output.Matched), "TX001 and TX004 are within bounds")
+	assert.Equal(t, 0, len(output.UnmatchedSystem), "Excluded items should not be reported as unmatched")
+	assert.Equal(t, 0, len(output.UnmatchedBank), "Excluded items should not be reported as unmatched")
+	assert.Equal(t, 2, output.ExcludedSystem)
+	assert.Equal(t, 3, output.ExcludedBank)
+}
+
+func TestReconciliationEngine_DateWindow(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDateWindow(3*24*time.Hour))
+
+	sysTime := time.Date(2024, 1, 15, 18, 30, 0, 0, time.UTC)
+	bankDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: sysTime},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: sysTime},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: sysTime},
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: bankDay, Source: "BankA"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(200.00), Date: bankDay.AddDate(0, 0, 3), Source: "BankA"}, // Edge of window
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(300.00), Date: bankDay.AddDate(0, 0, 5), Source: "BankA"}, // Outside window
+	}
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          bankDay.AddDate(0, 0, -1),
+		EndDate:            bankDay.AddDate(0, 0, 7),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(output.Matched))
+	assert.Equal(t, 1, len(output.DateMismatches))
+	assert.Equal(t, "TX003", output.DateMismatches[0].SystemTx.TrxID)
+	assert.Equal(t, 0, len(output.UnmatchedBank), "Date mismatches should not be reported as unmatched")
+
+	results := engine.BuildResults("job-date-window", output)
+	dateMismatchCount := 0
+	for _, r := range results {
+		if r.MatchStatus == domain.DateMismatch {
+			dateMismatchCount++
+		}
+	}
+	assert.Equal(t, 1, dateMismatchCount)
+}
+
+func TestNormalizedMatchStrategy_MatchesMangledReferences(t *testing.T) {
+	transforms, err := matcher.StripPatterns(`^REF-`, `-\d{2}$`)
+	assert.NoError(t, err)
+
+	strategy := matcher.NewNormalizedMatchStrategy(transforms...)
+	engine := matcher.NewReconciliationEngine(strategy)
+
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "tx-002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: now},
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "REF-TX001-00", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX 002", Amount: decimal.NewFromFloat(200.00), Date: now, Source: "BankA"},
+		{TrxRefID: "REF-TX999-01", Amount: decimal.NewFromFloat(300.00), Date: now, Source: "BankA"},
+	}
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(output.Matched))
+	assert.Equal(t, "TX001", output.Matched[0].SystemTx.TrxID, "Raw system ID should be preserved")
+	assert.Equal(t, "REF-TX001-00", output.Matched[0].BankStmt.TrxRefID, "Raw bank reference should be preserved")
+	assert.Equal(t, 1, len(output.UnmatchedSystem))
+	assert.Equal(t, 1, len(output.UnmatchedBank))
+	assert.Equal(t, "REF-TX999-01", output.UnmatchedBank[0].TrxRefID)
+}
+
+func TestNormalizedMatchStrategy_NormalizeKey(t *testing.T) {
+	transforms, err := matcher.StripPatterns(`^REF-`, `-\d{2}$`)
+	assert.NoError(t, err)
+
+	strategy := matcher.NewNormalizedMatchStrategy(transforms...)
+
+	assert.Equal(t, "TX001", strategy.NormalizeKey("REF-TX001-00"))
+	assert.Equal(t, "TX001", strategy.NormalizeKey(" tx_001 "))
+
+	_, err = matcher.StripPatterns(`(`)
+	assert.Error(t, err)
+}
+
+func TestReconciliationEngine_DuplicatePolicyClosestAmount(t *testing.T) {
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(180.00), Date: now, Source: "BankA"}, // Worse match
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"}, // Exact match
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(95.00), Date: now, Source: "BankA"},
+	}
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	}
+
+	// Default policy keeps the first duplicate
+	output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(output.Matched))
+	assert.Equal(t, 1, len(output.Discrepancies))
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDuplicatePolicy(matcher.DuplicateClosestAmount))
+	output, err = engine.Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(output.Matched), "The later, exact duplicate should be chosen")
+	assert.True(t, output.Matched[0].BankStmt.Amount.Equal(decimal.NewFromFloat(100.00)))
+	assert.Equal(t, 0, len(output.Discrepancies))
+	assert.Equal(t, 0, len(output.UnmatchedBank))
+	assert.Equal(t, 2, len(output.DuplicateBank), "Unchosen duplicates are reported as duplicates")
+}
+
+func TestReconciliationEngine_DuplicatePolicies(t *testing.T) {
+	now := time.Now()
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(150.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(20.00), Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now},
+			{TrxRefID: "TX002", Amount: decimal.NewFromFloat(20.00), Date: now},
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(50.00), Date: now},
+		},
+	}
+	reconcile := func(policy matcher.DuplicatePolicy) *matcher.ReconciliationOutput {
+		output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDuplicatePolicy(policy)).Reconcile(input)
+		assert.NoError(t, err, policy)
+		return output
+	}
+
+	for policy, bankAmount := range map[matcher.DuplicatePolicy]float64{
+		matcher.DuplicateFirst:         100.00,
+		matcher.DuplicateLast:          50.00,
+		matcher.DuplicateLargestAmount: 100.00,
+	} {
+		output := reconcile(policy)
+		if assert.Len(t, output.Discrepancies, 1, policy) {
+			assert.True(t, output.Discrepancies[0].BankStmt.Amount.Equal(decimal.NewFromFloat(bankAmount)), policy)
+		}
+		assert.Len(t, output.DuplicateBank, 1, policy)
+		assert.Len(t, output.Matched, 1, policy)
+	}
+
+	output := reconcile(matcher.DuplicateSum)
+	assert.Len(t, output.Matched, 2, "the parts add up to the system amount")
+	assert.Empty(t, output.DuplicateBank)
+	assert.Empty(t, output.UnmatchedBank)
+
+	_, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDuplicatePolicy(matcher.DuplicateError)).Reconcile(input)
+	var duplicates *matcher.DuplicateReferenceError
+	if assert.ErrorAs(t, err, &duplicates) {
+		assert.Equal(t, []string{"TX001"}, duplicates.References)
+		assert.EqualError(t, err, "duplicate bank reference IDs: TX001")
+	}
+
+	input.BankStatements = input.BankStatements[:2]
+	output, err = matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDuplicatePolicy(matcher.DuplicateError)).Reconcile(input)
+	assert.NoError(t, err, "unique references pass")
+	assert.Len(t, output.Matched, 1)
+}
+
+func TestReconciliationEngine_DuplicateLastIsChosenPerMatch(t *testing.T) {
+	now := time.Now()
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(30.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(10.00), Type: domain.Credit, TransactionTime: now},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(10.00), Date: now},
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(30.00), Date: now},
+	}
+	output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDuplicatePolicy(matcher.DuplicateLast)).
+		Reconcile(matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts})
+	assert.NoError(t, err)
+	if assert.Len(t, output.Matched, 1) {
+		assert.True(t, output.Matched[0].BankStmt.Amount.Equal(decimal.NewFromFloat(30.00)), "the last statement is matched")
+	}
+	assert.Len(t, output.Duplicates, 1, "the repeated system transaction")
+	assert.Len(t, output.DuplicateBank, 1)
+}
+
+func TestReconciliationEngine_DuplicateSumMixedDirections(t *testing.T) {
+	now := time.Now()
+	output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithDuplicatePolicy(matcher.DuplicateSum)).
+		Reconcile(matcher.ReconciliationInput{
+			SystemTransactions: []domain.Transaction{
+				{TrxID: "TX001", Amount: decimal.NewFromFloat(40.00), Type: domain.Debit, TransactionTime: now},
+			},
+			BankStatements: []domain.BankStatement{
+				{TrxRefID: "TX001", Amount: decimal.NewFromFloat(-50.00), Type: domain.Debit, Date: now},
+				{TrxRefID: "TX001", Amount: decimal.NewFromFloat(10.00), Type: domain.Credit, Date: now},
+			},
+		})
+	assert.NoError(t, err)
+	if assert.Len(t, output.Matched, 1, "a refund nets against the original debit") {
+		assert.Equal(t, domain.Debit, output.Matched[0].BankStmt.Type)
+	}
+}
+
+func TestReconciliationEngine_ReconcileBySource(t *testing.T) {
+	now := time.Now()
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(10.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(20.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(20.00), Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(10.00), Date: now, Source: "bank_a"},
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(10.00), Date: now, Source: "bank_b"},
+			{TrxRefID: "TXB99", Amount: decimal.NewFromFloat(1.00), Date: now, Source: "bank_b"},
+		},
+	}
+
+	outputs, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).ReconcileBySource(input)
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 2)
+	assert.Len(t, outputs["bank_a"].Matched, 1)
+	assert.Len(t, outputs["bank_b"].Matched, 1, "the same reference matches in each source")
+	assert.Empty(t, outputs["bank_a"].DuplicateBank, "statements of other sources are not duplicates")
+
+	merged := matcher.MergeSourceOutputs(outputs)
+	assert.Len(t, merged.Matched, 2)
+	assert.Len(t, merged.UnmatchedSystem, 1)
+	assert.Equal(t, "TX002", merged.UnmatchedSystem[0].TrxID)
+	assert.Len(t, merged.Duplicates, 1, "system duplicates are reported once")
+	assert.Len(t, merged.UnmatchedBank, 1)
+}
+
+func TestReconciliationEngine_ReconcileBalances(t *testing.T) {
+	now := time.Now()
+	maxAmount := decimal.NewFromInt(1000)
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(40.00), Type: domain.Debit, TransactionTime: now},
+			{TrxID: "TX003", Amount: decimal.NewFromFloat(5000.00), Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			// Offsetting errors: every line is off, but the totals agree
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(90.00), Date: now, Source: "bank_a"},
+			{TrxRefID: "TX002", Amount: decimal.NewFromFloat(-30.00), Date: now, Source: "bank_a"},
+			{TrxRefID: "TX009", Amount: decimal.NewFromFloat(7.00), Date: now, Source: "bank_b"},
+		},
+	}
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithAmountBounds(nil, &maxAmount))
+	check := engine.ReconcileBalances(input)
+	assert.True(t, check.SystemNet.Equal(decimal.NewFromInt(60)), "out-of-bounds TX003 is left out")
+	assert.True(t, check.BankNet.Equal(decimal.NewFromInt(67)))
+	assert.True(t, check.Difference.Equal(decimal.NewFromInt(-7)))
+	assert.False(t, check.Balanced)
+
+	input.BankStatements = input.BankStatements[:2]
+	assert.True(t, engine.ReconcileBalances(input).Balanced, "balanced although no line matched")
+
+	engine = matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithBankAmountConventions(matcher.BankAmountConventions{
+		Default:  matcher.BankAmountDebitsNegative,
+		BySource: map[string]matcher.BankAmountConvention{"bank_b": matcher.BankAmountAlwaysPositive},
+	}))
+	input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: "TX009", Amount: decimal.NewFromFloat(7.00), Date: now, Source: "bank_b"})
+	check = engine.ReconcileBalances(input)
+	assert.Equal(t, 1, check.UnsignedBank)
+	assert.True(t, check.BankNet.Equal(decimal.NewFromInt(60)), "amounts without a direction are not netted")
+}
+
+func TestReconciliationEngine_CheckLedgerBalance(t *testing.T) {
+	now := time.Now()
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(40.00), Type: domain.Debit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(90.00), Date: now},
+			{TrxRefID: "TX002", Amount: decimal.NewFromFloat(-40.00), Date: now},
+			{TrxRefID: "TX009", Amount: decimal.NewFromFloat(7.00), Date: now},
+		},
+	}
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	// 500 + 90 - 40 + 7: the discrepancy and the unmatched line explain the gap
+	check := engine.CheckLedgerBalance(decimal.NewFromInt(500), decimal.NewFromInt(557), output)
+	assert.True(t, check.Expected.Equal(decimal.NewFromInt(560)))
+	assert.True(t, check.Actual.Equal(decimal.NewFromInt(560)))
+	assert.True(t, check.Difference.IsZero())
+	assert.True(t, check.Balanced)
+
+	check = engine.CheckLedgerBalance(decimal.NewFromInt(500), decimal.NewFromInt(550), output)
+	assert.True(t, check.Difference.Equal(decimal.NewFromInt(-7)), "no line explains the missing 7")
+	assert.False(t, check.Balanced)
+}
+
+func TestReconciliationEngine_DuplicateSystemTransactions(t *testing.T) {
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(50.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(120.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(10.00), Type: domain.Debit, TransactionTime: now},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(10.00), Type: domain.Debit, TransactionTime: now},
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(50.00), Date: now, Source: "BankA"},
+	}
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	}
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(output.Matched), "The first TX001 is matched, not the later one")
+	assert.Equal(t, 0, len(output.Discrepancies))
+	assert.Equal(t, 1, len(output.UnmatchedSystem))
+	assert.Equal(t, "TX003", output.UnmatchedSystem[0].TrxID)
+
+	assert.Equal(t, 2, len(output.Duplicates))
+	assert.Equal(t, "TX001", output.Duplicates[0].TrxID)
+	assert.True(t, output.Duplicates[0].Amount.Equal(decimal.NewFromFloat(120.00)))
+	assert.Equal(t, "TX003", output.Duplicates[1].TrxID)
+
+	results := engine.BuildResults("job-1", output)
+	duplicates := 0
+	for _, r := range results {
+		if r.MatchStatus == domain.DuplicateSystem {
+			duplicates++
+			assert.Nil(t, r.TrxRefID)
+		}
+	}
+	assert.Equal(t, 2, duplicates)
+}
+
+func TestReconciliationEngine_DuplicateBankStatements(t *testing.T) {
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankB"},
+		{TrxRefID: "TX009", Amount: decimal.NewFromFloat(30.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX009", Amount: decimal.NewFromFloat(31.00), Date: now, Source: "BankA"},
+	}
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	}
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(output.Matched))
+	assert.Equal(t, 1, len(output.UnmatchedBank), "The first unmatched TX009 stays unmatched")
+	assert.True(t, output.UnmatchedBank[0].Amount.Equal(decimal.NewFromFloat(30.00)))
+
+	assert.Equal(t, 2, len(output.DuplicateBank))
+	assert.Equal(t, "BankB", output.DuplicateBank[0].Source, "A repeat of a matched statement is no longer hidden")
+	assert.True(t, output.DuplicateBank[1].Amount.Equal(decimal.NewFromFloat(31.00)))
+
+	results := engine.BuildResults("job-1", output)
+	duplicates := 0
+	for _, r := range results {
+		if r.MatchStatus == domain.DuplicateBank {
+			duplicates++
+			assert.Nil(t, r.TrxID)
+		}
+	}
+	assert.Equal(t, 2, duplicates)
+}
+
+func TestStreamingReconciliationEngine_DuplicatesAcrossBatches(t *testing.T) {
+	now := time.Now()
+
+	batches := make(chan []domain.Transaction, 2)
+	batches <- []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+	}
+	batches <- []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+	}
+	close(batches)
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+	}
+
+	output, err := matcher.NewStreamingReconciliationEngine(&matcher.ExactMatchStrategy{}, 1).ReconcileStreaming(batches, bankStmts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(output.Matched))
+	assert.Equal(t, 1, len(output.Duplicates))
+	assert.Equal(t, 0, len(output.UnmatchedSystem))
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	policy, err := matcher.ParseDuplicatePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, matcher.DuplicateFirst, policy)
+
+	policy, err = matcher.ParseDuplicatePolicy("CLOSEST_AMOUNT")
+	assert.NoError(t, err)
+	assert.Equal(t, matcher.DuplicateClosestAmount, policy)
+
+	for _, name := range []string{"first", "last", "largest_amount", "sum", "error"} {
+		policy, err = matcher.ParseDuplicatePolicy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, matcher.DuplicatePolicy(name), policy)
+	}
+
+	_, err = matcher.ParseDuplicatePolicy("random")
+	assert.Error(t, err)
+}
+
+func TestReconciliationEngine_CurrencyMismatch(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, Currency: "USD", TransactionTime: now},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, Currency: "USD", TransactionTime: now},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now}, // Unknown currency
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA", Currency: "usd"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA", Currency: "EUR"},
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA", Currency: "EUR"},
+	}
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(output.Matched), "Same currency and unknown currency should match")
+	assert.Equal(t, 1, len(output.CurrencyMismatches))
+	assert.Equal(t, "TX002", output.CurrencyMismatches[0].SystemTx.TrxID)
+
+	results := engine.BuildResults("job-currency", output)
+	for _, r := range results {
+		if r.MatchStatus == domain.CurrencyMismatch {
+			assert.Equal(t, "USD", *r.Currency)
+			assert.Equal(t, "EUR", *r.BankCurrency)
+		}
+	}
+}
+
+func TestReconciliationEngine_NegativeZeroAmount(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+	now := time.Now()
+
+	negativeZero := decimal.RequireFromString("-0.00")
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.Zero, Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX002", Amount: decimal.Zero, Type: domain.Debit, TransactionTime: now},
+			{TrxID: "TX003", Amount: negativeZero, Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX001", Amount: negativeZero, Date: now, Source: "BankA"},
+			{TrxRefID: "TX002", Amount: negativeZero, Date: now, Source: "BankA"},
+			{TrxRefID: "TX003", Amount: decimal.Zero, Date: now, Source: "BankA"},
+		},
+		StartDate: now.Add(-24 * time.Hour),
+		EndDate:   now.Add(24 * time.Hour),
+	}
+
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(output.Matched))
+	assert.Empty(t, output.Discrepancies)
+	assert.True(t, engine.CalculateDiscrepancyTotal(output).IsZero())
+
+	// Zero bank rows have no sign, so they follow the system transaction's direction
+	debits, credits := matcher.SplitByDirection(input)
+	assert.Equal(t, 1, len(debits.BankStatements))
+	assert.Equal(t, "TX002", debits.BankStatements[0].TrxRefID)
+	assert.Equal(t, 2, len(credits.BankStatements))
+}
+
+func TestToleranceWindowStrategy_Combinations(t *testing.T) {
+	strategy := matcher.NewToleranceWindowStrategy(decimal.NewFromFloat(1.00), 2*24*time.Hour)
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		bankAmount  float64
+		bankDate    time.Time
+		shouldMatch bool
+	}{
+		{"amount and date inside", 100.60, day.AddDate(0, 0, 2), true},
+		{"amount inside, date outside", 100.60, day.AddDate(0, 0, 3), false},
+		{"amount outside, date inside", 101.50, day.AddDate(0, 0, -1), false},
+		{"amount and date outside", 101.50, day.AddDate(0, 0, -5), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := matcher.NewReconciliationEngine(strategy)
+			input := matcher.ReconciliationInput{
+				SystemTransactions: []domain.Transaction{
+					{TrxID: "SYS-1", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+				},
+				BankStatements: []domain.BankStatement{
+					{TrxRefID: "BANK-9", Amount: decimal.NewFromFloat(tt.bankAmount), Date: tt.bankDate, Source: "BankA"},
+				},
+				StartDate: day.AddDate(0, 0, -7),
+				EndDate:   day.AddDate(0, 0, 7),
+			}
+
+			output, err := engine.Reconcile(input)
+			assert.NoError(t, err)
+			if tt.shouldMatch {
+				assert.Equal(t, 1, len(output.Discrepancies), "an inexact pair inside the tolerance keeps its difference")
+				assert.True(t, output.Discrepancies[0].Discrepancy.Equal(decimal.NewFromFloat(0.60)))
+				assert.Empty(t, output.UnmatchedSystem)
+				assert.Empty(t, output.UnmatchedBank)
+			} else {
+				assert.Empty(t, output.Discrepancies)
+				assert.Equal(t, 1, len(output.UnmatchedSystem))
+				assert.Equal(t, 1, len(output.UnmatchedBank))
+			}
+		})
+	}
+}
+
+func TestToleranceWindowStrategy_PicksClosestAndClaimsOnce(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(matcher.NewToleranceWindowStrategy(decimal.NewFromFloat(5.00), 24*time.Hour))
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "SYS-1", Amount: decimal.NewFromFloat(50.00), Type: domain.Debit, TransactionTime: day},
+			{TrxID: "SYS-2", Amount: decimal.NewFromFloat(50.00), Type: domain.Debit, TransactionTime: day},
+			{TrxID: "SYS-3", Amount: decimal.NewFromFloat(50.00), Type: domain.Debit, TransactionTime: day},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "", Amount: decimal.NewFromFloat(-52.00), Date: day.AddDate(0, 0, 1), Source: "BankA"},
+			{TrxRefID: "", Amount: decimal.NewFromFloat(-50.00), Date: day, Source: "BankA"},
+		},
+		StartDate: day.AddDate(0, 0, -7),
+		EndDate:   day.AddDate(0, 0, 7),
+	}
+
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(output.Matched), "the exact amount is preferred")
+	assert.Equal(t, "SYS-1", output.Matched[0].SystemTx.TrxID)
+	assert.Equal(t, 1, len(output.Discrepancies))
+	assert.Equal(t, "SYS-2", output.Discrepancies[0].SystemTx.TrxID)
+	assert.Equal(t, 1, len(output.UnmatchedSystem), "each bank statement is claimed once")
+	assert.Empty(t, output.UnmatchedBank)
+}
+
+func TestAmountDateMatchStrategy_PairsStatementsWithoutReference(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	strategy, err := matcher.NewStrategy("exact,amount_date", matcher.StrategyConfig{DateWindow: 24 * time.Hour})
+	assert.NoError(t, err)
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(42.50), Type: domain.Debit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(75.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(60.00), Type: domain.Credit, TransactionTime: day},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "BankA"},
+		// Posted a day later, within the window
+		{Amount: decimal.RequireFromString("-42.50"), Date: day.AddDate(0, 0, 1), Source: "BankA"},
+		// Outside the window
+		{Amount: decimal.NewFromFloat(75.00), Date: day.AddDate(0, 0, 3), Source: "BankA"},
+		// A reference not in the system is not paired on amount
+		{TrxRefID: "TX999", Amount: decimal.NewFromFloat(60.00), Date: day, Source: "BankA"},
+	}
+
+	batched, err := matcher.NewReconciliationEngine(strategy).Reconcile(matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts})
+	assert.NoError(t, err)
+
+	batches := make(chan []domain.Transaction, 2)
+	batches <- systemTxs[:2]
+	batches <- systemTxs[2:]
+	close(batches)
+	streamed, err := matcher.NewStreamingReconciliationEngine(strategy, 2).ReconcileStreaming(batches, bankStmts)
+	assert.NoError(t, err)
+
+	for name, output := range map[string]*matcher.ReconciliationOutput{"batch": batched, "stream": streamed} {
+		if !assert.Equal(t, 2, len(output.Matched), name) {
+			continue
+		}
+		assert.Equal(t, "exact", output.Matched[0].MatchedVia, name)
+		assert.Equal(t, "TX002", output.Matched[1].SystemTx.TrxID, name)
+		assert.Equal(t, "amount_date", output.Matched[1].MatchedVia, name)
+		assert.Equal(t, []string{"TX003", "TX004"}, systemIDs(output.UnmatchedSystem), name)
+		assert.Equal(t, 2, len(output.UnmatchedBank), name)
+		assert.Empty(t, output.AmbiguousSystem, name)
+		assert.Empty(t, output.AmbiguousBank, name)
+	}
+}
+
+func TestAmountDateMatchStrategy_FlagsTies(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	engine := matcher.NewReconciliationEngine(matcher.NewAmountDateMatchStrategy(0))
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			// Two statements fit
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(10.00), Type: domain.Credit, TransactionTime: day},
+			// Two transactions compete for one statement
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(20.00), Type: domain.Credit, TransactionTime: day},
+			{TrxID: "TX003", Amount: decimal.NewFromFloat(20.00), Type: domain.Credit, TransactionTime: day},
+			// Unique on both sides
+			{TrxID: "TX004", Amount: decimal.NewFromFloat(30.00), Type: domain.Credit, TransactionTime: day},
+		},
+		BankStatements: []domain.BankStatement{
+			{Amount: decimal.NewFromFloat(10.00), Date: day, Source: "BankA"},
+			{Amount: decimal.RequireFromString("10.0"), Date: day, Source: "BankA"},
+			{Amount: decimal.NewFromFloat(20.00), Date: day, Source: "BankA"},
+			{Amount: decimal.NewFromFloat(30.00), Date: day, Source: "BankA"},
+		},
+	})
+	assert.NoError(t, err)
+
+	if assert.Equal(t, 1, len(output.Matched)) {
+		assert.Equal(t, "TX004", output.Matched[0].SystemTx.TrxID)
+	}
+	assert.Equal(t, []string{"TX001", "TX002", "TX003"}, systemIDs(output.AmbiguousSystem))
+	assert.Equal(t, 3, len(output.AmbiguousBank), "tied statements are flagged once each")
+	assert.Empty(t, output.UnmatchedSystem)
+	assert.Empty(t, output.UnmatchedBank)
+
+	results := engine.BuildResults("job-1", output)
+	ambiguous := 0
+	for _, result := range results {
+		if result.MatchStatus == domain.AmbiguousMatch {
+			ambiguous++
+		}
+	}
+	assert.Equal(t, 6, ambiguous)
+}
+
+func TestReconciliationEngine_UnsignedAmounts(t *testing.T) {
+	now := time.Now()
+
+	systemTxs := []domain.Transaction{
+		// Same magnitude, bank posted the opposite direction
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: now},
+		// Same direction, different magnitude
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(50.00), Type: domain.Debit, TransactionTime: now},
+		// Upstream sent a signed debit; only its magnitude counts
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(-75.00), Type: domain.Debit, TransactionTime: now},
+		// Explicit bank type with an unsigned amount
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(20.00), Type: domain.Credit, TransactionTime: now},
+	}
+
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(-55.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(-75.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX004", Amount: decimal.NewFromFloat(20.00), Date: now, Source: "BankA", Type: domain.Credit},
+	}
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: systemTxs,
+		BankStatements:     bankStmts,
+		StartDate:          now.Add(-24 * time.Hour),
+		EndDate:            now.Add(24 * time.Hour),
+	}
+
+	// Signed comparison folds the reversed direction into an amount discrepancy
+	output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(output.DirectionMismatches))
+	assert.Equal(t, 3, len(output.Discrepancies))
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithUnsignedAmounts(true))
+	output, err = engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(output.DirectionMismatches))
+	assert.Equal(t, "TX001", output.DirectionMismatches[0].SystemTx.TrxID)
+
+	assert.Equal(t, 1, len(output.Discrepancies))
+	assert.Equal(t, "TX002", output.Discrepancies[0].SystemTx.TrxID)
+	assert.True(t, output.Discrepancies[0].Discrepancy.Equal(decimal.NewFromFloat(5.00)))
+
+	assert.Equal(t, 2, len(output.Matched))
+	assert.Equal(t, "TX003", output.Matched[0].SystemTx.TrxID)
+	assert.Equal(t, "TX004", output.Matched[1].SystemTx.TrxID)
+
+	results := engine.BuildResults("job-1", output)
+	statuses := make(map[domain.MatchStatus]int)
+	for _, r := range results {
+		statuses[r.MatchStatus]++
+	}
+	assert.Equal(t, 1, statuses[domain.DirectionMismatch])
+}
+
+func TestReconciliationEngine_ReferenceFormats(t *testing.T) {
+	now := time.Now()
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithReferenceFormats(matcher.ReferenceFormats{
+		System: regexp.MustCompile(`^TX\d{6}$`),
+		Bank: map[string]*regexp.Regexp{
+			"bank_b.csv":      regexp.MustCompile(`^B-\d+$`),
+			matcher.AnySource: regexp.MustCompile(`^TX\d{6}$`),
+		},
+	}))
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX000001", Amount: decimal.NewFromFloat(100), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX-000002", Amount: decimal.NewFromFloat(200), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX000003", Amount: decimal.NewFromFloat(300), Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX000001", Amount: decimal.NewFromFloat(100), Date: now, Source: "bank_a.csv"},
+			{TrxRefID: "tx000003", Amount: decimal.NewFromFloat(300), Date: now, Source: "bank_a.csv"},
+			{TrxRefID: "B-42", Amount: decimal.NewFromFloat(50), Date: now, Source: "bank_b.csv"},
+			{TrxRefID: "TX000009", Amount: decimal.NewFromFloat(60), Date: now, Source: "bank_b.csv"},
+		},
+		StartDate: now,
+		EndDate:   now,
+	}
+
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	assert.Len(t, output.Matched, 1)
+	assert.Equal(t, []string{"TX-000002"}, systemIDs(output.MalformedSystem))
+	assert.Len(t, output.MalformedBank, 2)
+	assert.Equal(t, "tx000003", output.MalformedBank[0].TrxRefID, "falls back to the * pattern")
+	assert.Equal(t, "TX000009", output.MalformedBank[1].TrxRefID, "uses the source's own pattern")
+
+	// Well-formed but unpaired items stay unmatched
+	assert.Equal(t, []string{"TX000003"}, systemIDs(output.UnmatchedSystem))
+	assert.Len(t, output.UnmatchedBank, 1)
+	assert.Equal(t, "B-42", output.UnmatchedBank[0].TrxRefID)
+
+	results := engine.BuildResults("job", output)
+	var malformed int
+	for _, r := range results {
+		if r.MatchStatus == domain.MalformedReference {
+			malformed++
+		}
+	}
+	assert.Equal(t, 3, malformed)
+}
+
+func TestReconciliationEngine_ReferenceFormatsUnset(t *testing.T) {
+	now := time.Now()
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{{TrxID: "anything", Amount: decimal.NewFromFloat(1), Type: domain.Credit, TransactionTime: now}},
+		BankStatements:     []domain.BankStatement{{TrxRefID: "anything", Amount: decimal.NewFromFloat(1), Date: now}},
+		StartDate:          now,
+		EndDate:            now,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, output.Matched, 1)
+	assert.Empty(t, output.MalformedSystem)
+	assert.Empty(t, output.MalformedBank)
+}
+
+func systemIDs(transactions []domain.Transaction) []string {
+	ids := make([]string, len(transactions))
+	for i, tx := range transactions {
+		ids[i] = tx.TrxID
+	}
+	return ids
+}
+
+func TestNewStrategy(t *testing.T) {
+	cfg := matcher.StrategyConfig{AmountTolerance: decimal.NewFromFloat(0.5), DateWindow: 24 * time.Hour}
+
+	for name, want := range map[string]interface{}{
+		"":                 &matcher.ExactMatchStrategy{},
+		"exact":            &matcher.ExactMatchStrategy{},
+		"Tolerance":        &matcher.ToleranceWindowStrategy{},
+		"tolerance_window": &matcher.ToleranceWindowStrategy{},
+		"normalized":       &matcher.NormalizedMatchStrategy{},
+		"amount_date":      &matcher.AmountDateMatchStrategy{},
+		"installment":      &matcher.InstallmentMatchStrategy{},
+	} {
+		strategy, err := matcher.NewStrategy(name, cfg)
+		assert.NoError(t, err, name)
+		assert.IsType(t, want, strategy, name)
+	}
+
+	tolerance, _ := matcher.NewStrategy("tolerance", cfg)
+	assert.True(t, tolerance.(*matcher.ToleranceWindowStrategy).AmountTolerance.Equal(decimal.NewFromFloat(0.5)))
+
+	_, err := matcher.NewStrategy("fuzzy", cfg)
+	assert.ErrorIs(t, err, matcher.ErrUnknownStrategy)
+}
+
+func TestReconciliationEngine_BasisPointTolerance(t *testing.T) {
+	now := time.Now()
+	system := func(id string, amount float64) domain.Transaction {
+		return domain.Transaction{TrxID: id, Amount: decimal.NewFromFloat(amount), Type: domain.Credit, TransactionTime: now}
+	}
+	bank := func(id string, amount float64, source string) domain.BankStatement {
+		return domain.BankStatement{TrxRefID: id, Amount: decimal.NewFromFloat(amount), Date: now, Source: source}
+	}
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			system("TX001", 1000.00), system("TX002", 1000.00), system("TX003", 1000.00),
+			system("TX004", 1000.00), system("TX005", 1000.00),
+		},
+		BankStatements: []domain.BankStatement{
+			// 10 bps: within the 25 bps default
+			bank("TX001", 999.00, "bank_a"),
+			// 30 bps: beyond the default
+			bank("TX002", 997.00, "bank_a"),
+			// 30 bps: bank_fees allows 50
+			bank("TX003", 997.00, "bank_fees"),
+			// 10 bps: bank_strict overrides the default with no tolerance
+			bank("TX004", 999.00, "bank_strict"),
+			// Exactly the 50 bps override
+			bank("TX005", 995.00, "bank_fees"),
+		},
+		StartDate: now.Add(-24 * time.Hour),
+		EndDate:   now.Add(24 * time.Hour),
+	}
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithBasisPointTolerance(matcher.BasisPointTolerance{
+		Default: decimal.NewFromInt(25),
+		BySource: map[string]decimal.Decimal{
+			"bank_fees":   decimal.NewFromInt(50),
+			"bank_strict": decimal.Zero,
+		},
+	}))
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	var matched, discrepancies []string
+	for _, pair := range output.Matched {
+		matched = append(matched, pair.SystemTx.TrxID)
+	}
+	for _, pair := range output.Discrepancies {
+		discrepancies = append(discrepancies, pair.SystemTx.TrxID)
+	}
+	assert.ElementsMatch(t, []string{"TX001", "TX003", "TX005"}, matched)
+	assert.ElementsMatch(t, []string{"TX002", "TX004"}, discrepancies)
+
+	// Without the option every gap is a discrepancy
+	output, err = matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Len(t, output.Discrepancies, 5)
+}
+
+func TestReconciliationEngine_CurrencyScales(t *testing.T) {
+	now := time.Now()
+	system := func(id, amount, currency string) domain.Transaction {
+		return domain.Transaction{TrxID: id, Amount: decimal.RequireFromString(amount), Type: domain.Credit, TransactionTime: now, Currency: currency}
+	}
+	bank := func(id, amount, currency string) domain.BankStatement {
+		return domain.BankStatement{TrxRefID: id, Amount: decimal.RequireFromString(amount), Date: now, Source: "BankA", Currency: currency}
+	}
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			system("JPY1", "1000", "JPY"),
+			system("JPY2", "1000", "JPY"),
+			system("BHD1", "10.000", "BHD"),
+			system("BHD2", "10.000", "BHD"),
+			system("USD1", "100.00", "USD"),
+			system("ANY1", "5.00", ""),
+		},
+		BankStatements: []domain.BankStatement{
+			bank("JPY1", "1000.004", "jpy"),
+			bank("JPY2", "1001", "JPY"),
+			bank("BHD1", "10.0004", "BHD"),
+			bank("BHD2", "10.004", "BHD"),
+			bank("USD1", "100.000", "USD"),
+			bank("ANY1", "5.004", ""),
+		},
+		StartDate: now.Add(-24 * time.Hour),
+		EndDate:   now.Add(24 * time.Hour),
+	}
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithCurrencyScales(matcher.CurrencyScales{
+		Default:    2,
+		ByCurrency: map[string]int32{"JPY": 0, "BHD": 3},
+	}))
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+
+	var matched []string
+	for _, pair := range output.Matched {
+		matched = append(matched, pair.SystemTx.TrxID)
+	}
+	assert.ElementsMatch(t, []string{"JPY1", "BHD1", "USD1", "ANY1"}, matched)
+
+	discrepancies := make(map[string]decimal.Decimal)
+	for _, pair := range output.Discrepancies {
+		discrepancies[pair.SystemTx.TrxID] = pair.Discrepancy
+	}
+	assert.Len(t, discrepancies, 2)
+	assert.True(t, discrepancies["JPY2"].Equal(decimal.NewFromInt(1)))
+	assert.True(t, discrepancies["BHD2"].Equal(decimal.RequireFromString("0.004")), "BHD keeps its third decimal")
+
+	// Compared as given, sub-unit precision shows up as discrepancies
+	output, err = matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Len(t, output.Discrepancies, 5)
+}
+
+func TestChainedMatchStrategy_ExactWinsOverNormalized(t *testing.T) {
+	now := time.Now()
+	chain := matcher.NewChainedMatchStrategy(&matcher.ExactMatchStrategy{}, matcher.NewNormalizedMatchStrategy())
+
+	systemTxs := []domain.Transaction{
+		// Normalizes to the same key as bank "TX001"; exact must pair TX001 with TX001
+		{TrxID: "tx-001", Amount: decimal.NewFromFloat(50.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "ref-002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "TX404", Amount: decimal.NewFromFloat(400.00), Type: domain.Credit, TransactionTime: now},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "BankA"},
+		{TrxRefID: "REF 002", Amount: decimal.NewFromFloat(200.00), Date: now, Source: "BankA"},
+		{TrxRefID: "TX999", Amount: decimal.NewFromFloat(999.00), Date: now, Source: "BankA"},
+	}
+
+	batched, err := matcher.NewReconciliationEngine(chain).Reconcile(matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts})
+	assert.NoError(t, err)
+
+	batches := make(chan []domain.Transaction, 2)
+	batches <- systemTxs[:2]
+	batches <- systemTxs[2:]
+	close(batches)
+	streamed, err := matcher.NewStreamingReconciliationEngine(chain, 2).ReconcileStreaming(batches, bankStmts)
+	assert.NoError(t, err)
+
+	for name, output := range map[string]*matcher.ReconciliationOutput{"batch": batched, "stream": streamed} {
+		if !assert.Equal(t, 2, len(output.Matched), name) {
+			continue
+		}
+		assert.Equal(t, "TX001", output.Matched[0].SystemTx.TrxID, name)
+		assert.Equal(t, "exact", output.Matched[0].MatchedVia, name)
+		assert.Equal(t, "ref-002", output.Matched[1].SystemTx.TrxID, name)
+		assert.Equal(t, "normalized", output.Matched[1].MatchedVia, name)
+
+		assert.Equal(t, 2, len(output.UnmatchedSystem), name)
+		assert.Equal(t, 0, len(output.Duplicates), name)
+		if assert.Equal(t, 1, len(output.UnmatchedBank), name) {
+			assert.Equal(t, "TX999", output.UnmatchedBank[0].TrxRefID, name)
+		}
+	}
+}
+
+func TestChainedMatchStrategy_TagsResults(t *testing.T) {
+	now := time.Now()
+	strategy, err := matcher.NewStrategy("exact, normalized", matcher.StrategyConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, "exact,normalized", strategy.(*matcher.ChainedMatchStrategy).Name())
+	assert.True(t, strategy.Match(domain.Transaction{TrxID: "a-1"}, domain.BankStatement{TrxRefID: "A1"}))
+
+	engine := matcher.NewReconciliationEngine(strategy)
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "tx-002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+			{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: now},
+			{TrxRefID: "TX002", Amount: decimal.NewFromFloat(250.00), Date: now},
+		},
+	})
+	assert.NoError(t, err)
+
+	via := make(map[string]string)
+	for _, result := range engine.BuildResults("job-1", output) {
+		if result.MatchedVia != nil {
+			via[*result.TrxID] = *result.MatchedVia
+		} else {
+			via[*result.TrxID] = "-"
+		}
+	}
+	assert.Equal(t, map[string]string{"TX001": "exact", "tx-002": "normalized", "TX003": "-"}, via)
+
+	_, err = matcher.NewStrategy("exact,,normalized", matcher.StrategyConfig{})
+	assert.ErrorIs(t, err, matcher.ErrUnknownStrategy)
+	_, err = matcher.NewStrategy("exact,fuzzy", matcher.StrategyConfig{})
+	assert.ErrorIs(t, err, matcher.ErrUnknownStrategy)
+}
+
+func TestReconciliationEngine_SignedDiscrepancy(t *testing.T) {
+	now := time.Now()
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			// Bank under-reported a credit: system - bank = +25
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now},
+			// Bank over-reported a debit: -100 - (-140) = +40
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: now},
+			// Bank over-reported a credit: 200 - 260 = -60
+			{TrxID: "TX003", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "TX001", Amount: decimal.NewFromFloat(75.00), Date: now},
+			{TrxRefID: "TX002", Amount: decimal.NewFromFloat(-140.00), Date: now},
+			{TrxRefID: "TX003", Amount: decimal.NewFromFloat(260.00), Date: now},
+		},
+	})
+	assert.NoError(t, err)
+	if !assert.Equal(t, 3, len(output.Discrepancies)) {
+		return
+	}
+
+	for i, want := range []float64{25, 40, -60} {
+		assert.True(t, output.Discrepancies[i].SignedDiscrepancy.Equal(decimal.NewFromFloat(want)), output.Discrepancies[i].SignedDiscrepancy.String())
+		assert.True(t, output.Discrepancies[i].Discrepancy.Equal(decimal.NewFromFloat(want).Abs()))
+	}
+	assert.True(t, engine.CalculateDiscrepancyTotal(output).Equal(decimal.NewFromFloat(125.00)))
+	assert.True(t, engine.CalculateNetDiscrepancy(output).Equal(decimal.NewFromFloat(5.00)))
+
+	results := engine.BuildResults("job-1", output)
+	for _, result := range results {
+		if assert.NotNil(t, result.SignedDiscrepancy) && *result.TrxID == "TX003" {
+			assert.True(t, result.SignedDiscrepancy.Equal(decimal.NewFromFloat(-60.00)))
+			assert.True(t, result.Discrepancy.Equal(decimal.NewFromFloat(60.00)))
+		}
+	}
+}
+
+func TestNormalizeBankAmount(t *testing.T) {
+	positive := domain.BankStatement{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00)}
+	negative := domain.BankStatement{TrxRefID: "TX002", Amount: decimal.NewFromFloat(-100.00)}
+	typed := domain.BankStatement{TrxRefID: "TX003", Amount: decimal.NewFromFloat(-100.00), Type: domain.Debit}
+
+	for _, tc := range []struct {
+		convention matcher.BankAmountConvention
+		stmt       domain.BankStatement
+		want       float64
+	}{
+		{matcher.BankAmountDebitsNegative, negative, -100},
+		{matcher.BankAmountSeparateColumns, positive, 100},
+		{matcher.BankAmountDebitsPositive, positive, -100},
+		{matcher.BankAmountDebitsPositive, negative, 100},
+		{matcher.BankAmountDebitsPositive, typed, -100},
+		{matcher.BankAmountAlwaysPositive, negative, 100},
+		{matcher.BankAmountAlwaysPositive, typed, -100},
+	} {
+		got := matcher.NormalizeBankAmount(tc.stmt, tc.convention)
+		assert.True(t, got.Amount.Equal(decimal.NewFromFloat(tc.want)), "%s %s: %s", tc.convention, tc.stmt.TrxRefID, got.Amount)
+		// Normalizing twice changes nothing
+		assert.True(t, matcher.NormalizeBankAmount(got, tc.convention).Amount.Equal(got.Amount))
+	}
+
+	for name, want := range map[string]matcher.BankAmountConvention{
+		"":                 matcher.BankAmountDebitsNegative,
+		"DEBITS_POSITIVE":  matcher.BankAmountDebitsPositive,
+		"always_positive":  matcher.BankAmountAlwaysPositive,
+		"separate_columns": matcher.BankAmountSeparateColumns,
+	} {
+		convention, err := matcher.ParseBankAmountConvention(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, convention, name)
+	}
+	_, err := matcher.ParseBankAmountConvention("mixed")
+	assert.Error(t, err)
+}
+
+func TestReconciliationEngine_BankAmountConventions(t *testing.T) {
+	now := time.Now()
+	systemTxs := []domain.Transaction{
+		{TrxID: "A1", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: now},
+		{TrxID: "A2", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "B1", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: now},
+		{TrxID: "B2", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "C1", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: now},
+		{TrxID: "C2", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: now},
+		{TrxID: "D1", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: now},
+	}
+	bankStmts := []domain.BankStatement{
+		// Debits negative
+		{TrxRefID: "A1", Amount: decimal.NewFromFloat(-100.00), Date: now, Source: "bank_a.csv"},
+		{TrxRefID: "A2", Amount: decimal.NewFromFloat(200.00), Date: now, Source: "bank_a.csv"},
+		// Debits positive
+		{TrxRefID: "B1", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "bank_b.csv"},
+		{TrxRefID: "B2", Amount: decimal.NewFromFloat(-200.00), Date: now, Source: "bank_b.csv"},
+		// Always positive
+		{TrxRefID: "C1", Amount: decimal.NewFromFloat(100.00), Date: now, Source: "bank_c.csv"},
+		{TrxRefID: "C2", Amount: decimal.NewFromFloat(200.00), Date: now, Source: "bank_c.csv"},
+		// Separate columns, already signed and typed by the parser
+		{TrxRefID: "D1", Amount: decimal.NewFromFloat(-100.00), Type: domain.Debit, Date: now, Source: "bank_d.csv"},
+	}
+	conventions := matcher.BankAmountConventions{
+		Default: matcher.BankAmountDebitsNegative,
+		BySource: map[string]matcher.BankAmountConvention{
+			"bank_b.csv": matcher.BankAmountDebitsPositive,
+			"bank_c.csv": matcher.BankAmountAlwaysPositive,
+			"bank_d.csv": matcher.BankAmountSeparateColumns,
+		},
+	}
+	input := matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts}
+
+	for name, opts := range map[string][]matcher.EngineOption{
+		"signed":   {matcher.WithBankAmountConventions(conventions)},
+		"unsigned": {matcher.WithBankAmountConventions(conventions), matcher.WithUnsignedAmounts(true)},
+	} {
+		output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, opts...).Reconcile(input)
+		assert.NoError(t, err, name)
+		assert.Equal(t, 7, len(output.Matched), name)
+		assert.Empty(t, output.Discrepancies, name)
+		assert.Empty(t, output.DirectionMismatches, name)
+	}
+
+	// Without the conventions the flipped and unsigned banks disagree
+	output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(output.Matched))
+	assert.Equal(t, 3, len(output.Discrepancies))
+
+	// An always-positive debit that really differs keeps its signed gap
+	output, err = matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithBankAmountConventions(conventions)).Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: systemTxs[4:5],
+		BankStatements:     []domain.BankStatement{{TrxRefID: "C1", Amount: decimal.NewFromFloat(120.00), Date: now, Source: "bank_c.csv"}},
+	})
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(output.Discrepancies)) {
+		assert.True(t, output.Discrepancies[0].SignedDiscrepancy.Equal(decimal.NewFromFloat(20.00)))
+	}
+}
+
+func TestReconciliationEngine_DetectBankAmountConventions(t *testing.T) {
+	now := time.Now()
+	var input matcher.ReconciliationInput
+	// pairs adds n system transactions alternating debit and credit, and
+	// their bank statements signed by sign(debit)
+	pairs := func(source string, n int, sign func(debit bool) float64) {
+		for i := 0; i < n; i++ {
+			id := fmt.Sprintf("%s-%d", source, i)
+			debit := i%2 == 0
+			txType := domain.Credit
+			if debit {
+				txType = domain.Debit
+			}
+			input.SystemTransactions = append(input.SystemTransactions, domain.Transaction{TrxID: id, Amount: decimal.NewFromFloat(100.00), Type: txType, TransactionTime: now})
+			input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: id, Amount: decimal.NewFromFloat(100.00 * sign(debit)), Date: now, Source: source})
+		}
+	}
+	flipped := func(debit bool) float64 {
+		if debit {
+			return 1
+		}
+		return -1
+	}
+	pairs("bank_flip.csv", 6, flipped)
+	pairs("bank_pos.csv", 6, func(bool) float64 { return 1 })
+	pairs("bank_few.csv", 2, flipped)
+	pairs("bank_fixed.csv", 6, flipped)
+	// A credit-only source is explained by two conventions alike
+	for i := 0; i < 6; i++ {
+		id := fmt.Sprintf("bank_credit.csv-%d", i)
+		input.SystemTransactions = append(input.SystemTransactions, domain.Transaction{TrxID: id, Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: now})
+		input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: id, Amount: decimal.NewFromFloat(100.00), Date: now, Source: "bank_credit.csv"})
+	}
+
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithBankAmountConventions(matcher.BankAmountConventions{
+		Default:  matcher.BankAmountAuto,
+		BySource: map[string]matcher.BankAmountConvention{"bank_fixed.csv": matcher.BankAmountDebitsPositive},
+	}))
+	detected, conventions := engine.DetectBankAmountConventions(input)
+	assert.Equal(t, map[string]domain.SignConvention{
+		"bank_flip.csv":   {Convention: "debits_positive", Detected: true, Pairs: 6, Agreement: 1},
+		"bank_pos.csv":    {Convention: "always_positive", Detected: true, Pairs: 6, Agreement: 1},
+		"bank_few.csv":    {Convention: "debits_negative", Pairs: 2, Agreement: 0},
+		"bank_credit.csv": {Convention: "debits_negative", Pairs: 6, Agreement: 1},
+	}, conventions, "configured sources are not detected")
+
+	output, err := detected.Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 24, len(output.Matched))
+	assert.Equal(t, 2, len(output.Discrepancies), "the small sample is left as it is")
+
+	// Without auto sources nothing is detected
+	plain := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+	same, conventions := plain.DetectBankAmountConventions(input)
+	assert.Same(t, plain, same)
+	assert.Nil(t, conventions)
+}
+
+func TestInstallmentMatchStrategy_SumsInstallments(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	strategy, err := matcher.NewStrategy("exact,installment", matcher.StrategyConfig{})
+	assert.NoError(t, err)
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(300.00), Type: domain.Debit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(500.00), Type: domain.Debit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(80.00), Type: domain.Debit, TransactionTime: day},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001-1", Amount: decimal.NewFromFloat(-100.00), Date: day.AddDate(0, 0, 2), Source: "BankA"},
+		{TrxRefID: "TX002-1", Amount: decimal.NewFromFloat(-200.00), Date: day, Source: "BankA"},
+		{TrxRefID: "TX001-2", Amount: decimal.NewFromFloat(-200.00), Date: day.AddDate(0, 0, 1), Source: "BankA"},
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(-80.00), Date: day, Source: "BankA"},
+		// Installments of a transaction the system does not have stay apart
+		{TrxRefID: "TX009-1", Amount: decimal.NewFromFloat(-10.00), Date: day, Source: "BankA"},
+		{TrxRefID: "TX009-2", Amount: decimal.NewFromFloat(-10.00), Date: day, Source: "BankA"},
+	}
+
+	output, err := matcher.NewReconciliationEngine(strategy).Reconcile(matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts})
+	assert.NoError(t, err)
+
+	if assert.Equal(t, 2, len(output.Matched)) {
+		assert.Equal(t, "TX003", output.Matched[0].SystemTx.TrxID)
+		assert.Equal(t, "exact", output.Matched[0].MatchedVia)
+		paid := output.Matched[1]
+		assert.Equal(t, "TX001", paid.BankStmt.TrxRefID)
+		assert.Equal(t, "installment", paid.MatchedVia)
+		assert.True(t, paid.BankStmt.Amount.Equal(decimal.NewFromFloat(-300.00)))
+		assert.Equal(t, day.AddDate(0, 0, 1), paid.BankStmt.Date, "a group is dated by its first installment")
+		assert.Equal(t, 2, len(paid.BankStmt.Installments))
+	}
+	if assert.Equal(t, 1, len(output.Discrepancies)) {
+		assert.Equal(t, "TX002", output.Discrepancies[0].SystemTx.TrxID)
+		assert.True(t, output.Discrepancies[0].Discrepancy.Equal(decimal.NewFromFloat(300.00)))
+	}
+	assert.Equal(t, []string{"TX009-1", "TX009-2"}, []string{output.UnmatchedBank[0].TrxRefID, output.UnmatchedBank[1].TrxRefID})
+	assert.Empty(t, output.DuplicateBank)
+
+	installments := matcher.InstallmentMatches(output)
+	if assert.Equal(t, 2, len(installments)) {
+		assert.Equal(t, "TX001", installments[0].TrxID)
+		assert.False(t, installments[0].Partial)
+		assert.True(t, installments[0].Outstanding.IsZero())
+		assert.Equal(t, []string{"TX001-1", "TX001-2"}, []string{installments[0].Installments[0].TrxRefID, installments[0].Installments[1].TrxRefID})
+
+		assert.Equal(t, "TX002", installments[1].TrxID)
+		assert.Equal(t, domain.Discrepancy, installments[1].MatchStatus)
+		assert.True(t, installments[1].Partial)
+		assert.True(t, installments[1].Outstanding.Equal(decimal.NewFromFloat(300.00)))
+	}
+}
+
+func TestInstallmentMatchStrategy_Pattern(t *testing.T) {
+	pattern, err := matcher.CompileInstallmentPattern(`^INS/(?P<parent>[A-Z0-9]+)/\d+$`)
+	assert.NoError(t, err)
+	strategy := matcher.NewInstallmentMatchStrategy(pattern)
+
+	parent, ok := strategy.ParentReference("INS/TX001/2")
+	assert.True(t, ok)
+	assert.Equal(t, "TX001", parent)
+	_, ok = strategy.ParentReference("TX001-2")
+	assert.False(t, ok)
+
+	parent, ok = matcher.NewInstallmentMatchStrategy(nil).ParentReference("LOAN-7-12")
+	assert.True(t, ok)
+	assert.Equal(t, "LOAN-7", parent)
+
+	_, err = matcher.CompileInstallmentPattern(`-\d+$`)
+	assert.ErrorContains(t, err, "captures the parent")
+	_, err = matcher.CompileInstallmentPattern(`(`)
+	assert.Error(t, err)
+}
+
+func TestReconciliationEngine_Confidence(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transforms, err := matcher.StripPatterns(`^REF-`)
+	assert.NoError(t, err)
+	strategy := matcher.NewChainedMatchStrategy(
+		&matcher.ExactMatchStrategy{},
+		matcher.NewNormalizedMatchStrategy(transforms...),
+		matcher.NewAmountDateMatchStrategy(3*24*time.Hour),
+	)
+
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(400.00), Type: domain.Credit, TransactionTime: day},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "BankA"},
+		{TrxRefID: "TX004", Amount: decimal.NewFromFloat(300.00), Date: day, Source: "BankA"},
+		{TrxRefID: "REF-TX002", Amount: decimal.NewFromFloat(200.00), Date: day.AddDate(0, 0, 1), Source: "BankA"},
+		{TrxRefID: "", Amount: decimal.NewFromFloat(300.00), Date: day, Source: "BankA"},
+	}
+
+	output, err := matcher.NewReconciliationEngine(strategy).Reconcile(matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts})
+	assert.NoError(t, err)
+
+	confidence := make(map[string]float64)
+	for _, pair := range output.Matched {
+		confidence[pair.SystemTx.TrxID] = pair.Confidence
+	}
+	assert.Equal(t, 1.0, confidence["TX001"], "exact matches score 1.0")
+	assert.Equal(t, 0.678, confidence["TX002"], "a normalized reference a day late scores lower")
+	assert.Equal(t, 0.5, confidence["TX003"], "a statement without a reference has no ID similarity")
+	if assert.Equal(t, 1, len(output.Discrepancies)) {
+		assert.Equal(t, 1.0, output.Discrepancies[0].Confidence, "an exact ID pairs with certainty despite the amounts")
+	}
+
+	for _, result := range matcher.NewReconciliationEngine(strategy).BuildResults("job-1", output) {
+		if assert.NotNil(t, result.Confidence) && result.MatchStatus == domain.Matched {
+			assert.Equal(t, confidence[*result.TrxID], *result.Confidence)
+		}
+	}
+}
+
+func TestConfidenceParts(t *testing.T) {
+	assert.Equal(t, 1.0, matcher.IDSimilarity("TX001", "TX001"))
+	assert.Equal(t, 0.8, matcher.IDSimilarity("TX001", "TX002"))
+	assert.Equal(t, 0.0, matcher.IDSimilarity("TX001", ""))
+
+	assert.Equal(t, 1.0, matcher.AmountCloseness(decimal.Zero, decimal.NewFromInt(100), decimal.NewFromInt(100)))
+	assert.Equal(t, 0.75, matcher.AmountCloseness(decimal.NewFromInt(25), decimal.NewFromInt(100), decimal.NewFromInt(75)))
+	assert.Equal(t, 0.0, matcher.AmountCloseness(decimal.NewFromInt(300), decimal.NewFromInt(100), decimal.NewFromInt(-200)))
+
+	assert.Equal(t, 1.0, matcher.DateProximity(0))
+	assert.Equal(t, 0.25, matcher.DateProximity(3))
+}
+
+func TestReconciliationEngine_OrderIDReference(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", OrderID: "ORD-1", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		// Without an order ID the transaction falls back to its trx_id
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "ORD-1", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "BankA"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(200.00), Date: day, Source: "BankA"},
+	}
+	input := matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts}
+
+	for _, name := range []string{"exact", "normalized", "exact,installment"} {
+		strategy, err := matcher.NewStrategy(name, matcher.StrategyConfig{ReferenceField: matcher.ReferenceOrderID})
+		assert.NoError(t, err)
+		output, err := matcher.NewReconciliationEngine(strategy).Reconcile(input)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(output.Matched), name)
+		assert.Empty(t, output.UnmatchedBank, name)
+		for _, pair := range output.Matched {
+			assert.Equal(t, 1.0, pair.Confidence, name)
+		}
+	}
+
+	// Keyed on trx_id, the order reference finds nothing
+	output, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(output.Matched))
+	assert.Equal(t, "ORD-1", output.UnmatchedBank[0].TrxRefID)
+}
+
+func TestParseReferenceField(t *testing.T) {
+	field, err := matcher.ParseReferenceField("")
+	assert.NoError(t, err)
+	assert.Equal(t, matcher.ReferenceTrxID, field)
+
+	field, err = matcher.ParseReferenceField(" Order_ID ")
+	assert.NoError(t, err)
+	assert.Equal(t, matcher.ReferenceOrderID, field)
+
+	_, err = matcher.ParseReferenceField("invoice_id")
+	assert.ErrorContains(t, err, "unknown reference field")
+}
+
+func TestReconciliationEngine_UniqueClaims(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	systemTxs := []domain.Transaction{
+		{TrxID: "TX001", OrderID: "ORD-1", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", OrderID: "ORD-1", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", OrderID: "ORD-3", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+		// Distinct as given, the same reference once normalized
+		{TrxID: "TX004", OrderID: "ord 4", Amount: decimal.NewFromFloat(400.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX005", OrderID: "ORD-4", Amount: decimal.NewFromFloat(400.00), Type: domain.Credit, TransactionTime: day},
+	}
+	bankStmts := []domain.BankStatement{
+		{TrxRefID: "ORD-1", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "BankA"},
+		{TrxRefID: "ORD-3", Amount: decimal.NewFromFloat(300.00), Date: day, Source: "BankA"},
+		{TrxRefID: "ORD4", Amount: decimal.NewFromFloat(400.00), Date: day, Source: "BankA"},
+	}
+	input := matcher.ReconciliationInput{SystemTransactions: systemTxs, BankStatements: bankStmts}
+	strategy, err := matcher.NewStrategy("normalized", matcher.StrategyConfig{ReferenceField: matcher.ReferenceOrderID})
+	assert.NoError(t, err)
+
+	// Unchecked, the first claimant takes the statement and the others stay unmatched
+	output, err := matcher.NewReconciliationEngine(strategy).Reconcile(input)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(output.Matched))
+	if assert.Len(t, output.UnmatchedSystem, 2) {
+		assert.Equal(t, "TX002", output.UnmatchedSystem[0].TrxID)
+		assert.Equal(t, "TX005", output.UnmatchedSystem[1].TrxID)
+	}
+
+	engine := matcher.NewReconciliationEngine(strategy, matcher.WithUniqueClaims(true))
+	output, err = engine.Reconcile(input)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(output.Matched)) {
+		assert.Equal(t, "TX003", output.Matched[0].SystemTx.TrxID)
+	}
+	var ambiguous []string
+	for _, tx := range output.AmbiguousSystem {
+		ambiguous = append(ambiguous, tx.TrxID)
+	}
+	assert.Equal(t, []string{"TX001", "TX002", "TX004", "TX005"}, ambiguous)
+	assert.Equal(t, []string{"ORD-1", "ORD4"}, []string{output.AmbiguousBank[0].TrxRefID, output.AmbiguousBank[1].TrxRefID})
+	assert.Empty(t, output.UnmatchedBank)
+	assert.Empty(t, output.UnmatchedSystem)
+
+	statuses := make(map[domain.MatchStatus]int)
+	for _, result := range engine.BuildResults("job-1", output) {
+		statuses[result.MatchStatus]++
+	}
+	assert.Equal(t, map[domain.MatchStatus]int{domain.Matched: 1, domain.AmbiguousMatch: 6}, statuses)
+
+	// Claimants split across batches are still caught
+	streaming := matcher.NewStreamingReconciliationEngine(strategy, 2, matcher.WithUniqueClaims(true))
+	batches := make(chan []domain.Transaction, 3)
+	batches <- systemTxs[:1]
+	batches <- systemTxs[1:3]
+	batches <- systemTxs[3:]
+	close(batches)
+	output, err = streaming.ReconcileStreaming(batches, bankStmts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(output.Matched))
+	assert.Equal(t, 4, len(output.AmbiguousSystem))
+	assert.Equal(t, 2, len(output.AmbiguousBank))
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/middleware"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/metrics"
+)
+
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	return rec.Body.String()
+}
+
+func TestMetrics_ExpositionFormat(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := metrics.NewCounterVec("test_events_total", "Events seen.", "kind")
+	gauge := metrics.NewGaugeVec("test_queue_depth", "Queue depth.")
+	histogram := metrics.NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	registry.MustRegister(counter, gauge, histogram)
+
+	counter.WithLabelValues(`a"b`).Inc()
+	counter.WithLabelValues("plain").Add(2.5)
+	gauge.WithLabelValues().Set(7)
+	histogram.WithLabelValues("/x").Observe(0.05)
+	histogram.WithLabelValues("/x").Observe(0.5)
+	histogram.WithLabelValues("/x").Observe(3)
+
+	body := scrape(t, registry)
+	for _, line := range []string{
+		"# HELP test_events_total Events seen.",
+		"# TYPE test_events_total counter",
+		`test_events_total{kind="a\"b"} 1`,
+		`test_events_total{kind="plain"} 2.5`,
+		"# TYPE test_queue_depth gauge",
+		"test_queue_depth 7",
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{route="/x",le="0.1"} 1`,
+		`test_latency_seconds_bucket{route="/x",le="1"} 2`,
+		`test_latency_seconds_bucket{route="/x",le="+Inf"} 3`,
+		`test_latency_seconds_sum{route="/x"} 3.55`,
+		`test_latency_seconds_count{route="/x"} 3`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+
+	assert.Panics(t, func() { registry.MustRegister(metrics.NewCounterVec("test_events_total", "dup")) })
+	assert.Panics(t, func() { counter.WithLabelValues() })
+}
+
+func TestMetrics_RecordsReconciliation(t *testing.T) {
+	dir := t.TempDir()
+	bankFile := filepath.Join(dir, "bank_metrics.csv")
+	assert.NoError(t, os.WriteFile(bankFile, []byte("trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTX002,250.00,2024-01-15\nTX999,5.00,2024-01-15\n"), 0644))
+
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Metrics())
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	_, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+
+	// The default registry is shared across tests, so check for series rather
+	// than exact totals
+	body := scrape(t, metrics.DefaultRegistry)
+	for _, series := range []string{
+		`recon_jobs_started_total{input="file"}`,
+		`recon_jobs_completed_total{input="file"}`,
+		`recon_job_duration_seconds_count{input="file"}`,
+		`recon_match_duration_seconds_count`,
+		`recon_match_results_total{status="MATCHED",bank_source="bank_metrics.csv"}`,
+		`recon_match_results_total{status="DISCREPANCY",bank_source="bank_metrics.csv"}`,
+		`recon_match_results_total{status="UNMATCHED_BANK",bank_source="bank_metrics.csv"}`,
+		`recon_last_run_matched{input="file"}`,
+		`recon_http_requests_total{method="GET",route="/ping",status="204"}`,
+		`recon_http_requests_total{method="GET",route="unmatched",status="404"}`,
+	} {
+		assert.True(t, strings.Contains(body, "\n"+series+" "), "missing %s", series)
+	}
+}
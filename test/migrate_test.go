@@ -0,0 +1,52 @@
+package test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/migrate"
+	"recon-engine/migrations"
+)
+
+func TestMigrateLoad_OrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_index.sql":  {Data: []byte("CREATE INDEX IF NOT EXISTS idx ON t(c);")},
+		"001_create_t.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"README.md":          {Data: []byte("not a migration")},
+		"010_add_column.sql": {Data: []byte("ALTER TABLE t ADD COLUMN d INT;")},
+	}
+	all, err := migrate.Load(fsys)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 3) {
+		assert.Equal(t, "001", all[0].Version)
+		assert.Equal(t, "001_create_t.sql", all[0].Name)
+		assert.Equal(t, "CREATE TABLE t (c INT);", all[0].SQL)
+		assert.Equal(t, "002", all[1].Version)
+		assert.Equal(t, "010", all[2].Version)
+	}
+}
+
+func TestMigrateLoad_RejectsBadNames(t *testing.T) {
+	_, err := migrate.Load(fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}})
+	assert.ErrorContains(t, err, "VERSION_description.sql")
+
+	_, err = migrate.Load(fstest.MapFS{
+		"003_one.sql": {Data: []byte("SELECT 1;")},
+		"003_two.sql": {Data: []byte("SELECT 2;")},
+	})
+	assert.ErrorContains(t, err, "version 003 is already used by 003_one.sql")
+}
+
+func TestMigrateLoad_EmbeddedMigrations(t *testing.T) {
+	all, err := migrate.Load(migrations.Files)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, all) {
+		assert.Equal(t, "001_init_schema.sql", all[0].Name)
+		assert.Contains(t, all[0].SQL, "CREATE TABLE IF NOT EXISTS reconciliation_results")
+	}
+	for i := 1; i < len(all); i++ {
+		assert.Less(t, all[i-1].Version, all[i].Version)
+	}
+}
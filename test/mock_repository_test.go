@@ -0,0 +1,516 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/repository"
+)
+
+// mockTransactionRepository is an in-memory TransactionRepository
+type mockTransactionRepository struct {
+	mu           sync.Mutex
+	transactions []domain.Transaction
+}
+
+func (r *mockTransactionRepository) Create(_ context.Context, tx *domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transactions = append(r.transactions, *tx)
+	return nil
+}
+
+// BulkCreate skips trx_ids already stored, like ON CONFLICT DO NOTHING
+func (r *mockTransactionRepository) BulkCreate(_ context.Context, transactions []domain.Transaction) (*domain.BulkCreateReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make(map[string]bool, len(r.transactions))
+	for _, tx := range r.transactions {
+		stored[tx.TrxID] = true
+	}
+	report := &domain.BulkCreateReport{}
+	for i, tx := range transactions {
+		outcome := domain.BulkRowOutcome{Index: i, TrxID: tx.TrxID, Status: domain.BulkRowInserted}
+		if stored[tx.TrxID] {
+			outcome.Status = domain.BulkRowDuplicate
+		} else {
+			stored[tx.TrxID] = true
+			r.transactions = append(r.transactions, tx)
+		}
+		report.Add(outcome)
+	}
+	return report, nil
+}
+
+func (r *mockTransactionRepository) GetByTrxID(_ context.Context, trxID string) (*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tx := range r.transactions {
+		if tx.TrxID == trxID {
+			tx := tx
+			return &tx, nil
+		}
+	}
+	return nil, fmt.Errorf("transaction not found")
+}
+
+func (r *mockTransactionRepository) Update(_ context.Context, tx *domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.transactions {
+		if r.transactions[i].TrxID == tx.TrxID {
+			tx.ID, tx.CreatedAt, tx.UpdatedAt = r.transactions[i].ID, r.transactions[i].CreatedAt, time.Now()
+			r.transactions[i] = *tx
+			return nil
+		}
+	}
+	return fmt.Errorf("transaction not found")
+}
+
+func (r *mockTransactionRepository) Delete(_ context.Context, trxID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.transactions {
+		if r.transactions[i].TrxID == trxID {
+			r.transactions = append(r.transactions[:i], r.transactions[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("transaction not found")
+}
+
+func (r *mockTransactionRepository) GetByDateRange(_ context.Context, startDate, endDate time.Time) ([]domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []domain.Transaction
+	for _, tx := range r.transactions {
+		if !tx.TransactionTime.Before(startDate) && tx.TransactionTime.Before(endDate) {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockTransactionRepository) GetByDateRangeStream(_ context.Context, startDate, endDate time.Time, batchSize int, callback func([]domain.Transaction) error) error {
+	transactions, _ := r.GetByDateRange(context.Background(), startDate, endDate)
+	for i := 0; i < len(transactions); i += batchSize {
+		end := i + batchSize
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+		if err := callback(transactions[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mockBankStatementRepository is an in-memory BankStatementRepository
+type mockBankStatementRepository struct {
+	mu         sync.Mutex
+	statements []domain.BankStatement
+	batches    int
+}
+
+func (r *mockBankStatementRepository) BulkCreate(_ context.Context, statements []domain.BankStatement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, statements...)
+	return nil
+}
+
+func (r *mockBankStatementRepository) GetByDateRangeAndSource(_ context.Context, startDate, endDate time.Time, source string) ([]domain.BankStatement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statements := make([]domain.BankStatement, 0)
+	for _, stmt := range r.statements {
+		if !stmt.Date.Before(startDate) && stmt.Date.Before(endDate) && (source == "" || stmt.Source == source) {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements, nil
+}
+
+func (r *mockBankStatementRepository) GetByDateRangeStream(_ context.Context, startDate, endDate time.Time, batchSize int, callback func([]domain.BankStatement) error) error {
+	var statements []domain.BankStatement
+	for _, stmt := range r.statements {
+		if !stmt.Date.Before(startDate) && stmt.Date.Before(endDate) {
+			statements = append(statements, stmt)
+		}
+	}
+	for i := 0; i < len(statements); i += batchSize {
+		end := i + batchSize
+		if end > len(statements) {
+			end = len(statements)
+		}
+		r.batches++
+		if err := callback(statements[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mockReconciliationRepository is an in-memory ReconciliationRepository
+type mockReconciliationRepository struct {
+	mu      sync.Mutex
+	jobs    map[string]domain.ReconciliationJob
+	results []domain.ReconciliationResult
+	// resultBatches counts BulkCreateResults calls
+	resultBatches int
+	lastResultID  int
+}
+
+func newMockReconciliationRepository() *mockReconciliationRepository {
+	return &mockReconciliationRepository{jobs: make(map[string]domain.ReconciliationJob)}
+}
+
+func (r *mockReconciliationRepository) CreateJob(_ context.Context, job *domain.ReconciliationJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job.ID = len(r.jobs) + 1
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	r.jobs[job.JobID] = *job
+	return nil
+}
+
+func (r *mockReconciliationRepository) UpdateJob(_ context.Context, job *domain.ReconciliationJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[job.JobID]; !ok {
+		return fmt.Errorf("reconciliation job not found")
+	}
+	job.UpdatedAt = time.Now()
+	r.jobs[job.JobID] = *job
+	return nil
+}
+
+func (r *mockReconciliationRepository) GetJobByID(_ context.Context, jobID string) (*domain.ReconciliationJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("reconciliation job not found")
+	}
+	return &job, nil
+}
+
+func (r *mockReconciliationRepository) ListCompletedJobs(_ context.Context, startDate, endDate time.Time) ([]domain.ReconciliationJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []domain.ReconciliationJob
+	for _, job := range r.jobs {
+		if job.Status == domain.Completed && !job.StartDate.Before(startDate) && !job.EndDate.After(endDate) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs, nil
+}
+
+func (r *mockReconciliationRepository) ListJobs(_ context.Context, filter domain.JobFilter, limit, offset int) ([]domain.ReconciliationJob, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs := make([]domain.ReconciliationJob, 0)
+	for _, job := range r.jobs {
+		if filter.Status != "" && job.Status != filter.Status {
+			continue
+		}
+		if filter.CreatedFrom != nil && job.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedBefore != nil && !job.CreatedAt.Before(*filter.CreatedBefore) {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+
+	total := len(jobs)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return jobs[offset:end], total, nil
+}
+
+func (r *mockReconciliationRepository) DeleteJob(_ context.Context, jobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok || job.Status == domain.Processing {
+		return fmt.Errorf("reconciliation job not found")
+	}
+	delete(r.jobs, jobID)
+	kept := r.results[:0]
+	for _, result := range r.results {
+		if result.JobID != jobID {
+			kept = append(kept, result)
+		}
+	}
+	r.results = kept
+	return nil
+}
+
+func (r *mockReconciliationRepository) CreateResult(_ context.Context, result *domain.ReconciliationResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastResultID++
+	result.ID = r.lastResultID
+	result.CreatedAt = time.Now()
+	r.results = append(r.results, *result)
+	return nil
+}
+
+func (r *mockReconciliationRepository) BulkCreateResults(_ context.Context, results []domain.ReconciliationResult) error {
+	r.mu.Lock()
+	r.resultBatches++
+	r.mu.Unlock()
+	for i := range results {
+		if err := r.CreateResult(context.Background(), &results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *mockReconciliationRepository) ReplaceUnmatchedResults(_ context.Context, jobID string, removedIDs []int, added []domain.ReconciliationResult, delta domain.JobTotalsDelta) (*domain.ReconciliationJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[jobID]
+	if !ok || job.Status != domain.Completed {
+		return nil, fmt.Errorf("completed reconciliation job not found")
+	}
+
+	removed := make(map[int]bool, len(removedIDs))
+	for _, id := range removedIDs {
+		removed[id] = true
+	}
+	kept := make([]domain.ReconciliationResult, 0, len(r.results))
+	for _, result := range r.results {
+		if removed[result.ID] && result.JobID == jobID && domain.IsUnmatched(result.MatchStatus) {
+			delete(removed, result.ID)
+			continue
+		}
+		kept = append(kept, result)
+	}
+	if len(removed) > 0 {
+		return nil, repository.ErrResultsChanged
+	}
+	for i := range added {
+		r.lastResultID++
+		added[i].ID = r.lastResultID
+		added[i].CreatedAt = time.Now()
+		kept = append(kept, added[i])
+	}
+	r.results = kept
+
+	job.TotalProcessed += delta.Processed
+	job.TotalMatched += delta.Matched
+	job.TotalUnmatched += delta.Unmatched
+	job.TotalDiscrepancies = job.TotalDiscrepancies.Add(delta.TotalDiscrepancies)
+	job.NetDiscrepancy = job.NetDiscrepancy.Add(delta.NetDiscrepancy)
+	r.jobs[jobID] = job
+	return &job, nil
+}
+
+func (r *mockReconciliationRepository) GetResultsByJobID(_ context.Context, jobID string) ([]domain.ReconciliationResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var results []domain.ReconciliationResult
+	for _, result := range r.results {
+		if result.JobID == jobID {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (r *mockReconciliationRepository) GetResultsByJobIDStream(_ context.Context, jobID string, batchSize int, callback func([]domain.ReconciliationResult) error) error {
+	results, _ := r.GetResultsByJobID(context.Background(), jobID)
+	for i := 0; i < len(results); i += batchSize {
+		end := i + batchSize
+		if end > len(results) {
+			end = len(results)
+		}
+		if err := callback(results[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *mockReconciliationRepository) QueryResults(_ context.Context, jobID string, filter domain.ResultFilter, limit, offset int) ([]domain.ReconciliationResult, int, error) {
+	results := r.filterResults(jobID, filter)
+	total := len(results)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return results[offset:end], total, nil
+}
+
+func (r *mockReconciliationRepository) QueryResultsAfter(_ context.Context, jobID string, filter domain.ResultFilter, after *domain.ResultCursor, limit int) ([]domain.ReconciliationResult, error) {
+	results := r.filterResults(jobID, filter)
+	sort.SliceStable(results, func(i, j int) bool {
+		if !results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].CreatedAt.Before(results[j].CreatedAt)
+		}
+		return results[i].ID < results[j].ID
+	})
+	page := make([]domain.ReconciliationResult, 0, limit)
+	for _, result := range results {
+		if after != nil && (result.CreatedAt.Before(after.CreatedAt) ||
+			result.CreatedAt.Equal(after.CreatedAt) && result.ID <= after.ID) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, result)
+	}
+	return page, nil
+}
+
+// filterResults returns the job's results matching filter in insertion order
+func (r *mockReconciliationRepository) filterResults(jobID string, filter domain.ResultFilter) []domain.ReconciliationResult {
+	all, _ := r.GetResultsByJobID(context.Background(), jobID)
+	results := make([]domain.ReconciliationResult, 0)
+	for _, result := range all {
+		if filter.Status != "" && result.MatchStatus != filter.Status {
+			continue
+		}
+		if filter.BankSource != "" && (result.BankSource == nil || *result.BankSource != filter.BankSource) {
+			continue
+		}
+		if filter.TrxID != "" && (result.TrxID == nil || *result.TrxID != filter.TrxID) {
+			continue
+		}
+		if filter.TrxRefID != "" && (result.TrxRefID == nil || *result.TrxRefID != filter.TrxRefID) {
+			continue
+		}
+		amount := result.SystemAmount
+		if amount == nil {
+			amount = result.BankAmount
+		}
+		if (filter.MinAmount != nil || filter.MaxAmount != nil) && amount == nil {
+			continue
+		}
+		if filter.MinAmount != nil && amount.Abs().LessThan(*filter.MinAmount) {
+			continue
+		}
+		if filter.MaxAmount != nil && amount.Abs().GreaterThan(*filter.MaxAmount) {
+			continue
+		}
+		if filter.MinDiscrepancy != nil && (result.Discrepancy == nil || result.Discrepancy.Abs().LessThan(*filter.MinDiscrepancy)) {
+			continue
+		}
+		if filter.MinConfidence != nil && (result.Confidence == nil || *result.Confidence < *filter.MinConfidence) {
+			continue
+		}
+		if filter.UnmatchedBefore != nil && (!domain.IsUnmatched(result.MatchStatus) ||
+			result.TransactionDate == nil || !result.TransactionDate.Before(*filter.UnmatchedBefore)) {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (r *mockReconciliationRepository) GetResultCountsByStatus(_ context.Context, jobID string) ([]domain.ResultCount, error) {
+	all, _ := r.GetResultsByJobID(context.Background(), jobID)
+	index := make(map[domain.ResultCount]int)
+	counts := make([]domain.ResultCount, 0)
+	for _, result := range all {
+		key := domain.ResultCount{MatchStatus: result.MatchStatus}
+		if result.BankSource != nil {
+			key.BankSource = *result.BankSource
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(counts)
+			index[key] = i
+			counts = append(counts, key)
+		}
+		counts[i].Count++
+	}
+	return counts, nil
+}
+
+// mockAttachmentRepository is an in-memory AttachmentRepository; results
+// lists the result IDs that exist
+type mockAttachmentRepository struct {
+	mu          sync.Mutex
+	results     map[int]bool
+	attachments []domain.Attachment
+}
+
+func (r *mockAttachmentRepository) Create(_ context.Context, attachment *domain.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attachment.ID = len(r.attachments) + 1
+	attachment.CreatedAt = time.Now()
+	r.attachments = append(r.attachments, *attachment)
+	return nil
+}
+
+func (r *mockAttachmentRepository) ListByResultID(_ context.Context, resultID int) ([]domain.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attachments := make([]domain.Attachment, 0)
+	for _, a := range r.attachments {
+		if a.ResultID == resultID {
+			attachments = append(attachments, a)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *mockAttachmentRepository) ResultExists(_ context.Context, resultID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results[resultID], nil
+}
+
+type idempotencyClaim struct {
+	jobID     string
+	expiresAt time.Time
+}
+
+// mockIdempotencyRepository is an in-memory IdempotencyRepository
+type mockIdempotencyRepository struct {
+	mu     sync.Mutex
+	claims map[string]idempotencyClaim
+}
+
+func newMockIdempotencyRepository() *mockIdempotencyRepository {
+	return &mockIdempotencyRepository{claims: make(map[string]idempotencyClaim)}
+}
+
+func (r *mockIdempotencyRepository) Claim(_ context.Context, key, jobID string, expiresAt time.Time) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if claim, ok := r.claims[key]; ok && claim.expiresAt.After(time.Now()) {
+		return claim.jobID, nil
+	}
+	r.claims[key] = idempotencyClaim{jobID: jobID, expiresAt: expiresAt}
+	return "", nil
+}
+
+func (r *mockIdempotencyRepository) Release(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.claims, key)
+	return nil
+}
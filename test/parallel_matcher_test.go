@@ -0,0 +1,133 @@
+package test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/matcher"
+)
+
+// largeReconciliationInput builds n system transactions with a mix of
+// matches, discrepancies, unmatched rows and duplicates on both sides
+func largeReconciliationInput(n int) matcher.ReconciliationInput {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	input := matcher.ReconciliationInput{
+		SystemTransactions: make([]domain.Transaction, 0, n),
+		BankStatements:     make([]domain.BankStatement, 0, n),
+		StartDate:          day,
+		EndDate:            day,
+	}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("TX%07d", i)
+		amount := decimal.NewFromInt(int64(i%1000 + 1))
+		input.SystemTransactions = append(input.SystemTransactions, domain.Transaction{
+			TrxID: id, Amount: amount, Type: domain.Credit, TransactionTime: day,
+		})
+		switch i % 10 {
+		case 0: // unmatched system
+		case 1: // discrepancy
+			input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: id, Amount: amount.Add(decimal.NewFromInt(1)), Date: day})
+		case 2: // duplicate bank statement
+			input.BankStatements = append(input.BankStatements,
+				domain.BankStatement{TrxRefID: id, Amount: amount, Date: day},
+				domain.BankStatement{TrxRefID: id, Amount: amount, Date: day})
+		case 3: // duplicate system transaction
+			input.SystemTransactions = append(input.SystemTransactions, input.SystemTransactions[len(input.SystemTransactions)-1])
+			input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: id, Amount: amount, Date: day})
+		default:
+			input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: id, Amount: amount, Date: day})
+		}
+		if i%25 == 0 {
+			input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: "BK" + id, Amount: amount, Date: day})
+		}
+	}
+	return input
+}
+
+func TestReconcile_ParallelMatchesSequential(t *testing.T) {
+	input := largeReconciliationInput(40000)
+
+	sequential, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithWorkers(1)).Reconcile(input)
+	assert.NoError(t, err)
+	parallel, err := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithWorkers(4)).Reconcile(input)
+	assert.NoError(t, err)
+
+	assert.Equal(t, sequential, parallel, "output and ordering must not depend on the worker count")
+	assert.NotEmpty(t, parallel.Matched)
+	assert.NotEmpty(t, parallel.Discrepancies)
+	assert.NotEmpty(t, parallel.UnmatchedSystem)
+	assert.NotEmpty(t, parallel.UnmatchedBank)
+	assert.NotEmpty(t, parallel.Duplicates)
+	assert.NotEmpty(t, parallel.DuplicateBank)
+}
+
+func TestReconcileStreaming_ParallelMatchesSequential(t *testing.T) {
+	input := largeReconciliationInput(30000)
+
+	run := func(workers int) *matcher.ReconciliationOutput {
+		engine := matcher.NewStreamingReconciliationEngine(&matcher.ExactMatchStrategy{}, 12000, matcher.WithWorkers(workers))
+		batches := make(chan []domain.Transaction)
+		go func() {
+			defer close(batches)
+			for i := 0; i < len(input.SystemTransactions); i += 12000 {
+				end := i + 12000
+				if end > len(input.SystemTransactions) {
+					end = len(input.SystemTransactions)
+				}
+				batches <- input.SystemTransactions[i:end]
+			}
+		}()
+		output, err := engine.ReconcileStreaming(batches, input.BankStatements)
+		assert.NoError(t, err)
+		return output
+	}
+
+	assert.Equal(t, run(1), run(3))
+}
+
+// Run with -race: transactions sharing an order_id must not be matched on
+// several workers, and each bank line pairs with at most one of them
+func TestReconcile_ParallelSharedOrderID(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	input := matcher.ReconciliationInput{StartDate: day, EndDate: day}
+	for i := 0; i < 20000; i++ {
+		input.SystemTransactions = append(input.SystemTransactions, domain.Transaction{
+			TrxID: fmt.Sprintf("TX%07d", i), OrderID: "ORD", Amount: decimal.NewFromInt(10), Type: domain.Credit, TransactionTime: day,
+		})
+	}
+	for i := 0; i < 3; i++ {
+		input.BankStatements = append(input.BankStatements, domain.BankStatement{TrxRefID: "ORD", Amount: decimal.NewFromInt(10), Date: day})
+	}
+
+	strategy := &matcher.ExactMatchStrategy{ReferenceField: matcher.ReferenceOrderID}
+	for _, policy := range []matcher.DuplicatePolicy{matcher.DuplicateFirst, matcher.DuplicateLast} {
+		t.Run(string(policy), func(t *testing.T) {
+			output, err := matcher.NewReconciliationEngine(strategy, matcher.WithWorkers(4), matcher.WithDuplicatePolicy(policy)).Reconcile(input)
+			assert.NoError(t, err)
+			assert.Len(t, output.Matched, 3, "one transaction per bank line")
+			assert.Len(t, output.UnmatchedSystem, 19997)
+			assert.Empty(t, output.UnmatchedBank)
+		})
+	}
+}
+
+func benchmarkReconcile(b *testing.B, workers int) {
+	input := largeReconciliationInput(200000)
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{}, matcher.WithWorkers(workers))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.Reconcile(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReconcile_Sequential(b *testing.B) { benchmarkReconcile(b, 1) }
+
+func BenchmarkReconcile_Parallel(b *testing.B) { benchmarkReconcile(b, runtime.NumCPU()) }
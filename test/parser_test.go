@@ -1,10 +1,17 @@
 package test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
 	"recon-engine/internal/domain"
@@ -113,3 +120,712 @@ TX004,400.00,CREDIT,2024-01-18T13:00:00Z
 	// Should only parse valid rows (TX001 and TX004)
 	assert.Equal(t, 2, len(transactions))
 }
+
+func TestCSVBankStatementParser_RetriesTransientCallbackError(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "bank_retry.csv")
+
+	csvContent := `trx_ref_id,amount,date
+TX001,100.50,2024-01-15
+TX002,-200.75,2024-01-16
+`
+
+	err := os.WriteFile(csvFile, []byte(csvContent), 0644)
+	assert.NoError(t, err)
+
+	p := parser.NewCSVBankStatementParser("TestBank", parser.WithCallbackRetry(3, time.Millisecond, nil))
+
+	calls := 0
+	var statements []domain.BankStatement
+	err = p.Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		calls++
+		if calls == 1 {
+			return &parser.TransientError{Err: errors.New("connection reset")}
+		}
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "Callback should fail once then succeed")
+	assert.Equal(t, 2, len(statements))
+}
+
+func TestTransactionCSVParser_DoesNotRetryPermanentCallbackError(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "transactions_retry.csv")
+
+	csvContent := `trx_id,amount,type,transaction_time
+TX001,100.00,DEBIT,2024-01-15T10:00:00Z
+`
+
+	err := os.WriteFile(csvFile, []byte(csvContent), 0644)
+	assert.NoError(t, err)
+
+	p := parser.NewTransactionCSVParser(parser.WithCallbackRetry(3, time.Millisecond, nil))
+
+	calls := 0
+	err = p.Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		calls++
+		return errors.New("constraint violation")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls, "Permanent errors should not be retried")
+}
+
+func TestCSVBankStatementParser_ParsesCurrency(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "bank_currency.csv")
+
+	csvContent := `trx_ref_id,amount,date,currency
+TX001,100.50,2024-01-15,eur
+TX002,-200.75,2024-01-16,
+`
+
+	err := os.WriteFile(csvFile, []byte(csvContent), 0644)
+	assert.NoError(t, err)
+
+	p := parser.NewCSVBankStatementParser("TestBank", parser.WithDefaultCurrency("usd"))
+	var statements []domain.BankStatement
+	err = p.Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", statements[0].Currency)
+	assert.Equal(t, "USD", statements[1].Currency, "Empty values fall back to the default")
+}
+
+func TestTransactionCSVParser_CurrencyColumnIsOptional(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "transactions_currency.csv")
+
+	csvContent := `trx_id,amount,type,transaction_time
+TX001,100.00,DEBIT,2024-01-15T10:00:00Z
+`
+
+	err := os.WriteFile(csvFile, []byte(csvContent), 0644)
+	assert.NoError(t, err)
+
+	var transactions []domain.Transaction
+	err = parser.NewTransactionCSVParser().Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transactions))
+	assert.Equal(t, "", transactions[0].Currency)
+}
+
+func TestTransactionCSVParser_OrderIDColumn(t *testing.T) {
+	csvFile := writeFile(t, t.TempDir(), "transactions_orders.csv", `trx_id,amount,type,transaction_time,order_id
+TX001,100.00,DEBIT,2024-01-15T10:00:00Z, ORD-9 
+TX002,200.00,CREDIT,2024-01-15T11:00:00Z,
+`)
+
+	var transactions []domain.Transaction
+	err := parser.NewTransactionCSVParser().Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(transactions)) {
+		assert.Equal(t, "ORD-9", transactions[0].OrderID)
+		assert.Equal(t, "", transactions[1].OrderID)
+	}
+}
+
+func TestParsers_DescriptionColumn(t *testing.T) {
+	statements, err := parseBankFile(t, `trx_ref_id,amount,date,description
+TX001,100.00,2024-01-15, ACME Coffee #12 
+TX002,200.00,2024-01-15,
+`)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, "ACME Coffee #12", statements[0].Description)
+		assert.Equal(t, "", statements[1].Description)
+	}
+
+	dir := t.TempDir()
+	jsonlFile := writeFile(t, dir, "bank_memo.jsonl", `{"trx_ref_id": "TX001", "amount": "100.00", "date": "2024-01-15", "description": "Refund memo"}
+`)
+	var jsonl []domain.BankStatement
+	err = parser.NewJSONLBankStatementParser("bank_memo").Parse(jsonlFile, 100, func(batch []domain.BankStatement) error {
+		jsonl = append(jsonl, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(jsonl)) {
+		assert.Equal(t, "Refund memo", jsonl[0].Description)
+	}
+
+	systemFile := writeFile(t, dir, "transactions_memo.csv", `trx_id,amount,type,transaction_time,description
+TX001,100.00,DEBIT,2024-01-15T10:00:00Z,Invoice 42
+`)
+	var transactions []domain.Transaction
+	err = parser.NewTransactionCSVParser().Parse(systemFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(transactions)) {
+		assert.Equal(t, "Invoice 42", transactions[0].Description)
+	}
+}
+
+func TestCSVBankStatementParser_ColumnMapping(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "bank_aliases.csv")
+
+	csvContent := `Ref_No,Value,Posting_Date
+TX001,100.50,2024-01-15
+TX002,-200.75,2024-01-16
+`
+
+	err := os.WriteFile(csvFile, []byte(csvContent), 0644)
+	assert.NoError(t, err)
+
+	mapping := parser.ColumnMapping{
+		"ref_no":       "trx_ref_id",
+		"value":        "amount",
+		"posting_date": "date",
+	}
+	p := parser.NewCSVBankStatementParserWithMapping("TestBank", mapping)
+	var statements []domain.BankStatement
+	err = p.Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements))
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+	assert.Equal(t, "100.5", statements[0].Amount.String())
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), statements[1].Date)
+
+	// Without the mapping the same file is rejected
+	err = parser.NewCSVBankStatementParser("TestBank").Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestCSVBankStatementParser_CanonicalColumnWinsOverAlias(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvFile := filepath.Join(tmpDir, "bank_both.csv")
+
+	csvContent := `trx_ref_id,ref_no,amount,date
+TX001,OTHER,100.00,2024-01-15
+`
+
+	err := os.WriteFile(csvFile, []byte(csvContent), 0644)
+	assert.NoError(t, err)
+
+	p := parser.NewCSVBankStatementParserWithMapping("TestBank", parser.ColumnMapping{"ref_no": "trx_ref_id"})
+	var statements []domain.BankStatement
+	err = p.Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+}
+
+const preambleCSV = `Account Statement,,
+Account: 1234567890,Period: 2024-01,
+trx_ref_id,amount,date
+TX001,100.50,2024-01-15
+TX002,-200.75,2024-01-16
+`
+
+func TestCSVBankStatementParser_SkipRows(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank_preamble.csv")
+	assert.NoError(t, os.WriteFile(csvFile, []byte(preambleCSV), 0644))
+
+	var statements []domain.BankStatement
+	err := parser.NewCSVBankStatementParser("TestBank", parser.WithSkipRows(2)).Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements))
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+
+	// Without skipping, the preamble is taken as the header
+	err = parser.NewCSVBankStatementParser("TestBank").Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestCSVBankStatementParser_HeaderDetection(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank_preamble.csv")
+	assert.NoError(t, os.WriteFile(csvFile, []byte(preambleCSV), 0644))
+
+	var statements []domain.BankStatement
+	err := parser.NewCSVBankStatementParser("TestBank", parser.WithHeaderDetection(true)).Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements))
+	assert.Equal(t, "-200.75", statements[1].Amount.String())
+}
+
+func TestCSVBankStatementParser_HeaderDetectionUsesColumnMapping(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank_preamble.csv")
+	content := "Statement for January\n\nRef_No,Value,Posting_Date\nTX001,100.50,2024-01-15\n"
+	assert.NoError(t, os.WriteFile(csvFile, []byte(content), 0644))
+
+	mapping := parser.ColumnMapping{"ref_no": "trx_ref_id", "value": "amount", "posting_date": "date"}
+	var statements []domain.BankStatement
+	err := parser.NewCSVBankStatementParserWithMapping("TestBank", mapping, parser.WithHeaderDetection(true)).Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statements))
+}
+
+func TestCSVBankStatementParser_HeaderDetectionNoHeader(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank_noheader.csv")
+	assert.NoError(t, os.WriteFile(csvFile, []byte("Account Statement\nnothing,here\n"), 0644))
+
+	err := parser.NewCSVBankStatementParser("TestBank", parser.WithHeaderDetection(true)).Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestTransactionCSVParser_SkipRows(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "system_preamble.csv")
+	content := `Exported from ledger
+Generated 2024-01-31
+trx_id,amount,type,transaction_time
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z
+`
+	assert.NoError(t, os.WriteFile(csvFile, []byte(content), 0644))
+
+	var transactions []domain.Transaction
+	err := parser.NewTransactionCSVParser(parser.WithSkipRows(2)).Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transactions))
+	assert.Equal(t, "TX001", transactions[0].TrxID)
+}
+
+// commentedCSV has banner rows, comment lines and a memo spanning two lines
+const commentedCSV = `Bank BRI - Account Statement
+Period: January 2024
+trx_ref_id,amount,date,memo
+# exported 2024-02-01
+TX001,100.50,2024-01-15,"Invoice 1001
+paid in full"
+# TX002 reversed below
+TX003,-50.00,2024-01-16,refund
+`
+
+func TestCSVBankStatementParser_CommentsAndBanner(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank_commented.csv")
+	assert.NoError(t, os.WriteFile(csvFile, []byte(commentedCSV), 0644))
+
+	var report parser.ParseReport
+	var statements []domain.BankStatement
+	p := parser.NewCSVBankStatementParser("TestBank", parser.WithSkipRows(2), parser.WithComment('#'), parser.WithParseReport(&report))
+	err := p.Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, "TX001", statements[0].TrxRefID)
+		assert.Equal(t, "TX003", statements[1].TrxRefID)
+	}
+	assert.Equal(t, 0, report.SkippedRows)
+
+	// Without the comment character the comment lines are malformed rows
+	report = parser.ParseReport{}
+	err = parser.NewCSVBankStatementParser("TestBank", parser.WithSkipRows(2), parser.WithParseReport(&report)).Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.SkippedRows)
+}
+
+func TestParsers_RowErrors(t *testing.T) {
+	var report parser.ParseReport
+	statements, err := parseBankFile(t, `trx_ref_id;amount;date
+TX001;100.00;2024-01-15
+TX002;n/a;2024-01-15
+TX003;300.00;not-a-date
+TX004;400.00
+TX005;500.00;2024-01-15
+`, parser.WithDelimiter(';'), parser.WithParseReport(&report), parser.WithMaxRowErrors(2))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements), "bad rows are skipped, not fatal")
+	assert.Equal(t, 3, report.SkippedRows, "rows past the limit are still counted")
+	if assert.Len(t, report.RowErrors, 2) {
+		assert.Equal(t, 3, report.RowErrors[0].Line)
+		assert.Equal(t, "TX002;n/a;2024-01-15", report.RowErrors[0].Raw)
+		assert.Contains(t, report.RowErrors[0].Error, "invalid amount")
+		assert.Equal(t, "TX003;300.00;not-a-date", report.RowErrors[1].Raw)
+	}
+
+	jsonlFile := filepath.Join(t.TempDir(), "bank_a.jsonl")
+	assert.NoError(t, os.WriteFile(jsonlFile, []byte(`{"trx_ref_id":"TX001","amount":"100.00","date":"2024-01-15"}
+{"trx_ref_id":"TX002"
+`), 0644))
+	report = parser.ParseReport{}
+	err = parser.NewJSONLBankStatementParser("TestBank", parser.WithParseReport(&report)).Parse(jsonlFile, 100, func([]domain.BankStatement) error { return nil })
+	assert.NoError(t, err)
+	if assert.Len(t, report.RowErrors, 1) {
+		assert.Equal(t, `{"trx_ref_id":"TX002"`, report.RowErrors[0].Raw)
+	}
+}
+
+func TestTransactionCSVParser_Comments(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "system_commented.csv")
+	content := `Exported from ledger
+trx_id,amount,type,transaction_time
+# opening entries
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z
+;TX002,200.00,CREDIT,2024-01-15T11:00:00Z
+`
+	assert.NoError(t, os.WriteFile(csvFile, []byte(content), 0644))
+
+	var transactions []domain.Transaction
+	err := parser.NewTransactionCSVParser(parser.WithSkipRows(1), parser.WithComment(';')).Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transactions), "the ';' line is a comment and the '#' line is malformed")
+}
+
+func TestCSVBankStatementParser_TypeColumn(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank_typed.csv")
+	content := `trx_ref_id,amount,date,type
+TX001,100.00,2024-01-15,DEBIT
+TX002,-50.00,2024-01-15,credit
+TX003,25.00,2024-01-15,
+TX004,10.00,2024-01-15,REFUND
+`
+	assert.NoError(t, os.WriteFile(csvFile, []byte(content), 0644))
+
+	var statements []domain.BankStatement
+	err := parser.NewCSVBankStatementParser("TestBank").Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(statements), "rows with an unknown type are skipped")
+
+	assert.Equal(t, domain.Debit, statements[0].Type)
+	assert.Equal(t, "-100", statements[0].Amount.String(), "amounts are stored signed by their type")
+	assert.Equal(t, domain.Credit, statements[1].Type)
+	assert.Equal(t, "50", statements[1].Amount.String())
+	assert.Equal(t, domain.TransactionType(""), statements[2].Type)
+	assert.Equal(t, "25", statements[2].Amount.String())
+}
+
+// writeGzip writes content gzip-compressed to path
+func writeGzip(t *testing.T, path, content string) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestCSVBankStatementParser_Gzip(t *testing.T) {
+	dir := t.TempDir()
+	content := `trx_ref_id,amount,date
+TX001,100.50,2024-01-15
+TX002,-200.75,2024-01-16
+`
+	plainFile := filepath.Join(dir, "bank.csv")
+	gzipFile := filepath.Join(dir, "bank.csv.gz")
+	assert.NoError(t, os.WriteFile(plainFile, []byte(content), 0644))
+	writeGzip(t, gzipFile, content)
+
+	parse := func(path string) []domain.BankStatement {
+		var statements []domain.BankStatement
+		err := parser.NewCSVBankStatementParser("TestBank").Parse(path, 100, func(batch []domain.BankStatement) error {
+			statements = append(statements, batch...)
+			return nil
+		})
+		assert.NoError(t, err)
+		return statements
+	}
+
+	assert.Equal(t, parse(plainFile), parse(gzipFile))
+}
+
+func TestTransactionCSVParser_GzipDetectedWithoutExtension(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "transactions.csv")
+	writeGzip(t, csvFile, `trx_id,amount,type,transaction_time
+TX001,100.00,DEBIT,2024-01-15T10:00:00Z
+`)
+
+	var transactions []domain.Transaction
+	err := parser.NewTransactionCSVParser().Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transactions))
+	assert.Equal(t, "TX001", transactions[0].TrxID)
+}
+
+func TestCSVBankStatementParser_InvalidGzip(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "bank.csv.gz")
+	assert.NoError(t, os.WriteFile(csvFile, []byte("trx_ref_id,amount,date\n"), 0644))
+
+	err := parser.NewCSVBankStatementParser("TestBank").Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+}
+
+var testBalanceRows = parser.BalanceRows{
+	Column:  "trx_ref_id",
+	Opening: regexp.MustCompile(`(?i)^opening balance`),
+	Closing: regexp.MustCompile(`(?i)^closing balance`),
+}
+
+const balanceStatement = `trx_ref_id,amount,date
+Opening Balance,"1,000.00"
+TX001,100.50,2024-01-15
+TX002,-200.75,2024-01-16
+Closing Balance,899.75,2024-01-31
+`
+
+func parseBankFile(t *testing.T, content string, opts ...parser.ParserOption) ([]domain.BankStatement, error) {
+	csvFile := filepath.Join(t.TempDir(), "bank_balance.csv")
+	assert.NoError(t, os.WriteFile(csvFile, []byte(content), 0644))
+
+	var statements []domain.BankStatement
+	err := parser.NewCSVBankStatementParser("TestBank", opts...).Parse(csvFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+	return statements, err
+}
+
+func TestCSVBankStatementParser_ExcludesBalanceRows(t *testing.T) {
+	statements, err := parseBankFile(t, balanceStatement, parser.WithBalanceRows(testBalanceRows))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements))
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+	assert.Equal(t, "TX002", statements[1].TrxRefID)
+}
+
+func TestCSVBankStatementParser_ValidatesBalance(t *testing.T) {
+	rows := testBalanceRows
+	rows.Validate = true
+
+	statements, err := parseBankFile(t, balanceStatement, parser.WithBalanceRows(rows))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements))
+
+	_, err = parseBankFile(t, strings.Replace(balanceStatement, "899.75", "900.00", 1), parser.WithBalanceRows(rows))
+	assert.EqualError(t, err, "balance check failed: opening 1000.00 + transactions -100.25 = 899.75, closing balance is 900.00")
+
+	_, err = parseBankFile(t, strings.Replace(balanceStatement, "Opening Balance", "TX000", 1), parser.WithBalanceRows(rows))
+	assert.ErrorContains(t, err, "has no opening balance")
+
+	_, err = parseBankFile(t, "trx_ref_id,amount,date\nTX001,100.50,2024-01-15\n", parser.WithBalanceRows(rows))
+	assert.NoError(t, err, "files without balance lines are not checked")
+}
+
+func TestCSVBankStatementParser_BalanceRowsByAmountPattern(t *testing.T) {
+	content := `trx_ref_id,amount,date
+BAL,B/F 500.00,2024-01-01
+TX001,100.00,2024-01-15
+BAL,C/F 600.00,2024-01-31
+`
+	rows := parser.BalanceRows{
+		Column:   "amount",
+		Opening:  regexp.MustCompile(`^B/F `),
+		Closing:  regexp.MustCompile(`^C/F `),
+		Validate: true,
+	}
+
+	statements, err := parseBankFile(t, content, parser.WithBalanceRows(rows))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statements))
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+}
+
+func TestCSVBankStatementParser_SemicolonDecimalComma(t *testing.T) {
+	content := `trx_ref_id;amount;date
+TX001;1.234,56;2024-01-15
+TX002;-0,75;2024-01-16
+TX003;"12.345.678,9";2024-01-17
+`
+	statements, err := parseBankFile(t, content, parser.WithDelimiter(';'), parser.WithDecimalComma(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(statements))
+	assert.Equal(t, "1234.56", statements[0].Amount.String())
+	assert.Equal(t, "-0.75", statements[1].Amount.String())
+	assert.Equal(t, "12345678.9", statements[2].Amount.String())
+
+	// Without the options the header is one column and the file is rejected
+	_, err = parseBankFile(t, content)
+	assert.Error(t, err)
+}
+
+func TestCSVBankStatementParser_TabDelimited(t *testing.T) {
+	statements, err := parseBankFile(t, "trx_ref_id\tamount\tdate\nTX001\t100.50\t2024-01-15\n", parser.WithDelimiter('\t'))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statements))
+	assert.Equal(t, "100.5", statements[0].Amount.String())
+}
+
+func TestCSVBankStatementParser_DecimalCommaBalance(t *testing.T) {
+	content := `trx_ref_id;amount;date
+Opening Balance;1.000,00;2024-01-01
+TX001;100,50;2024-01-15
+Closing Balance;1.100,50;2024-01-31
+`
+	rows := testBalanceRows
+	rows.Validate = true
+
+	statements, err := parseBankFile(t, content, parser.WithDelimiter(';'), parser.WithDecimalComma(true), parser.WithBalanceRows(rows))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statements))
+}
+
+func TestCSVBankStatementParser_DebitCreditColumns(t *testing.T) {
+	statements, err := parseBankFile(t, `trx_ref_id,debit,credit,date
+TX001,150.00,,2024-01-15
+TX002,,200.00,2024-01-15
+TX003,0,75.50,2024-01-15
+TX004,10.00,20.00,2024-01-15
+TX005,abc,,2024-01-15
+`)
+
+	assert.NoError(t, err)
+	if !assert.Equal(t, 3, len(statements)) {
+		return
+	}
+	assert.True(t, statements[0].Amount.Equal(decimal.NewFromFloat(-150.00)))
+	assert.Equal(t, domain.Debit, statements[0].Type)
+	assert.True(t, statements[1].Amount.Equal(decimal.NewFromFloat(200.00)))
+	assert.Equal(t, domain.Credit, statements[1].Type)
+	assert.True(t, statements[2].Amount.Equal(decimal.NewFromFloat(75.50)))
+
+	_, err = parseBankFile(t, "trx_ref_id,debit,date\nTX001,150.00,2024-01-15\n")
+	assert.Error(t, err, "a debit column alone is not an amount")
+}
+
+func TestCSVBankStatementParser_DirectionFlags(t *testing.T) {
+	statements, err := parseBankFile(t, `trx_ref_id,amount,type,date
+TX001,100.00,DR,2024-01-15
+TX002,100.00,c,2024-01-15
+TX003,100.00,D,2024-01-15
+TX004,100.00,X,2024-01-15
+`)
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 3, len(statements)) {
+		assert.Equal(t, domain.Debit, statements[0].Type)
+		assert.True(t, statements[0].Amount.IsNegative())
+		assert.Equal(t, domain.Credit, statements[1].Type)
+		assert.Equal(t, domain.Debit, statements[2].Type)
+	}
+}
+
+func TestCSVBankStatementParser_CustomDateFormats(t *testing.T) {
+	content := `trx_ref_id,amount,date
+TX001,100.50,"Jan 15, 2024"
+TX002,200.00,2024-01-16
+`
+	statements, err := parseBankFile(t, content, parser.WithDateFormats("Jan 2, 2006"))
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), statements[0].Date)
+		assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), statements[1].Date, "built-in formats still apply")
+	}
+
+	// Without the format the row is skipped
+	statements, err = parseBankFile(t, content)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statements))
+}
+
+func TestParsers_DayFirst(t *testing.T) {
+	content := `trx_ref_id,amount,date
+TX001,100.50,03/04/2024
+TX002,200.00,25/12/2024
+`
+	statements, err := parseBankFile(t, content)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, time.April, statements[0].Date.Month(), "day first by default")
+		assert.Equal(t, time.December, statements[1].Date.Month())
+	}
+
+	statements, err = parseBankFile(t, content, parser.WithDayFirst(false))
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, time.March, statements[0].Date.Month())
+		assert.Equal(t, 4, statements[0].Date.Day())
+		assert.Equal(t, time.December, statements[1].Date.Month(), "only valid day first")
+	}
+
+	csvFile := filepath.Join(t.TempDir(), "system.csv")
+	assert.NoError(t, os.WriteFile(csvFile, []byte("trx_id,amount,type,transaction_time\nTX001,100.50,CREDIT,03/04/2024\nTX002,10.00,DEBIT,15 Jan 2024\n"), 0644))
+	var transactions []domain.Transaction
+	err = parser.NewTransactionCSVParser(parser.WithDayFirst(false), parser.WithDateFormats("2 Jan 2006")).Parse(csvFile, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(transactions)) {
+		assert.Equal(t, time.March, transactions[0].TransactionTime.Month())
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), transactions[1].TransactionTime)
+	}
+}
+
+func TestParsers_DateLayout(t *testing.T) {
+	content := `trx_ref_id,amount,date
+TX001,100.50,03/04/2024
+TX002,200.00,2024-03-04
+`
+	us, err := parseBankFile(t, content, parser.WithDateLayout("01/02/2006"))
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(us), "only the explicit layout is read") {
+		assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), us[0].Date)
+	}
+
+	// The layout wins over the day-first setting
+	eu, err := parseBankFile(t, content, parser.WithDayFirst(false), parser.WithDateLayout("02/01/2006"))
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(eu)) {
+		assert.Equal(t, time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), eu[0].Date)
+	}
+
+	// An empty layout auto-detects
+	statements, err := parseBankFile(t, content, parser.WithDateLayout(""))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements))
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/middleware"
+)
+
+func newRateLimitRouter(limiter *middleware.RateLimiter, key middleware.RateLimitKey) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/reconcile", middleware.RateLimit(limiter, key), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func postReconcile(router *gin.Engine, remoteAddr, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/reconcile", nil)
+	req.RemoteAddr = remoteAddr
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_PerClientIP(t *testing.T) {
+	router := newRateLimitRouter(middleware.NewRateLimiter(1, 2), middleware.ClientIPKey)
+
+	assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.1:1234", "").Code)
+
+	rec := postReconcile(router, "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), "RATE_LIMITED")
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.True(t, retryAfter > 0 && retryAfter <= 60, "one token a minute: %d", retryAfter)
+
+	assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.2:1234", "").Code, "other clients keep their own bucket")
+}
+
+func TestRateLimit_PerAPIKey(t *testing.T) {
+	router := newRateLimitRouter(middleware.NewRateLimiter(1, 1), middleware.APIKeyKey)
+
+	assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.1:1234", "key-a").Code)
+	assert.Equal(t, http.StatusTooManyRequests, postReconcile(router, "10.0.0.2:1234", "key-a").Code)
+	assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.1:1234", "key-b").Code)
+	assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.1:1234", "").Code, "no key falls back to the IP")
+}
+
+func TestRateLimit_DisabledWithoutLimiter(t *testing.T) {
+	router := newRateLimitRouter(nil, middleware.ClientIPKey)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, postReconcile(router, "10.0.0.1:1234", "").Code)
+	}
+}
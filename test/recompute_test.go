@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func TestReconciliationService_RecomputeJobTotals(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+	ctx := context.Background()
+
+	summary, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+
+	// Totals that agree with the results are left alone
+	recompute, err := svc.RecomputeJobTotals(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.False(t, recompute.Changed)
+	assert.Equal(t, summary.TotalMatched, recompute.Totals.TotalMatched)
+	assert.Equal(t, summary.TotalUnmatched, recompute.Totals.TotalUnmatched)
+
+	// A discrepancy stored without its totals, as after a write that failed
+	// part way, is counted in
+	discrepancy, signed := decimal.NewFromInt(5), decimal.NewFromInt(-5)
+	reconRepo.results = append(reconRepo.results, domain.ReconciliationResult{
+		JobID:             summary.JobID,
+		MatchStatus:       domain.Discrepancy,
+		Discrepancy:       &discrepancy,
+		SignedDiscrepancy: &signed,
+	})
+	job := reconRepo.jobs[summary.JobID]
+	job.TotalMatched += 3
+	reconRepo.jobs[summary.JobID] = job
+
+	recompute, err = svc.RecomputeJobTotals(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.True(t, recompute.Changed)
+	assert.Equal(t, summary.TotalMatched+3, recompute.Previous.TotalMatched)
+	assert.Equal(t, summary.TotalMatched, recompute.Totals.TotalMatched)
+	assert.True(t, recompute.Totals.TotalDiscrepancies.Equal(summary.TotalDiscrepancies.Add(discrepancy)))
+	assert.True(t, recompute.Totals.NetDiscrepancy.Equal(summary.NetDiscrepancy.Add(signed)))
+
+	stored := reconRepo.jobs[summary.JobID]
+	assert.Equal(t, summary.TotalMatched, stored.TotalMatched)
+	assert.Equal(t, summary.TotalProcessed, stored.TotalProcessed)
+	assert.True(t, stored.TotalDiscrepancies.Equal(recompute.Totals.TotalDiscrepancies))
+	assert.Equal(t, domain.Completed, stored.Status)
+}
+
+func TestReconciliationHandler_RecomputeJobTotals(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+
+	router := gin.New()
+	router.POST("/api/v1/reconcile/jobs/:job_id/recompute", handler.NewReconciliationHandler(svc).RecomputeJobTotals)
+	post := func(jobID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/jobs/"+jobID+"/recompute", nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, post("missing").Code)
+	assert.Equal(t, http.StatusConflict, post("running").Code)
+
+	job := reconRepo.jobs[summary.JobID]
+	job.TotalUnmatched = 0
+	reconRepo.jobs[summary.JobID] = job
+	rec := post(summary.JobID)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Data domain.JobRecompute `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.True(t, body.Data.Changed)
+	assert.Zero(t, body.Data.Previous.TotalUnmatched)
+	assert.Equal(t, summary.TotalUnmatched, body.Data.Totals.TotalUnmatched)
+	assert.Equal(t, summary.TotalUnmatched, body.Data.Job.TotalUnmatched)
+}
+
+func TestReconciliationService_RecomputeRejectsRunningJobs(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+
+	_, err := newJobLifecycleService(reconRepo).RecomputeJobTotals(context.Background(), "running")
+	assert.ErrorIs(t, err, service.ErrJobNotCompleted)
+}
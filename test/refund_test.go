@@ -0,0 +1,121 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/matcher"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/service"
+)
+
+func TestReconciliationEngine_MatchesRefunds(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{})
+	now := time.Now()
+
+	input := matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			// Refunds are outflows whether recorded signed or as magnitudes
+			{TrxID: "RF001", Amount: decimal.NewFromFloat(-50.00), Type: domain.Refund, TransactionTime: now},
+			{TrxID: "RF002", Amount: decimal.NewFromFloat(75.00), Type: domain.Refund, TransactionTime: now},
+			{TrxID: "CB001", Amount: decimal.NewFromFloat(-120.00), Type: domain.Chargeback, TransactionTime: now},
+			{TrxID: "CB002", Amount: decimal.NewFromFloat(-30.00), Type: domain.Chargeback, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "RF001", Amount: decimal.NewFromFloat(-50.00), Date: now, Source: "BankA"},
+			{TrxRefID: "RF002", Amount: decimal.NewFromFloat(-75.00), Date: now, Source: "BankA"},
+			{TrxRefID: "CB001", Amount: decimal.NewFromFloat(-120.00), Date: now, Source: "BankA"},
+			{TrxRefID: "CB002", Amount: decimal.NewFromFloat(30.00), Date: now, Source: "BankA"},
+		},
+		StartDate: now.Add(-24 * time.Hour),
+		EndDate:   now.Add(24 * time.Hour),
+	}
+
+	output, err := engine.Reconcile(input)
+	assert.NoError(t, err)
+	assert.Len(t, output.Matched, 3)
+	if assert.Len(t, output.Discrepancies, 1, "a credit on the bank side does not match a chargeback") {
+		assert.Equal(t, "CB002", output.Discrepancies[0].SystemTx.TrxID)
+		assert.True(t, output.Discrepancies[0].Discrepancy.Equal(decimal.NewFromFloat(60)))
+	}
+
+	debits, credits := matcher.SplitByDirection(input)
+	assert.Len(t, debits.SystemTransactions, 4, "reversals reconcile with the debits")
+	assert.Empty(t, credits.SystemTransactions)
+}
+
+func TestReconciliationEngine_MatchesUnsignedRefunds(t *testing.T) {
+	engine := matcher.NewReconciliationEngine(&matcher.ExactMatchStrategy{},
+		matcher.WithBankAmountConventions(matcher.BankAmountConventions{Default: matcher.BankAmountAlwaysPositive}))
+	now := time.Now()
+
+	output, err := engine.Reconcile(matcher.ReconciliationInput{
+		SystemTransactions: []domain.Transaction{
+			{TrxID: "RF001", Amount: decimal.NewFromFloat(-50.00), Type: domain.Refund, TransactionTime: now},
+		},
+		BankStatements: []domain.BankStatement{
+			{TrxRefID: "RF001", Amount: decimal.NewFromFloat(50.00), Date: now, Source: "BankA"},
+		},
+		StartDate: now.Add(-24 * time.Hour),
+		EndDate:   now.Add(24 * time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, output.Matched, 1, "a magnitude takes the refund's outgoing direction")
+}
+
+func TestTransactionCSVParser_ParsesReversals(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "system.csv", `trx_id,amount,type,transaction_time
+RF001,-50.00,refund,2024-01-15T10:00:00Z
+CB001,120.00,CHARGEBACK,2024-01-15T11:00:00Z
+TX001,10.00,TRANSFER,2024-01-15T12:00:00Z
+`)
+
+	var transactions []domain.Transaction
+	err := parser.NewTransactionCSVParser().Parse(path, 100, func(batch []domain.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 2, "unknown types are still skipped") {
+		assert.Equal(t, domain.Refund, transactions[0].Type)
+		assert.True(t, transactions[0].Amount.Equal(decimal.NewFromFloat(-50)))
+		assert.Equal(t, domain.Chargeback, transactions[1].Type)
+	}
+}
+
+func TestTransactionService_AllowsNegativeReversals(t *testing.T) {
+	svc := service.NewTransactionService(&mockTransactionRepository{})
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, svc.Create(context.Background(), &domain.Transaction{TrxID: "RF001", Amount: decimal.NewFromFloat(-50), Type: domain.Refund, TransactionTime: at}))
+	assert.NoError(t, svc.Create(context.Background(), &domain.Transaction{TrxID: "CB001", Amount: decimal.NewFromFloat(-20), Type: domain.Chargeback, TransactionTime: at}))
+
+	err := svc.Create(context.Background(), &domain.Transaction{TrxID: "TX001", Amount: decimal.NewFromFloat(-50), Type: domain.Debit, TransactionTime: at})
+	assert.EqualError(t, err, "amount must be positive")
+	err = svc.Create(context.Background(), &domain.Transaction{TrxID: "RF002", Amount: decimal.Zero, Type: domain.Refund, TransactionTime: at})
+	assert.EqualError(t, err, "amount must be non-zero")
+}
+
+func TestTransactionHandler_UpdatesRefundWithNegativeAmount(t *testing.T) {
+	repo := seededTransactions()
+	payload, _ := json.Marshal(map[string]interface{}{"amount": -40.5, "type": "REFUND", "transaction_time": "2024-01-16T09:00:00Z"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/transactions/TX001", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newTransactionRouter(repo).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	updated, err := repo.GetByTrxID(context.Background(), "TX001")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Refund, updated.Type)
+	assert.True(t, decimal.NewFromFloat(-40.5).Equal(updated.Amount))
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/repository"
+)
+
+// flakyDB is a database/sql driver whose statements numbered in failAt,
+// counting from 1, fail with a Postgres error code. Rows inserted in a
+// transaction count once committed.
+type flakyDB struct {
+	mu        sync.Mutex
+	failAt    map[int]bool
+	code      pq.ErrorCode
+	execs     int
+	committed int
+}
+
+func (d *flakyDB) Connect(context.Context) (driver.Conn, error) { return &flakyConn{db: d}, nil }
+func (d *flakyDB) Driver() driver.Driver                        { return nil }
+
+func (d *flakyDB) exec() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs++
+	if d.failAt[d.execs] {
+		return &pq.Error{Code: d.code}
+	}
+	return nil
+}
+
+type flakyConn struct {
+	db      *flakyDB
+	pending int
+}
+
+func (c *flakyConn) Prepare(string) (driver.Stmt, error) { return &flakyStmt{conn: c}, nil }
+func (c *flakyConn) Close() error                        { return nil }
+func (c *flakyConn) Begin() (driver.Tx, error)           { c.pending = 0; return c, nil }
+
+func (c *flakyConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.committed += c.pending
+	return nil
+}
+
+func (c *flakyConn) Rollback() error { c.pending = 0; return nil }
+
+type flakyStmt struct {
+	conn *flakyConn
+}
+
+func (s *flakyStmt) Close() error  { return nil }
+func (s *flakyStmt) NumInput() int { return -1 }
+
+func (s *flakyStmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := s.conn.db.exec(); err != nil {
+		return nil, err
+	}
+	s.conn.pending++
+	return driver.RowsAffected(1), nil
+}
+
+func (s *flakyStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func newFlakyRepository(code pq.ErrorCode, attempts int, failAt ...int) (repository.ReconciliationRepository, *flakyDB) {
+	db := &flakyDB{failAt: map[int]bool{}, code: code}
+	for _, n := range failAt {
+		db.failAt[n] = true
+	}
+	repo := repository.NewReconciliationRepository(sql.OpenDB(db), repository.WithRetry(attempts, time.Millisecond, 5*time.Millisecond))
+	return repo, db
+}
+
+func TestReconciliationRepository_RetriesTransientWrites(t *testing.T) {
+	repo, db := newFlakyRepository("53300", 3, 1, 2)
+
+	err := repo.UpdateJob(context.Background(), &domain.ReconciliationJob{JobID: "job-1", Status: domain.Completed})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, db.execs)
+}
+
+func TestReconciliationRepository_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo, db := newFlakyRepository("08006", 3, 1, 2, 3, 4, 5)
+
+	err := repo.UpdateJob(context.Background(), &domain.ReconciliationJob{JobID: "job-1", Status: domain.Completed})
+	assert.Error(t, err)
+	assert.Equal(t, 3, db.execs)
+}
+
+func TestReconciliationRepository_DoesNotRetryPermanentErrors(t *testing.T) {
+	repo, db := newFlakyRepository("23505", 3, 1)
+
+	err := repo.UpdateJob(context.Background(), &domain.ReconciliationJob{JobID: "job-1", Status: domain.Completed})
+	assert.Error(t, err)
+	assert.Equal(t, 1, db.execs)
+}
+
+func TestReconciliationRepository_RetriesBulkCreateWithoutDuplicates(t *testing.T) {
+	// The second row fails, after the first was inserted in the same transaction
+	repo, db := newFlakyRepository("40001", 3, 2)
+	results := []domain.ReconciliationResult{
+		{JobID: "job-1", MatchStatus: domain.Matched},
+		{JobID: "job-1", MatchStatus: domain.Matched},
+		{JobID: "job-1", MatchStatus: domain.Matched},
+	}
+
+	err := repo.BulkCreateResults(context.Background(), results)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, db.committed)
+	assert.Equal(t, 5, db.execs)
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, repository.IsTransientError(&pq.Error{Code: "53300"}))
+	assert.True(t, repository.IsTransientError(&pq.Error{Code: "08006"}))
+	assert.True(t, repository.IsTransientError(driver.ErrBadConn))
+	assert.False(t, repository.IsTransientError(&pq.Error{Code: "23505"}))
+	assert.False(t, repository.IsTransientError(errors.New("boom")))
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func TestReconciliationService_ReprocessUnmatched(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+	ctx := context.Background()
+
+	// TX002 has no bank statement until the late file arrives
+	summary, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Len(t, summary.UnmatchedSystem, 1)
+
+	dir := t.TempDir()
+	late := writeFile(t, dir, "bank_late.csv", "trx_ref_id,amount,date\nTX002,200.00,2024-01-15\nTX999,5.00,2024-01-15\n")
+	reprocessed, err := svc.ReprocessUnmatched(ctx, summary.JobID, []string{late})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reprocessed.Reprocessed)
+	assert.Equal(t, 1, reprocessed.Resolved)
+	assert.Zero(t, reprocessed.StillUnmatched)
+	assert.Equal(t, 1, reprocessed.UnmatchedBank)
+	assert.Len(t, reprocessed.Results, 1)
+	assert.Equal(t, domain.Matched, reprocessed.Results[0].MatchStatus)
+	assert.Equal(t, 2, reprocessed.Job.TotalMatched)
+	assert.Zero(t, reprocessed.Job.TotalUnmatched)
+
+	results, _ := reconRepo.GetResultsByJobID(ctx, summary.JobID)
+	statuses := make(map[domain.MatchStatus]int)
+	for _, result := range results {
+		statuses[result.MatchStatus]++
+	}
+	assert.Equal(t, map[domain.MatchStatus]int{domain.Matched: 2}, statuses)
+
+	// Nothing is left to retry, so a repeat changes nothing
+	again, err := svc.ReprocessUnmatched(ctx, summary.JobID, []string{late})
+	assert.NoError(t, err)
+	assert.Zero(t, again.Reprocessed)
+	results, _ = reconRepo.GetResultsByJobID(ctx, summary.JobID)
+	assert.Len(t, results, 2)
+}
+
+func TestReconciliationService_ReprocessRejectsJobs(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+	head := "abc"
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+	reconRepo.jobs["chained"] = domain.ReconciliationJob{JobID: "chained", Status: domain.Completed, ResultChainHead: &head}
+
+	_, err := svc.ReprocessUnmatched(context.Background(), "running", []string{"bank.csv"})
+	assert.ErrorIs(t, err, service.ErrJobNotCompleted)
+	_, err = svc.ReprocessUnmatched(context.Background(), "chained", []string{"bank.csv"})
+	assert.ErrorIs(t, err, service.ErrJobChained)
+}
+
+func TestReconciliationHandler_ReprocessUnmatched(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing}
+
+	router := gin.New()
+	router.POST("/api/v1/reconcile/jobs/:job_id/reprocess", handler.NewReconciliationHandler(svc).ReprocessUnmatched)
+	post := func(jobID string, paths []string) int {
+		body, _ := json.Marshal(handler.ReprocessRequest{BankFilePaths: paths})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/jobs/"+jobID+"/reprocess", bytes.NewReader(body)))
+		return rec.Code
+	}
+
+	late := writeFile(t, t.TempDir(), "bank_late.csv", "trx_ref_id,amount,date\nTX002,200.00,2024-01-15\n")
+	assert.Equal(t, http.StatusUnprocessableEntity, post(summary.JobID, nil))
+	assert.Equal(t, http.StatusNotFound, post("missing", []string{late}))
+	assert.Equal(t, http.StatusConflict, post("running", []string{late}))
+	assert.Equal(t, http.StatusOK, post(summary.JobID, []string{late}))
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/middleware"
+	"recon-engine/pkg/logger"
+)
+
+func newRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Logger())
+	router.GET("/ping", func(c *gin.Context) {
+		logger.FromContext(c).Info("Handling ping")
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRequestID_ReusesHeader(t *testing.T) {
+	hook := test.NewLocal(logger.GetLogger())
+	defer hook.Reset()
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(logger.RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	newRequestIDRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, "abc-123", rec.Header().Get(logger.RequestIDHeader))
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2, "handler and request log lines")
+	for _, entry := range entries {
+		assert.Equal(t, "abc-123", entry.Data[logger.RequestIDKey], entry.Message)
+	}
+}
+
+func TestRequestID_GeneratesWhenMissingOrInvalid(t *testing.T) {
+	for name, header := range map[string]string{"missing": "", "invalid": "has spaces\tand tabs"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if header != "" {
+				req.Header.Set(logger.RequestIDHeader, header)
+			}
+			rec := httptest.NewRecorder()
+			newRequestIDRouter().ServeHTTP(rec, req)
+
+			_, err := uuid.Parse(rec.Header().Get(logger.RequestIDHeader))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFromContext_WithoutRequestID(t *testing.T) {
+	entry := logger.FromContext(context.Background())
+	assert.NotContains(t, entry.Data, logger.RequestIDKey)
+}
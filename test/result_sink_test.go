@@ -0,0 +1,262 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/kafka"
+)
+
+// proxyRequest is a produce request as a REST Proxy reads it
+type proxyRequest struct {
+	Records []struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	} `json:"records"`
+}
+
+// newKafkaProxy answers each produce request with respond and records what it received
+func newKafkaProxy(t *testing.T, respond func(call int, req proxyRequest, w http.ResponseWriter)) (*httptest.Server, *[]proxyRequest) {
+	var calls int32
+	var received []proxyRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1))
+		assert.Equal(t, "/topics/recon.exceptions", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		var req proxyRequest
+		assert.NoError(t, json.Unmarshal(body, &req))
+		received = append(received, req)
+		respond(call, req, w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+// writeOffsets acknowledges every record, failing those listed with errorCode
+func writeOffsets(w http.ResponseWriter, count int, errorCode int, failed ...int) {
+	offsets := make([]map[string]interface{}, count)
+	for i := range offsets {
+		offsets[i] = map[string]interface{}{"partition": 0, "offset": i, "error_code": nil, "error": nil}
+	}
+	for _, i := range failed {
+		offsets[i]["error_code"] = errorCode
+		offsets[i]["error"] = "leader not available"
+	}
+	w.Header().Set("Content-Type", "application/vnd.kafka.v2+json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"offsets": offsets})
+}
+
+func TestKafkaProducer_Produce(t *testing.T) {
+	messages := []kafka.Message{{Key: "job-1", Value: map[string]int{"n": 0}}, {Key: "job-1", Value: map[string]int{"n": 1}}, {Key: "job-1", Value: map[string]int{"n": 2}}}
+
+	// A 503, then the second record failing retriably, then success
+	server, received := newKafkaProxy(t, func(call int, req proxyRequest, w http.ResponseWriter) {
+		switch call {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			writeOffsets(w, len(req.Records), 1, 1)
+		default:
+			writeOffsets(w, len(req.Records), 0)
+		}
+	})
+	producer := kafka.NewProducer(server.URL+"/", kafka.WithRetries(3, time.Millisecond), kafka.WithBatchSize(3))
+	assert.NoError(t, producer.Produce(context.Background(), "recon.exceptions", messages))
+	if assert.Len(t, *received, 3) {
+		assert.Len(t, (*received)[1].Records, 3)
+		if assert.Len(t, (*received)[2].Records, 1, "only the failed record is resent") {
+			assert.Equal(t, "job-1", (*received)[2].Records[0].Key)
+			assert.JSONEq(t, `{"n": 1}`, string((*received)[2].Records[0].Value))
+		}
+	}
+
+	// A record the proxy rejects outright is not retried; later batches are not sent
+	server, received = newKafkaProxy(t, func(call int, req proxyRequest, w http.ResponseWriter) {
+		writeOffsets(w, len(req.Records), 2, 0)
+	})
+	producer = kafka.NewProducer(server.URL, kafka.WithRetries(3, time.Millisecond), kafka.WithBatchSize(2))
+	err := producer.Produce(context.Background(), "recon.exceptions", messages)
+	assert.ErrorContains(t, err, "after 0 of 3 messages")
+	assert.Len(t, *received, 1)
+}
+
+// captureProducer keeps every message produced
+type captureProducer struct {
+	topic    string
+	messages []kafka.Message
+}
+
+func (p *captureProducer) Produce(_ context.Context, topic string, messages []kafka.Message) error {
+	p.topic = topic
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func TestReconciliationService_KafkaSink(t *testing.T) {
+	ctx := context.Background()
+
+	// Kafka only: exceptions are published, the results table stays empty
+	producer := &captureProducer{}
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo,
+		service.WithRepositoryResults(false),
+		service.WithResultSinks(service.NewKafkaSink(producer, "recon.exceptions")),
+	)
+	summary, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.FlushSinks(ctx))
+	results, err := reconRepo.GetResultsByJobID(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	assert.Equal(t, "recon.exceptions", producer.topic)
+	var events []domain.ExceptionEvent
+	for _, message := range producer.messages {
+		assert.Equal(t, summary.JobID, message.Key)
+		events = append(events, message.Value.(domain.ExceptionEvent))
+	}
+	if assert.Len(t, events, 1, "one message per exception, none for the match") {
+		assert.Equal(t, domain.ExceptionEventVersion, events[0].SchemaVersion)
+		assert.Equal(t, summary.JobID+":0", events[0].EventID)
+		assert.Equal(t, domain.ExceptionRaised, events[0].EventType)
+		assert.Equal(t, domain.UnmatchedSystem, events[0].MatchStatus)
+		assert.Equal(t, "TX002", *events[0].TrxID)
+		assert.False(t, events[0].EmittedAt.IsZero())
+	}
+
+	// An unreachable broker is logged; the job completes and its results are saved
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	reconRepo = newMockReconciliationRepository()
+	svc = newJobLifecycleService(reconRepo,
+		service.WithResultSinks(service.NewKafkaSink(kafka.NewProducer(down.URL, kafka.WithRetries(1, time.Millisecond)), "recon.exceptions")),
+	)
+	summary, err = svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.FlushSinks(ctx))
+	job, err := reconRepo.GetJobByID(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Completed, job.Status)
+	results, err = reconRepo.GetResultsByJobID(ctx, summary.JobID)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+}
+
+// blockingProducer holds every delivery until release is closed
+type blockingProducer struct {
+	captureProducer
+	release chan struct{}
+}
+
+func (p *blockingProducer) Produce(ctx context.Context, topic string, messages []kafka.Message) error {
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.captureProducer.Produce(ctx, topic, messages)
+}
+
+func TestReconciliationService_SinksRunInBackground(t *testing.T) {
+	ctx := context.Background()
+	producer := &blockingProducer{release: make(chan struct{})}
+	svc := newJobLifecycleService(newMockReconciliationRepository(),
+		service.WithResultSinks(service.NewKafkaSink(producer, "recon.exceptions")),
+	)
+
+	// The job completes while the sink is still stuck
+	_, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, svc.FlushSinks(waitCtx), context.DeadlineExceeded)
+
+	close(producer.release)
+	assert.NoError(t, svc.FlushSinks(ctx))
+	assert.Len(t, producer.messages, 1)
+
+	// A delivery is abandoned once the sink timeout passes
+	stuck := &blockingProducer{release: make(chan struct{})}
+	svc = newJobLifecycleService(newMockReconciliationRepository(),
+		service.WithResultSinks(service.NewKafkaSink(stuck, "recon.exceptions")),
+		service.WithSinkTimeout(10*time.Millisecond),
+	)
+	_, err = svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.FlushSinks(ctx))
+	assert.Empty(t, stuck.messages)
+}
+
+func TestReconciliationService_SinksReceiveReplacements(t *testing.T) {
+	ctx := context.Background()
+	reconRepo := newManualMatchRepository(t)
+	producer := &captureProducer{}
+	svc := newJobLifecycleService(reconRepo,
+		service.WithResultSinks(service.NewKafkaSink(producer, "recon.exceptions")),
+	)
+
+	matched, err := svc.ManualMatch(ctx, "job-review", domain.ManualMatch{TrxID: "TX002", TrxRefID: "TX-0002", ReviewedBy: "alice"})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.FlushSinks(ctx))
+	assert.True(t, matched.Result.ManualMatch)
+
+	var events []domain.ExceptionEvent
+	for _, message := range producer.messages {
+		assert.Equal(t, "job-review", message.Key)
+		events = append(events, message.Value.(domain.ExceptionEvent))
+	}
+	if assert.Len(t, events, 2, "both replaced exceptions are resolved; the match raises nothing") {
+		assert.ElementsMatch(t, []domain.MatchStatus{domain.UnmatchedSystem, domain.UnmatchedBank}, []domain.MatchStatus{events[0].MatchStatus, events[1].MatchStatus})
+		for _, event := range events {
+			assert.Equal(t, domain.ExceptionResolved, event.EventType)
+			assert.Equal(t, domain.Matched, *event.ResolvedAs)
+			assert.Equal(t, "alice", *event.ReviewedBy)
+			assert.Regexp(t, `^job-review:resolved:\d+$`, event.EventID)
+		}
+		assert.NotEqual(t, events[0].EventID, events[1].EventID)
+	}
+}
+
+func TestReconciliationService_SinksReceiveReprocessedResults(t *testing.T) {
+	ctx := context.Background()
+	producer := &captureProducer{}
+	svc := newJobLifecycleService(newMockReconciliationRepository(),
+		service.WithResultSinks(service.NewKafkaSink(producer, "recon.exceptions")),
+	)
+	summary, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.FlushSinks(ctx))
+	producer.messages = nil
+
+	// TX002 now pairs, but with a different amount
+	late := writeFile(t, t.TempDir(), "bank_late.csv", "trx_ref_id,amount,date\nTX002,190.00,2024-01-15\n")
+	reprocessed, err := svc.ReprocessUnmatched(ctx, summary.JobID, []string{late})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reprocessed.Resolved)
+	assert.NoError(t, svc.FlushSinks(ctx))
+
+	if assert.Len(t, producer.messages, 2) {
+		resolved := producer.messages[0].Value.(domain.ExceptionEvent)
+		assert.Equal(t, domain.ExceptionResolved, resolved.EventType)
+		assert.Equal(t, domain.UnmatchedSystem, resolved.MatchStatus)
+		assert.Equal(t, domain.Discrepancy, *resolved.ResolvedAs)
+		assert.Nil(t, resolved.ReviewedBy)
+
+		raised := producer.messages[1].Value.(domain.ExceptionEvent)
+		assert.Equal(t, domain.ExceptionRaised, raised.EventType)
+		assert.Equal(t, domain.Discrepancy, raised.MatchStatus)
+		assert.Equal(t, "TX002", *raised.TrxID)
+		assert.NotEqual(t, resolved.EventID, raised.EventID)
+	}
+}
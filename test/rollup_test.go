@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func rollupDay(day int) time.Time {
+	return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newRollupService reconciles three daily jobs from the database:
+// day 1 has a match and an unmatched system transaction, day 2 a discrepancy
+// and an unmatched bank statement, and day 3 a match from another bank
+func newRollupService(t *testing.T) (service.ReconciliationService, *mockReconciliationRepository, []string) {
+	at := func(day int) time.Time { return rollupDay(day).Add(10 * time.Hour) }
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: at(1)},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(75.00), Type: domain.Credit, TransactionTime: at(1)},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: at(2)},
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(50.00), Type: domain.Credit, TransactionTime: at(3)},
+	}}
+	bankRepo := &mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: at(1), Source: "bank_a"},
+		{TrxRefID: "TX003", Amount: decimal.NewFromFloat(210.00), Date: at(2), Source: "bank_a"},
+		{TrxRefID: "BX001", Amount: decimal.NewFromFloat(30.00), Date: at(2), Source: "bank_a"},
+		{TrxRefID: "TX004", Amount: decimal.NewFromFloat(50.00), Date: at(3), Source: "bank_b"},
+	}}
+	reconRepo := newMockReconciliationRepository()
+	svc := service.NewReconciliationService(txRepo, reconRepo, 100, service.WithBankStatementRepository(bankRepo))
+
+	var jobIDs []string
+	for day := 1; day <= 3; day++ {
+		summary, err := svc.ReconcileFromDatabase(context.Background(), rollupDay(day), rollupDay(day), false)
+		assert.NoError(t, err)
+		jobIDs = append(jobIDs, summary.JobID)
+	}
+	return svc, reconRepo, jobIDs
+}
+
+func TestReconciliationService_RollupJobs(t *testing.T) {
+	svc, reconRepo, jobIDs := newRollupService(t)
+
+	rollup, err := svc.RollupJobs(context.Background(), jobIDs)
+	assert.NoError(t, err)
+
+	wantDiscrepancies := decimal.Zero
+	for _, jobID := range jobIDs {
+		wantDiscrepancies = wantDiscrepancies.Add(reconRepo.jobs[jobID].TotalDiscrepancies)
+	}
+
+	assert.Equal(t, jobIDs, rollup.JobIDs)
+	assert.Equal(t, rollupDay(1), rollup.StartDate)
+	assert.Equal(t, rollupDay(3), rollup.EndDate)
+	assert.Equal(t, 5, rollup.TotalResults)
+	assert.Equal(t, 2, rollup.TotalMatched)
+	assert.Equal(t, 2, rollup.TotalUnmatched)
+	assert.True(t, wantDiscrepancies.Equal(rollup.TotalDiscrepancies))
+	assert.False(t, rollup.TotalDiscrepancies.IsZero())
+	assert.Equal(t, 1, rollup.StatusCounts[domain.Discrepancy])
+	assert.Equal(t, 0, rollup.SupersededResults)
+
+	assert.Len(t, rollup.BySource, 2, "unmatched system results have no bank source")
+	assert.Equal(t, 3, rollup.BySource["bank_a"].TotalResults)
+	assert.Equal(t, 1, rollup.BySource["bank_a"].TotalMatched)
+	assert.Equal(t, 1, rollup.BySource["bank_a"].TotalUnmatched)
+	assert.Equal(t, 1, rollup.BySource["bank_b"].TotalMatched)
+}
+
+func TestReconciliationService_RollupDropsOverlappingResults(t *testing.T) {
+	svc, _, jobIDs := newRollupService(t)
+
+	// Re-running day 2 and a job over all three days repeat earlier results
+	rerun, err := svc.RerunJob(context.Background(), jobIDs[1])
+	assert.NoError(t, err)
+	whole, err := svc.ReconcileFromDatabase(context.Background(), rollupDay(1), rollupDay(3), false)
+	assert.NoError(t, err)
+
+	rollup, err := svc.RollupJobs(context.Background(), append(jobIDs, rerun.JobID, whole.JobID, jobIDs[0]))
+	assert.NoError(t, err)
+
+	assert.Len(t, rollup.JobIDs, 5, "repeated job IDs count once")
+	assert.Equal(t, whole.JobID, rollup.JobIDs[4], "jobs are ordered oldest first")
+	assert.Equal(t, 5, rollup.TotalResults)
+	assert.Equal(t, 2, rollup.TotalMatched)
+	assert.Equal(t, 2, rollup.TotalUnmatched)
+	assert.Equal(t, 7, rollup.SupersededResults, "every daily result is covered by the newer whole-range job")
+	assert.True(t, whole.TotalDiscrepancies.Equal(rollup.TotalDiscrepancies))
+}
+
+func TestReconciliationService_RollupDateRange(t *testing.T) {
+	svc, reconRepo, jobIDs := newRollupService(t)
+	reconRepo.jobs["running"] = domain.ReconciliationJob{JobID: "running", Status: domain.Processing, StartDate: rollupDay(2), EndDate: rollupDay(2)}
+
+	rollup, err := svc.RollupDateRange(context.Background(), rollupDay(1), rollupDay(2))
+	assert.NoError(t, err)
+	assert.Equal(t, jobIDs[:2], rollup.JobIDs, "only completed jobs within the range")
+	assert.Equal(t, 4, rollup.TotalResults)
+
+	_, err = svc.RollupDateRange(context.Background(), rollupDay(10), rollupDay(20))
+	assert.ErrorIs(t, err, service.ErrNoJobs)
+
+	_, err = svc.RollupJobs(context.Background(), []string{jobIDs[0], "running"})
+	assert.ErrorIs(t, err, service.ErrJobNotCompleted)
+}
+
+func TestRollupJobsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, _, jobIDs := newRollupService(t)
+	router := gin.New()
+	router.POST("/api/v1/reconcile/rollup", handler.NewReconciliationHandler(svc).RollupJobs)
+
+	post := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/rollup", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(map[string]interface{}{"start_date": "2024-01-01", "end_date": "2024-01-31"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Data domain.RollupSummary `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, jobIDs, resp.Data.JobIDs)
+
+	assert.Equal(t, http.StatusNotFound, post(map[string]interface{}{"job_ids": []string{jobIDs[0], "missing"}}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(map[string]interface{}{}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(map[string]interface{}{
+		"job_ids": jobIDs, "start_date": "2024-01-01", "end_date": "2024-01-31",
+	}).Code)
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/service"
+	"recon-engine/internal/storage"
+)
+
+// objectStore serves objects by path-style /bucket/key and records the
+// Authorization header of the last request
+type objectStore struct {
+	objects       map[string][]byte
+	authorization string
+	token         string
+}
+
+func (s *objectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.authorization = r.Header.Get("Authorization")
+	s.token = r.Header.Get("X-Amz-Security-Token")
+	content, ok := s.objects[r.URL.EscapedPath()]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code></Error>`)
+		return
+	}
+	w.Write(content)
+}
+
+func newS3Opener(t *testing.T, store *objectStore) parser.FileOpener {
+	t.Helper()
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+	return parser.SchemeOpener{Schemes: map[string]parser.FileOpener{
+		"s3": storage.NewS3Opener("eu-west-1",
+			storage.WithS3Endpoint(server.URL),
+			storage.WithS3Credentials(storage.S3Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}),
+		),
+	}}
+}
+
+func TestS3Opener_ParsesSignedObject(t *testing.T) {
+	store := &objectStore{objects: map[string][]byte{
+		"/statements/2024/bank%20a.csv": []byte("trx_ref_id,amount,date\nTX001,100.50,2024-01-15\n"),
+	}}
+	csvParser := parser.NewCSVBankStatementParser("BankA", parser.WithFileOpener(newS3Opener(t, store)))
+
+	var statements []domain.BankStatement
+	err := csvParser.Parse("s3://statements/2024/bank a.csv", 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 1) {
+		assert.Equal(t, "TX001", statements[0].TrxRefID)
+	}
+	assert.True(t, strings.HasPrefix(store.authorization, "AWS4-HMAC-SHA256 Credential=AKID/"), store.authorization)
+	assert.Contains(t, store.authorization, "/eu-west-1/s3/aws4_request")
+	assert.Equal(t, "token", store.token)
+}
+
+func TestS3Opener_ReportsMissingObject(t *testing.T) {
+	opener := newS3Opener(t, &objectStore{})
+
+	_, err := opener.Open("s3://statements/missing.csv")
+	assert.ErrorContains(t, err, "NoSuchKey")
+
+	_, err = opener.Open("s3://statements")
+	assert.ErrorContains(t, err, "s3://bucket/key")
+
+	_, err = opener.Open("ftp://statements/bank.csv")
+	assert.ErrorContains(t, err, "unsupported file URL scheme")
+}
+
+func TestReconciliationService_ReconcilesFromObjectStorage(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	entry, err := writer.Create("bank_b.csv")
+	assert.NoError(t, err)
+	io.WriteString(entry, "trx_ref_id,amount,date\nTX002,200.00,2024-01-15\n")
+	assert.NoError(t, writer.Close())
+
+	store := &objectStore{objects: map[string][]byte{
+		"/recon/system.csv":     []byte("trx_id,amount,type,transaction_time\nTX001,100.00,CREDIT,2024-01-15 10:00:00\nTX002,200.00,CREDIT,2024-01-15 11:00:00\n"),
+		"/recon/bank_a.csv":     []byte("trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n"),
+		"/recon/statements.zip": archive.Bytes(),
+	}}
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100,
+		service.WithParserOptions(parser.WithFileOpener(newS3Opener(t, store))))
+
+	summary, err := svc.Reconcile(context.Background(),
+		[]string{"s3://recon/system.csv"},
+		[]string{"s3://recon/bank_a.csv", "s3://recon/statements.zip"},
+		lifecycleDay, lifecycleDay, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalMatched)
+	assert.Equal(t, 0, summary.TotalUnmatched)
+}
@@ -0,0 +1,898 @@
+package test
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/matcher"
+	"recon-engine/internal/service"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestReconciliationService_SplitByDirection(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Debit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Debit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(400.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	reconRepo := newMockReconciliationRepository()
+
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", `trx_ref_id,amount,date
+TX001,-100.00,2024-01-15
+TX002,-250.00,2024-01-15
+TX003,300.00,2024-01-15
+TX999,50.00,2024-01-15
+`)
+
+	svc := service.NewReconciliationService(txRepo, reconRepo, 100, service.WithSplitByDirection(true))
+
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 23, 59, 59, 0, time.UTC), false)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, summary.Debits)
+	assert.NotNil(t, summary.Credits)
+
+	// Debits: TX001 matched, TX002 discrepancy of 50
+	assert.Equal(t, 4, summary.Debits.TotalProcessed)
+	assert.Equal(t, 1, summary.Debits.TotalMatched)
+	assert.Equal(t, 0, summary.Debits.TotalUnmatched)
+	assert.True(t, summary.Debits.TotalDiscrepancies.Equal(decimal.NewFromFloat(50.00)))
+	assert.True(t, summary.Debits.NetDiscrepancy.Equal(decimal.NewFromFloat(50.00)), "bank debited more than the system")
+
+	// Credits: TX003 matched, TX004 and TX999 unmatched
+	assert.Equal(t, 4, summary.Credits.TotalProcessed)
+	assert.Equal(t, 1, summary.Credits.TotalMatched)
+	assert.Equal(t, 2, summary.Credits.TotalUnmatched)
+	assert.True(t, summary.Credits.TotalDiscrepancies.IsZero())
+
+	// Combined totals
+	assert.Equal(t, 8, summary.TotalProcessed)
+	assert.Equal(t, 2, summary.TotalMatched)
+	assert.Equal(t, 2, summary.TotalUnmatched)
+	assert.True(t, summary.TotalDiscrepancies.Equal(decimal.NewFromFloat(50.00)))
+}
+
+func TestReconciliationService_PerSource(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	dir := t.TempDir()
+	bankA := writeFile(t, dir, "bank_a.csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTX002,250.00,2024-01-15\n")
+	bankB := writeFile(t, dir, "bank_b.csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTXB99,5.00,2024-01-15\n")
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100, service.WithPerSource(true))
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankA, bankB}, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+
+	// TX001 is matched within each bank; neither bank sees the other's rows
+	a, b := summary.BySource["bank_a.csv"], summary.BySource["bank_b.csv"]
+	if assert.NotNil(t, a) && assert.NotNil(t, b) {
+		assert.Equal(t, 5, a.TotalProcessed)
+		assert.Equal(t, 1, a.TotalMatched)
+		assert.Len(t, a.Discrepancies, 1)
+		assert.Len(t, a.UnmatchedSystem, 1, "TX003")
+		assert.Equal(t, 1, b.TotalMatched)
+		assert.Len(t, b.UnmatchedSystem, 2, "TX002 and TX003 are not in bank_b")
+		assert.Len(t, b.UnmatchedBank["bank_b.csv"], 1)
+	}
+
+	// Combined: a system transaction is unmatched only if no bank matched it
+	assert.Equal(t, 7, summary.TotalProcessed)
+	assert.Equal(t, 2, summary.TotalMatched)
+	assert.Len(t, summary.Discrepancies, 1)
+	if assert.Len(t, summary.UnmatchedSystem, 1) {
+		assert.Equal(t, "TX003", *summary.UnmatchedSystem[0].TrxID)
+	}
+	assert.Equal(t, 2, summary.TotalUnmatched)
+}
+
+func TestReconciliationService_EndDateIncludesWholeDay(t *testing.T) {
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	lastInstant := time.Date(2024, 1, 15, 23, 59, 59, 999000000, time.UTC)
+	nextMidnight := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: startOfDay},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: lastInstant},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: nextMidnight},
+	}}
+
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,200.00,2024-01-15
+TX003,300.00,2024-01-16
+`)
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+
+	// Day one: the 23:59:59.999 transaction is kept, next midnight is not
+	day1, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, day1.TotalMatched)
+	assert.Equal(t, 0, day1.TotalUnmatched)
+
+	// Day two: next midnight belongs here only, so nothing is double-counted
+	day2, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, nextMidnight, nextMidnight, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, day2.TotalMatched)
+	assert.Equal(t, 0, day2.TotalUnmatched)
+}
+
+func TestReconciliationService_EndDateIncludesWholeDayFromCSV(t *testing.T) {
+	dir := t.TempDir()
+	systemFile := writeFile(t, dir, "system.csv", `trx_id,amount,type,transaction_time
+TX001,100.00,CREDIT,2024-01-15T00:00:00Z
+TX002,200.00,CREDIT,2024-01-15T23:59:59.999Z
+TX003,300.00,CREDIT,2024-01-16T00:00:00Z
+`)
+	bankFile := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,200.00,2024-01-15
+`)
+
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	summary, err := svc.Reconcile(context.Background(), []string{systemFile}, []string{bankFile}, day, day, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalMatched)
+	assert.Equal(t, 0, summary.TotalUnmatched, "TX003 falls on the next day and must be excluded")
+}
+
+func TestReconciliationService_MultipleSystemFiles(t *testing.T) {
+	dir := t.TempDir()
+	north := writeFile(t, dir, "system_north.csv", `trx_id,amount,type,transaction_time
+TX001,100.00,CREDIT,2024-01-15T09:00:00Z
+TX002,200.00,CREDIT,2024-01-15T10:00:00Z
+`)
+	south := writeFile(t, dir, "system_south.csv", `trx_id,amount,type,transaction_time
+TX003,300.00,CREDIT,2024-01-15T11:00:00Z
+TX002,200.00,CREDIT,2024-01-15T10:00:00Z
+`)
+	bankFile := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,200.00,2024-01-15
+TX003,300.00,2024-01-15
+`)
+
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+
+	summary, err := svc.Reconcile(context.Background(), []string{north, south}, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.TotalMatched)
+	assert.Len(t, summary.DuplicateSystem, 1)
+	assert.Equal(t, []domain.DuplicateTransaction{
+		{TrxID: "TX002", Files: []string{"system_north.csv", "system_south.csv"}},
+	}, summary.CrossFileDuplicates)
+
+	_, err = svc.Reconcile(context.Background(), []string{north, filepath.Join(dir, "missing.csv")}, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+	assert.ErrorContains(t, err, "missing.csv")
+}
+
+func TestReconciliationService_DuplicatePolicyError(t *testing.T) {
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX001,100.00,2024-01-15
+`)
+	reconRepo := newMockReconciliationRepository()
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100,
+		service.WithEngineOptions(matcher.WithDuplicatePolicy(matcher.DuplicateError)))
+
+	_, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+	assert.ErrorContains(t, err, "duplicate bank reference IDs: TX001")
+	for _, job := range reconRepo.jobs {
+		assert.Equal(t, domain.Failed, job.Status)
+	}
+}
+
+func TestReconciliationService_DetectsSignConventions(t *testing.T) {
+	bankFile := writeFile(t, t.TempDir(), "bank_flip.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,-200.00,2024-01-15
+TX003,300.00,2024-01-15
+TX004,-400.00,2024-01-15
+TX005,500.00,2024-01-15
+`)
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{}
+	for i := 1; i <= 5; i++ {
+		txType := domain.Credit
+		if i%2 == 1 {
+			txType = domain.Debit
+		}
+		txRepo.transactions = append(txRepo.transactions, domain.Transaction{
+			TrxID: fmt.Sprintf("TX%03d", i), Amount: decimal.NewFromInt(int64(i * 100)), Type: txType, TransactionTime: day,
+		})
+	}
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100,
+		service.WithEngineOptions(matcher.WithBankAmountConventions(matcher.BankAmountConventions{
+			BySource: map[string]matcher.BankAmountConvention{"bank_flip.csv": matcher.BankAmountAuto},
+		})))
+
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, lifecycleDay, lifecycleDay, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, summary.TotalMatched)
+	assert.Equal(t, map[string]domain.SignConvention{
+		"bank_flip.csv": {Convention: "debits_positive", Detected: true, Pairs: 5, Agreement: 1},
+	}, summary.SignConventions)
+}
+
+func TestReconciliationService_ReportsRowErrors(t *testing.T) {
+	dir := t.TempDir()
+	systemFile := writeFile(t, dir, "system.csv", `trx_id,amount,type,transaction_time
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z
+TX002,abc,CREDIT,2024-01-15T10:00:00Z
+`)
+	bankFile := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,,2024-01-15
+`)
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+
+	summary, err := svc.Reconcile(context.Background(), []string{systemFile}, []string{bankFile}, lifecycleDay, lifecycleDay, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMatched)
+	if assert.Len(t, summary.SystemFileReport, 1) && assert.Len(t, summary.SystemFileReport[0].RowErrors, 1) {
+		assert.Equal(t, 1, summary.SystemFileReport[0].SkippedRows)
+		assert.Equal(t, 3, summary.SystemFileReport[0].RowErrors[0].Line)
+		assert.Equal(t, "TX002,abc,CREDIT,2024-01-15T10:00:00Z", summary.SystemFileReport[0].RowErrors[0].Raw)
+	}
+	if assert.Len(t, summary.FileLoadReport, 1) && assert.Len(t, summary.FileLoadReport[0].RowErrors, 1) {
+		assert.Equal(t, 3, summary.FileLoadReport[0].RowErrors[0].Line)
+		assert.Equal(t, "TX002,,2024-01-15", summary.FileLoadReport[0].RowErrors[0].Raw)
+	}
+}
+
+func TestReconciliationService_CarriesDescriptions(t *testing.T) {
+	dir := t.TempDir()
+	systemFile := writeFile(t, dir, "system.csv", `trx_id,amount,type,transaction_time,description
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z,Invoice 42
+TX002,200.00,CREDIT,2024-01-15T10:00:00Z,
+`)
+	bankFile := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date,description
+TX001,100.00,2024-01-15,ACME Coffee
+TX009,50.00,2024-01-15,Unknown merchant
+`)
+	reconRepo := newMockReconciliationRepository()
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100)
+
+	summary, err := svc.Reconcile(context.Background(), []string{systemFile}, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	if assert.Len(t, summary.UnmatchedSystem, 1) {
+		assert.Nil(t, summary.UnmatchedSystem[0].Description, "an empty description is not stored")
+	}
+	var unmatchedBank []domain.ReconciliationResult
+	for _, results := range summary.UnmatchedBank {
+		unmatchedBank = append(unmatchedBank, results...)
+	}
+	if assert.Len(t, unmatchedBank, 1) {
+		assert.Equal(t, "Unknown merchant", *unmatchedBank[0].BankDescription)
+	}
+
+	results, err := reconRepo.GetResultsByJobID(context.Background(), summary.JobID)
+	assert.NoError(t, err)
+	matched := 0
+	for _, result := range results {
+		if result.MatchStatus == domain.Matched {
+			matched++
+			assert.Equal(t, "Invoice 42", *result.Description)
+			assert.Equal(t, "ACME Coffee", *result.BankDescription)
+		}
+	}
+	assert.Equal(t, 1, matched, "descriptions are saved with the results")
+}
+
+func TestReconciliationService_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	bankFile := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,250.00,2024-01-15
+TX999,5.00,2024-01-15
+`)
+
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	reconRepo := newMockReconciliationRepository()
+	svc := service.NewReconciliationService(txRepo, reconRepo, 100)
+
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, true)
+	assert.NoError(t, err)
+	assert.True(t, summary.DryRun)
+	assert.Empty(t, summary.JobID)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.Equal(t, 1, summary.TotalUnmatched)
+	assert.Equal(t, 1, len(summary.Discrepancies))
+	assert.Equal(t, 1, len(summary.UnmatchedBank["bank_a.csv"]))
+
+	assert.Empty(t, reconRepo.jobs, "a dry run must not create a job")
+	assert.Empty(t, reconRepo.results, "a dry run must not save results")
+}
+
+func TestReconciliationService_ReconcileFromDatabase(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	nextDay := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX004", Amount: decimal.NewFromFloat(400.00), Type: domain.Credit, TransactionTime: nextDay},
+	}}
+	bankRepo := &mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "bank_a"},
+		{TrxRefID: "TX002", Amount: decimal.NewFromFloat(250.00), Date: day, Source: "bank_a"},
+		{TrxRefID: "TX999", Amount: decimal.NewFromFloat(50.00), Date: day, Source: "bank_b"},
+		{TrxRefID: "TX004", Amount: decimal.NewFromFloat(400.00), Date: nextDay, Source: "bank_b"},
+	}}
+	reconRepo := newMockReconciliationRepository()
+
+	svc := service.NewReconciliationService(txRepo, reconRepo, 2, service.WithBankStatementRepository(bankRepo))
+
+	summary, err := svc.ReconcileFromDatabase(context.Background(), day, day, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bankRepo.batches, "bank statements should be read in batches")
+
+	assert.Equal(t, 6, summary.TotalProcessed)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.Equal(t, 2, summary.TotalUnmatched)
+	assert.True(t, summary.TotalDiscrepancies.Equal(decimal.NewFromFloat(50.00)))
+	assert.True(t, summary.NetDiscrepancy.Equal(decimal.NewFromFloat(-50.00)), "bank credited more than the system")
+	if assert.Len(t, summary.Discrepancies, 1) {
+		assert.True(t, summary.Discrepancies[0].SignedDiscrepancy.Equal(decimal.NewFromFloat(-50.00)))
+	}
+	assert.Len(t, summary.UnmatchedSystem, 1)
+	assert.Equal(t, "TX003", *summary.UnmatchedSystem[0].TrxID)
+	assert.Len(t, summary.UnmatchedBank["bank_b"], 1)
+	assert.Equal(t, "TX999", *summary.UnmatchedBank["bank_b"][0].TrxRefID)
+
+	job, err := reconRepo.GetJobByID(context.Background(), summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Completed, job.Status)
+}
+
+func TestReconciliationService_ReconcileStreaming(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	bankFile := writeFile(t, dir, "bank_a.csv", "trx_ref_id,amount,date\n"+
+		"TX001,100.00,2024-01-15\n"+
+		"TX002,250.00,2024-01-15\n"+
+		"TX999,50.00,2024-01-15\n")
+
+	reconcile := func(opts ...service.ServiceOption) (*domain.ReconciliationSummary, *mockReconciliationRepository) {
+		txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+			{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+			{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+			{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+		}}
+		reconRepo := newMockReconciliationRepository()
+		svc := service.NewReconciliationService(txRepo, reconRepo, 2, opts...)
+		summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, lifecycleDay, lifecycleDay, false)
+		assert.NoError(t, err)
+		return summary, reconRepo
+	}
+
+	inMemory, _ := reconcile()
+	streamed, reconRepo := reconcile(service.WithStreaming(true))
+
+	assert.Equal(t, 6, streamed.TotalProcessed)
+	assert.Equal(t, inMemory.TotalMatched, streamed.TotalMatched)
+	assert.Equal(t, inMemory.TotalUnmatched, streamed.TotalUnmatched)
+	assert.True(t, streamed.TotalDiscrepancies.Equal(inMemory.TotalDiscrepancies))
+	assert.Len(t, streamed.UnmatchedSystem, 1)
+	assert.Len(t, streamed.UnmatchedBank["bank_a.csv"], 1)
+	if assert.NotNil(t, streamed.BalanceDiscrepancy) {
+		assert.Equal(t, inMemory.BalanceDiscrepancy, streamed.BalanceDiscrepancy)
+		assert.True(t, streamed.BalanceDiscrepancy.SystemNet.Equal(decimal.NewFromInt(600)))
+		assert.True(t, streamed.BalanceDiscrepancy.BankNet.Equal(decimal.NewFromInt(400)))
+		assert.True(t, streamed.BalanceDiscrepancy.Difference.Equal(decimal.NewFromInt(200)))
+		assert.False(t, streamed.BalanceDiscrepancy.Balanced)
+	}
+
+	// Four results saved two at a time
+	assert.Len(t, reconRepo.results, 4)
+	assert.Equal(t, 2, reconRepo.resultBatches, "results should be saved in batches")
+}
+
+func benchmarkServiceReconcile(b *testing.B, opts ...service.ServiceOption) {
+	input := largeReconciliationInput(100000)
+	dir := b.TempDir()
+	var bankCSV strings.Builder
+	bankCSV.WriteString("trx_ref_id,amount,date\n")
+	for _, stmt := range input.BankStatements {
+		fmt.Fprintf(&bankCSV, "%s,%s,2024-01-15\n", stmt.TrxRefID, stmt.Amount.String())
+	}
+	bankFile := filepath.Join(dir, "bank.csv")
+	if err := os.WriteFile(bankFile, []byte(bankCSV.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	txRepo := &mockTransactionRepository{transactions: input.SystemTransactions}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 10000, opts...)
+		if _, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, lifecycleDay, lifecycleDay, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkServiceReconcile_InMemory(b *testing.B) { benchmarkServiceReconcile(b) }
+
+func BenchmarkServiceReconcile_Streaming(b *testing.B) {
+	benchmarkServiceReconcile(b, service.WithStreaming(true))
+}
+
+// writeBankFiles writes n small bank files, each with rows statements
+func writeBankFiles(tb testing.TB, dir string, n, rows int) []string {
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		var content strings.Builder
+		content.WriteString("trx_ref_id,amount,date\n")
+		for j := 0; j < rows; j++ {
+			fmt.Fprintf(&content, "B%03d-%03d,%d.00,2024-01-15\n", i, j, j+1)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("bank_%03d.csv", i))
+		if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestReconciliationService_BankFileWorkers(t *testing.T) {
+	bankFiles := writeBankFiles(t, t.TempDir(), 20, 3)
+	bankFiles = append(bankFiles[:10], append([]string{"/missing/bank.csv"}, bankFiles[10:]...)...)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "B005-001", Amount: decimal.NewFromInt(2), Type: domain.Credit, TransactionTime: lifecycleDay},
+	}}
+	reconcile := func(workers int) *domain.ReconciliationSummary {
+		svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100, service.WithBankFileWorkers(workers))
+		summary, err := svc.Reconcile(context.Background(), nil, bankFiles, lifecycleDay, lifecycleDay, true)
+		assert.NoError(t, err)
+		return summary
+	}
+
+	sequential := reconcile(1)
+	parallel := reconcile(8)
+	if assert.Len(t, parallel.FileLoadReport, 21) {
+		assert.Equal(t, sequential.FileLoadReport, parallel.FileLoadReport, "reports keep the file order")
+		assert.Equal(t, bankFiles[0], parallel.FileLoadReport[0].File)
+		assert.NotEmpty(t, parallel.FileLoadReport[10].Error)
+	}
+	assert.True(t, parallel.Incomplete)
+	assert.Equal(t, 1, parallel.TotalMatched)
+	assert.Equal(t, sequential.UnmatchedBank, parallel.UnmatchedBank)
+}
+
+func benchmarkLoadBankFiles(b *testing.B, workers int) {
+	bankFiles := writeBankFiles(b, b.TempDir(), 200, 50)
+	txRepo := &mockTransactionRepository{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 1000, service.WithBankFileWorkers(workers))
+		if _, err := svc.Reconcile(context.Background(), nil, bankFiles, lifecycleDay, lifecycleDay, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadBankFiles_Sequential(b *testing.B) { benchmarkLoadBankFiles(b, 1) }
+
+func BenchmarkLoadBankFiles_Parallel(b *testing.B) { benchmarkLoadBankFiles(b, 8) }
+
+func TestReconciliationService_ReconcileFromDatabaseRequiresRepository(t *testing.T) {
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	_, err := svc.ReconcileFromDatabase(context.Background(), day, day, false)
+	assert.Error(t, err)
+}
+
+func TestDayAfter(t *testing.T) {
+	day := time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), domain.DayAfter(day))
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), domain.DayAfter(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestReconciliationService_GetJobResultsPaged(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	var transactions []domain.Transaction
+	csv := "trx_ref_id,amount,date\n"
+	for i := 1; i <= 5; i++ {
+		id := fmt.Sprintf("TX%03d", i)
+		transactions = append(transactions, domain.Transaction{TrxID: id, Amount: decimal.NewFromInt(int64(i)), Type: domain.Credit, TransactionTime: day})
+		csv += fmt.Sprintf("%s,%d.50,2024-01-15\n", id, i)
+	}
+	csv += "TX999,1.00,2024-01-15\n"
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", csv)
+
+	svc := service.NewReconciliationService(&mockTransactionRepository{transactions: transactions}, newMockReconciliationRepository(), 100)
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+
+	page, err := svc.GetJobResults(context.Background(), summary.JobID, domain.ResultFilter{Status: domain.Discrepancy}, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, 3, page.TotalPages)
+	assert.Equal(t, 2, len(page.Results))
+	assert.Equal(t, "TX003", *page.Results[0].TrxID)
+
+	last, err := svc.GetJobResults(context.Background(), summary.JobID, domain.ResultFilter{Status: domain.Discrepancy}, 3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(last.Results))
+
+	all, err := svc.GetJobResults(context.Background(), summary.JobID, domain.ResultFilter{}, 1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, all.Total)
+
+	_, err = svc.GetJobResults(context.Background(), "missing", domain.ResultFilter{}, 1, 10)
+	assert.Error(t, err)
+}
+
+func TestReconciliationService_GetJobResultsFiltered(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transactions := []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromInt(500), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromInt(12000), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromInt(25000), Type: domain.Credit, TransactionTime: day},
+	}
+	dir := t.TempDir()
+	bankA := writeFile(t, dir, "bank_a.csv", "trx_ref_id,amount,date\nTX001,450.00,2024-01-15\nTX002,11000.00,2024-01-15\n")
+	bankB := writeFile(t, dir, "bank_b.csv", "trx_ref_id,amount,date\nTX003,24000.00,2024-01-15\nBX001,-15000.00,2024-01-15\n")
+
+	svc := service.NewReconciliationService(&mockTransactionRepository{transactions: transactions}, newMockReconciliationRepository(), 100)
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankA, bankB}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+
+	tenThousand := decimal.NewFromInt(10000)
+	twentyThousand := decimal.NewFromInt(20000)
+	trxRefs := func(filter domain.ResultFilter) []string {
+		page, err := svc.GetJobResults(context.Background(), summary.JobID, filter, 1, 100)
+		assert.NoError(t, err)
+		assert.NotNil(t, page.Results)
+		refs := make([]string, 0, len(page.Results))
+		for _, result := range page.Results {
+			refs = append(refs, *result.TrxRefID)
+		}
+		return refs
+	}
+
+	assert.Equal(t, []string{"TX003"}, trxRefs(domain.ResultFilter{Status: domain.Discrepancy, BankSource: "bank_b.csv", MinAmount: &tenThousand}))
+	assert.ElementsMatch(t, []string{"TX002", "TX003"}, trxRefs(domain.ResultFilter{Status: domain.Discrepancy, MinAmount: &tenThousand}))
+	assert.Equal(t, []string{"TX001", "TX002"}, trxRefs(domain.ResultFilter{Status: domain.Discrepancy, MaxAmount: &twentyThousand}))
+	// Unmatched bank lines are compared by their bank amount's magnitude
+	assert.Equal(t, []string{"BX001"}, trxRefs(domain.ResultFilter{Status: domain.UnmatchedBank, MinAmount: &tenThousand, MaxAmount: &twentyThousand}))
+	assert.Empty(t, trxRefs(domain.ResultFilter{BankSource: "bank_z.csv"}))
+}
+
+func writeZip(t *testing.T, dir, name string, entries [][2]string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for _, entry := range entries {
+		w, err := zw.Create(entry[0])
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(entry[1]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+	return path
+}
+
+func TestReconciliationService_ForStrategy(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", "trx_ref_id,amount,date\ntx-001,100.00,2024-01-15\n")
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+
+	reconcile := func(svc service.ReconciliationService) *domain.ReconciliationSummary {
+		summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile},
+			time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true)
+		assert.NoError(t, err)
+		return summary
+	}
+
+	normalized, err := svc.ForStrategy("normalized")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reconcile(normalized).TotalMatched)
+	assert.Equal(t, 0, reconcile(svc).TotalMatched, "the default exact strategy is unchanged")
+
+	_, err = svc.ForStrategy("fuzzy")
+	assert.ErrorIs(t, err, matcher.ErrUnknownStrategy)
+}
+
+func TestReconciliationService_ZipArchive(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+	}}
+
+	dir := t.TempDir()
+	archive := writeZip(t, dir, "daily.zip", [][2]string{
+		{"2024-01-15/bank_a.csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTXA99,5.00,2024-01-15\n"},
+		{"2024-01-15/bank_b.csv", "trx_ref_id,amount,date\nTX002,200.00,2024-01-15\nTXB99,7.00,2024-01-15\nTXB98,8.00,2024-01-15\n"},
+		{"__MACOSX/2024-01-15/._bank_a.csv", "junk"},
+		{"README.txt", "not a bank file"},
+	})
+	plain := writeFile(t, dir, "bank_c.csv", "trx_ref_id,amount,date\nTX003,300.00,2024-01-15\n")
+
+	before, _ := filepath.Glob(filepath.Join(os.TempDir(), "recon-archive-*"))
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{archive, plain},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.TotalMatched)
+	assert.Len(t, summary.UnmatchedBank, 2, "only the two archive entries have unmatched rows")
+	assert.Len(t, summary.UnmatchedBank["bank_a.csv"], 1)
+	assert.Len(t, summary.UnmatchedBank["bank_b.csv"], 2)
+
+	after, _ := filepath.Glob(filepath.Join(os.TempDir(), "recon-archive-*"))
+	assert.Equal(t, len(before), len(after), "extracted files should be removed")
+}
+
+func TestReconciliationService_GzipBankFileKeepsPlainSource(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+	}}
+
+	bankFile := filepath.Join(t.TempDir(), "bank_a.csv.gz")
+	writeGzip(t, bankFile, "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTXA99,5.00,2024-01-15\n")
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.Len(t, summary.UnmatchedBank["bank_a.csv"], 1, "the .gz suffix is not part of the source")
+}
+
+func TestReconciliationService_FileLoadReport(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+
+	dir := t.TempDir()
+	good := writeFile(t, dir, "bank_a.csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTX002,200.00,2024-01-15\n")
+	bad := writeFile(t, dir, "bank_b.csv", "id,value\n1,100\n")
+	archive := writeZip(t, dir, "daily.zip", [][2]string{
+		{"2024-01-15/bank_c.csv", "trx_ref_id,amount,date\nTX003,5.00,2024-01-15\n"},
+	})
+	missing := filepath.Join(dir, "missing.zip")
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{good, bad, archive, missing},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false)
+
+	assert.NoError(t, err, "the job completes with the files that loaded")
+	assert.Equal(t, 2, summary.TotalMatched)
+	assert.True(t, summary.Incomplete)
+
+	reports := make(map[string]domain.FileLoadReport)
+	for _, report := range summary.FileLoadReport {
+		reports[report.File] = report
+	}
+	assert.Len(t, reports, 4)
+	assert.Equal(t, domain.FileLoadReport{File: good, Source: "bank_a.csv", Rows: 2}, reports[good])
+	assert.Equal(t, 0, reports[bad].Rows)
+	assert.Contains(t, reports[bad].Error, "missing required columns")
+	assert.Equal(t, 1, reports[archive+"/2024-01-15/bank_c.csv"].Rows)
+	assert.Equal(t, "bank_c.csv", reports[archive+"/2024-01-15/bank_c.csv"].Source)
+	assert.NotEmpty(t, reports[missing].Error)
+}
+
+func TestReconciliationService_FileLoadReportComplete(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n")
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false)
+
+	assert.NoError(t, err)
+	assert.False(t, summary.Incomplete)
+	assert.Len(t, summary.FileLoadReport, 1)
+}
+
+func TestReconciliationService_ZipArchiveSizeLimit(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+	}}
+
+	archive := writeZip(t, t.TempDir(), "daily.zip", [][2]string{
+		{"bank_a.csv", "trx_ref_id,amount,date\n" + strings.Repeat("TX001,100.00,2024-01-15\n", 100)},
+	})
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100, service.WithMaxArchiveSize(256))
+	_, err := svc.Reconcile(context.Background(), nil, []string{archive},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false)
+
+	assert.EqualError(t, err, "no bank statements loaded")
+}
+
+func TestReconciliationService_PersistsBankStatements(t *testing.T) {
+	dir := t.TempDir()
+	bankFile := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+TX002,250.00,2024-01-15
+TX003,75.00,2024-01-20
+`)
+
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	bankRepo := &mockBankStatementRepository{}
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100,
+		service.WithBankStatementRepository(bankRepo),
+		service.WithBankStatementPersistence(true))
+
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	_, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, true)
+	assert.NoError(t, err)
+	assert.Empty(t, bankRepo.statements, "a dry run must not save bank statements")
+
+	summary, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+	assert.Len(t, bankRepo.statements, 3, "every loaded line is kept, including those outside the range")
+	for _, stmt := range bankRepo.statements {
+		assert.Equal(t, summary.JobID, stmt.JobID)
+		assert.Equal(t, "bank_a.csv", stmt.Source)
+	}
+
+	// The stored statements reconcile again without the original file
+	assert.NoError(t, os.Remove(bankFile))
+	rerun, err := svc.RerunJob(context.Background(), summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, summary.TotalMatched, rerun.TotalMatched)
+	assert.True(t, summary.TotalDiscrepancies.Equal(rerun.TotalDiscrepancies))
+}
+
+func TestReconciliationService_ForBatchSize(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+
+	for _, size := range []int{-1, service.MaxBatchSize + 1} {
+		_, err := svc.ForBatchSize(size)
+		assert.Error(t, err, size)
+	}
+
+	// Two results saved one at a time; the configured size would save both at once
+	scoped, err := svc.ForBatchSize(1)
+	assert.NoError(t, err)
+	_, err = scoped.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Len(t, reconRepo.results, 2)
+	assert.Equal(t, 2, reconRepo.resultBatches)
+}
+
+func TestReconciliationService_ForLedgerBalance(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+
+	opening := decimal.NewFromInt(1000)
+	_, err := svc.ForLedgerBalance(&opening, nil)
+	assert.Error(t, err, "both balances are required")
+
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	assert.Nil(t, summary.LedgerBalance)
+
+	// TX001 is the only bank line and it matched, so the bank should show 1100
+	closing := decimal.NewFromInt(1150)
+	scoped, err := svc.ForLedgerBalance(&opening, &closing)
+	assert.NoError(t, err)
+	summary, err = scoped.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, summary.LedgerBalance) {
+		assert.True(t, summary.LedgerBalance.Expected.Equal(decimal.NewFromInt(1100)))
+		assert.True(t, summary.LedgerBalance.Difference.Equal(decimal.NewFromInt(50)))
+		assert.False(t, summary.LedgerBalance.Balanced)
+	}
+}
+
+func TestReconciliationService_GetJobSummaryMinDiscrepancy(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	job := &domain.ReconciliationJob{JobID: "job-noise", Status: domain.Completed, TotalDiscrepancies: decimal.NewFromFloat(25.75)}
+	assert.NoError(t, reconRepo.CreateJob(context.Background(), job))
+	for i, gap := range []float64{0.25, 0.50, 25.00} {
+		id := fmt.Sprintf("TX%03d", i)
+		discrepancy := decimal.NewFromFloat(gap)
+		assert.NoError(t, reconRepo.CreateResult(context.Background(), &domain.ReconciliationResult{
+			JobID: job.JobID, TrxID: &id, Discrepancy: &discrepancy, MatchStatus: domain.Discrepancy,
+		}))
+	}
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100)
+
+	summary, err := svc.GetJobSummary(context.Background(), job.JobID, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, summary.Discrepancies, 3)
+
+	threshold := decimal.NewFromFloat(0.50)
+	summary, err = svc.GetJobSummary(context.Background(), job.JobID, &threshold, nil)
+	assert.NoError(t, err)
+	assert.Len(t, summary.Discrepancies, 2, "the threshold is inclusive")
+	assert.True(t, summary.TotalDiscrepancies.Equal(decimal.NewFromFloat(25.75)), "totals still cover every discrepancy")
+}
+
+func TestReconciliationService_GetJobSummaryMinConfidence(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	job := &domain.ReconciliationJob{JobID: "job-review", Status: domain.Completed}
+	assert.NoError(t, reconRepo.CreateJob(context.Background(), job))
+	for i, score := range []float64{0.5, 0.9, 1} {
+		id := fmt.Sprintf("TX%03d", i)
+		confidence := score
+		assert.NoError(t, reconRepo.CreateResult(context.Background(), &domain.ReconciliationResult{
+			JobID: job.JobID, TrxID: &id, Confidence: &confidence, MatchStatus: domain.DateMismatch,
+		}))
+	}
+	unmatched := "TX100"
+	assert.NoError(t, reconRepo.CreateResult(context.Background(), &domain.ReconciliationResult{
+		JobID: job.JobID, TrxID: &unmatched, MatchStatus: domain.UnmatchedSystem,
+	}))
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100)
+
+	threshold := 0.9
+	summary, err := svc.GetJobSummary(context.Background(), job.JobID, nil, &threshold)
+	assert.NoError(t, err)
+	assert.Len(t, summary.DateMismatches, 2, "the threshold is inclusive")
+	assert.Len(t, summary.UnmatchedSystem, 1, "unpaired results are listed regardless")
+}
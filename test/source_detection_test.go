@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/service"
+)
+
+var testFingerprints = []parser.SourceFingerprint{
+	{Source: "bank_bri.csv", Headers: []string{"Ref_No", "posting_date"}},
+	{Source: "bank_bca.csv", MarkerColumn: "bank", MarkerValue: "BCA"},
+	{Source: "bank_mandiri.csv", MarkerColumn: "bank", MarkerValue: "MANDIRI"},
+}
+
+func TestDetectSource(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name, content, want string
+		found               bool
+	}{
+		{"header signature", "ref_no,value,posting_date\nTX001,100.00,2024-01-15\n", "bank_bri.csv", true},
+		{"marker value", "trx_ref_id,amount,date,bank\nTX001,100.00,2024-01-15, mandiri \n", "bank_mandiri.csv", true},
+		{"header after a preamble", "Statement of account\n\nREF_NO,value,POSTING_DATE\n", "bank_bri.csv", true},
+		{"marker column without data", "trx_ref_id,amount,date,bank\n", "", false},
+		{"no fingerprint", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, "export.csv", tt.content)
+			source, found, err := parser.DetectSource(path, testFingerprints)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, source)
+		})
+	}
+}
+
+func TestDetectSource_XLSXAndGzip(t *testing.T) {
+	dir := t.TempDir()
+
+	xlsxFile := filepath.Join(dir, "download.xlsx")
+	writeXLSX(t, xlsxFile, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>3</v></c></row>
+<row r="2"><c r="A2" t="s"><v>4</v></c><c r="B2"><v>100</v></c><c r="C2"><v>45306</v></c><c r="D2" t="s"><v>5</v></c></row>
+`, []string{"trx_ref_id", "amount", "date", "bank", "TX001", "BCA"})
+	source, found, err := parser.DetectSource(xlsxFile, testFingerprints)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "bank_bca.csv", source)
+
+	gzipFile := filepath.Join(dir, "download.csv.gz")
+	writeGzip(t, gzipFile, "ref_no,value,posting_date\nTX001,100.00,2024-01-15\n")
+	source, _, err = parser.DetectSource(gzipFile, testFingerprints)
+	assert.NoError(t, err)
+	assert.Equal(t, "bank_bri.csv", source)
+}
+
+func TestReconciliationService_SourceFromContent(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+	}}
+
+	// A renamed BRI export only parses with the aliases configured for its source
+	dir := t.TempDir()
+	renamed := writeFile(t, dir, "statement (1).csv", "ref_no,value,posting_date\nTX001,100.00,2024-01-15\nTX999,5.00,2024-01-15\n")
+	unknown := writeFile(t, dir, "bank_c.csv", "trx_ref_id,amount,date\nTX998,7.00,2024-01-15\n")
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100,
+		service.WithSourceFingerprints(testFingerprints),
+		service.WithColumnMappings(map[string]parser.ColumnMapping{
+			"bank_bri.csv": {"ref_no": "trx_ref_id", "value": "amount", "posting_date": "date"},
+		}),
+	)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{renamed, unknown},
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.Len(t, summary.UnmatchedBank["bank_bri.csv"], 1, "the renamed file is sourced by its headers")
+	assert.Len(t, summary.UnmatchedBank["bank_c.csv"], 1, "unrecognised files fall back to the file name")
+}
+
+func TestDetectSource_Delimiter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("ref_no;value;posting_date\nBRI001;1,00;2024-01-15\n"), 0644))
+
+	source, found, err := parser.DetectSource(path, testFingerprints, parser.WithDelimiter(';'))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "bank_bri.csv", source)
+}
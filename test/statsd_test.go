@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/metrics"
+)
+
+// statsdSink records each write as one packet
+type statsdSink struct {
+	mu      sync.Mutex
+	packets []string
+}
+
+func (s *statsdSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packets = append(s.packets, string(p))
+	return len(p), nil
+}
+
+func (s *statsdSink) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, p := range s.packets {
+		lines = append(lines, strings.Split(p, "\n")...)
+	}
+	return lines
+}
+
+func TestStatsD_Format(t *testing.T) {
+	sink := &statsdSink{}
+	statsd := metrics.NewStatsD(sink, metrics.WithStatsDPrefix("app."))
+
+	statsd.JobStarted("file")
+	statsd.JobFailed("database")
+	statsd.JobCompleted("file", 1500*time.Millisecond, 10, 3, 42.5)
+
+	assert.Len(t, sink.packets, 3, "one packet per event")
+	assert.Equal(t, []string{
+		"app.jobs.started.file:1|c",
+		"app.jobs.failed.database:1|c",
+		"app.jobs.completed.file:1|c",
+		"app.job.duration.file:1500|ms",
+		"app.job.matched.file:10|g",
+		"app.job.unmatched.file:3|g",
+		"app.job.discrepancy.file:42.5|g",
+	}, sink.lines())
+}
+
+func TestStatsD_Tags(t *testing.T) {
+	sink := &statsdSink{}
+	statsd := metrics.NewStatsD(sink, metrics.WithStatsDTags(true))
+
+	statsd.JobStarted("database")
+	assert.Equal(t, []string{"recon.jobs.started:1|c|#input:database"}, sink.lines())
+}
+
+func TestStatsD_ReconciliationJob(t *testing.T) {
+	sink := &statsdSink{}
+	recorder := metrics.Recorders(metrics.Prometheus, metrics.NewStatsD(sink))
+
+	bankFile := filepath.Join(t.TempDir(), "bank_a.csv")
+	assert.NoError(t, os.WriteFile(bankFile, []byte("trx_ref_id,amount,date\nTX001,100.00,2024-01-15\nTX002,250.00,2024-01-15\n"), 0644))
+
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromFloat(300.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100, service.WithMetricsRecorder(recorder))
+
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	_, err := svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+
+	lines := sink.lines()
+	assert.Contains(t, lines, "recon.jobs.started.file:1|c")
+	assert.Contains(t, lines, "recon.jobs.completed.file:1|c")
+	assert.Contains(t, lines, "recon.job.matched.file:1|g")
+	assert.Contains(t, lines, "recon.job.unmatched.file:1|g")
+	assert.Contains(t, lines, "recon.job.discrepancy.file:50|g")
+
+	// Dry runs are not reported
+	sink.packets = nil
+	_, err = svc.Reconcile(context.Background(), nil, []string{bankFile}, startOfDay, startOfDay, true)
+	assert.NoError(t, err)
+	assert.Empty(t, sink.packets)
+}
+
+func TestStatsD_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	statsd, err := metrics.DialStatsD(conn.LocalAddr().String())
+	assert.NoError(t, err)
+	defer statsd.Close()
+
+	statsd.JobStarted("file")
+
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 512)
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "recon.jobs.started.file:1|c", string(buf[:n]))
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/middleware"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/response"
+)
+
+// slowTransactionRepository answers lookups only once the request's context ends
+type slowTransactionRepository struct {
+	mockTransactionRepository
+}
+
+func (r *slowTransactionRepository) GetByTrxID(ctx context.Context, trxID string) (*domain.Transaction, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeout_SetsDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for name, timeout := range map[string]time.Duration{"enabled": time.Minute, "disabled": 0} {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.Timeout(timeout))
+			var hasDeadline bool
+			router.GET("/ping", func(c *gin.Context) {
+				_, hasDeadline = c.Request.Context().Deadline()
+				c.Status(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, timeout > 0, hasDeadline)
+		})
+	}
+}
+
+func TestTimeout_SlowQueryAnswersGatewayTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewTransactionHandler(service.NewTransactionService(&slowTransactionRepository{}))
+	router := gin.New()
+	router.Use(middleware.Timeout(20 * time.Millisecond))
+	router.GET("/api/v1/transactions/:trx_id", h.GetTransaction)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/transactions/TX001", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	var body response.Response
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "TIMEOUT", body.Error.Code)
+}
+
+func TestReconciliationService_CancelledContextFailsJob(t *testing.T) {
+	reconRepo := newMockReconciliationRepository()
+	svc := newJobLifecycleService(reconRepo)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := svc.ReconcileFromDatabase(ctx, lifecycleDay, lifecycleDay, false)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Len(t, reconRepo.jobs, 1)
+	for _, job := range reconRepo.jobs {
+		assert.Equal(t, domain.Failed, job.Status)
+	}
+	assert.Empty(t, reconRepo.results)
+}
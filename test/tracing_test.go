@@ -0,0 +1,97 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"recon-engine/internal/middleware"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/tracing"
+)
+
+// recordSpans routes spans to an in-memory recorder for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	_, err := tracing.Init(context.Background())
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+func TestTracing_ContinuesIncomingTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Tracing())
+	router.GET("/jobs/:job_id", func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "child")
+		span.End()
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		child, server := spans[0], spans[1]
+		assert.Equal(t, "GET /jobs/:job_id", server.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+		assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	}
+}
+
+func TestReconciliationService_TracesReconcile(t *testing.T) {
+	recorder := recordSpans(t)
+	dir := t.TempDir()
+	systemFile := writeFile(t, dir, "system.csv", `trx_id,amount,type,transaction_time
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z
+`)
+	bankA := writeFile(t, dir, "bank_a.csv", `trx_ref_id,amount,date
+TX001,100.00,2024-01-15
+`)
+	bankB := writeFile(t, dir, "bank_b.csv", `trx_ref_id,amount,date
+TX009,5.00,2024-01-15
+`)
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+
+	ctx, root := tracing.Start(context.Background(), "request")
+	_, err := svc.Reconcile(ctx, []string{systemFile}, []string{bankA, bankB}, lifecycleDay, lifecycleDay, false)
+	root.End()
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Equal(t, []string{
+		"reconcile.load_system_files",
+		"reconcile.load_bank_file",
+		"reconcile.load_bank_file",
+		"reconcile.match",
+		"reconcile.bulk_create_results",
+		"request",
+	}, spanNames(spans))
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+)
+
+func newTransactionRouter(repo *mockTransactionRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewTransactionHandler(service.NewTransactionService(repo))
+	router := gin.New()
+	router.POST("/api/v1/transactions", h.CreateTransaction)
+	router.POST("/api/v1/transactions/bulk", h.BulkCreateTransactions)
+	router.PUT("/api/v1/transactions/:trx_id", h.UpdateTransaction)
+	router.DELETE("/api/v1/transactions/:trx_id", h.DeleteTransaction)
+	return router
+}
+
+func seededTransactions() *mockTransactionRepository {
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return &mockTransactionRepository{transactions: []domain.Transaction{
+		{ID: 1, TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: at},
+		{ID: 2, TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Debit, TransactionTime: at},
+	}}
+}
+
+func TestTransactionHandler_UpdateTransaction(t *testing.T) {
+	repo := seededTransactions()
+	router := newTransactionRouter(repo)
+	put := func(trxID string, body map[string]interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/transactions/"+trxID, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	valid := map[string]interface{}{"amount": 150.25, "type": "DEBIT", "transaction_time": "2024-01-16T09:00:00Z"}
+
+	rec := put("TX001", valid)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	updated, err := repo.GetByTrxID(context.Background(), "TX001")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated.ID)
+	assert.True(t, decimal.NewFromFloat(150.25).Equal(updated.Amount))
+	assert.Equal(t, domain.Debit, updated.Type)
+	assert.Equal(t, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC), updated.TransactionTime)
+
+	assert.Equal(t, http.StatusNotFound, put("TX999", valid).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, put("TX001", map[string]interface{}{"amount": -1, "type": "DEBIT", "transaction_time": "2024-01-16T09:00:00Z"}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, put("TX001", map[string]interface{}{"amount": 1, "type": "TRANSFER", "transaction_time": "2024-01-16T09:00:00Z"}).Code)
+	assert.Equal(t, http.StatusBadRequest, put("TX001", map[string]interface{}{"amount": 1, "type": "DEBIT", "transaction_time": "16/01/2024"}).Code)
+}
+
+func TestTransactionService_UpdateValidates(t *testing.T) {
+	repo := seededTransactions()
+	svc := service.NewTransactionService(repo)
+
+	err := svc.Update(context.Background(), &domain.Transaction{TrxID: "TX001", Amount: decimal.NewFromFloat(-5), Type: domain.Credit, TransactionTime: time.Now()})
+	assert.ErrorIs(t, err, service.ErrInvalidTransaction)
+	unchanged, _ := repo.GetByTrxID(context.Background(), "TX001")
+	assert.True(t, decimal.NewFromFloat(100.00).Equal(unchanged.Amount))
+
+	err = svc.Update(context.Background(), &domain.Transaction{TrxID: "TX999", Amount: decimal.NewFromFloat(5), Type: domain.Credit, TransactionTime: time.Now()})
+	assert.EqualError(t, err, "transaction not found")
+}
+
+func TestTransactionHandler_DeleteTransaction(t *testing.T) {
+	repo := seededTransactions()
+	router := newTransactionRouter(repo)
+	del := func(trxID string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/transactions/"+trxID, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, del("TX002"))
+	_, err := repo.GetByTrxID(context.Background(), "TX002")
+	assert.Error(t, err)
+	assert.Len(t, repo.transactions, 1)
+
+	assert.Equal(t, http.StatusNotFound, del("TX002"))
+}
+
+func TestTransactionHandler_BulkCreateReportsEachRow(t *testing.T) {
+	repo := seededTransactions()
+	router := newTransactionRouter(repo)
+	post := func(rows ...map[string]interface{}) (*httptest.ResponseRecorder, domain.BulkCreateReport) {
+		payload, _ := json.Marshal(map[string]interface{}{"transactions": rows})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/bulk", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var body struct {
+			Data domain.BulkCreateReport `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec, body.Data
+	}
+	row := func(trxID, at string) map[string]interface{} {
+		return map[string]interface{}{"trx_id": trxID, "amount": 10.5, "type": "CREDIT", "transaction_time": at}
+	}
+	at := "2024-01-16T09:00:00Z"
+
+	rec, report := post(row("TX010", at), row("TX001", at), row("TX011", "16/01/2024"), row("TX010", at))
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Equal(t, 1, report.Inserted)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	if assert.Len(t, report.Rows, 4) {
+		for i, want := range []domain.BulkRowStatus{domain.BulkRowInserted, domain.BulkRowDuplicate, domain.BulkRowFailed, domain.BulkRowDuplicate} {
+			assert.Equal(t, i, report.Rows[i].Index)
+			assert.Equal(t, want, report.Rows[i].Status, report.Rows[i].TrxID)
+		}
+		assert.Equal(t, "TX011", report.Rows[2].TrxID)
+		assert.NotEmpty(t, report.Rows[2].Reason)
+	}
+	assert.Len(t, repo.transactions, 3)
+
+	rec, report = post(row("TX012", at))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, 1, report.Inserted)
+}
+
+func TestTransactionHandler_CreateTransactionAmounts(t *testing.T) {
+	repo := &mockTransactionRepository{}
+	router := newTransactionRouter(repo)
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	const rest = `"type":"CREDIT","transaction_time":"2024-01-16T09:00:00Z"`
+
+	for trxID, amount := range map[string]string{
+		"TX010": `"amount":"19.99"`,
+		"TX011": `"amount_minor":1999`,
+		"TX012": `"amount_minor":19990,"amount_scale":3`,
+		"TX013": `"amount":19.99`,
+		"TX014": `"amount":"19.990000"`,
+	} {
+		rec := post(`{"trx_id":"` + trxID + `",` + amount + `,` + rest + `}`)
+		if assert.Equal(t, http.StatusCreated, rec.Code, trxID) {
+			tx, err := repo.GetByTrxID(context.Background(), trxID)
+			assert.NoError(t, err)
+			assert.Equal(t, "19.99", tx.Amount.String(), trxID)
+		}
+	}
+
+	for _, amount := range []string{
+		`"amount":"19.99","amount_minor":1999`,
+		`"amount":"abc"`,
+		`"amount":"0"`,
+		`"amount_minor":1999,"amount_scale":19`,
+		`"amount_minor":1999999,"amount_scale":5`,
+		`"amount":"19.99999"`,
+		`"amount":19.99999`,
+		`"amount":"19.99","amount_scale":2`,
+		`"order_id":"ORD1"`,
+	} {
+		assert.Equal(t, http.StatusUnprocessableEntity, post(`{"trx_id":"TX020",`+amount+`,`+rest+`}`).Code, amount)
+	}
+}
+
+func TestTransactionService_BulkCreateReportsInvalidRows(t *testing.T) {
+	repo := &mockTransactionRepository{}
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	report, err := service.NewTransactionService(repo).BulkCreate(context.Background(), []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(-5), Type: domain.Credit, TransactionTime: at},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(5), Type: domain.Debit, TransactionTime: at},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Inserted)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, "amount must be positive", report.Rows[0].Reason)
+	assert.Equal(t, 1, report.Rows[1].Index)
+	if assert.Len(t, repo.transactions, 1) {
+		assert.Equal(t, "TX002", repo.transactions[0].TrxID)
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+)
+
+func TestReconciliationService_GetUnmatchedBank(t *testing.T) {
+	svc := newJobLifecycleService(newManualMatchRepository(t))
+	ctx := context.Background()
+
+	pages, err := svc.GetUnmatchedBank(ctx, "job-review", "", 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bank_a", "bank_b"}, sortedKeys(pages.Sources), "only sources with unmatched items")
+	assert.Equal(t, 2, pages.Sources["bank_a"].Total)
+	assert.Equal(t, 2, pages.Sources["bank_a"].TotalPages)
+	if assert.Len(t, pages.Sources["bank_a"].Results, 1) {
+		assert.Equal(t, "TX-0002", *pages.Sources["bank_a"].Results[0].TrxRefID)
+	}
+	assert.Equal(t, 1, pages.Sources["bank_b"].Total)
+
+	pages, err = svc.GetUnmatchedBank(ctx, "job-review", "bank_a", 2, 1)
+	assert.NoError(t, err)
+	assert.Len(t, pages.Sources, 1)
+	if assert.Len(t, pages.Sources["bank_a"].Results, 1) {
+		assert.Equal(t, "REF9", *pages.Sources["bank_a"].Results[0].TrxRefID)
+		assert.Equal(t, domain.UnmatchedBank, pages.Sources["bank_a"].Results[0].MatchStatus)
+	}
+
+	pages, err = svc.GetUnmatchedBank(ctx, "job-review", "bank_z", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pages.Sources["bank_z"].Total, "an unknown source has an empty page")
+
+	_, err = svc.GetUnmatchedBank(ctx, "missing", "", 1, 10)
+	assert.Error(t, err)
+}
+
+func TestReconciliationHandler_GetUnmatchedBank(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/reconcile/jobs/:job_id/unmatched-bank", handler.NewReconciliationHandler(newJobLifecycleService(newManualMatchRepository(t))).GetUnmatchedBank)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/v1/reconcile/jobs/job-review/unmatched-bank?source=bank_b")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data domain.UnmatchedBankPages `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, []string{"bank_b"}, sortedKeys(body.Data.Sources))
+	assert.Equal(t, 1, body.Data.Sources["bank_b"].Total)
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/reconcile/jobs/missing/unmatched-bank").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/reconcile/jobs/job-review/unmatched-bank?size=5000").Code)
+}
+
+func sortedKeys(pages map[string]*domain.ResultPage) []string {
+	keys := make([]string, 0, len(pages))
+	for key := range pages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/response"
+)
+
+type uploadPart struct {
+	field, name, contentType, content string
+}
+
+func newUploadRequest(t *testing.T, fields map[string]string, parts []uploadPart) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		assert.NoError(t, w.WriteField(k, v))
+	}
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+p.field+`"; filename="`+p.name+`"`)
+		h.Set("Content-Type", p.contentType)
+		pw, err := w.CreatePart(h)
+		assert.NoError(t, err)
+		_, err = pw.Write([]byte(p.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func newUploadRouter(opts ...handler.HandlerOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+
+	router := gin.New()
+	router.POST("/api/v1/reconcile/upload", handler.NewReconciliationHandler(svc, opts...).ReconcileUpload)
+	return router
+}
+
+var uploadDates = map[string]string{"start_date": "2024-01-15", "end_date": "2024-01-15"}
+
+func TestReconcileUpload(t *testing.T) {
+	before, _ := filepath.Glob(filepath.Join(os.TempDir(), "recon-upload-*"))
+
+	req := newUploadRequest(t, uploadDates, []uploadPart{
+		{"bank_files", "bank_a.csv", "text/csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n"},
+		{"bank_files", "bank_b.csv", "text/csv", "trx_ref_id,amount,date\nTX002,250.00,2024-01-15\nTX999,5.00,2024-01-15\n"},
+	})
+	rec := httptest.NewRecorder()
+	newUploadRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		response.Response
+		Data domain.ReconciliationSummary `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.TotalMatched)
+	assert.Equal(t, 1, len(resp.Data.Discrepancies))
+	assert.Equal(t, 1, len(resp.Data.UnmatchedBank["bank_b.csv"]), "bank source comes from the uploaded file name")
+
+	after, _ := filepath.Glob(filepath.Join(os.TempDir(), "recon-upload-*"))
+	assert.Equal(t, len(before), len(after), "temp upload directory should be removed")
+}
+
+func TestReconcileUpload_MultipleSystemFiles(t *testing.T) {
+	header := "trx_id,amount,type,transaction_time\n"
+	req := newUploadRequest(t, uploadDates, []uploadPart{
+		{"system_file", "system.csv", "text/csv", header + "TX101,10.00,CREDIT,2024-01-15T09:00:00Z\n"},
+		{"system_file", "system.csv", "text/csv", header + "TX102,20.00,CREDIT,2024-01-15T09:00:00Z\n"},
+		{"bank_files", "bank_a.csv", "text/csv", "trx_ref_id,amount,date\nTX101,10.00,2024-01-15\nTX102,20.00,2024-01-15\n"},
+	})
+	rec := httptest.NewRecorder()
+	newUploadRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		response.Response
+		Data domain.ReconciliationSummary `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Data.TotalMatched, "system files with the same name are both loaded")
+	assert.Equal(t, 0, resp.Data.TotalUnmatched)
+}
+
+func TestReconcileUpload_PartialLoad(t *testing.T) {
+	req := newUploadRequest(t, uploadDates, []uploadPart{
+		{"bank_files", "bank_a.csv", "text/csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n"},
+		{"bank_files", "bank_b.csv", "text/csv", "id,value\n1,100\n"},
+	})
+	rec := httptest.NewRecorder()
+	newUploadRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		response.Response
+		Data domain.ReconciliationSummary `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Message, "incomplete")
+	assert.True(t, resp.Data.Incomplete)
+	assert.Equal(t, 1, resp.Data.TotalMatched)
+	if assert.Len(t, resp.Data.FileLoadReport, 2) {
+		assert.Equal(t, "bank_a.csv", resp.Data.FileLoadReport[0].File, "files are reported by their uploaded names")
+		assert.Equal(t, 1, resp.Data.FileLoadReport[0].Rows)
+		assert.Equal(t, "bank_b.csv", resp.Data.FileLoadReport[1].File)
+		assert.NotEmpty(t, resp.Data.FileLoadReport[1].Error)
+	}
+}
+
+func TestReconcileUpload_RejectsContentType(t *testing.T) {
+	req := newUploadRequest(t, uploadDates, []uploadPart{
+		{"bank_files", "bank_a.csv", "image/png", "trx_ref_id,amount,date\n"},
+	})
+	rec := httptest.NewRecorder()
+	newUploadRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestReconcileUpload_RejectsUnknownStrategy(t *testing.T) {
+	fields := map[string]string{"start_date": "2024-01-15", "end_date": "2024-01-15", "strategy": "fuzzy"}
+	req := newUploadRequest(t, fields, []uploadPart{
+		{"bank_files", "bank_a.csv", "text/csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n"},
+	})
+	rec := httptest.NewRecorder()
+	newUploadRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown matching strategy")
+}
+
+func TestReconcileUpload_RequiresBankFiles(t *testing.T) {
+	req := newUploadRequest(t, uploadDates, nil)
+	rec := httptest.NewRecorder()
+	newUploadRouter().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestReconcileUpload_MaxUploadSize(t *testing.T) {
+	req := newUploadRequest(t, uploadDates, []uploadPart{
+		{"bank_files", "bank_a.csv", "text/csv", "trx_ref_id,amount,date\n" + string(bytes.Repeat([]byte("TX001,1.00,2024-01-15\n"), 100))},
+	})
+	rec := httptest.NewRecorder()
+	newUploadRouter(handler.WithMaxUploadSize(512)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
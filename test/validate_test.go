@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/response"
+)
+
+func TestReconciliationService_ValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	systemFile := writeFile(t, dir, "system.csv", `trx_id,amount,type,transaction_time
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z
+TX002,not-a-number,CREDIT,2024-01-16T10:00:00Z
+TX003,300.00,DEBIT,2024-01-17T10:00:00Z
+`)
+	bankFile := writeFile(t, dir, "bank_bri.csv", `ref_no,amount,posting_date
+TX001,100.00,2024-01-14
+TX003,-300.00,2024-01-18
+`)
+	reconRepo := newMockReconciliationRepository()
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, reconRepo, 100,
+		service.WithColumnMappings(map[string]parser.ColumnMapping{
+			"bank_bri.csv": {"ref_no": "trx_ref_id", "posting_date": "date"},
+		}))
+
+	report, err := svc.ValidateFiles(context.Background(), []string{systemFile}, []string{bankFile})
+	assert.NoError(t, err)
+	assert.False(t, report.Valid, "a skipped row fails validation")
+	if !assert.Len(t, report.Files, 2) {
+		return
+	}
+
+	system := report.Files[0]
+	assert.Equal(t, domain.SystemFile, system.Kind)
+	assert.Equal(t, 2, system.Rows)
+	assert.Equal(t, 1, system.SkippedRows)
+	if assert.Len(t, system.RowErrors, 1) {
+		assert.Equal(t, 3, system.RowErrors[0].Line)
+		assert.Contains(t, system.RowErrors[0].Error, "invalid amount")
+	}
+	assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), *system.FirstDate)
+	assert.Equal(t, time.Date(2024, 1, 17, 10, 0, 0, 0, time.UTC), *system.LastDate)
+
+	bank := report.Files[1]
+	assert.Equal(t, domain.BankFile, bank.Kind)
+	assert.Equal(t, "bank_bri.csv", bank.Source)
+	assert.Equal(t, 2, bank.Rows)
+	assert.Equal(t, 0, bank.SkippedRows)
+	assert.Equal(t, map[string]string{"trx_ref_id": "ref_no", "amount": "amount", "date": "posting_date"}, bank.Columns)
+	assert.Equal(t, time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC), *bank.LastDate)
+
+	jobs, _, _ := reconRepo.ListJobs(context.Background(), domain.JobFilter{}, 10, 0)
+	assert.Empty(t, jobs, "validation stores no job")
+}
+
+func TestReconciliationService_PerSourceDateLayouts(t *testing.T) {
+	dir := t.TempDir()
+	usFile := writeFile(t, dir, "bank_us.csv", "trx_ref_id,amount,date\nTX001,100.00,03/04/2024\n")
+	euFile := writeFile(t, dir, "bank_eu.csv", "trx_ref_id,amount,date\nTX002,200.00,03/04/2024\n")
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100,
+		service.WithParserOptions(parser.WithDayFirst(false)),
+		service.WithDateLayouts(map[string]string{
+			"bank_us.csv": "01/02/2006",
+			"bank_eu.csv": "02/01/2006",
+		}))
+
+	report, err := svc.ValidateFiles(context.Background(), nil, []string{usFile, euFile})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Files, 2) {
+		assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), *report.Files[0].FirstDate, "US source reads month first")
+		assert.Equal(t, time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), *report.Files[1].FirstDate, "EU source reads day first")
+	}
+}
+
+func TestReconciliationService_ValidateFilesReportsUnreadableFile(t *testing.T) {
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", "reference,value\nTX001,100.00\n")
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+
+	report, err := svc.ValidateFiles(context.Background(), nil, []string{bankFile, "/missing/bank_b.csv"})
+	assert.NoError(t, err)
+	assert.False(t, report.Valid)
+	if assert.Len(t, report.Files, 2) {
+		assert.Contains(t, report.Files[0].Error, "missing required columns")
+		assert.Contains(t, report.Files[1].Error, "failed to open file")
+	}
+}
+
+func newValidateRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	svc := service.NewReconciliationService(&mockTransactionRepository{}, newMockReconciliationRepository(), 100)
+	router := gin.New()
+	router.POST("/api/v1/reconcile/validate", handler.NewReconciliationHandler(svc).ValidateFiles)
+	return router
+}
+
+func TestReconciliationHandler_ValidateFiles(t *testing.T) {
+	bankFile := writeFile(t, t.TempDir(), "bank_a.csv", "trx_ref_id,amount,date\nTX001,100.00,2024-01-15\n")
+	body := `{"bank_file_paths": ["` + bankFile + `"]}`
+
+	rec := httptest.NewRecorder()
+	newValidateRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/validate", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		response.Response
+		Data domain.ValidationReport `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Valid)
+	if assert.Len(t, resp.Data.Files, 1) {
+		assert.Equal(t, 1, resp.Data.Files[0].Rows)
+	}
+
+	rec = httptest.NewRecorder()
+	newValidateRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconcile/validate", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
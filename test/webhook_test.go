@@ -0,0 +1,204 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/handler"
+	"recon-engine/internal/service"
+	"recon-engine/pkg/webhook"
+)
+
+// callbackServer answers with statuses in turn, then 200, and hands each
+// request body and signature to received
+type callbackServer struct {
+	*httptest.Server
+	calls    int32
+	received chan [2]string
+}
+
+func newCallbackServer(t *testing.T, statuses ...int) *callbackServer {
+	s := &callbackServer{received: make(chan [2]string, 10)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&s.calls, 1))
+		body, _ := io.ReadAll(r.Body)
+		s.received <- [2]string{string(body), r.Header.Get(webhook.SignatureHeader)}
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *callbackServer) next(t *testing.T) (string, string) {
+	t.Helper()
+	select {
+	case got := <-s.received:
+		return got[0], got[1]
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback not received")
+		return "", ""
+	}
+}
+
+func TestWebhookClient_SignsAndRetries(t *testing.T) {
+	server := newCallbackServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	secret := []byte("secret")
+	client := webhook.NewClient(webhook.WithRetries(2, time.Millisecond), webhook.WithSecret(secret))
+
+	assert.NoError(t, client.Post(server.URL, map[string]string{"job_id": "job-1"}))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&server.calls))
+
+	body, signature := server.next(t)
+	assert.JSONEq(t, `{"job_id":"job-1"}`, body)
+	assert.Equal(t, webhook.Sign(secret, []byte(body)), signature)
+}
+
+func TestWebhookClient_GivesUp(t *testing.T) {
+	server := newCallbackServer(t, http.StatusBadRequest)
+	err := webhook.NewClient(webhook.WithRetries(3, time.Millisecond)).Post(server.URL, "payload")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.calls), "client errors are not retried")
+	_, signature := server.next(t)
+	assert.Empty(t, signature, "unsigned without a secret")
+
+	server = newCallbackServer(t, 500, 500, 500)
+	err = webhook.NewClient(webhook.WithRetries(1, time.Millisecond)).Post(server.URL, "payload")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.calls))
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	assert.Error(t, webhook.NewClient(webhook.WithTimeout(20*time.Millisecond)).Post(slow.URL, "payload"))
+}
+
+func newNotifyingService(bankRepo *mockBankStatementRepository) service.ReconciliationService {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: domain.Credit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(200.00), Type: domain.Credit, TransactionTime: day},
+	}}
+	notifier := webhook.NewClient(webhook.WithRetries(1, time.Millisecond), webhook.WithSecret([]byte("secret")))
+	return service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100,
+		service.WithBankStatementRepository(bankRepo), service.WithNotifier(notifier))
+}
+
+func TestReconciliationService_NotifiesCallback(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	server := newCallbackServer(t)
+	svc := newNotifyingService(&mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "bank_a"},
+	}}).ForCallback(server.URL)
+
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	assert.NoError(t, err)
+
+	body, signature := server.next(t)
+	assert.Equal(t, webhook.Sign([]byte("secret"), []byte(body)), signature)
+	var notification domain.JobNotification
+	assert.NoError(t, json.Unmarshal([]byte(body), &notification))
+	assert.Equal(t, summary.JobID, notification.JobID)
+	assert.Equal(t, domain.Completed, notification.Status)
+	assert.Equal(t, 1, notification.TotalMatched)
+	assert.Equal(t, 1, notification.TotalUnmatched)
+
+	// Dry runs have no job to report
+	_, err = svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, true)
+	assert.NoError(t, err)
+	select {
+	case <-server.received:
+		t.Fatal("a dry run must not notify")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReconciliationService_NotifiesFailure(t *testing.T) {
+	server := newCallbackServer(t)
+	svc := newNotifyingService(&mockBankStatementRepository{}).ForCallback(server.URL)
+
+	_, err := svc.Reconcile(context.Background(), nil, []string{"/nonexistent/bank.csv"}, lifecycleDay, lifecycleDay, false)
+	assert.Error(t, err)
+
+	body, _ := server.next(t)
+	var notification domain.JobNotification
+	assert.NoError(t, json.Unmarshal([]byte(body), &notification))
+	assert.Equal(t, domain.Failed, notification.Status)
+	assert.Equal(t, "no bank statements loaded", notification.ErrorMessage)
+}
+
+func TestReconciliationService_UndeliveredCallbackKeepsJob(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	server := newCallbackServer(t, 500, 500)
+	svc := newNotifyingService(&mockBankStatementRepository{statements: []domain.BankStatement{
+		{TrxRefID: "TX001", Amount: decimal.NewFromFloat(100.00), Date: day, Source: "bank_a"},
+	}}).ForCallback(server.URL)
+
+	summary, err := svc.ReconcileFromDatabase(context.Background(), lifecycleDay, lifecycleDay, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	server.next(t)
+	server.next(t)
+
+	job, err := svc.GetJobStatus(context.Background(), summary.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Completed, job.Status)
+}
+
+func TestReconcileHandler_RejectsInvalidCallbackURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(newNotifyingService(&mockBankStatementRepository{})).Reconcile)
+
+	for _, callbackURL := range []string{"not a url", "ftp://example.com/hook", "/relative"} {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"bank_source":  "database",
+			"start_date":   "2024-01-15",
+			"end_date":     "2024-01-15",
+			"callback_url": callbackURL,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, callbackURL)
+	}
+}
+
+func TestReconcileHandler_RejectsInvalidSystemFilePaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/reconcile", handler.NewReconciliationHandler(newNotifyingService(&mockBankStatementRepository{})).Reconcile)
+
+	for name, request := range map[string]map[string]interface{}{
+		"empty":     {"system_file_paths": []string{"a.csv", ""}},
+		"duplicate": {"system_file_path": "a.csv", "system_file_paths": []string{"b.csv", "a.csv"}},
+	} {
+		request["bank_file_paths"] = []string{"bank.csv"}
+		request["start_date"] = "2024-01-15"
+		request["end_date"] = "2024-01-15"
+		payload, _ := json.Marshal(request)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconcile", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, name)
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"recon-engine/internal/domain"
+	"recon-engine/internal/parser"
+	"recon-engine/internal/service"
+)
+
+// writeXLSX builds a minimal workbook whose first sheet holds sheetData
+func writeXLSX(t *testing.T, path, sheetData string, shared []string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var sst strings.Builder
+	sst.WriteString(`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	for _, s := range shared {
+		sst.WriteString("<si><t>" + s + "</t></si>")
+	}
+	sst.WriteString("</sst>")
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Statement" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			sheetData + `</sheetData></worksheet>`,
+		"xl/sharedStrings.xml": sst.String(),
+	}
+
+	zw := zip.NewWriter(f)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+}
+
+func TestXLSXBankStatementParser_Parse(t *testing.T) {
+	xlsxFile := filepath.Join(t.TempDir(), "bank_test.xlsx")
+
+	// Header and IDs use shared strings; amounts and the first date are numeric cells
+	writeXLSX(t, xlsxFile, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>100.5</v></c><c r="C2"><v>45306</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>TX002</t></is></c><c r="B3" t="n"><v>-200.75</v></c><c r="C3" t="s"><v>4</v></c></row>
+<row r="5"><c r="A5" t="s"><v>5</v></c><c r="C5" t="s"><v>4</v></c></row>
+`, []string{"trx_ref_id", "amount", "date", "TX001", "2024-01-16", "TX003"})
+
+	p := parser.NewXLSXBankStatementParser("TestBank")
+	var statements []domain.BankStatement
+	err := p.Parse(xlsxFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(statements), "TX003 has no amount and is skipped")
+
+	assert.Equal(t, "TX001", statements[0].TrxRefID)
+	assert.True(t, statements[0].Amount.Equal(decimal.NewFromFloat(100.50)))
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), statements[0].Date)
+	assert.Equal(t, "TestBank", statements[0].Source)
+
+	assert.Equal(t, "TX002", statements[1].TrxRefID)
+	assert.True(t, statements[1].Amount.Equal(decimal.NewFromFloat(-200.75)))
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), statements[1].Date)
+}
+
+func TestXLSXBankStatementParser_ColumnMapping(t *testing.T) {
+	xlsxFile := filepath.Join(t.TempDir(), "bank_aliases.xlsx")
+
+	writeXLSX(t, xlsxFile, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>1.5E2</v></c><c r="C2"><v>45306.5</v></c></row>
+`, []string{"ref_no", "value", "posting_date", "TX001"})
+
+	mapping := parser.ColumnMapping{"ref_no": "trx_ref_id", "value": "amount", "posting_date": "date"}
+	var statements []domain.BankStatement
+	err := parser.NewXLSXBankStatementParserWithMapping("TestBank", mapping).Parse(xlsxFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statements))
+	assert.True(t, statements[0].Amount.Equal(decimal.NewFromInt(150)))
+	assert.Equal(t, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), statements[0].Date)
+}
+
+func TestXLSXBankStatementParser_DateLayout(t *testing.T) {
+	xlsxFile := filepath.Join(t.TempDir(), "bank_us.xlsx")
+
+	writeXLSX(t, xlsxFile, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>100</v></c><c r="C2" t="s"><v>5</v></c></row>
+<row r="3"><c r="A3" t="s"><v>4</v></c><c r="B3"><v>200</v></c><c r="C3"><v>45306</v></c></row>
+`, []string{"trx_ref_id", "amount", "date", "TX001", "TX002", "03/04/2024"})
+
+	var statements []domain.BankStatement
+	err := parser.NewXLSXBankStatementParser("TestBank", parser.WithDateLayout("01/02/2006")).Parse(xlsxFile, 100, func(batch []domain.BankStatement) error {
+		statements = append(statements, batch...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(statements)) {
+		assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), statements[0].Date)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), statements[1].Date, "serial dates ignore the layout")
+	}
+}
+
+func TestXLSXBankStatementParser_HeaderDetection(t *testing.T) {
+	xlsxFile := filepath.Join(t.TempDir(), "bank_preamble.xlsx")
+
+	writeXLSX(t, xlsxFile, `
+<row r="1"><c r="A1" t="s"><v>0</v></c></row>
+<row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2" t="s"><v>2</v></c></row>
+<row r="3"><c r="A3" t="s"><v>3</v></c><c r="B3" t="s"><v>4</v></c><c r="C3" t="s"><v>5</v></c></row>
+<row r="4"><c r="A4" t="s"><v>6</v></c><c r="B4"><v>100</v></c><c r="C4"><v>45306</v></c></row>
+`, []string{"Account Statement", "Account", "1234567890", "trx_ref_id", "amount", "date", "TX001"})
+
+	for name, opt := range map[string]parser.ParserOption{
+		"skip rows":        parser.WithSkipRows(2),
+		"header detection": parser.WithHeaderDetection(true),
+	} {
+		var statements []domain.BankStatement
+		err := parser.NewXLSXBankStatementParser("TestBank", opt).Parse(xlsxFile, 100, func(batch []domain.BankStatement) error {
+			statements = append(statements, batch...)
+			return nil
+		})
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, 1, len(statements), name)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), statements[0].Date, name)
+	}
+}
+
+func TestXLSXBankStatementParser_EmptySheet(t *testing.T) {
+	xlsxFile := filepath.Join(t.TempDir(), "bank_empty.xlsx")
+	writeXLSX(t, xlsxFile, "", nil)
+
+	err := parser.NewXLSXBankStatementParser("TestBank").Parse(xlsxFile, 100, func(batch []domain.BankStatement) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sheet is empty")
+}
+
+func TestReconciliationService_ReconcilesXLSXBankFile(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{transactions: []domain.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.50), Type: domain.Credit, TransactionTime: day},
+	}}
+
+	xlsxFile := filepath.Join(t.TempDir(), "bank_a.xlsx")
+	writeXLSX(t, xlsxFile, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>100.5</v></c><c r="C2"><v>45306</v></c></row>
+`, []string{"trx_ref_id", "amount", "date", "TX001"})
+
+	svc := service.NewReconciliationService(txRepo, newMockReconciliationRepository(), 100)
+
+	startOfDay := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	summary, err := svc.Reconcile(context.Background(), nil, []string{xlsxFile}, startOfDay, startOfDay, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMatched)
+	assert.Equal(t, 0, summary.TotalUnmatched)
+}